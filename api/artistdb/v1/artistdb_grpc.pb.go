@@ -0,0 +1,960 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: artistdb/v1/artistdb.proto
+
+package artistdbv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ArtistServiceClient is the client API for ArtistService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArtistServiceClient interface {
+	GetArtist(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Artist, error)
+	// UpsertArtist creates or replaces an artist. An ID is assigned if unset.
+	UpsertArtist(ctx context.Context, in *UpsertArtistRequest, opts ...grpc.CallOption) (*Artist, error)
+	DeleteArtist(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListArtists streams all artists ordered by ID.
+	ListArtists(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (ArtistService_ListArtistsClient, error)
+	// WatchArtists streams changes until the client cancels.
+	WatchArtists(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (ArtistService_WatchArtistsClient, error)
+}
+
+type artistServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArtistServiceClient(cc grpc.ClientConnInterface) ArtistServiceClient {
+	return &artistServiceClient{cc}
+}
+
+func (c *artistServiceClient) GetArtist(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Artist, error) {
+	out := new(Artist)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.ArtistService/GetArtist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *artistServiceClient) UpsertArtist(ctx context.Context, in *UpsertArtistRequest, opts ...grpc.CallOption) (*Artist, error) {
+	out := new(Artist)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.ArtistService/UpsertArtist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *artistServiceClient) DeleteArtist(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.ArtistService/DeleteArtist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *artistServiceClient) ListArtists(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (ArtistService_ListArtistsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ArtistService_ServiceDesc.Streams[0], "/artistdb.v1.ArtistService/ListArtists", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &artistServiceListArtistsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ArtistService_ListArtistsClient interface {
+	Recv() (*Artist, error)
+	grpc.ClientStream
+}
+
+type artistServiceListArtistsClient struct {
+	grpc.ClientStream
+}
+
+func (x *artistServiceListArtistsClient) Recv() (*Artist, error) {
+	m := new(Artist)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *artistServiceClient) WatchArtists(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (ArtistService_WatchArtistsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ArtistService_ServiceDesc.Streams[1], "/artistdb.v1.ArtistService/WatchArtists", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &artistServiceWatchArtistsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ArtistService_WatchArtistsClient interface {
+	Recv() (*ArtistChange, error)
+	grpc.ClientStream
+}
+
+type artistServiceWatchArtistsClient struct {
+	grpc.ClientStream
+}
+
+func (x *artistServiceWatchArtistsClient) Recv() (*ArtistChange, error) {
+	m := new(ArtistChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ArtistServiceServer is the server API for ArtistService service.
+// All implementations must embed UnimplementedArtistServiceServer
+// for forward compatibility
+type ArtistServiceServer interface {
+	GetArtist(context.Context, *GetRequest) (*Artist, error)
+	// UpsertArtist creates or replaces an artist. An ID is assigned if unset.
+	UpsertArtist(context.Context, *UpsertArtistRequest) (*Artist, error)
+	DeleteArtist(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	// ListArtists streams all artists ordered by ID.
+	ListArtists(*ListRequest, ArtistService_ListArtistsServer) error
+	// WatchArtists streams changes until the client cancels.
+	WatchArtists(*WatchRequest, ArtistService_WatchArtistsServer) error
+	mustEmbedUnimplementedArtistServiceServer()
+}
+
+// UnimplementedArtistServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedArtistServiceServer struct {
+}
+
+func (UnimplementedArtistServiceServer) GetArtist(context.Context, *GetRequest) (*Artist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArtist not implemented")
+}
+func (UnimplementedArtistServiceServer) UpsertArtist(context.Context, *UpsertArtistRequest) (*Artist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertArtist not implemented")
+}
+func (UnimplementedArtistServiceServer) DeleteArtist(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteArtist not implemented")
+}
+func (UnimplementedArtistServiceServer) ListArtists(*ListRequest, ArtistService_ListArtistsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListArtists not implemented")
+}
+func (UnimplementedArtistServiceServer) WatchArtists(*WatchRequest, ArtistService_WatchArtistsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchArtists not implemented")
+}
+func (UnimplementedArtistServiceServer) mustEmbedUnimplementedArtistServiceServer() {}
+
+// UnsafeArtistServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArtistServiceServer will
+// result in compilation errors.
+type UnsafeArtistServiceServer interface {
+	mustEmbedUnimplementedArtistServiceServer()
+}
+
+func RegisterArtistServiceServer(s grpc.ServiceRegistrar, srv ArtistServiceServer) {
+	s.RegisterService(&ArtistService_ServiceDesc, srv)
+}
+
+func _ArtistService_GetArtist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArtistServiceServer).GetArtist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.ArtistService/GetArtist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArtistServiceServer).GetArtist(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArtistService_UpsertArtist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertArtistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArtistServiceServer).UpsertArtist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.ArtistService/UpsertArtist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArtistServiceServer).UpsertArtist(ctx, req.(*UpsertArtistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArtistService_DeleteArtist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArtistServiceServer).DeleteArtist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.ArtistService/DeleteArtist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArtistServiceServer).DeleteArtist(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArtistService_ListArtists_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArtistServiceServer).ListArtists(m, &artistServiceListArtistsServer{stream})
+}
+
+type ArtistService_ListArtistsServer interface {
+	Send(*Artist) error
+	grpc.ServerStream
+}
+
+type artistServiceListArtistsServer struct {
+	grpc.ServerStream
+}
+
+func (x *artistServiceListArtistsServer) Send(m *Artist) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ArtistService_WatchArtists_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArtistServiceServer).WatchArtists(m, &artistServiceWatchArtistsServer{stream})
+}
+
+type ArtistService_WatchArtistsServer interface {
+	Send(*ArtistChange) error
+	grpc.ServerStream
+}
+
+type artistServiceWatchArtistsServer struct {
+	grpc.ServerStream
+}
+
+func (x *artistServiceWatchArtistsServer) Send(m *ArtistChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ArtistService_ServiceDesc is the grpc.ServiceDesc for ArtistService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ArtistService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "artistdb.v1.ArtistService",
+	HandlerType: (*ArtistServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetArtist",
+			Handler:    _ArtistService_GetArtist_Handler,
+		},
+		{
+			MethodName: "UpsertArtist",
+			Handler:    _ArtistService_UpsertArtist_Handler,
+		},
+		{
+			MethodName: "DeleteArtist",
+			Handler:    _ArtistService_DeleteArtist_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListArtists",
+			Handler:       _ArtistService_ListArtists_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchArtists",
+			Handler:       _ArtistService_WatchArtists_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "artistdb/v1/artistdb.proto",
+}
+
+// LocationServiceClient is the client API for LocationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LocationServiceClient interface {
+	GetLocation(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Location, error)
+	// UpsertLocation creates or replaces a location. An ID is assigned if unset.
+	UpsertLocation(ctx context.Context, in *UpsertLocationRequest, opts ...grpc.CallOption) (*Location, error)
+	DeleteLocation(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListLocations streams all locations ordered by ID.
+	ListLocations(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (LocationService_ListLocationsClient, error)
+	// WatchLocations streams changes until the client cancels.
+	WatchLocations(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (LocationService_WatchLocationsClient, error)
+}
+
+type locationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLocationServiceClient(cc grpc.ClientConnInterface) LocationServiceClient {
+	return &locationServiceClient{cc}
+}
+
+func (c *locationServiceClient) GetLocation(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Location, error) {
+	out := new(Location)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.LocationService/GetLocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locationServiceClient) UpsertLocation(ctx context.Context, in *UpsertLocationRequest, opts ...grpc.CallOption) (*Location, error) {
+	out := new(Location)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.LocationService/UpsertLocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locationServiceClient) DeleteLocation(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.LocationService/DeleteLocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locationServiceClient) ListLocations(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (LocationService_ListLocationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LocationService_ServiceDesc.Streams[0], "/artistdb.v1.LocationService/ListLocations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &locationServiceListLocationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LocationService_ListLocationsClient interface {
+	Recv() (*Location, error)
+	grpc.ClientStream
+}
+
+type locationServiceListLocationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *locationServiceListLocationsClient) Recv() (*Location, error) {
+	m := new(Location)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *locationServiceClient) WatchLocations(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (LocationService_WatchLocationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LocationService_ServiceDesc.Streams[1], "/artistdb.v1.LocationService/WatchLocations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &locationServiceWatchLocationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LocationService_WatchLocationsClient interface {
+	Recv() (*LocationChange, error)
+	grpc.ClientStream
+}
+
+type locationServiceWatchLocationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *locationServiceWatchLocationsClient) Recv() (*LocationChange, error) {
+	m := new(LocationChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LocationServiceServer is the server API for LocationService service.
+// All implementations must embed UnimplementedLocationServiceServer
+// for forward compatibility
+type LocationServiceServer interface {
+	GetLocation(context.Context, *GetRequest) (*Location, error)
+	// UpsertLocation creates or replaces a location. An ID is assigned if unset.
+	UpsertLocation(context.Context, *UpsertLocationRequest) (*Location, error)
+	DeleteLocation(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	// ListLocations streams all locations ordered by ID.
+	ListLocations(*ListRequest, LocationService_ListLocationsServer) error
+	// WatchLocations streams changes until the client cancels.
+	WatchLocations(*WatchRequest, LocationService_WatchLocationsServer) error
+	mustEmbedUnimplementedLocationServiceServer()
+}
+
+// UnimplementedLocationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLocationServiceServer struct {
+}
+
+func (UnimplementedLocationServiceServer) GetLocation(context.Context, *GetRequest) (*Location, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLocation not implemented")
+}
+func (UnimplementedLocationServiceServer) UpsertLocation(context.Context, *UpsertLocationRequest) (*Location, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertLocation not implemented")
+}
+func (UnimplementedLocationServiceServer) DeleteLocation(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLocation not implemented")
+}
+func (UnimplementedLocationServiceServer) ListLocations(*ListRequest, LocationService_ListLocationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListLocations not implemented")
+}
+func (UnimplementedLocationServiceServer) WatchLocations(*WatchRequest, LocationService_WatchLocationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLocations not implemented")
+}
+func (UnimplementedLocationServiceServer) mustEmbedUnimplementedLocationServiceServer() {}
+
+// UnsafeLocationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LocationServiceServer will
+// result in compilation errors.
+type UnsafeLocationServiceServer interface {
+	mustEmbedUnimplementedLocationServiceServer()
+}
+
+func RegisterLocationServiceServer(s grpc.ServiceRegistrar, srv LocationServiceServer) {
+	s.RegisterService(&LocationService_ServiceDesc, srv)
+}
+
+func _LocationService_GetLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocationServiceServer).GetLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.LocationService/GetLocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocationServiceServer).GetLocation(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocationService_UpsertLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocationServiceServer).UpsertLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.LocationService/UpsertLocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocationServiceServer).UpsertLocation(ctx, req.(*UpsertLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocationService_DeleteLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocationServiceServer).DeleteLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.LocationService/DeleteLocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocationServiceServer).DeleteLocation(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocationService_ListLocations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LocationServiceServer).ListLocations(m, &locationServiceListLocationsServer{stream})
+}
+
+type LocationService_ListLocationsServer interface {
+	Send(*Location) error
+	grpc.ServerStream
+}
+
+type locationServiceListLocationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *locationServiceListLocationsServer) Send(m *Location) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LocationService_WatchLocations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LocationServiceServer).WatchLocations(m, &locationServiceWatchLocationsServer{stream})
+}
+
+type LocationService_WatchLocationsServer interface {
+	Send(*LocationChange) error
+	grpc.ServerStream
+}
+
+type locationServiceWatchLocationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *locationServiceWatchLocationsServer) Send(m *LocationChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// LocationService_ServiceDesc is the grpc.ServiceDesc for LocationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LocationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "artistdb.v1.LocationService",
+	HandlerType: (*LocationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLocation",
+			Handler:    _LocationService_GetLocation_Handler,
+		},
+		{
+			MethodName: "UpsertLocation",
+			Handler:    _LocationService_UpsertLocation_Handler,
+		},
+		{
+			MethodName: "DeleteLocation",
+			Handler:    _LocationService_DeleteLocation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListLocations",
+			Handler:       _LocationService_ListLocations_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchLocations",
+			Handler:       _LocationService_WatchLocations_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "artistdb/v1/artistdb.proto",
+}
+
+// EventServiceClient is the client API for EventService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EventServiceClient interface {
+	GetEvent(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Event, error)
+	// UpsertEvent creates or replaces an event including its invitations. An
+	// ID is assigned if unset.
+	UpsertEvent(ctx context.Context, in *UpsertEventRequest, opts ...grpc.CallOption) (*Event, error)
+	DeleteEvent(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListEvents streams all events ordered by ID.
+	ListEvents(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (EventService_ListEventsClient, error)
+	// ListInvitations streams the invitations of an event.
+	ListInvitations(ctx context.Context, in *ListInvitationsRequest, opts ...grpc.CallOption) (EventService_ListInvitationsClient, error)
+	// WatchEvents streams changes until the client cancels.
+	WatchEvents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (EventService_WatchEventsClient, error)
+}
+
+type eventServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventServiceClient(cc grpc.ClientConnInterface) EventServiceClient {
+	return &eventServiceClient{cc}
+}
+
+func (c *eventServiceClient) GetEvent(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.EventService/GetEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UpsertEvent(ctx context.Context, in *UpsertEventRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.EventService/UpsertEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) DeleteEvent(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/artistdb.v1.EventService/DeleteEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ListEvents(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (EventService_ListEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[0], "/artistdb.v1.EventService/ListEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventServiceListEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventService_ListEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type eventServiceListEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventServiceListEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventServiceClient) ListInvitations(ctx context.Context, in *ListInvitationsRequest, opts ...grpc.CallOption) (EventService_ListInvitationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[1], "/artistdb.v1.EventService/ListInvitations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventServiceListInvitationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventService_ListInvitationsClient interface {
+	Recv() (*Invitation, error)
+	grpc.ClientStream
+}
+
+type eventServiceListInvitationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventServiceListInvitationsClient) Recv() (*Invitation, error) {
+	m := new(Invitation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventServiceClient) WatchEvents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (EventService_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[2], "/artistdb.v1.EventService/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventServiceWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventService_WatchEventsClient interface {
+	Recv() (*EventChange, error)
+	grpc.ClientStream
+}
+
+type eventServiceWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventServiceWatchEventsClient) Recv() (*EventChange, error) {
+	m := new(EventChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EventServiceServer is the server API for EventService service.
+// All implementations must embed UnimplementedEventServiceServer
+// for forward compatibility
+type EventServiceServer interface {
+	GetEvent(context.Context, *GetRequest) (*Event, error)
+	// UpsertEvent creates or replaces an event including its invitations. An
+	// ID is assigned if unset.
+	UpsertEvent(context.Context, *UpsertEventRequest) (*Event, error)
+	DeleteEvent(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	// ListEvents streams all events ordered by ID.
+	ListEvents(*ListRequest, EventService_ListEventsServer) error
+	// ListInvitations streams the invitations of an event.
+	ListInvitations(*ListInvitationsRequest, EventService_ListInvitationsServer) error
+	// WatchEvents streams changes until the client cancels.
+	WatchEvents(*WatchRequest, EventService_WatchEventsServer) error
+	mustEmbedUnimplementedEventServiceServer()
+}
+
+// UnimplementedEventServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedEventServiceServer struct {
+}
+
+func (UnimplementedEventServiceServer) GetEvent(context.Context, *GetRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvent not implemented")
+}
+func (UnimplementedEventServiceServer) UpsertEvent(context.Context, *UpsertEventRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertEvent not implemented")
+}
+func (UnimplementedEventServiceServer) DeleteEvent(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteEvent not implemented")
+}
+func (UnimplementedEventServiceServer) ListEvents(*ListRequest, EventService_ListEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
+}
+func (UnimplementedEventServiceServer) ListInvitations(*ListInvitationsRequest, EventService_ListInvitationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListInvitations not implemented")
+}
+func (UnimplementedEventServiceServer) WatchEvents(*WatchRequest, EventService_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
+
+// UnsafeEventServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventServiceServer will
+// result in compilation errors.
+type UnsafeEventServiceServer interface {
+	mustEmbedUnimplementedEventServiceServer()
+}
+
+func RegisterEventServiceServer(s grpc.ServiceRegistrar, srv EventServiceServer) {
+	s.RegisterService(&EventService_ServiceDesc, srv)
+}
+
+func _EventService_GetEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.EventService/GetEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetEvent(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UpsertEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UpsertEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.EventService/UpsertEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UpsertEvent(ctx, req.(*UpsertEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_DeleteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).DeleteEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/artistdb.v1.EventService/DeleteEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).DeleteEvent(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ListEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).ListEvents(m, &eventServiceListEventsServer{stream})
+}
+
+type EventService_ListEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type eventServiceListEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventServiceListEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EventService_ListInvitations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListInvitationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).ListInvitations(m, &eventServiceListInvitationsServer{stream})
+}
+
+type EventService_ListInvitationsServer interface {
+	Send(*Invitation) error
+	grpc.ServerStream
+}
+
+type eventServiceListInvitationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventServiceListInvitationsServer) Send(m *Invitation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EventService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).WatchEvents(m, &eventServiceWatchEventsServer{stream})
+}
+
+type EventService_WatchEventsServer interface {
+	Send(*EventChange) error
+	grpc.ServerStream
+}
+
+type eventServiceWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventServiceWatchEventsServer) Send(m *EventChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EventService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "artistdb.v1.EventService",
+	HandlerType: (*EventServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetEvent",
+			Handler:    _EventService_GetEvent_Handler,
+		},
+		{
+			MethodName: "UpsertEvent",
+			Handler:    _EventService_UpsertEvent_Handler,
+		},
+		{
+			MethodName: "DeleteEvent",
+			Handler:    _EventService_DeleteEvent_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListEvents",
+			Handler:       _EventService_ListEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListInvitations",
+			Handler:       _EventService_ListInvitations_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _EventService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "artistdb/v1/artistdb.proto",
+}
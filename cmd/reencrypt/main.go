@@ -0,0 +1,75 @@
+// Command reencrypt rewrites the encrypted columns of all artists after a key
+// rotation or a change of ADB_ENCRYPTION_COLUMNS: values are encrypted with
+// the current key, and columns which are no longer configured are decrypted.
+// Webhook signing secrets are encrypted with the current key as well.
+// It reads the same configuration as the API and is safe to run while the
+// API is serving.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/config"
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/encryption"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const batchSize = 100
+
+func main() {
+	cfg := config.New()
+
+	logger, err := observability.NewLogger(cfg.LoggingMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer logger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	enc, err := encryption.NewFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatal("setting up encryption failed", zap.Error(err))
+	}
+
+	// Decrypting everything still needs the keys, with no columns set.
+	if enc == nil {
+		logger.Fatal("no key provider configured")
+	}
+
+	db, err := database.NewDatabase(ctx, cfg.DbConnectionString,
+		database.WithLogger(logger),
+		database.WithEncryption(enc, cfg.Encryption.Columns...),
+	)
+	if err != nil {
+		logger.Fatal("setting up database connection failed", zap.Error(err))
+	}
+
+	defer db.Close()
+
+	if err := db.CreateTables(cfg.DbConnectionString); err != nil {
+		logger.Fatal("creating tables failed", zap.Error(err))
+	}
+
+	n, err := db.ArtistHandler.Reencrypt(ctx, batchSize)
+	if err != nil {
+		logger.Fatal("re-encryption failed", zap.Error(err), zap.Int("count", n))
+	}
+
+	logger.Info("re-encryption finished", zap.Int("count", n), zap.Strings("columns", cfg.Encryption.Columns))
+
+	n, err = db.WebhookHandler.ReencryptSecrets(ctx)
+	if err != nil {
+		logger.Fatal("re-encrypting webhook secrets failed", zap.Error(err))
+	}
+
+	logger.Info("webhook secrets re-encrypted", zap.Int("count", n))
+}
@@ -0,0 +1,48 @@
+// Writes the hashes of all GraphQL documents of the frontend to a JSON file,
+// which the API accepts as allowlist (ADB_GRAPHQL_ALLOWLIST_FILE).
+//
+// Usage: node scripts/persisted-queries.js [srcDir] [outFile]
+//
+// Documents are hashed as Apollo sends them, i.e. with __typename added and
+// printed by graphql-js.
+const crypto = require('crypto');
+const fs = require('fs');
+const path = require('path');
+const { parse, print } = require('graphql');
+const { addTypenameToDocument } = require('@apollo/client/utilities');
+
+const srcDir = process.argv[2] || 'src';
+const outFile = process.argv[3] || path.join('dist', 'persisted-queries.json');
+
+function sourceFiles(dir) {
+  return fs.readdirSync(dir, { withFileTypes: true }).flatMap((entry) => {
+    const p = path.join(dir, entry.name);
+    if (entry.isDirectory()) {
+      return sourceFiles(p);
+    }
+
+    return entry.name.endsWith('.ts') && !entry.name.endsWith('.spec.ts') ? [p] : [];
+  });
+}
+
+const queries = {};
+
+for (const file of sourceFiles(srcDir)) {
+  const source = fs.readFileSync(file, 'utf8');
+
+  for (const match of source.matchAll(/gql`([^`]*)`/g)) {
+    if (match[1].includes('${')) {
+      throw new Error(`${file}: interpolated documents are not supported`);
+    }
+
+    const query = print(addTypenameToDocument(parse(match[1])));
+    const hash = crypto.createHash('sha256').update(query).digest('hex');
+
+    queries[hash] = query;
+  }
+}
+
+fs.mkdirSync(path.dirname(outFile), { recursive: true });
+fs.writeFileSync(outFile, JSON.stringify(queries, null, 2) + '\n');
+
+console.log(`wrote ${Object.keys(queries).length} queries to ${outFile}`);
@@ -5,6 +5,7 @@ import { HttpClientModule } from '@angular/common/http';
 import { APOLLO_OPTIONS } from 'apollo-angular';
 import { HttpLink } from 'apollo-angular/http';
 import { ApolloClientOptions, InMemoryCache } from '@apollo/client/core';
+import { createPersistedQueryLink } from '@apollo/client/link/persisted-queries';
 
 import { AppRoutingModule } from './app-routing.module';
 import { AppComponent } from './app.component';
@@ -25,9 +26,17 @@ const appInitializerFn = (config: AppConfigService) => {
   };
 };
 
+// sha256 hashes queries for automatic persisted queries, so the API can be
+// sent hashes instead of whole queries.
+const sha256 = async (query: string): Promise<string> => {
+  const digest = await crypto.subtle.digest('SHA-256', new TextEncoder().encode(query));
+  return Array.from(new Uint8Array(digest)).map(b => b.toString(16).padStart(2, '0')).join('');
+};
+
 const apolloInitializerFn = (httpLink: HttpLink, appConfigService: AppConfigService): ApolloClientOptions<any> => {
   return {
-    link: httpLink.create({uri: appConfigService.getConfig().apiUri}),
+    link: createPersistedQueryLink({sha256, useGETForHashedQueries: true})
+      .concat(httpLink.create({uri: appConfigService.getConfig().apiUri})),
     cache: new InMemoryCache(),
   };
 }
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+// batchMode converts the mode of a batch, which defaults to partial.
+func batchMode(mode *model.BatchMode) core.BatchMode {
+	if mode != nil && *mode == model.BatchModeAtomic {
+		return core.BatchAtomic
+	}
+
+	return core.BatchPartial
+}
+
+// itemError converts the error of a single item of a batch. Its code and
+// field are the same as those of the error of a whole operation.
+func (r *Resolver) itemError(ctx context.Context, err error) *model.ItemError {
+	gqlErr := r.presenter(ctx, err)
+
+	out := &model.ItemError{Message: gqlErr.Message}
+
+	if code, ok := gqlErr.Extensions["code"].(string); ok {
+		out.Code = code
+	}
+
+	if field, ok := gqlErr.Extensions["field"].(string); ok {
+		out.Field = &field
+	}
+
+	if traceID, ok := gqlErr.Extensions["traceID"].(string); ok {
+		out.TraceID = &traceID
+	}
+
+	return out
+}
@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/report"
+)
+
+// databaseBudgetLines converts BudgetLineInputs as defined in the GraphQL
+// models to BudgetLines defined in the database.
+func databaseBudgetLines(input ...*model.BudgetLineInput) []*event.BudgetLine {
+	lines := make([]*event.BudgetLine, 0, len(input))
+
+	for _, in := range input {
+		l := &event.BudgetLine{
+			ID:          conversion.String(in.ID),
+			EventID:     in.EventID,
+			Category:    event.BudgetCategory(strings.ToLower(in.Category.String())),
+			Description: conversion.String(in.Description),
+			Planned:     int64(in.Planned),
+			Currency:    strings.ToUpper(in.Currency),
+		}
+
+		if in.Actual != nil {
+			actual := int64(*in.Actual)
+			l.Actual = &actual
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines
+}
+
+func modelBudgetLine(l event.BudgetLine) *model.BudgetLine {
+	ml := &model.BudgetLine{
+		ID:       l.ID,
+		Category: model.BudgetCategory(strings.ToUpper(string(l.Category))),
+		Planned:  int(l.Planned),
+		Currency: l.Currency,
+	}
+
+	if l.Description != "" {
+		description := l.Description
+		ml.Description = &description
+	}
+
+	if l.Actual != nil {
+		actual := int(*l.Actual)
+		ml.Actual = &actual
+	}
+
+	return ml
+}
+
+func modelCost(c event.Cost) *model.Cost {
+	return &model.Cost{
+		Category: model.BudgetCategory(strings.ToUpper(string(c.Category))),
+		Currency: c.Currency,
+		Planned:  int(c.Planned),
+		Actual:   int(c.Actual),
+		Variance: int(c.Variance()),
+	}
+}
+
+// budget returns the budgets of the given events in order, or of all events
+// with costs. Budget lines are not converted.
+func (r *Resolver) budget(ctx context.Context, eventIDs []string, conv Conversion) ([]*model.EventBudget, error) {
+	costs, err := r.db.EventHandler.Costs(ctx, eventIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving costs: %w", err)
+	}
+
+	if costs, err = conv.costs(costs); err != nil {
+		return nil, err
+	}
+
+	lines, err := r.db.EventHandler.BudgetLines(ctx, eventIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving budget lines: %w", err)
+	}
+
+	if len(eventIDs) == 0 {
+		for _, c := range costs {
+			if n := len(eventIDs); n == 0 || eventIDs[n-1] != c.EventID {
+				eventIDs = append(eventIDs, c.EventID)
+			}
+		}
+	}
+
+	var (
+		budgets = make([]*model.EventBudget, 0, len(eventIDs))
+		byID    = make(map[string]*model.EventBudget)
+		events  = make(map[string]*model.Event)
+	)
+
+	for _, id := range eventIDs {
+		if _, ok := byID[id]; ok {
+			continue
+		}
+
+		ev, err := r.eventByID(ctx, events, id)
+		if err != nil {
+			return nil, err
+		}
+
+		b := &model.EventBudget{
+			Event: ev,
+			Lines: []*model.BudgetLine{},
+			Costs: []*model.Cost{},
+		}
+
+		byID[id] = b
+		budgets = append(budgets, b)
+	}
+
+	for _, l := range lines {
+		byID[l.EventID].Lines = append(byID[l.EventID].Lines, modelBudgetLine(l))
+	}
+
+	for _, c := range costs {
+		byID[c.EventID].Costs = append(byID[c.EventID].Costs, modelCost(c))
+	}
+
+	return budgets, nil
+}
+
+// WriteBudgetCSV writes the costs of the given events, or of all events with
+// costs, as CSV.
+func WriteBudgetCSV(ctx context.Context, db *database.Database, w io.Writer, conv Conversion, eventIDs ...string) error {
+	costs, err := db.EventHandler.Costs(ctx, eventIDs...)
+	if err != nil {
+		return fmt.Errorf("retrieving costs: %w", err)
+	}
+
+	if costs, err = conv.costs(costs); err != nil {
+		return err
+	}
+
+	var (
+		rows  = make([]report.Row, 0, len(costs))
+		names = make(map[string]string)
+	)
+
+	for _, c := range costs {
+		name, ok := names[c.EventID]
+		if !ok {
+			events, err := db.EventHandler.Get(ctx, event.ByID(c.EventID))
+			if err != nil {
+				return fmt.Errorf("fetching event %q: %w", c.EventID, err)
+			}
+
+			name = events[0].Name
+			names[c.EventID] = name
+		}
+
+		rows = append(rows, report.Row{
+			EventID:  c.EventID,
+			Event:    name,
+			Category: string(c.Category),
+			Currency: c.Currency,
+			Planned:  c.Planned,
+			Actual:   c.Actual,
+		})
+	}
+
+	return report.WriteCSV(w, rows)
+}
@@ -0,0 +1,16 @@
+package graph
+
+import (
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+// modelChangeAction converts a change action from the database to the
+// ChangeAction defined in the GraphQL model.
+func modelChangeAction(action string) model.ChangeAction {
+	if action == core.ActionDelete {
+		return model.ChangeActionDelete
+	}
+
+	return model.ChangeActionUpsert
+}
@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/location"
+)
+
+// extensionWarnings is the key of the response extension holding warnings.
+const extensionWarnings = "warnings"
+
+// ConflictExtensions describes conflicts in a form which can be added to the
+// extensions of a GraphQL response or error.
+func ConflictExtensions(conflicts ...event.Conflict) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(conflicts))
+
+	for _, c := range conflicts {
+		ext := map[string]interface{}{
+			"kind":       strings.ToUpper(string(c.Kind)),
+			"resourceID": c.ResourceID,
+			"eventIDs":   []string{c.EventID, c.OtherEventID},
+			"start":      c.Start.Format(time.RFC3339),
+			"end":        c.End.Format(time.RFC3339),
+			"message":    c.String(),
+		}
+
+		if c.Stage != "" {
+			ext["stage"] = c.Stage
+		}
+
+		if c.SlotID != "" {
+			ext["slotIDs"] = []string{c.SlotID, c.OtherSlotID}
+		}
+
+		out = append(out, ext)
+	}
+
+	return out
+}
+
+// addConflictWarnings adds conflicts to the warnings of the response.
+// Mutations run serially, so no locking is needed.
+func addConflictWarnings(ctx context.Context, conflicts ...event.Conflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	warnings, ok := graphql.GetExtension(ctx, extensionWarnings).(*[]map[string]interface{})
+	if !ok {
+		warnings = &[]map[string]interface{}{}
+		graphql.RegisterExtension(ctx, extensionWarnings, warnings)
+	}
+
+	*warnings = append(*warnings, ConflictExtensions(conflicts...)...)
+}
+
+// modelConflicts takes Conflicts returned from the database and converts them
+// to Conflicts defined in the GraphQL model.
+func (r *Resolver) modelConflicts(ctx context.Context, conflicts ...event.Conflict) ([]*model.Conflict, error) {
+	var (
+		out    []*model.Conflict
+		events = make(map[string]*model.Event)
+	)
+
+	for _, c := range conflicts {
+		mc := &model.Conflict{
+			Kind:  model.ConflictKind(strings.ToUpper(string(c.Kind))),
+			Start: c.Start,
+			End:   c.End,
+		}
+
+		for _, id := range []string{c.EventID, c.OtherEventID} {
+			ev, err := r.eventByID(ctx, events, id)
+			if err != nil {
+				return nil, err
+			}
+
+			mc.Events = append(mc.Events, ev)
+		}
+
+		// The overlap is returned in the time zone of the first event.
+		if start := mc.Events[0].Start; start != nil {
+			mc.Start = c.Start.In(start.Location())
+			mc.End = c.End.In(start.Location())
+		}
+
+		if c.SlotID != "" {
+			for _, ev := range mc.Events {
+				for _, slot := range ev.Slots {
+					if slot.ID == c.SlotID || slot.ID == c.OtherSlotID {
+						mc.Slots = append(mc.Slots, slot)
+					}
+				}
+
+				// Both slots belong to the same event.
+				if len(mc.Slots) > 0 {
+					break
+				}
+			}
+		}
+
+		switch c.Kind {
+		case event.ConflictArtist:
+			dbArtists, err := r.db.ArtistHandler.Get(ctx, artist.ByID(c.ResourceID))
+			if err != nil {
+				return nil, fmt.Errorf("fetching artist %q: %w", c.ResourceID, err)
+			}
+
+			artists, err := modelArtists(dbArtists...)
+			if err != nil {
+				return nil, fmt.Errorf("converting artist: %w", err)
+			}
+
+			mc.Artist = artists[0]
+		case event.ConflictLocation:
+			dbLocs, err := r.db.LocationHandler.Get(ctx, location.ByID(c.ResourceID))
+			if err != nil {
+				return nil, fmt.Errorf("fetching location %q: %w", c.ResourceID, err)
+			}
+
+			locs, err := modelLocations(dbLocs...)
+			if err != nil {
+				return nil, fmt.Errorf("converting location: %w", err)
+			}
+
+			mc.Location = locs[0]
+		case event.ConflictStage:
+			stage := c.Stage
+			mc.Stage = &stage
+		}
+
+		out = append(out, mc)
+	}
+
+	return out, nil
+}
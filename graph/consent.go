@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/artist"
+)
+
+// databaseConsent converts a ConsentInput as defined in the GraphQL models to
+// a Consent defined in the database.
+func databaseConsent(in model.ConsentInput) *artist.Consent {
+	c := &artist.Consent{
+		ArtistID: in.ArtistID,
+		Purpose:  artist.ConsentPurpose(strings.ToLower(in.Purpose.String())),
+		Granted:  in.Granted,
+		Method:   artist.ConsentMethod(strings.ToLower(in.Method.String())),
+		Note:     conversion.String(in.Note),
+	}
+
+	if in.RecordedAt != nil {
+		c.RecordedAt = *in.RecordedAt
+	}
+
+	return c
+}
+
+func modelConsent(c artist.Consent) *model.Consent {
+	mc := &model.Consent{
+		ID:         c.ID,
+		Purpose:    model.ConsentPurpose(strings.ToUpper(string(c.Purpose))),
+		Granted:    c.Granted,
+		Method:     model.ConsentMethod(strings.ToUpper(string(c.Method))),
+		RecordedAt: c.RecordedAt,
+	}
+
+	if c.Note != "" {
+		note := c.Note
+		mc.Note = &note
+	}
+
+	return mc
+}
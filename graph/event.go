@@ -30,19 +30,75 @@ func databaseEvents(events ...*model.EventInput) ([]*event.Event, error) {
 
 		var opts []event.Option
 
-		if ev.StartTime != nil {
+		switch {
+		case ev.Start != nil && ev.StartTime != nil:
+			return nil, errors.New("start and startTime are mutually exclusive")
+		case ev.Start != nil:
+			opts = append(opts, event.WithStartTime(*ev.Start))
+		case ev.StartTime != nil:
 			opts = append(opts, event.WithStartTime(time.Unix(int64(*ev.StartTime), 0).UTC()))
 		}
 
+		if ev.End != nil {
+			opts = append(opts, event.WithEndTime(*ev.End))
+		}
+
+		if ev.Timezone != nil {
+			opts = append(opts, event.WithTimezone(*ev.Timezone))
+		}
+
 		if ev.LocationID != nil {
 			opts = append(opts, event.WithLocationID(*ev.LocationID))
 		}
 
-		for _, ia := range ev.InvitedArtists {
-			opts = append(opts, event.WithInvitedArtists(event.InvitedArtist{
-				ID:        ia.ID,
-				Confirmed: ia.Confirmed,
-			}))
+		// Unset lists keep the stored invitations and slots.
+		if ev.InvitedArtists != nil {
+			invited := make([]event.InvitedArtist, 0, len(ev.InvitedArtists))
+			for _, ia := range ev.InvitedArtists {
+				if ia == nil {
+					continue
+				}
+
+				invited = append(invited, event.InvitedArtist{
+					ID:        ia.ID,
+					Confirmed: ia.Confirmed,
+					Fee:       databaseFee(ia.Fee),
+				})
+			}
+
+			opts = append(opts, event.WithInvitedArtists(invited...))
+		}
+
+		if ev.Slots != nil {
+			slots := make([]event.Slot, 0, len(ev.Slots))
+			for _, slot := range ev.Slots {
+				slots = append(slots, event.Slot{
+					ID:        conversion.String(slot.ID),
+					ArtistID:  slot.ArtistID,
+					StartTime: slot.Start,
+					EndTime:   slot.End,
+					Stage:     conversion.String(slot.Stage),
+				})
+			}
+
+			opts = append(opts, event.WithSlots(slots...))
+		}
+
+		if ev.Recurrence != nil {
+			var exceptions []time.Time
+			for _, ex := range ev.Exceptions {
+				exceptions = append(exceptions, *ex)
+			}
+
+			opts = append(opts, event.WithRecurrence(*ev.Recurrence, exceptions...))
+		} else if len(ev.Exceptions) > 0 {
+			return nil, errors.New("exceptions require a recurrence")
+		}
+
+		if conversion.Bool(ev.Published) {
+			opts = append(opts, event.WithPublication(ev.PublishFrom, ev.PublishUntil))
+		} else if ev.PublishFrom != nil || ev.PublishUntil != nil {
+			return nil, errors.New("publication window requires published")
 		}
 
 		dbEv, err := event.New(ev.Name, opts...)
@@ -66,7 +122,7 @@ func databaseEvents(events ...*model.EventInput) ([]*event.Event, error) {
 
 // modelEvents takes Events returned from the database and converts them to
 // Events defined in the GraphQL model.
-func (r *queryResolver) modelEvents(ctx context.Context, events ...*event.Event) ([]*model.Event, error) {
+func (r *Resolver) modelEvents(ctx context.Context, events ...*event.Event) ([]*model.Event, error) {
 	var out []*model.Event
 
 	for _, ev := range events {
@@ -85,7 +141,11 @@ func (r *queryResolver) modelEvents(ctx context.Context, events ...*event.Event)
 			loc = l[0]
 		}
 
-		var artists []*model.InvitedArtist
+		var (
+			artists []*model.InvitedArtist
+			byID    = make(map[string]*model.Artist)
+		)
+
 		for _, a := range ev.InvitedArtists {
 			dbArtists, err := r.db.ArtistHandler.Get(ctx, artist.ByID(a.ID))
 			if err != nil {
@@ -98,23 +158,151 @@ func (r *queryResolver) modelEvents(ctx context.Context, events ...*event.Event)
 			}
 
 			for _, ca := range convertedArtists {
+				byID[ca.ID] = ca
 				artists = append(artists, &model.InvitedArtist{
 					Artist:    ca,
 					Confirmed: a.Confirmed,
+					Fee:       modelFee(a.Fee),
 				})
 			}
 
 		}
 
-		t := int(conversion.Time(ev.StartTime).Unix())
-		out = append(out, &model.Event{
-			ID:        ev.ID,
-			Name:      ev.Name,
-			StartTime: &t,
-			Location:  loc,
-			Artists:   artists,
-		})
+		tz := ev.Location()
+
+		var slots []*model.Slot
+		for _, slot := range ev.Slots {
+			a, ok := byID[slot.ArtistID]
+			if !ok {
+				return nil, fmt.Errorf("artist %q of slot %q not invited", slot.ArtistID, slot.ID)
+			}
+
+			ms := &model.Slot{
+				ID:     slot.ID,
+				Artist: a,
+				Start:  slot.StartTime.In(tz),
+				End:    slot.EndTime.In(tz),
+			}
+
+			if slot.Stage != "" {
+				stage := slot.Stage
+				ms.Stage = &stage
+			}
+
+			slots = append(slots, ms)
+		}
+
+		me := &model.Event{
+			ID:       ev.ID,
+			Name:     ev.Name,
+			Location: loc,
+			Artists:  artists,
+			Slots:    slots,
+		}
+
+		if ev.StartTime != nil {
+			start := ev.StartTime.In(tz)
+			unix := int(start.Unix())
+
+			me.Start = &start
+			me.StartTime = &unix
+		}
+
+		if ev.EndTime != nil {
+			end := ev.EndTime.In(tz)
+			me.End = &end
+		}
+
+		if ev.Timezone != "" {
+			me.Timezone = &ev.Timezone
+		}
+
+		if ev.Recurrence != nil {
+			rule := ev.Recurrence.String()
+			me.Recurrence = &rule
+		}
+
+		for _, ex := range ev.Exceptions {
+			t := ex.In(tz)
+			me.Exceptions = append(me.Exceptions, &t)
+		}
+
+		me.Published = ev.Published
+
+		if ev.PublishFrom != nil {
+			from := ev.PublishFrom.In(tz)
+			me.PublishFrom = &from
+		}
+
+		if ev.PublishUntil != nil {
+			until := ev.PublishUntil.In(tz)
+			me.PublishUntil = &until
+		}
+
+		out = append(out, me)
+	}
+
+	return out, nil
+}
+
+// modelOccurrences takes Occurrences returned from the database and converts
+// them to Occurrences defined in the GraphQL model. Times are returned in the
+// time zone of their event.
+func (r *Resolver) modelOccurrences(ctx context.Context, occurrences ...event.Occurrence) ([]*model.Occurrence, error) {
+	var (
+		out    []*model.Occurrence
+		events = make(map[string]*model.Event)
+	)
+
+	for _, occ := range occurrences {
+		ev, err := r.eventByID(ctx, events, occ.EventID)
+		if err != nil {
+			return nil, err
+		}
+
+		tz := time.UTC
+		if ev.Timezone != nil {
+			if tz, err = time.LoadLocation(*ev.Timezone); err != nil {
+				return nil, fmt.Errorf("loading timezone: %w", err)
+			}
+		}
+
+		mo := &model.Occurrence{
+			Event:        ev,
+			RecurrenceID: occ.RecurrenceID.In(tz),
+			Name:         occ.Name,
+			Start:        occ.StartTime.In(tz),
+			Overridden:   occ.Overridden,
+		}
+
+		if occ.EndTime != nil {
+			end := occ.EndTime.In(tz)
+			mo.End = &end
+		}
+
+		out = append(out, mo)
 	}
 
 	return out, nil
 }
+
+// eventByID returns an Event by ID. Results are cached in seen.
+func (r *Resolver) eventByID(ctx context.Context, seen map[string]*model.Event, id string) (*model.Event, error) {
+	if ev, ok := seen[id]; ok {
+		return ev, nil
+	}
+
+	dbEvents, err := r.db.EventHandler.Get(ctx, event.ByID(id))
+	if err != nil {
+		return nil, fmt.Errorf("fetching event %q: %w", id, err)
+	}
+
+	events, err := r.modelEvents(ctx, dbEvents...)
+	if err != nil {
+		return nil, fmt.Errorf("converting event: %w", err)
+	}
+
+	seen[id] = events[0]
+
+	return events[0], nil
+}
@@ -0,0 +1,153 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/event"
+)
+
+// databaseFee converts a FeeInput as defined in the GraphQL models to a Fee
+// defined in the database.
+func databaseFee(in *model.FeeInput) *event.Fee {
+	if in == nil {
+		return nil
+	}
+
+	fee := &event.Fee{
+		Amount:       int64(in.Amount),
+		Currency:     strings.ToUpper(in.Currency),
+		DueDate:      in.DueDate,
+		PaidAt:       in.PaidAt,
+		ContractRefs: in.ContractRefs,
+	}
+
+	if in.Type != nil {
+		fee.Type = event.FeeType(strings.ToLower(in.Type.String()))
+	}
+
+	if in.Days != nil {
+		fee.Days = *in.Days
+	}
+
+	if in.TravelAllowance != nil {
+		fee.TravelAllowance = int64(*in.TravelAllowance)
+	}
+
+	if in.AccommodationAllowance != nil {
+		fee.AccommodationAllowance = int64(*in.AccommodationAllowance)
+	}
+
+	if in.Status != nil {
+		fee.Status = event.PaymentStatus(strings.ToLower(in.Status.String()))
+	}
+
+	return fee
+}
+
+// modelFee converts a Fee from the database to a Fee defined in the GraphQL
+// model.
+func modelFee(fee *event.Fee) *model.Fee {
+	if fee == nil {
+		return nil
+	}
+
+	return &model.Fee{
+		Amount:                 int(fee.Amount),
+		Currency:               fee.Currency,
+		Type:                   model.FeeType(strings.ToUpper(string(fee.Type))),
+		Days:                   fee.Days,
+		TravelAllowance:        int(fee.TravelAllowance),
+		AccommodationAllowance: int(fee.AccommodationAllowance),
+		Total:                  int(fee.Total()),
+		Status:                 model.PaymentStatus(strings.ToUpper(string(fee.Status))),
+		DueDate:                fee.DueDate,
+		PaidAt:                 fee.PaidAt,
+		ContractRefs:           fee.ContractRefs,
+	}
+}
+
+func modelFeeTotal(t event.FeeTotal) *model.FeeTotal {
+	return &model.FeeTotal{
+		Currency:    t.Currency,
+		Fees:        int(t.Fees),
+		Allowances:  int(t.Allowances),
+		Total:       int(t.Total()),
+		Paid:        int(t.Paid),
+		Outstanding: int(t.Outstanding),
+		Overdue:     int(t.Overdue),
+	}
+}
+
+// feeReport collects the fee totals per event and per artist.
+func (r *Resolver) feeReport(ctx context.Context, filter event.FeeFilter, conv Conversion) (*model.FeeReport, error) {
+	report := &model.FeeReport{
+		Events:  []*model.EventFeeTotals{},
+		Artists: []*model.ArtistFeeTotals{},
+	}
+
+	byEvent, err := r.db.EventHandler.FeeTotalsByEvent(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving event totals: %w", err)
+	}
+
+	if byEvent, err = conv.feeTotals(byEvent); err != nil {
+		return nil, err
+	}
+
+	events := make(map[string]*model.Event)
+
+	// Totals are ordered by ID, so totals of the same event are adjacent.
+	for _, t := range byEvent {
+		if n := len(report.Events); n > 0 && report.Events[n-1].Event.ID == t.ID {
+			report.Events[n-1].Totals = append(report.Events[n-1].Totals, modelFeeTotal(t))
+			continue
+		}
+
+		ev, err := r.eventByID(ctx, events, t.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Events = append(report.Events, &model.EventFeeTotals{
+			Event:  ev,
+			Totals: []*model.FeeTotal{modelFeeTotal(t)},
+		})
+	}
+
+	byArtist, err := r.db.EventHandler.FeeTotalsByArtist(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving artist totals: %w", err)
+	}
+
+	if byArtist, err = conv.feeTotals(byArtist); err != nil {
+		return nil, err
+	}
+
+	for _, t := range byArtist {
+		if n := len(report.Artists); n > 0 && report.Artists[n-1].Artist.ID == t.ID {
+			report.Artists[n-1].Totals = append(report.Artists[n-1].Totals, modelFeeTotal(t))
+			continue
+		}
+
+		dbArtists, err := r.db.ArtistHandler.Get(ctx, artist.ByID(t.ID))
+		if err != nil {
+			return nil, fmt.Errorf("fetching artist %q: %w", t.ID, err)
+		}
+
+		artists, err := modelArtists(dbArtists...)
+		if err != nil {
+			return nil, fmt.Errorf("converting artist: %w", err)
+		}
+
+		report.Artists = append(report.Artists, &model.ArtistFeeTotals{
+			Artist: artists[0],
+			Totals: []*model.FeeTotal{modelFeeTotal(t)},
+		})
+	}
+
+	return report, nil
+}
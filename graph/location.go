@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/conversion"
 	"github.com/obitech/artist-db/internal/database/location"
 )
 
@@ -14,8 +15,12 @@ func modelLocations(locations ...*location.Location) ([]*model.Location, error)
 
 	for _, loc := range locations {
 		out = append(out, &model.Location{
-			ID:   loc.ID,
-			Name: loc.Name,
+			ID:      loc.ID,
+			Name:    loc.Name,
+			Street:  &loc.Address.Street,
+			Zip:     &loc.Address.Zip,
+			City:    &loc.Address.City,
+			Country: &loc.Address.Country,
 		})
 	}
 
@@ -42,6 +47,12 @@ func databaseLocations(locations ...*model.LocationInput) ([]*location.Location,
 		out = append(out, &location.Location{
 			ID:   id,
 			Name: loc.Name,
+			Address: location.Address{
+				Street:  conversion.String(loc.Street),
+				Zip:     conversion.String(loc.Zip),
+				City:    conversion.String(loc.City),
+				Country: conversion.String(loc.Country),
+			},
 		})
 	}
 
@@ -2,6 +2,15 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/obitech/artist-db/internal/money"
+)
+
 type Artist struct {
 	ID           string    `json:"id"`
 	FirstName    string    `json:"firstName"`
@@ -20,6 +29,17 @@ type Artist struct {
 	Email        *string   `json:"email"`
 }
 
+type ArtistChange struct {
+	Action ChangeAction `json:"action"`
+	ID     string       `json:"id"`
+	Artist *Artist      `json:"artist"`
+}
+
+type ArtistFeeTotals struct {
+	Artist *Artist     `json:"artist"`
+	Totals []*FeeTotal `json:"totals"`
+}
+
 type ArtistInput struct {
 	ID           *string   `json:"id"`
 	FirstName    string    `json:"firstName"`
@@ -38,21 +58,30 @@ type ArtistInput struct {
 	Email        *string   `json:"email"`
 }
 
+type ArtistResult struct {
+	// The position of the item in the input.
+	Index  int        `json:"index"`
+	ID     *string    `json:"id"`
+	Status ItemStatus `json:"status"`
+	Artist *Artist    `json:"artist"`
+	Error  *ItemError `json:"error"`
+}
+
 type Artwork struct {
-	ID              string    `json:"id"`
-	Title           *string   `json:"title"`
-	Artist          *Artist   `json:"artist"`
-	SynopsisEn      *string   `json:"synopsisEN"`
-	SynopsisDe      *string   `json:"synopsisDE"`
-	Pictures        []*string `json:"pictures"`
-	MaterialDemands *string   `json:"materialDemands"`
-	InsuranceAmount *string   `json:"insuranceAmount"`
-	SalesVal        *string   `json:"salesVal"`
-	Height          *float64  `json:"height"`
-	Length          *float64  `json:"length"`
-	Width           *float64  `json:"width"`
-	Weight          *float64  `json:"weight"`
-	Category        *string   `json:"category"`
+	ID              string       `json:"id"`
+	Title           *string      `json:"title"`
+	Artist          *Artist      `json:"artist"`
+	SynopsisEn      *string      `json:"synopsisEN"`
+	SynopsisDe      *string      `json:"synopsisDE"`
+	Pictures        []*string    `json:"pictures"`
+	MaterialDemands *string      `json:"materialDemands"`
+	InsuranceAmount *money.Money `json:"insuranceAmount"`
+	SalesVal        *money.Money `json:"salesVal"`
+	Height          *float64     `json:"height"`
+	Length          *float64     `json:"length"`
+	Width           *float64     `json:"width"`
+	Weight          *float64     `json:"weight"`
+	Category        *string      `json:"category"`
 }
 
 type ArtworkEventLocation struct {
@@ -72,26 +101,185 @@ type ArtworkEventLocation struct {
 	PubAgreement               *string   `json:"pubAgreement"`
 }
 
+// A BudgetLine is a planned cost of an event. Amounts are in minor units of the currency.
+type BudgetLine struct {
+	ID          string         `json:"id"`
+	Category    BudgetCategory `json:"category"`
+	Description *string        `json:"description"`
+	Planned     int            `json:"planned"`
+	// Unset until the cost is known.
+	Actual   *int   `json:"actual"`
+	Currency string `json:"currency"`
+}
+
+type BudgetLineInput struct {
+	ID      *string `json:"id"`
+	EventID string  `json:"eventID"`
+	// ARTIST_FEES is derived from invitations and rejected.
+	Category    BudgetCategory `json:"category"`
+	Description *string        `json:"description"`
+	Planned     int            `json:"planned"`
+	Actual      *int           `json:"actual"`
+	Currency    string         `json:"currency"`
+}
+
+// A Conflict is an overlap of two events or slots in the schedule.
+type Conflict struct {
+	Kind     ConflictKind `json:"kind"`
+	Artist   *Artist      `json:"artist"`
+	Location *Location    `json:"location"`
+	Stage    *string      `json:"stage"`
+	Events   []*Event     `json:"events"`
+	// The conflicting slots, if the conflict is between slots.
+	Slots []*Slot   `json:"slots"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// A Consent records that an artist granted or withdrew consent. The latest consent per purpose is in effect.
+type Consent struct {
+	ID         string         `json:"id"`
+	Purpose    ConsentPurpose `json:"purpose"`
+	Granted    bool           `json:"granted"`
+	Method     ConsentMethod  `json:"method"`
+	Note       *string        `json:"note"`
+	RecordedAt time.Time      `json:"recordedAt"`
+}
+
+type ConsentInput struct {
+	ArtistID string         `json:"artistID"`
+	Purpose  ConsentPurpose `json:"purpose"`
+	// False records a withdrawal.
+	Granted bool          `json:"granted"`
+	Method  ConsentMethod `json:"method"`
+	Note    *string       `json:"note"`
+	// Defaults to now.
+	RecordedAt *time.Time `json:"recordedAt"`
+}
+
+// Sum of budget lines and artist fees in a category and currency. Allowances of artists count as travel and accommodation.
+type Cost struct {
+	Category BudgetCategory `json:"category"`
+	Currency string         `json:"currency"`
+	Planned  int            `json:"planned"`
+	Actual   int            `json:"actual"`
+	// How much more than planned was spent.
+	Variance int `json:"variance"`
+}
+
 type Event struct {
-	ID        string           `json:"id"`
-	Name      string           `json:"name"`
-	StartTime *int             `json:"startTime"`
-	Location  *Location        `json:"location"`
-	Artists   []*InvitedArtist `json:"artists"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	StartTime *int       `json:"startTime"`
+	Start     *time.Time `json:"start"`
+	End       *time.Time `json:"end"`
+	// IANA time zone, e.g. Europe/Berlin. Times are returned in this zone.
+	Timezone *string          `json:"timezone"`
+	Location *Location        `json:"location"`
+	Artists  []*InvitedArtist `json:"artists"`
+	Slots    []*Slot          `json:"slots"`
+	// RFC 5545 recurrence rule, e.g. FREQ=WEEKLY;BYDAY=TH.
+	Recurrence *string `json:"recurrence"`
+	// Cancelled occurrences.
+	Exceptions []*time.Time `json:"exceptions"`
+	// Published events are shown by the public API between publishFrom and publishUntil.
+	Published    bool       `json:"published"`
+	PublishFrom  *time.Time `json:"publishFrom"`
+	PublishUntil *time.Time `json:"publishUntil"`
+}
+
+type EventBudget struct {
+	Event *Event        `json:"event"`
+	Lines []*BudgetLine `json:"lines"`
+	Costs []*Cost       `json:"costs"`
+}
+
+type EventChange struct {
+	Action ChangeAction `json:"action"`
+	ID     string       `json:"id"`
+	Event  *Event       `json:"event"`
+}
+
+type EventFeeTotals struct {
+	Event  *Event      `json:"event"`
+	Totals []*FeeTotal `json:"totals"`
 }
 
 type EventInput struct {
-	ID             *string               `json:"id"`
-	Name           string                `json:"name"`
-	StartTime      *int                  `json:"startTime"`
-	LocationID     *string               `json:"locationID"`
+	ID   *string `json:"id"`
+	Name string  `json:"name"`
+	// Deprecated, use start.
+	StartTime  *int       `json:"startTime"`
+	Start      *time.Time `json:"start"`
+	End        *time.Time `json:"end"`
+	Timezone   *string    `json:"timezone"`
+	LocationID *string    `json:"locationID"`
+	// Replaces the invitations if set. Invitations of artists who aren't listed are removed, along with their slots.
 	InvitedArtists []*InvitedArtistInput `json:"invitedArtists"`
+	// Replaces the slots if set. Slots which aren't listed are removed.
+	Slots        []*SlotInput `json:"slots"`
+	Recurrence   *string      `json:"recurrence"`
+	Exceptions   []*time.Time `json:"exceptions"`
+	Published    *bool        `json:"published"`
+	PublishFrom  *time.Time   `json:"publishFrom"`
+	PublishUntil *time.Time   `json:"publishUntil"`
+}
+
+// A Fee is the agreement with an invited artist. Amounts are in minor units of the currency, e.g. cents.
+type Fee struct {
+	Amount int `json:"amount"`
+	// ISO 4217 code, e.g. EUR.
+	Currency               string  `json:"currency"`
+	Type                   FeeType `json:"type"`
+	Days                   int     `json:"days"`
+	TravelAllowance        int     `json:"travelAllowance"`
+	AccommodationAllowance int     `json:"accommodationAllowance"`
+	// The amount owed including allowances.
+	Total   int           `json:"total"`
+	Status  PaymentStatus `json:"status"`
+	DueDate *time.Time    `json:"dueDate"`
+	PaidAt  *time.Time    `json:"paidAt"`
+	// References to the contract documents, e.g. file paths or URLs.
+	ContractRefs []string `json:"contractRefs"`
+}
+
+type FeeInput struct {
+	Amount                 int            `json:"amount"`
+	Currency               string         `json:"currency"`
+	Type                   *FeeType       `json:"type"`
+	Days                   *int           `json:"days"`
+	TravelAllowance        *int           `json:"travelAllowance"`
+	AccommodationAllowance *int           `json:"accommodationAllowance"`
+	Status                 *PaymentStatus `json:"status"`
+	DueDate                *time.Time     `json:"dueDate"`
+	// Defaults to now if status is PAID.
+	PaidAt       *time.Time `json:"paidAt"`
+	ContractRefs []string   `json:"contractRefs"`
+}
+
+type FeeReport struct {
+	Events  []*EventFeeTotals  `json:"events"`
+	Artists []*ArtistFeeTotals `json:"artists"`
+}
+
+// Sums of fees in a single currency. Cancelled fees are not included.
+type FeeTotal struct {
+	Currency    string `json:"currency"`
+	Fees        int    `json:"fees"`
+	Allowances  int    `json:"allowances"`
+	Total       int    `json:"total"`
+	Paid        int    `json:"paid"`
+	Outstanding int    `json:"outstanding"`
+	// The outstanding amount past its due date.
+	Overdue int `json:"overdue"`
 }
 
 type GetArtistInput struct {
 	ID         *string `json:"id"`
 	LastName   *string `json:"lastName"`
 	ArtistName *string `json:"artistName"`
+	// Compared case-insensitive, also if emails are encrypted.
+	Email *string `json:"email"`
 }
 
 type GetEventInput struct {
@@ -104,14 +292,32 @@ type GetLocationInput struct {
 	Name *string `json:"name"`
 }
 
+type InvitationChange struct {
+	Action    ChangeAction `json:"action"`
+	EventID   string       `json:"eventID"`
+	ArtistID  string       `json:"artistID"`
+	Confirmed bool         `json:"confirmed"`
+}
+
 type InvitedArtist struct {
 	Artist    *Artist `json:"artist"`
 	Confirmed bool    `json:"confirmed"`
+	Fee       *Fee    `json:"fee"`
 }
 
 type InvitedArtistInput struct {
 	ID        string `json:"id"`
 	Confirmed bool   `json:"confirmed"`
+	// Leaves an existing fee unchanged if omitted.
+	Fee *FeeInput `json:"fee"`
+}
+
+// The error of a single item of a batch. Code and field are the same as in the extensions of errors.
+type ItemError struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Field   *string `json:"field"`
+	TraceID *string `json:"traceID"`
 }
 
 type Location struct {
@@ -128,6 +334,526 @@ type Location struct {
 }
 
 type LocationInput struct {
-	ID   *string `json:"id"`
-	Name string  `json:"name"`
+	ID      *string `json:"id"`
+	Name    string  `json:"name"`
+	Street  *string `json:"street"`
+	Zip     *string `json:"zip"`
+	City    *string `json:"city"`
+	Country *string `json:"country"`
+}
+
+// An Occurrence is a single instance of a possibly recurring event.
+type Occurrence struct {
+	Event *Event `json:"event"`
+	// The start of the occurrence according to the recurrence rule.
+	RecurrenceID time.Time  `json:"recurrenceID"`
+	Name         string     `json:"name"`
+	Start        time.Time  `json:"start"`
+	End          *time.Time `json:"end"`
+	Overridden   bool       `json:"overridden"`
+}
+
+type OccurrenceInput struct {
+	Name  *string    `json:"name"`
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+// A Slot assigns an invited artist to a time range and stage, e.g. a performance.
+type Slot struct {
+	ID     string    `json:"id"`
+	Artist *Artist   `json:"artist"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Stage  *string   `json:"stage"`
+}
+
+type SlotInput struct {
+	ID       *string   `json:"id"`
+	ArtistID string    `json:"artistID"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Stage    *string   `json:"stage"`
+}
+
+type Webhook struct {
+	ID       string         `json:"id"`
+	URL      string         `json:"url"`
+	Entities []ChangeEntity `json:"entities"`
+	Actions  []ChangeAction `json:"actions"`
+}
+
+type WebhookDelivery struct {
+	ID         string       `json:"id"`
+	DeliveryID string       `json:"deliveryID"`
+	Entity     ChangeEntity `json:"entity"`
+	Action     ChangeAction `json:"action"`
+	EntityID   string       `json:"entityID"`
+	Attempt    int          `json:"attempt"`
+	StatusCode *int         `json:"statusCode"`
+	Error      *string      `json:"error"`
+	Succeeded  bool         `json:"succeeded"`
+	CreatedAt  string       `json:"createdAt"`
+}
+
+type WebhookInput struct {
+	ID       *string        `json:"id"`
+	URL      string         `json:"url"`
+	Secret   string         `json:"secret"`
+	Entities []ChangeEntity `json:"entities"`
+	Actions  []ChangeAction `json:"actions"`
+}
+
+type BatchMode string
+
+const (
+	// Items which fail are skipped, all others are saved.
+	BatchModePartial BatchMode = "PARTIAL"
+	// Nothing is saved if any item fails.
+	BatchModeAtomic BatchMode = "ATOMIC"
+)
+
+var AllBatchMode = []BatchMode{
+	BatchModePartial,
+	BatchModeAtomic,
+}
+
+func (e BatchMode) IsValid() bool {
+	switch e {
+	case BatchModePartial, BatchModeAtomic:
+		return true
+	}
+	return false
+}
+
+func (e BatchMode) String() string {
+	return string(e)
+}
+
+func (e *BatchMode) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BatchMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BatchMode", str)
+	}
+	return nil
+}
+
+func (e BatchMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type BudgetCategory string
+
+const (
+	// Derived from the fees of invited artists.
+	BudgetCategoryArtistFees BudgetCategory = "ARTIST_FEES"
+	BudgetCategoryVenue      BudgetCategory = "VENUE"
+	// Shipping and spedition of artworks.
+	BudgetCategoryShipping      BudgetCategory = "SHIPPING"
+	BudgetCategoryTravel        BudgetCategory = "TRAVEL"
+	BudgetCategoryAccommodation BudgetCategory = "ACCOMMODATION"
+	BudgetCategoryProduction    BudgetCategory = "PRODUCTION"
+	BudgetCategoryMarketing     BudgetCategory = "MARKETING"
+	BudgetCategoryStaff         BudgetCategory = "STAFF"
+	BudgetCategoryOther         BudgetCategory = "OTHER"
+)
+
+var AllBudgetCategory = []BudgetCategory{
+	BudgetCategoryArtistFees,
+	BudgetCategoryVenue,
+	BudgetCategoryShipping,
+	BudgetCategoryTravel,
+	BudgetCategoryAccommodation,
+	BudgetCategoryProduction,
+	BudgetCategoryMarketing,
+	BudgetCategoryStaff,
+	BudgetCategoryOther,
+}
+
+func (e BudgetCategory) IsValid() bool {
+	switch e {
+	case BudgetCategoryArtistFees, BudgetCategoryVenue, BudgetCategoryShipping, BudgetCategoryTravel, BudgetCategoryAccommodation, BudgetCategoryProduction, BudgetCategoryMarketing, BudgetCategoryStaff, BudgetCategoryOther:
+		return true
+	}
+	return false
+}
+
+func (e BudgetCategory) String() string {
+	return string(e)
+}
+
+func (e *BudgetCategory) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BudgetCategory(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BudgetCategory", str)
+	}
+	return nil
+}
+
+func (e BudgetCategory) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ChangeAction string
+
+const (
+	ChangeActionUpsert ChangeAction = "UPSERT"
+	ChangeActionDelete ChangeAction = "DELETE"
+)
+
+var AllChangeAction = []ChangeAction{
+	ChangeActionUpsert,
+	ChangeActionDelete,
+}
+
+func (e ChangeAction) IsValid() bool {
+	switch e {
+	case ChangeActionUpsert, ChangeActionDelete:
+		return true
+	}
+	return false
+}
+
+func (e ChangeAction) String() string {
+	return string(e)
+}
+
+func (e *ChangeAction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ChangeAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ChangeAction", str)
+	}
+	return nil
+}
+
+func (e ChangeAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ChangeEntity string
+
+const (
+	ChangeEntityArtist     ChangeEntity = "ARTIST"
+	ChangeEntityEvent      ChangeEntity = "EVENT"
+	ChangeEntityLocation   ChangeEntity = "LOCATION"
+	ChangeEntityInvitation ChangeEntity = "INVITATION"
+)
+
+var AllChangeEntity = []ChangeEntity{
+	ChangeEntityArtist,
+	ChangeEntityEvent,
+	ChangeEntityLocation,
+	ChangeEntityInvitation,
+}
+
+func (e ChangeEntity) IsValid() bool {
+	switch e {
+	case ChangeEntityArtist, ChangeEntityEvent, ChangeEntityLocation, ChangeEntityInvitation:
+		return true
+	}
+	return false
+}
+
+func (e ChangeEntity) String() string {
+	return string(e)
+}
+
+func (e *ChangeEntity) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ChangeEntity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ChangeEntity", str)
+	}
+	return nil
+}
+
+func (e ChangeEntity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ConflictKind string
+
+const (
+	// An artist invited to overlapping events, or in overlapping slots.
+	ConflictKindArtist ConflictKind = "ARTIST"
+	// A location hosting overlapping events.
+	ConflictKindLocation ConflictKind = "LOCATION"
+	// A stage with overlapping slots.
+	ConflictKindStage ConflictKind = "STAGE"
+)
+
+var AllConflictKind = []ConflictKind{
+	ConflictKindArtist,
+	ConflictKindLocation,
+	ConflictKindStage,
+}
+
+func (e ConflictKind) IsValid() bool {
+	switch e {
+	case ConflictKindArtist, ConflictKindLocation, ConflictKindStage:
+		return true
+	}
+	return false
+}
+
+func (e ConflictKind) String() string {
+	return string(e)
+}
+
+func (e *ConflictKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConflictKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConflictKind", str)
+	}
+	return nil
+}
+
+func (e ConflictKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ConsentMethod string
+
+const (
+	ConsentMethodForm     ConsentMethod = "FORM"
+	ConsentMethodEmail    ConsentMethod = "EMAIL"
+	ConsentMethodWritten  ConsentMethod = "WRITTEN"
+	ConsentMethodVerbal   ConsentMethod = "VERBAL"
+	ConsentMethodContract ConsentMethod = "CONTRACT"
+)
+
+var AllConsentMethod = []ConsentMethod{
+	ConsentMethodForm,
+	ConsentMethodEmail,
+	ConsentMethodWritten,
+	ConsentMethodVerbal,
+	ConsentMethodContract,
+}
+
+func (e ConsentMethod) IsValid() bool {
+	switch e {
+	case ConsentMethodForm, ConsentMethodEmail, ConsentMethodWritten, ConsentMethodVerbal, ConsentMethodContract:
+		return true
+	}
+	return false
+}
+
+func (e ConsentMethod) String() string {
+	return string(e)
+}
+
+func (e *ConsentMethod) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConsentMethod(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConsentMethod", str)
+	}
+	return nil
+}
+
+func (e ConsentMethod) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ConsentPurpose string
+
+const (
+	// Storing personal data beyond names.
+	ConsentPurposeStoreData      ConsentPurpose = "STORE_DATA"
+	ConsentPurposePublishProfile ConsentPurpose = "PUBLISH_PROFILE"
+	ConsentPurposePublishBio     ConsentPurpose = "PUBLISH_BIO"
+)
+
+var AllConsentPurpose = []ConsentPurpose{
+	ConsentPurposeStoreData,
+	ConsentPurposePublishProfile,
+	ConsentPurposePublishBio,
+}
+
+func (e ConsentPurpose) IsValid() bool {
+	switch e {
+	case ConsentPurposeStoreData, ConsentPurposePublishProfile, ConsentPurposePublishBio:
+		return true
+	}
+	return false
+}
+
+func (e ConsentPurpose) String() string {
+	return string(e)
+}
+
+func (e *ConsentPurpose) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConsentPurpose(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConsentPurpose", str)
+	}
+	return nil
+}
+
+func (e ConsentPurpose) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FeeType string
+
+const (
+	FeeTypeFlat FeeType = "FLAT"
+	// Paid for each of days.
+	FeeTypeDaily FeeType = "DAILY"
+)
+
+var AllFeeType = []FeeType{
+	FeeTypeFlat,
+	FeeTypeDaily,
+}
+
+func (e FeeType) IsValid() bool {
+	switch e {
+	case FeeTypeFlat, FeeTypeDaily:
+		return true
+	}
+	return false
+}
+
+func (e FeeType) String() string {
+	return string(e)
+}
+
+func (e *FeeType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FeeType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FeeType", str)
+	}
+	return nil
+}
+
+func (e FeeType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ItemStatus string
+
+const (
+	ItemStatusSaved  ItemStatus = "SAVED"
+	ItemStatusFailed ItemStatus = "FAILED"
+	// The item succeeded, but was rolled back because another item of an atomic batch failed.
+	ItemStatusRolledBack ItemStatus = "ROLLED_BACK"
+)
+
+var AllItemStatus = []ItemStatus{
+	ItemStatusSaved,
+	ItemStatusFailed,
+	ItemStatusRolledBack,
+}
+
+func (e ItemStatus) IsValid() bool {
+	switch e {
+	case ItemStatusSaved, ItemStatusFailed, ItemStatusRolledBack:
+		return true
+	}
+	return false
+}
+
+func (e ItemStatus) String() string {
+	return string(e)
+}
+
+func (e *ItemStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ItemStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ItemStatus", str)
+	}
+	return nil
+}
+
+func (e ItemStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending   PaymentStatus = "PENDING"
+	PaymentStatusInvoiced  PaymentStatus = "INVOICED"
+	PaymentStatusPaid      PaymentStatus = "PAID"
+	PaymentStatusCancelled PaymentStatus = "CANCELLED"
+)
+
+var AllPaymentStatus = []PaymentStatus{
+	PaymentStatusPending,
+	PaymentStatusInvoiced,
+	PaymentStatusPaid,
+	PaymentStatusCancelled,
+}
+
+func (e PaymentStatus) IsValid() bool {
+	switch e {
+	case PaymentStatusPending, PaymentStatusInvoiced, PaymentStatusPaid, PaymentStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (e PaymentStatus) String() string {
+	return string(e)
+}
+
+func (e *PaymentStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PaymentStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PaymentStatus", str)
+	}
+	return nil
+}
+
+func (e PaymentStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
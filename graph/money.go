@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/money"
+)
+
+// Conversion converts amounts into Currency at the exchange rates valid at
+// At. Without a Currency, amounts are left in their currencies.
+type Conversion struct {
+	Rates    *money.Rates
+	Currency string
+	At       time.Time
+}
+
+// NewConversion returns a Conversion into currency, which may be empty. At
+// defaults to now.
+func NewConversion(rates *money.Rates, currency string, at *time.Time) (Conversion, error) {
+	c := Conversion{
+		Rates:    rates,
+		Currency: strings.ToUpper(currency),
+		At:       time.Now(),
+	}
+
+	if c.Currency != "" && !money.IsCurrency(c.Currency) {
+		return Conversion{}, fmt.Errorf("invalid currency %q", currency)
+	}
+
+	if at != nil {
+		c.At = *at
+	}
+
+	return c, nil
+}
+
+func (r *Resolver) conversion(currency *string, at *time.Time) (Conversion, error) {
+	var to string
+	if currency != nil {
+		to = *currency
+	}
+
+	conv, err := NewConversion(r.rates, to, at)
+	if err != nil {
+		return Conversion{}, &InputError{Err: err}
+	}
+
+	return conv, nil
+}
+
+func (c Conversion) costs(costs []event.Cost) ([]event.Cost, error) {
+	if c.Currency == "" {
+		return costs, nil
+	}
+
+	converted, err := event.ConvertCosts(costs, c.Rates, c.Currency, c.At)
+	if err != nil {
+		return nil, fmt.Errorf("converting costs: %w", err)
+	}
+
+	return converted, nil
+}
+
+func (c Conversion) feeTotals(totals []event.FeeTotal) ([]event.FeeTotal, error) {
+	if c.Currency == "" {
+		return totals, nil
+	}
+
+	converted, err := event.ConvertFeeTotals(totals, c.Rates, c.Currency, c.At)
+	if err != nil {
+		return nil, fmt.Errorf("converting totals: %w", err)
+	}
+
+	return converted, nil
+}
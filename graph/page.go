@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/location"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageArgs returns the cursor and limit of a list query without input.
+func pageArgs(after *string, limit *int) (string, int, error) {
+	var cursor string
+	if after != nil {
+		if _, err := uuid.Parse(*after); err != nil {
+			return "", 0, core.ErrInvalidUUID
+		}
+
+		cursor = *after
+	}
+
+	n := defaultPageLimit
+	if limit != nil {
+		if *limit < 1 || *limit > maxPageLimit {
+			return "", 0, &InputError{Err: fmt.Errorf("limit must be between 1 and %d", maxPageLimit)}
+		}
+
+		n = *limit
+	}
+
+	return cursor, n, nil
+}
+
+// ArtistPage returns up to limit Artists ordered by ID, starting after the
+// Artist with the given ID.
+func (r *Resolver) ArtistPage(ctx context.Context, after string, limit int) ([]*model.Artist, error) {
+	dbArtists, err := r.db.ArtistHandler.Get(ctx, artist.After(after, limit))
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		r.logger.Error("get failed", zap.Error(err), observability.TraceField(ctx))
+		return nil, err
+	}
+
+	return modelArtists(dbArtists...)
+}
+
+// LocationPage returns up to limit Locations ordered by ID, starting after
+// the Location with the given ID.
+func (r *Resolver) LocationPage(ctx context.Context, after string, limit int) ([]*model.Location, error) {
+	dbLocations, err := r.db.LocationHandler.Get(ctx, location.After(after, limit))
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		r.logger.Error("get failed", zap.Error(err), observability.TraceField(ctx))
+		return nil, err
+	}
+
+	return modelLocations(dbLocations...)
+}
+
+// EventPage returns up to limit Events ordered by ID, starting after the
+// Event with the given ID.
+func (r *Resolver) EventPage(ctx context.Context, after string, limit int) ([]*model.Event, error) {
+	dbEvents, err := r.db.EventHandler.Get(ctx, event.After(after, limit))
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return r.modelEvents(ctx, dbEvents...)
+}
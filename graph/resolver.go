@@ -1,23 +1,56 @@
 package graph
 
 import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
 	"go.uber.org/zap"
 
+	"github.com/obitech/artist-db/internal/broker"
 	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/money"
 )
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
+// Subscriber allows subscribing to committed changes.
+type Subscriber interface {
+	Subscribe(ctx context.Context, filter broker.Filter) <-chan core.Change
+}
+
+// InputError is returned by resolvers if their input is invalid.
+type InputError struct {
+	Err error
+}
+
+func (e *InputError) Error() string {
+	return "invalid input: " + e.Err.Error()
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
 type Resolver struct {
-	db     *database.Database
-	logger *zap.Logger
+	db         *database.Database
+	subscriber Subscriber
+	rates      *money.Rates
+	presenter  graphql.ErrorPresenterFunc
+	logger     *zap.Logger
 }
 
-func NewResolver(db *database.Database, logger *zap.Logger) *Resolver {
+// NewResolver returns a Resolver. Amounts are only converted between
+// currencies if rates is set. Errors of single items of batches are converted
+// by presenter, like errors of whole operations.
+func NewResolver(db *database.Database, subscriber Subscriber, rates *money.Rates, presenter graphql.ErrorPresenterFunc, logger *zap.Logger) *Resolver {
 	return &Resolver{
-		db:     db,
-		logger: logger,
+		db:         db,
+		subscriber: subscriber,
+		rates:      rates,
+		presenter:  presenter,
+		logger:     logger,
 	}
 }
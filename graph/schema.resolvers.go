@@ -5,22 +5,30 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/obitech/artist-db/graph/generated"
 	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/broker"
+	"github.com/obitech/artist-db/internal/conversion"
 	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
 	"github.com/obitech/artist-db/internal/database/event"
 	"github.com/obitech/artist-db/internal/database/location"
+	"github.com/obitech/artist-db/internal/money"
 	"github.com/obitech/artist-db/internal/observability"
 )
 
 func (r *mutationResolver) UpsertArtists(ctx context.Context, input []*model.ArtistInput) ([]*model.Artist, error) {
 	dbArtists, err := databaseArtists(input...)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, &InputError{Err: err}
 	}
 
 	if err := r.db.ArtistHandler.Upsert(ctx, dbArtists...); err != nil {
@@ -41,6 +49,47 @@ func (r *mutationResolver) UpsertArtists(ctx context.Context, input []*model.Art
 	return ret, nil
 }
 
+func (r *mutationResolver) UpsertArtistsBatch(ctx context.Context, input []*model.ArtistInput, mode *model.BatchMode) ([]*model.ArtistResult, error) {
+	dbArtists, err := databaseArtists(input...)
+	if err != nil {
+		return nil, &InputError{Err: err}
+	}
+
+	errs, err := r.db.ArtistHandler.UpsertBatch(ctx, batchMode(mode), dbArtists...)
+	if err != nil {
+		msg := "upsert failed"
+
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	ret := make([]*model.ArtistResult, 0, len(dbArtists))
+	for i, itemErr := range errs {
+		res := &model.ArtistResult{Index: i, ID: input[i].ID}
+
+		switch {
+		case itemErr == nil:
+			a, err := modelArtists(dbArtists[i])
+			if err != nil {
+				msg := "conversion failed"
+
+				r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+				return nil, fmt.Errorf("%s: %w", msg, err)
+			}
+
+			res.ID, res.Status, res.Artist = &dbArtists[i].ID, model.ItemStatusSaved, a[0]
+		case errors.Is(itemErr, core.ErrRolledBack):
+			res.Status = model.ItemStatusRolledBack
+		default:
+			res.Status, res.Error = model.ItemStatusFailed, r.itemError(ctx, itemErr)
+		}
+
+		ret = append(ret, res)
+	}
+
+	return ret, nil
+}
+
 func (r *mutationResolver) DeleteArtistByID(ctx context.Context, id string) (bool, error) {
 	if err := r.db.ArtistHandler.DeleteByID(ctx, id); err != nil {
 		r.logger.Error("delete failed", zap.Error(err), zap.String("id", id), observability.TraceField(ctx))
@@ -50,10 +99,30 @@ func (r *mutationResolver) DeleteArtistByID(ctx context.Context, id string) (boo
 	return true, nil
 }
 
+func (r *mutationResolver) AnonymizeArtist(ctx context.Context, id string) (bool, error) {
+	if err := r.db.ArtistHandler.Anonymize(ctx, id); err != nil {
+		r.logger.Error("anonymize failed", zap.Error(err), zap.String("id", id), observability.TraceField(ctx))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RecordConsent(ctx context.Context, input model.ConsentInput) (string, error) {
+	c := databaseConsent(input)
+
+	if err := r.db.ArtistHandler.RecordConsent(ctx, c); err != nil {
+		r.logger.Error("record failed", zap.Error(err), zap.String("artistID", input.ArtistID), observability.TraceField(ctx))
+		return "", err
+	}
+
+	return c.ID, nil
+}
+
 func (r *mutationResolver) UpsertLocations(ctx context.Context, input []*model.LocationInput) ([]string, error) {
 	dbLocations, err := databaseLocations(input...)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, &InputError{Err: err}
 	}
 
 	if err := r.db.LocationHandler.Upsert(ctx, dbLocations...); err != nil {
@@ -80,13 +149,23 @@ func (r *mutationResolver) DeleteLocationByID(ctx context.Context, input string)
 	return true, nil
 }
 
-func (r *mutationResolver) UpsertEvents(ctx context.Context, input []*model.EventInput) ([]string, error) {
+func (r *mutationResolver) UpsertEvents(ctx context.Context, input []*model.EventInput, allowConflicts *bool) ([]string, error) {
 	dbEvents, err := databaseEvents(input...)
 	if err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, &InputError{Err: err}
 	}
 
-	if err := r.db.EventHandler.Upsert(ctx, dbEvents...); err != nil {
+	if allowConflicts != nil && *allowConflicts {
+		conflicts, err := r.db.EventHandler.UpsertAllowingConflicts(ctx, dbEvents...)
+		if err != nil {
+			msg := "upsert failed"
+
+			r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+			return nil, fmt.Errorf("%s: %w", msg, err)
+		}
+
+		addConflictWarnings(ctx, conflicts...)
+	} else if err := r.db.EventHandler.Upsert(ctx, dbEvents...); err != nil {
 		msg := "upsert failed"
 
 		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
@@ -110,7 +189,102 @@ func (r *mutationResolver) DeleteEventByID(ctx context.Context, id string) (bool
 	return true, nil
 }
 
-func (r *queryResolver) GetArtists(ctx context.Context, input []*model.GetArtistInput) ([]*model.Artist, error) {
+func (r *mutationResolver) OverrideOccurrence(ctx context.Context, eventID string, recurrenceID time.Time, input model.OccurrenceInput) (bool, error) {
+	override := event.Override{
+		RecurrenceID: recurrenceID,
+		Name:         conversion.String(input.Name),
+		StartTime:    input.Start,
+		EndTime:      input.End,
+	}
+
+	if err := r.db.EventHandler.OverrideOccurrence(ctx, eventID, override); err != nil {
+		r.logger.Error("override failed", zap.Error(err), zap.String("id", eventID), observability.TraceField(ctx))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) CancelOccurrence(ctx context.Context, eventID string, recurrenceID time.Time) (bool, error) {
+	if err := r.db.EventHandler.CancelOccurrence(ctx, eventID, recurrenceID); err != nil {
+		r.logger.Error("cancel failed", zap.Error(err), zap.String("id", eventID), observability.TraceField(ctx))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) SetInvitationFee(ctx context.Context, eventID string, artistID string, input *model.FeeInput) (bool, error) {
+	if err := r.db.EventHandler.SetFee(ctx, eventID, artistID, databaseFee(input)); err != nil {
+		r.logger.Error("setting fee failed", zap.Error(err), zap.String("id", eventID), observability.TraceField(ctx))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) UpsertBudgetLines(ctx context.Context, input []*model.BudgetLineInput) ([]string, error) {
+	lines := databaseBudgetLines(input...)
+
+	if err := r.db.EventHandler.UpsertBudgetLines(ctx, lines...); err != nil {
+		msg := "upsert failed"
+
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	ret := make([]string, 0, len(lines))
+	for _, l := range lines {
+		ret = append(ret, l.ID)
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) DeleteBudgetLine(ctx context.Context, id string) (bool, error) {
+	if err := r.db.EventHandler.DeleteBudgetLine(ctx, id); err != nil {
+		r.logger.Error("delete failed", zap.Error(err), zap.String("id", id), observability.TraceField(ctx))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) UpsertWebhooks(ctx context.Context, input []*model.WebhookInput) ([]*model.Webhook, error) {
+	dbWebhooks, err := databaseWebhooks(input...)
+	if err != nil {
+		return nil, &InputError{Err: err}
+	}
+
+	if err := r.db.WebhookHandler.Upsert(ctx, dbWebhooks...); err != nil {
+		msg := "upsert failed"
+
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return modelWebhooks(dbWebhooks...), nil
+}
+
+func (r *mutationResolver) DeleteWebhookByID(ctx context.Context, id string) (bool, error) {
+	if err := r.db.WebhookHandler.DeleteByID(ctx, id); err != nil {
+		r.logger.Error("delete failed", zap.Error(err), zap.String("id", id), observability.TraceField(ctx))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *queryResolver) GetArtists(ctx context.Context, input []*model.GetArtistInput, after *string, limit *int) ([]*model.Artist, error) {
+	if len(input) == 0 {
+		cursor, n, err := pageArgs(after, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return r.ArtistPage(ctx, cursor, n)
+	}
+
 	var artists []*model.Artist
 
 	for i := range input {
@@ -126,6 +300,8 @@ func (r *queryResolver) GetArtists(ctx context.Context, input []*model.GetArtist
 			dbArtists, err = r.db.ArtistHandler.Get(ctx, artist.ByLastName(*input[i].LastName))
 		case input[i].ArtistName != nil:
 			dbArtists, err = r.db.ArtistHandler.Get(ctx, artist.ByLastName(*input[i].ArtistName))
+		case input[i].Email != nil:
+			dbArtists, err = r.db.ArtistHandler.Get(ctx, artist.ByEmail(*input[i].Email))
 		}
 
 		if err != nil {
@@ -180,7 +356,16 @@ func (r *queryResolver) GetLocations(ctx context.Context, input []*model.GetLoca
 	return locations, nil
 }
 
-func (r *queryResolver) GetEvents(ctx context.Context, input []*model.GetEventInput) ([]*model.Event, error) {
+func (r *queryResolver) GetEvents(ctx context.Context, input []*model.GetEventInput, after *string, limit *int) ([]*model.Event, error) {
+	if len(input) == 0 {
+		cursor, n, err := pageArgs(after, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return r.EventPage(ctx, cursor, n)
+	}
+
 	var events []*model.Event
 
 	for _, ev := range input {
@@ -191,6 +376,8 @@ func (r *queryResolver) GetEvents(ctx context.Context, input []*model.GetEventIn
 			req = event.ByID(*ev.ID)
 		case ev.Name != nil:
 			req = event.ByName(*ev.Name)
+		default:
+			continue
 		}
 
 		dbEvents, err := r.db.EventHandler.Get(ctx, req)
@@ -213,13 +400,294 @@ func (r *queryResolver) GetEvents(ctx context.Context, input []*model.GetEventIn
 	return events, nil
 }
 
+func (r *queryResolver) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	dbWebhooks, err := r.db.WebhookHandler.Get(ctx)
+	if err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return modelWebhooks(dbWebhooks...), nil
+}
+
+func (r *queryResolver) GetWebhookDeliveries(ctx context.Context, webhookID string, limit *int) ([]*model.WebhookDelivery, error) {
+	n := 50
+	if limit != nil && *limit > 0 {
+		n = *limit
+	}
+
+	deliveries, err := r.db.WebhookHandler.Deliveries(ctx, webhookID, n)
+	if err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return modelWebhookDeliveries(deliveries...), nil
+}
+
+func (r *queryResolver) Conflicts(ctx context.Context, from time.Time, to time.Time) ([]*model.Conflict, error) {
+	conflicts, err := r.db.EventHandler.Conflicts(ctx, from, to)
+	if err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	out, err := r.modelConflicts(ctx, conflicts...)
+	if err != nil {
+		msg := "conversion failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	// Never return null for a non-null list.
+	if out == nil {
+		out = []*model.Conflict{}
+	}
+
+	return out, nil
+}
+
+func (r *queryResolver) Occurrences(ctx context.Context, from time.Time, to time.Time) ([]*model.Occurrence, error) {
+	occurrences, err := r.db.EventHandler.Occurrences(ctx, from, to)
+	if err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	out, err := r.modelOccurrences(ctx, occurrences...)
+	if err != nil {
+		msg := "conversion failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	// Never return null for a non-null list.
+	if out == nil {
+		out = []*model.Occurrence{}
+	}
+
+	return out, nil
+}
+
+func (r *queryResolver) FeeReport(ctx context.Context, eventIDs []string, artistIDs []string, currency *string, at *time.Time) (*model.FeeReport, error) {
+	conv, err := r.conversion(currency, at)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := r.feeReport(ctx, event.FeeFilter{EventIDs: eventIDs, ArtistIDs: artistIDs}, conv)
+	if err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return report, nil
+}
+
+func (r *queryResolver) Budget(ctx context.Context, eventIDs []string, currency *string, at *time.Time) ([]*model.EventBudget, error) {
+	conv, err := r.conversion(currency, at)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := r.budget(ctx, eventIDs, conv)
+	if err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return nil, fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return budgets, nil
+}
+
+func (r *queryResolver) BudgetCSV(ctx context.Context, eventIDs []string, currency *string, at *time.Time) (string, error) {
+	conv, err := r.conversion(currency, at)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := WriteBudgetCSV(ctx, r.db, &sb, conv, eventIDs...); err != nil {
+		msg := "get failed"
+		r.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+		return "", fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return sb.String(), nil
+}
+
+func (r *queryResolver) Convert(ctx context.Context, amount money.Money, currency string, at *time.Time) (*money.Money, error) {
+	conv, err := r.conversion(&currency, at)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := conv.Rates.Convert(amount, conv.Currency, conv.At)
+	if err != nil {
+		return nil, err
+	}
+
+	return &converted, nil
+}
+
+func (r *queryResolver) ExportArtistPersonalData(ctx context.Context, id string) (string, error) {
+	data, err := r.db.ArtistHandler.ExportPersonalData(ctx, id)
+	if err != nil {
+		r.logger.Error("export failed", zap.Error(err), zap.String("id", id), observability.TraceField(ctx))
+		return "", err
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("encoding export: %w", err)
+	}
+
+	return string(b), nil
+}
+
+func (r *queryResolver) Consents(ctx context.Context, artistID string) ([]*model.Consent, error) {
+	consents, err := r.db.ArtistHandler.Consents(ctx, artistID)
+	if err != nil {
+		r.logger.Error("get failed", zap.Error(err), zap.String("artistID", artistID), observability.TraceField(ctx))
+		return nil, err
+	}
+
+	ret := make([]*model.Consent, 0, len(consents))
+	for _, c := range consents {
+		ret = append(ret, modelConsent(c))
+	}
+
+	return ret, nil
+}
+
+func (r *subscriptionResolver) ArtistChanged(ctx context.Context) (<-chan *model.ArtistChange, error) {
+	changes := r.subscriber.Subscribe(ctx, broker.ForEntity(core.EntityArtist))
+	out := make(chan *model.ArtistChange)
+
+	// Changes are looked up right after they were committed, which lagging
+	// replicas may not have seen yet.
+	primary := core.WithPrimary(ctx)
+
+	go func() {
+		defer close(out)
+
+		for change := range changes {
+			ac := &model.ArtistChange{
+				Action: modelChangeAction(change.Action),
+				ID:     change.ID,
+			}
+
+			if change.Action == core.ActionUpsert {
+				dbArtists, err := r.db.ArtistHandler.Get(primary, artist.ByID(change.ID))
+				if err != nil {
+					r.logger.Error("get failed", zap.Error(err), zap.String("id", change.ID), observability.TraceField(ctx))
+					continue
+				}
+
+				a, err := modelArtists(dbArtists...)
+				if err != nil {
+					r.logger.Error("conversion failed", zap.Error(err), observability.TraceField(ctx))
+					continue
+				}
+
+				ac.Artist = a[0]
+			}
+
+			select {
+			case out <- ac:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (r *subscriptionResolver) EventChanged(ctx context.Context, id string) (<-chan *model.EventChange, error) {
+	changes := r.subscriber.Subscribe(ctx, broker.ForID(core.EntityEvent, id))
+	out := make(chan *model.EventChange)
+
+	// Changes are looked up right after they were committed, which lagging
+	// replicas may not have seen yet.
+	primary := core.WithPrimary(ctx)
+
+	go func() {
+		defer close(out)
+
+		for change := range changes {
+			ec := &model.EventChange{
+				Action: modelChangeAction(change.Action),
+				ID:     change.ID,
+			}
+
+			if change.Action == core.ActionUpsert {
+				dbEvents, err := r.db.EventHandler.Get(primary, event.ByID(change.ID))
+				if err != nil {
+					r.logger.Error("get failed", zap.Error(err), zap.String("id", change.ID), observability.TraceField(ctx))
+					continue
+				}
+
+				e, err := r.modelEvents(primary, dbEvents...)
+				if err != nil {
+					r.logger.Error("conversion failed", zap.Error(err), observability.TraceField(ctx))
+					continue
+				}
+
+				ec.Event = e[0]
+			}
+
+			select {
+			case out <- ec:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (r *subscriptionResolver) InvitationStatusChanged(ctx context.Context, eventID string) (<-chan *model.InvitationChange, error) {
+	changes := r.subscriber.Subscribe(ctx, broker.ForInvitations(eventID))
+	out := make(chan *model.InvitationChange)
+
+	go func() {
+		defer close(out)
+
+		for change := range changes {
+			select {
+			case out <- &model.InvitationChange{
+				Action:    modelChangeAction(change.Action),
+				EventID:   change.EventID,
+				ArtistID:  change.ID,
+				Confirmed: change.Confirmed,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
 type (
-	mutationResolver struct{ *Resolver }
-	queryResolver    struct{ *Resolver }
+	mutationResolver     struct{ *Resolver }
+	queryResolver        struct{ *Resolver }
+	subscriptionResolver struct{ *Resolver }
 )
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/webhook"
+)
+
+// modelWebhooks takes Webhooks returned from the database and converts them to
+// Webhooks defined in the GraphQL model. Secrets are never returned.
+func modelWebhooks(webhooks ...*webhook.Webhook) []*model.Webhook {
+	var out []*model.Webhook
+
+	for _, w := range webhooks {
+		mw := &model.Webhook{
+			ID:       w.ID,
+			URL:      w.URL,
+			Entities: []model.ChangeEntity{},
+			Actions:  []model.ChangeAction{},
+		}
+
+		for _, e := range w.Entities {
+			mw.Entities = append(mw.Entities, model.ChangeEntity(strings.ToUpper(e)))
+		}
+
+		for _, a := range w.Actions {
+			mw.Actions = append(mw.Actions, modelChangeAction(a))
+		}
+
+		out = append(out, mw)
+	}
+
+	return out
+}
+
+// modelWebhookDeliveries converts delivery attempts returned from the database
+// to WebhookDeliveries defined in the GraphQL model.
+func modelWebhookDeliveries(deliveries ...*webhook.Delivery) []*model.WebhookDelivery {
+	var out []*model.WebhookDelivery
+
+	for _, d := range deliveries {
+		md := &model.WebhookDelivery{
+			ID:         d.ID,
+			DeliveryID: d.DeliveryID,
+			Entity:     model.ChangeEntity(strings.ToUpper(d.Entity)),
+			Action:     modelChangeAction(d.Action),
+			EntityID:   d.EntityID,
+			Attempt:    d.Attempt,
+			Succeeded:  d.Succeeded,
+			CreatedAt:  d.CreatedAt.Format(time.RFC3339),
+		}
+
+		if d.StatusCode != 0 {
+			md.StatusCode = &d.StatusCode
+		}
+
+		if d.Error != "" {
+			md.Error = &d.Error
+		}
+
+		out = append(out, md)
+	}
+
+	return out
+}
+
+// databaseWebhooks takes WebhookInputs as defined in the GraphQL models and
+// converts them to Webhooks defined in the database.
+func databaseWebhooks(webhooks ...*model.WebhookInput) ([]*webhook.Webhook, error) {
+	var out []*webhook.Webhook
+
+	for _, w := range webhooks {
+		if w == nil {
+			continue
+		}
+
+		if err := webhook.CheckURL(w.URL); err != nil {
+			return nil, err
+		}
+
+		if w.Secret == "" {
+			return nil, fmt.Errorf("secret of webhook %q is empty", w.URL)
+		}
+
+		var id string
+		if w.ID != nil {
+			if _, err := uuid.Parse(*w.ID); err != nil {
+				return nil, fmt.Errorf("invalid webhook ID %q", *w.ID)
+			}
+
+			id = *w.ID
+		} else {
+			id = uuid.NewString()
+		}
+
+		dw := &webhook.Webhook{
+			ID:     id,
+			URL:    w.URL,
+			Secret: w.Secret,
+		}
+
+		for _, e := range w.Entities {
+			dw.Entities = append(dw.Entities, strings.ToLower(e.String()))
+		}
+
+		for _, a := range w.Actions {
+			dw.Actions = append(dw.Actions, strings.ToLower(a.String()))
+		}
+
+		out = append(out, dw)
+	}
+
+	return out, nil
+}
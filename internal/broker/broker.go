@@ -0,0 +1,111 @@
+package broker
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+// subscriberBuffer is the number of changes buffered per subscriber. Changes
+// for slow subscribers are dropped once the buffer is full.
+const subscriberBuffer = 64
+
+// Filter decides whether a subscriber receives a change.
+type Filter func(change core.Change) bool
+
+// ForEntity returns a Filter which matches all changes of an entity.
+func ForEntity(entity string) Filter {
+	return func(change core.Change) bool {
+		return change.Entity == entity
+	}
+}
+
+// ForID returns a Filter which matches changes of a single entity.
+func ForID(entity, id string) Filter {
+	return func(change core.Change) bool {
+		return change.Entity == entity && change.ID == id
+	}
+}
+
+// ForInvitations returns a Filter which matches invitation changes of an
+// event.
+func ForInvitations(eventID string) Filter {
+	return func(change core.Change) bool {
+		return change.Entity == core.EntityInvitation && change.EventID == eventID
+	}
+}
+
+type subscriber struct {
+	ch     chan core.Change
+	filter Filter
+}
+
+// Broker fans out changes to subscribers within this process.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	logger      *zap.Logger
+}
+
+// New returns an in-process Broker.
+func New(logger *zap.Logger) *Broker {
+	return &Broker{
+		subscribers: make(map[*subscriber]struct{}),
+		logger:      logger,
+	}
+}
+
+// Publish delivers changes to all matching subscribers. It never blocks.
+func (b *Broker) Publish(_ context.Context, changes ...core.Change) {
+	b.dispatch(changes...)
+}
+
+func (b *Broker) dispatch(changes ...core.Change) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, change := range changes {
+		for sub := range b.subscribers {
+			if !sub.filter(change) {
+				continue
+			}
+
+			select {
+			case sub.ch <- change:
+			default:
+				b.logger.Warn("subscriber too slow, dropping change",
+					zap.String("entity", change.Entity),
+					zap.String("id", change.ID),
+				)
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all changes matching filter. The
+// channel is closed once ctx is done.
+func (b *Broker) Subscribe(ctx context.Context, filter Filter) <-chan core.Change {
+	sub := &subscriber{
+		ch:     make(chan core.Change, subscriberBuffer),
+		filter: filter,
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+
+		close(sub.ch)
+	}()
+
+	return sub.ch
+}
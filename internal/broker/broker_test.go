@@ -0,0 +1,49 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+func TestBroker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := New(zap.NewNop())
+
+	artists := b.Subscribe(ctx, ForEntity(core.EntityArtist))
+	invitations := b.Subscribe(ctx, ForInvitations("event-1"))
+
+	b.Publish(ctx,
+		core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: "artist-1"},
+		core.Change{Entity: core.EntityInvitation, Action: core.ActionUpsert, ID: "artist-1", EventID: "event-2"},
+		core.Change{Entity: core.EntityInvitation, Action: core.ActionUpsert, ID: "artist-1", EventID: "event-1", Confirmed: true},
+	)
+
+	t.Run("subscribers receive matching changes", func(t *testing.T) {
+		assert.Equal(t, core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: "artist-1"}, <-artists)
+		assert.Equal(t, "event-1", (<-invitations).EventID)
+
+		assert.Len(t, artists, 0)
+		assert.Len(t, invitations, 0)
+	})
+
+	t.Run("channel is closed after unsubscribing", func(t *testing.T) {
+		subCtx, subCancel := context.WithCancel(ctx)
+		ch := b.Subscribe(subCtx, ForID(core.EntityEvent, "event-1"))
+
+		subCancel()
+
+		require.Eventually(t, func() bool {
+			_, ok := <-ch
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+	})
+}
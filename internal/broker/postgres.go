@@ -0,0 +1,133 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	otelTrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+const (
+	channelChanges = "artistdb_changes"
+	reconnectDelay = 2 * time.Second
+	targetBroker   = "broker"
+)
+
+// PostgresBroker distributes changes between all API replicas via Postgres
+// LISTEN/NOTIFY. Published changes are only delivered to local subscribers
+// once they come back as notification, so every replica sees the same
+// stream.
+type PostgresBroker struct {
+	*Broker
+
+	conn       *core.ConnectionPool
+	connString string
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// NewPostgres returns a PostgresBroker which publishes and listens on its own
+// connections to connString until Close is called.
+func NewPostgres(ctx context.Context, connString string, logger *zap.Logger, tp otelTrace.TracerProvider) (*PostgresBroker, error) {
+	conn, err := core.NewConnectionPool(ctx, targetBroker, connString, core.PoolConfig{MaxConns: 2}, tp)
+	if err != nil {
+		return nil, fmt.Errorf("connecting failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &PostgresBroker{
+		Broker:     New(logger),
+		conn:       conn,
+		connString: connString,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	go b.listen(ctx)
+
+	return b, nil
+}
+
+// Publish sends changes to all replicas.
+func (b *PostgresBroker) Publish(ctx context.Context, changes ...core.Change) {
+	for _, change := range changes {
+		payload, err := json.Marshal(change)
+		if err != nil {
+			b.logger.Error("marshalling change failed", zap.Error(err))
+			continue
+		}
+
+		if _, err := b.conn.Exec(ctx, "SELECT pg_notify($1, $2)", channelChanges, string(payload)); err != nil {
+			b.logger.Error("publishing change failed",
+				zap.String("entity", change.Entity),
+				zap.String("id", change.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Close stops listening for notifications.
+func (b *PostgresBroker) Close() {
+	b.cancel()
+	<-b.done
+
+	b.conn.Close()
+}
+
+// listen receives notifications and reconnects on errors until ctx is done.
+func (b *PostgresBroker) listen(ctx context.Context) {
+	defer close(b.done)
+
+	for {
+		if err := b.receive(ctx); err != nil && ctx.Err() == nil {
+			b.logger.Error("listening for changes failed, reconnecting", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (b *PostgresBroker) receive(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.connString)
+	if err != nil {
+		return fmt.Errorf("connecting failed: %w", err)
+	}
+
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf("LISTEN %s", channelChanges)); err != nil {
+		return fmt.Errorf("listen failed: %w", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("waiting for notification failed: %w", err)
+		}
+
+		var change core.Change
+		if err := json.Unmarshal([]byte(n.Payload), &change); err != nil {
+			b.logger.Error("unmarshalling change failed", zap.Error(err))
+			continue
+		}
+
+		b.dispatch(change)
+	}
+}
@@ -0,0 +1,249 @@
+// Package calendar renders events in the iCalendar format (RFC 5545).
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/obitech/artist-db/internal"
+)
+
+const (
+	// ContentType is the media type of an iCalendar feed.
+	ContentType = "text/calendar; charset=utf-8"
+
+	dateTimeFormat      = "20060102T150405Z"
+	localDateTimeFormat = "20060102T150405"
+	maxLineLength       = 75
+)
+
+// Event is a single calendar entry.
+type Event struct {
+	// UID has to be globally unique and stable across feed refreshes.
+	UID          string
+	Summary      string
+	Start        time.Time
+	End          *time.Time
+	Location     string
+	Description  string
+	LastModified time.Time
+
+	// TZ is the IANA time zone of recurring events, whose occurrences keep
+	// their wall clock time across daylight saving changes. Without it,
+	// times are written in UTC.
+	TZ *time.Location
+	// RRule is the recurrence rule, e.g. FREQ=WEEKLY;BYDAY=TH.
+	RRule string
+	// ExDates are cancelled occurrences.
+	ExDates []time.Time
+	// RecurrenceID is set if the Event overrides a single occurrence of the
+	// recurring Event with the same UID.
+	RecurrenceID *time.Time
+}
+
+// Calendar is a named collection of Events.
+type Calendar struct {
+	Name   string
+	Events []Event
+	// Stamp is when the Calendar is generated, which is written as DTSTAMP.
+	// Zero means now.
+	Stamp time.Time
+}
+
+// Encode writes c to w.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := &encoder{w: bw}
+
+	enc.line("BEGIN", "VCALENDAR")
+	enc.line("VERSION", "2.0")
+	enc.line("PRODID", fmt.Sprintf("-//%s//%s//EN", internal.Name, internal.Version))
+	enc.line("CALSCALE", "GREGORIAN")
+	enc.line("METHOD", "PUBLISH")
+
+	if c.Name != "" {
+		enc.text("X-WR-CALNAME", c.Name)
+	}
+
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	for _, tz := range c.timezones(stamp) {
+		enc.timezone(tz.loc, tz.from, tz.to)
+	}
+
+	for _, ev := range c.Events {
+		enc.line("BEGIN", "VEVENT")
+		enc.line("UID", ev.UID)
+		enc.line("DTSTAMP", stamp.UTC().Format(dateTimeFormat))
+		enc.line("LAST-MODIFIED", ev.LastModified.UTC().Format(dateTimeFormat))
+		enc.time("DTSTART", ev.TZ, ev.Start)
+
+		if ev.End != nil {
+			enc.time("DTEND", ev.TZ, *ev.End)
+		}
+
+		if ev.RecurrenceID != nil {
+			enc.time("RECURRENCE-ID", ev.TZ, *ev.RecurrenceID)
+		}
+
+		if ev.RRule != "" {
+			enc.line("RRULE", ev.RRule)
+		}
+
+		if len(ev.ExDates) > 0 {
+			enc.time("EXDATE", ev.TZ, ev.ExDates...)
+		}
+
+		enc.text("SUMMARY", ev.Summary)
+
+		if ev.Location != "" {
+			enc.text("LOCATION", ev.Location)
+		}
+
+		if ev.Description != "" {
+			enc.text("DESCRIPTION", ev.Description)
+		}
+
+		enc.line("END", "VEVENT")
+	}
+
+	enc.line("END", "VCALENDAR")
+
+	if enc.err != nil {
+		return enc.err
+	}
+
+	return bw.Flush()
+}
+
+type usedTimezone struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// timezones returns the time zones Events are local to, in order of first
+// use, with the range their times span. The range extends timezoneYears
+// after stamp for recurring Events.
+func (c *Calendar) timezones(stamp time.Time) []usedTimezone {
+	var (
+		out   []usedTimezone
+		index = make(map[string]int)
+	)
+
+	for _, ev := range c.Events {
+		if ev.TZ == nil || ev.TZ == time.UTC {
+			continue
+		}
+
+		times := append([]time.Time{ev.Start}, ev.ExDates...)
+		if ev.End != nil {
+			times = append(times, *ev.End)
+		}
+
+		if ev.RecurrenceID != nil {
+			times = append(times, *ev.RecurrenceID)
+		}
+
+		i, ok := index[ev.TZ.String()]
+		if !ok {
+			i = len(out)
+			index[ev.TZ.String()] = i
+			out = append(out, usedTimezone{loc: ev.TZ, from: ev.Start, to: stamp.AddDate(timezoneYears, 0, 0)})
+		}
+
+		tz := &out[i]
+		for _, t := range times {
+			if t.Before(tz.from) {
+				tz.from = t
+			}
+
+			if !t.Before(tz.to) {
+				tz.to = t.Add(time.Second)
+			}
+		}
+	}
+
+	// Observances start a day early, so the offset at from is covered.
+	for i := range out {
+		out[i].from = out[i].from.Truncate(time.Hour).Add(-24 * time.Hour)
+	}
+
+	return out
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// time writes a property with one or more DATE-TIME values. Times are local
+// to tz if it is given, in UTC otherwise. The VTIMEZONE of tz has to be
+// written as well.
+func (e *encoder) time(name string, tz *time.Location, times ...time.Time) {
+	values := make([]string, 0, len(times))
+
+	if tz == nil || tz == time.UTC {
+		for _, t := range times {
+			values = append(values, t.UTC().Format(dateTimeFormat))
+		}
+
+		e.line(name, strings.Join(values, ","))
+		return
+	}
+
+	for _, t := range times {
+		values = append(values, t.In(tz).Format(localDateTimeFormat))
+	}
+
+	e.line(name+";TZID="+tz.String(), strings.Join(values, ","))
+}
+
+// text writes a property with a TEXT value, which has to be escaped.
+func (e *encoder) text(name, value string) {
+	e.line(name, escape(value))
+}
+
+// line writes a content line, folded after 75 octets.
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+
+	l := name + ":" + value
+
+	// The leading space of a continuation line counts towards its length.
+	for limit := maxLineLength; len(l) > limit; limit = maxLineLength - 1 {
+		// Never split a multi-byte character.
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(l[cut]) {
+			cut--
+		}
+
+		if _, e.err = e.w.WriteString(l[:cut] + "\r\n "); e.err != nil {
+			return
+		}
+
+		l = l[cut:]
+	}
+
+	_, e.err = e.w.WriteString(l + "\r\n")
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,152 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	start := time.Date(2022, 7, 1, 18, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	modified := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cal := &Calendar{
+		Name: "Festival",
+		Events: []Event{{
+			UID:          "1@artist-db",
+			Summary:      "Opening; Night, 1",
+			Start:        start,
+			Location:     "Club, Street 1, 12345 Berlin",
+			Description:  "Line-up:\n- Bob",
+			LastModified: modified,
+		}},
+		Stamp: time.Date(2022, 6, 2, 8, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+
+	out := buf.String()
+
+	t.Run("lines end with CRLF", func(t *testing.T) {
+		assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+		assert.NotContains(t, strings.ReplaceAll(out, "\r\n", ""), "\n")
+	})
+
+	t.Run("times are UTC", func(t *testing.T) {
+		assert.Contains(t, out, "DTSTART:20220701T163000Z\r\n")
+		assert.Contains(t, out, "LAST-MODIFIED:20220601T120000Z\r\n")
+	})
+
+	t.Run("DTSTAMP is the generation time", func(t *testing.T) {
+		assert.Contains(t, out, "DTSTAMP:20220602T080000Z\r\n")
+	})
+
+	t.Run("UTC times need no VTIMEZONE", func(t *testing.T) {
+		assert.NotContains(t, out, "VTIMEZONE")
+	})
+
+	t.Run("text is escaped", func(t *testing.T) {
+		assert.Contains(t, out, `SUMMARY:Opening\; Night\, 1`+"\r\n")
+		assert.Contains(t, out, `LOCATION:Club\, Street 1\, 12345 Berlin`+"\r\n")
+		assert.Contains(t, out, `DESCRIPTION:Line-up:\n- Bob`+"\r\n")
+	})
+
+	t.Run("events without end have no DTEND", func(t *testing.T) {
+		assert.NotContains(t, out, "DTEND")
+	})
+}
+
+func TestCalendar_EncodeRecurring(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	start := time.Date(2023, 3, 16, 20, 0, 0, 0, berlin)
+	exception := start.AddDate(0, 0, 7)
+	moved := start.AddDate(0, 0, 14)
+
+	cal := &Calendar{Stamp: start, Events: []Event{
+		{
+			UID:     "1@artist-db",
+			Summary: "Open Stage",
+			Start:   start,
+			TZ:      berlin,
+			RRule:   "FREQ=WEEKLY;COUNT=4",
+			ExDates: []time.Time{exception, start.AddDate(0, 0, 21)},
+		},
+		{
+			UID:          "1@artist-db",
+			Summary:      "Open Stage Special",
+			Start:        moved.Add(time.Hour),
+			TZ:           berlin,
+			RecurrenceID: &moved,
+		},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+
+	out := buf.String()
+
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Berlin:20230316T200000\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;COUNT=4\r\n")
+	assert.Contains(t, out, "EXDATE;TZID=Europe/Berlin:20230323T200000,20230406T200000\r\n")
+	assert.Contains(t, out, "RECURRENCE-ID;TZID=Europe/Berlin:20230330T200000\r\n")
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Berlin:20230330T210000\r\n")
+
+	t.Run("time zones are defined", func(t *testing.T) {
+		assert.Equal(t, 1, strings.Count(out, "BEGIN:VTIMEZONE\r\n"))
+		assert.Contains(t, out, "TZID:Europe/Berlin\r\n")
+		assert.Contains(t, out, "BEGIN:DAYLIGHT\r\nDTSTART:20230326T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n")
+		assert.Contains(t, out, "BEGIN:STANDARD\r\nDTSTART:20231029T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n")
+		assert.Less(t, strings.Index(out, "END:VTIMEZONE"), strings.Index(out, "BEGIN:VEVENT"))
+	})
+}
+
+func TestFormatOffset(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: "+0000"},
+		{seconds: 2 * 60 * 60, want: "+0200"},
+		{seconds: -(3*60*60 + 30*60), want: "-0330"},
+		{seconds: 53*60 + 28, want: "+005328"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatOffset(tt.seconds))
+		})
+	}
+}
+
+func TestEncoder_Fold(t *testing.T) {
+	var buf bytes.Buffer
+
+	cal := &Calendar{Events: []Event{{
+		UID:     "1",
+		Summary: strings.Repeat("ä", 100),
+	}}}
+
+	require.NoError(t, cal.Encode(&buf))
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength)
+		assert.True(t, utf8.ValidString(line), "line %q splits a character", line)
+
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+
+	assert.Contains(t, unfolded.String(), "\nSUMMARY:"+strings.Repeat("ä", 100)+"\n")
+}
@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// timezoneYears is how long after the feed is generated the UTC offsets of
+// a VTIMEZONE are listed. Later times keep the last offset.
+const timezoneYears = 10
+
+// transition is a change of the UTC offset of a time zone.
+type transition struct {
+	at       time.Time
+	name     string
+	from, to int
+	dst      bool
+}
+
+// transitions returns the changes of the UTC offset of tz within [from, to).
+// Offsets are compared daily, which finds the transitions of all real time
+// zones.
+func transitions(tz *time.Location, from, to time.Time) []transition {
+	var out []transition
+
+	_, offset := from.In(tz).Zone()
+
+	for t := from.Unix(); t < to.Unix(); t += 24 * 60 * 60 {
+		next := t + 24*60*60
+		if _, o := time.Unix(next, 0).In(tz).Zone(); o == offset {
+			continue
+		}
+
+		// The first second with the new offset is within (lo, hi].
+		lo, hi := t, next
+		for hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			if _, o := time.Unix(mid, 0).In(tz).Zone(); o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		at := time.Unix(hi, 0).In(tz)
+		name, o := at.Zone()
+
+		out = append(out, transition{at: at.UTC(), name: name, from: offset, to: o, dst: at.IsDST()})
+		offset = o
+	}
+
+	return out
+}
+
+// timezone writes a VTIMEZONE component, which clients need to resolve the
+// TZID of times local to tz within [from, to). Each transition is listed on
+// its own instead of as a recurrence rule, since rules of time zones change.
+func (e *encoder) timezone(tz *time.Location, from, to time.Time) {
+	e.line("BEGIN", "VTIMEZONE")
+	e.line("TZID", tz.String())
+
+	local := from.In(tz)
+	name, offset := local.Zone()
+	e.observance(transition{at: from, name: name, from: offset, to: offset, dst: local.IsDST()})
+
+	for _, t := range transitions(tz, from, to) {
+		e.observance(t)
+	}
+
+	e.line("END", "VTIMEZONE")
+}
+
+// observance writes the STANDARD or DAYLIGHT component starting at t.
+func (e *encoder) observance(t transition) {
+	kind := "STANDARD"
+	if t.dst {
+		kind = "DAYLIGHT"
+	}
+
+	e.line("BEGIN", kind)
+	// The start is local to the offset before the transition.
+	e.line("DTSTART", t.at.In(time.FixedZone("", t.from)).Format(localDateTimeFormat))
+	e.line("TZOFFSETFROM", formatOffset(t.from))
+	e.line("TZOFFSETTO", formatOffset(t.to))
+
+	if t.name != "" {
+		e.text("TZNAME", t.name)
+	}
+
+	e.line("END", kind)
+}
+
+// formatOffset formats a UTC offset in seconds as a UTC-OFFSET value, e.g.
+// +0100.
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+
+	return s
+}
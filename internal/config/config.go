@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/alecthomas/kong"
 	kongyaml "github.com/alecthomas/kong-yaml"
 
@@ -13,10 +15,104 @@ type serviceConfig struct {
 }
 
 type Config struct {
-	ListenAddress      string        `env:"ADB_LISTEN_ADDRESS_HTTP" help:"listen address of the http server" default:":8080"`
-	LoggingMode        string        `env:"ADB_LOGGING_MODE" help:"for which environment logging should be configured (dev,prod)" enum:"dev,prod" default:"dev"`
-	DbConnectionString string        `env:"ADB_CONN_STRING" help:"connection string to the database"`
-	Tracing            TracingConfig `embed:"" prefix:"tracing-"`
+	ListenAddress      string             `env:"ADB_LISTEN_ADDRESS_HTTP" help:"listen address of the http server" default:":8080"`
+	LoggingMode        string             `env:"ADB_LOGGING_MODE" help:"for which environment logging should be configured (dev,prod)" enum:"dev,prod" default:"dev"`
+	DbConnectionString string             `env:"ADB_CONN_STRING" help:"connection string to the database"`
+	ReadinessTimeout   time.Duration      `env:"ADB_READINESS_TIMEOUT" help:"deadline for all readiness checks combined" default:"2s"`
+	OperationTimeout   time.Duration      `env:"ADB_OPERATION_TIMEOUT" help:"deadline for a single GraphQL operation. 0 disables it" default:"10s"`
+	PublicMaxAge       time.Duration      `env:"ADB_PUBLIC_MAX_AGE" help:"how long responses of the public API may be cached" default:"5m"`
+	ChangeBackend      string             `env:"ADB_CHANGE_BACKEND" help:"how changes are distributed to subscribers (local,postgres)" enum:"local,postgres" default:"local"`
+	DbPool             DbPoolConfig       `embed:"" prefix:"db-pool-"`
+	DbReplicas         DbReplicaConfig    `embed:"" prefix:"db-replica-"`
+	Webhooks           WebhookConfig      `embed:"" prefix:"webhook-"`
+	Outbox             OutboxConfig       `embed:"" prefix:"outbox-"`
+	Tracing            TracingConfig      `embed:"" prefix:"tracing-"`
+	ExchangeRates      ExchangeRateConfig `embed:"" prefix:"exchange-rates-"`
+	Retention          RetentionConfig    `embed:"" prefix:"retention-"`
+	Encryption         EncryptionConfig   `embed:"" prefix:"encryption-"`
+	GRPC               GRPCConfig         `embed:"" prefix:"grpc-"`
+	GraphQL            GraphQLConfig      `embed:"" prefix:"graphql-"`
+	RateLimit          RateLimitConfig    `embed:"" prefix:"rate-limit-"`
+}
+
+type DbPoolConfig struct {
+	MaxConns          int32         `env:"ADB_DB_POOL_MAX_CONNS" help:"maximum size of the connection pool. 0 uses the pgx default"`
+	MinConns          int32         `env:"ADB_DB_POOL_MIN_CONNS" help:"minimum size of the connection pool"`
+	MaxConnLifetime   time.Duration `env:"ADB_DB_POOL_MAX_CONN_LIFETIME" help:"duration after which a connection is closed. 0 uses the pgx default"`
+	MaxConnIdleTime   time.Duration `env:"ADB_DB_POOL_MAX_CONN_IDLE_TIME" help:"duration after which an idle connection is closed. 0 uses the pgx default"`
+	HealthCheckPeriod time.Duration `env:"ADB_DB_POOL_HEALTH_CHECK_PERIOD" help:"duration between health checks of idle connections. 0 uses the pgx default"`
+	StatementTimeout  time.Duration `env:"ADB_DB_STATEMENT_TIMEOUT" help:"Postgres statement_timeout set on every connection. 0 disables it" default:"5s"`
+}
+
+type DbReplicaConfig struct {
+	ConnectionStrings []string      `env:"ADB_REPLICA_CONN_STRINGS" help:"comma-separated connection strings to read replicas"`
+	CheckInterval     time.Duration `env:"ADB_REPLICA_CHECK_INTERVAL" help:"interval between replica health checks" default:"5s"`
+}
+
+type WebhookConfig struct {
+	Enabled     bool          `env:"ADB_WEBHOOKS_ENABLED" help:"deliver changes to registered webhooks" default:"true"`
+	Timeout     time.Duration `env:"ADB_WEBHOOK_TIMEOUT" help:"deadline for a single delivery attempt" default:"5s"`
+	MaxAttempts int           `env:"ADB_WEBHOOK_MAX_ATTEMPTS" help:"number of delivery attempts before giving up" default:"5"`
+	Workers     int           `env:"ADB_WEBHOOK_WORKERS" help:"number of concurrent deliveries" default:"4"`
+
+	AllowInternalTargets bool `env:"ADB_WEBHOOK_ALLOW_INTERNAL_TARGETS" help:"deliver to loopback, link-local and private addresses" default:"false"`
+}
+
+type OutboxConfig struct {
+	Interval  time.Duration `env:"ADB_OUTBOX_INTERVAL" help:"interval between polls of the outbox" default:"200ms"`
+	BatchSize int           `env:"ADB_OUTBOX_BATCH_SIZE" help:"maximum number of changes relayed at once" default:"100"`
+	Retention time.Duration `env:"ADB_OUTBOX_RETENTION" help:"how long relayed changes are kept in the outbox" default:"24h"`
+}
+
+type ExchangeRateConfig struct {
+	File string `env:"ADB_EXCHANGE_RATES_FILE" help:"CSV file of dated exchange rates (date,currency,rate). Amounts are not converted without it"`
+	Base string `env:"ADB_EXCHANGE_RATES_BASE" help:"currency the exchange rates are against" default:"EUR"`
+}
+
+type RetentionConfig struct {
+	Enabled     bool          `env:"ADB_RETENTION_ENABLED" help:"periodically purge expired personal data and long soft-deleted rows"`
+	Interval    time.Duration `env:"ADB_RETENTION_INTERVAL" help:"interval between purges" default:"24h"`
+	ContactData time.Duration `env:"ADB_RETENTION_CONTACT_DATA" help:"how long email and socials are kept after an artist's last event or update. 0 keeps them" default:"26280h"`
+	OriginData  time.Duration `env:"ADB_RETENTION_ORIGIN_DATA" help:"how long date and place of birth and nationality are kept after an artist's last event or update. 0 keeps them" default:"26280h"`
+	ProfileData time.Duration `env:"ADB_RETENTION_PROFILE_DATA" help:"how long pronouns, language and bios are kept after an artist's last event or update. 0 keeps them" default:"0"`
+	DeletedRows time.Duration `env:"ADB_RETENTION_DELETED_ROWS" help:"how long soft-deleted artists, events and locations are kept. 0 keeps them" default:"2160h"`
+}
+
+// EncryptionConfig configures encryption of artist data at rest. Keys of the
+// env provider are read from ADB_ENCRYPTION_KEYS, so they never end up in a
+// config file.
+type EncryptionConfig struct {
+	Provider string   `env:"ADB_ENCRYPTION_PROVIDER" help:"source of the key encryption keys (none,file,env,kms). kms is a local stand-in backed by the key file" enum:"none,file,env,kms" default:"none"`
+	KeyFile  string   `env:"ADB_ENCRYPTION_KEY_FILE" help:"file with one key encryption key per line as <id>:<base64 key>. The last key encrypts"`
+	KMSKeyID string   `env:"ADB_ENCRYPTION_KMS_KEY_ID" help:"ID of the KMS key which wraps data keys"`
+	IndexKey string   `env:"ADB_ENCRYPTION_INDEX_KEY" help:"base64 key of at least 32 bytes for the blind index on email"`
+	Columns  []string `env:"ADB_ENCRYPTION_COLUMNS" help:"artist columns to encrypt" default:"email,date_of_birth,place_of_birth"`
+}
+
+// GraphQLConfig configures which queries the GraphQL endpoint accepts.
+type GraphQLConfig struct {
+	APQCache      string `env:"ADB_GRAPHQL_APQ_CACHE" help:"where automatic persisted queries are stored (lru,postgres)" enum:"lru,postgres" default:"lru"`
+	APQCacheSize  int    `env:"ADB_GRAPHQL_APQ_CACHE_SIZE" help:"number of persisted queries kept in memory" default:"100"`
+	APQMaxStored  int    `env:"ADB_GRAPHQL_APQ_MAX_STORED" help:"number of persisted queries kept in Postgres, the least recently used are deleted first" default:"10000"`
+	AllowlistFile string `env:"ADB_GRAPHQL_ALLOWLIST_FILE" help:"JSON file of hashes to queries known from the frontend build"`
+	Strict        bool   `env:"ADB_GRAPHQL_STRICT" help:"only accept queries of the allowlist"`
+	MaxComplexity int    `env:"ADB_GRAPHQL_MAX_COMPLEXITY" help:"maximum complexity of an operation according to the @cost directives. 0 disables the limit" default:"5000"`
+	MaxDepth      int    `env:"ADB_GRAPHQL_MAX_DEPTH" help:"maximum number of nested fields of an operation. 0 disables the limit" default:"10"`
+}
+
+// RateLimitConfig configures the token bucket each client of the HTTP APIs
+// gets.
+type RateLimitConfig struct {
+	Rate           float64  `env:"ADB_RATE_LIMIT_RATE" help:"requests per second allowed per client. 0 disables rate limiting" default:"0"`
+	Burst          int      `env:"ADB_RATE_LIMIT_BURST" help:"number of requests a client may send at once" default:"50"`
+	APIKeys        []string `env:"ADB_RATE_LIMIT_API_KEYS" help:"comma-separated API keys which are limited on their own instead of by IP, sent as X-API-Key header"`
+	TrustedProxies []string `env:"ADB_RATE_LIMIT_TRUSTED_PROXIES" help:"comma-separated IPs or CIDR ranges of reverse proxies whose X-Forwarded-For header identifies clients"`
+}
+
+// GRPCConfig configures the gRPC API for internal services.
+type GRPCConfig struct {
+	ListenAddress string   `env:"ADB_LISTEN_ADDRESS_GRPC" help:"listen address of the gRPC server. Empty disables it"`
+	Tokens        []string `env:"ADB_GRPC_TOKENS" help:"comma-separated bearer tokens accepted by the gRPC server. Calls are not authenticated without any"`
 }
 
 type TracingConfig struct {
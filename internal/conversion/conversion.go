@@ -12,6 +12,30 @@ func String(s *string) string {
 	return *s
 }
 
+func Bool(b *bool) bool {
+	if b == nil {
+		return false
+	}
+
+	return *b
+}
+
+func Int(i *int) int {
+	if i == nil {
+		return 0
+	}
+
+	return *i
+}
+
+func Int64(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+
+	return *i
+}
+
 func Time(t *time.Time) time.Time {
 	if t == nil {
 		return time.Time{}
@@ -9,3 +9,9 @@ import (
 func TestNewArtist(t *testing.T) {
 	require.NotEmpty(t, New().ID)
 }
+
+func TestConsent_Validate(t *testing.T) {
+	require.NoError(t, (&Consent{Purpose: ConsentPublishBio, Method: ConsentForm}).Validate())
+	require.Error(t, (&Consent{Purpose: "sell_data", Method: ConsentForm}).Validate())
+	require.Error(t, (&Consent{Purpose: ConsentStoreData, Method: "telepathy"}).Validate())
+}
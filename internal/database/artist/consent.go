@@ -0,0 +1,182 @@
+package artist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const entityConsent = "consent"
+
+// ConsentPurpose is what an Artist consents to.
+type ConsentPurpose string
+
+const (
+	// ConsentStoreData covers storing personal data beyond names.
+	ConsentStoreData ConsentPurpose = "store_data"
+	// ConsentPublishProfile covers publishing social media profiles, and the
+	// full name of Artists without an artist name.
+	ConsentPublishProfile ConsentPurpose = "publish_profile"
+	// ConsentPublishBio covers publishing biographies.
+	ConsentPublishBio ConsentPurpose = "publish_bio"
+)
+
+// ConsentMethod is how consent was given or withdrawn.
+type ConsentMethod string
+
+const (
+	ConsentForm     ConsentMethod = "form"
+	ConsentEmail    ConsentMethod = "email"
+	ConsentWritten  ConsentMethod = "written"
+	ConsentVerbal   ConsentMethod = "verbal"
+	ConsentContract ConsentMethod = "contract"
+)
+
+// Consent records that an Artist granted or withdrew consent for a purpose.
+// Consents are never modified, the latest Consent per purpose is in effect.
+type Consent struct {
+	ID         string
+	ArtistID   string
+	Purpose    ConsentPurpose
+	Granted    bool
+	Method     ConsentMethod
+	Note       string
+	RecordedAt time.Time
+}
+
+// Validate returns an error if c can't be recorded.
+func (c *Consent) Validate() error {
+	switch c.Purpose {
+	case ConsentStoreData, ConsentPublishProfile, ConsentPublishBio:
+	default:
+		return fmt.Errorf("unknown consent purpose %q", c.Purpose)
+	}
+
+	switch c.Method {
+	case ConsentForm, ConsentEmail, ConsentWritten, ConsentVerbal, ConsentContract:
+	default:
+		return fmt.Errorf("unknown consent method %q", c.Method)
+	}
+
+	return nil
+}
+
+// RecordConsent stores c. An unset ID or RecordedAt is initialized.
+func (h *Handler) RecordConsent(ctx context.Context, c *Consent) error {
+	if _, err := uuid.Parse(c.ArtistID); err != nil {
+		return core.ErrInvalidUUID
+	}
+
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", core.ErrInvalidInput, err)
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+
+	if c.RecordedAt.IsZero() {
+		c.RecordedAt = time.Now()
+	}
+
+	c.RecordedAt = c.RecordedAt.UTC()
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "consent.record")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(id, artist_id, purpose, granted, method, note, recorded_at)
+		SELECT
+			$1, id, $3, $4, $5, NULLIF($6, ''), $7
+		FROM
+			%q
+		WHERE
+			id=$2 AND anonymized_at IS NULL`, core.TableArtistConsents, core.TableArtists)
+
+	tag, err := h.conn.Exec(spanCtx, stmt, c.ID, c.ArtistID, string(c.Purpose), c.Granted, string(c.Method), c.Note, c.RecordedAt)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityConsent, "record")
+		return fmt.Errorf("recording consent: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityConsent, "record")
+	h.logger.Info("tuple modified",
+		zap.String("action", "record"),
+		zap.String("entity", entityConsent),
+		zap.String("id", c.ID),
+		zap.String("artistID", c.ArtistID),
+	)
+
+	return nil
+}
+
+// Consents returns all Consents of an Artist, latest first.
+func (h *Handler) Consents(ctx context.Context, artistID string) ([]Consent, error) {
+	if _, err := uuid.Parse(artistID); err != nil {
+		return nil, core.ErrInvalidUUID
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "consent.get")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			id, artist_id, purpose, granted, method, note, recorded_at
+		FROM
+			%q
+		WHERE
+			artist_id=$1
+		ORDER BY
+			recorded_at DESC, id`, core.TableArtistConsents)
+
+	rows, err := h.conn.Query(spanCtx, stmt, artistID)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityConsent, "get")
+		return nil, fmt.Errorf("querying consents: %w", err)
+	}
+
+	defer rows.Close()
+
+	consents := []Consent{}
+
+	for rows.Next() {
+		var (
+			c               Consent
+			purpose, method string
+			note            *string
+		)
+
+		if err := rows.Scan(&c.ID, &c.ArtistID, &purpose, &c.Granted, &method, &note, &c.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scanning consent: %w", err)
+		}
+
+		c.Purpose = ConsentPurpose(purpose)
+		c.Method = ConsentMethod(method)
+		c.Note = conversion.String(note)
+		c.RecordedAt = c.RecordedAt.UTC()
+
+		consents = append(consents, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading consents: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsRetrieved(len(consents), entityConsent)
+
+	return consents, nil
+}
@@ -0,0 +1,309 @@
+package artist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/encryption"
+)
+
+// EncryptableColumns are the columns of the artists table which can be
+// encrypted.
+var EncryptableColumns = []string{"email", "date_of_birth", "place_of_birth", "nationality"}
+
+// Option customizes a Handler.
+type Option func(h *Handler)
+
+// WithEncryption encrypts columns, which must be EncryptableColumns, on
+// upsert and decrypts them on retrieval. Emails are looked up by blind index.
+func WithEncryption(enc *encryption.Encrypter, columns ...string) Option {
+	return func(h *Handler) {
+		h.enc = enc
+		h.encrypted = make(map[string]bool, len(columns))
+
+		for _, c := range columns {
+			h.encrypted[c] = true
+		}
+	}
+}
+
+// seal encrypts value if column is encrypted. Empty values stay empty.
+func (h *Handler) seal(ctx context.Context, column, value string) (string, error) {
+	if value == "" || !h.encrypted[column] {
+		return value, nil
+	}
+
+	sealed, err := h.enc.Encrypt(ctx, column, value)
+	if err != nil {
+		return "", fmt.Errorf("encrypting %s: %w", column, err)
+	}
+
+	return sealed, nil
+}
+
+// unseal decrypts value if it is encrypted, regardless of whether column is
+// still configured to be encrypted.
+func (h *Handler) unseal(ctx context.Context, column string, value *string) (string, error) {
+	s := conversion.String(value)
+	if !encryption.IsEncrypted(s) {
+		return s, nil
+	}
+
+	if h.enc == nil {
+		return "", fmt.Errorf("%s is encrypted but encryption is not configured", column)
+	}
+
+	plaintext, err := h.enc.Decrypt(ctx, column, s)
+	if err != nil {
+		return "", fmt.Errorf("decrypting %s: %w", column, err)
+	}
+
+	return plaintext, nil
+}
+
+// sealDate formats t and encrypts it if date_of_birth is encrypted. The zero
+// time is stored as NULL.
+func (h *Handler) sealDate(ctx context.Context, t time.Time) (*string, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	sealed, err := h.seal(ctx, "date_of_birth", t.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealed, nil
+}
+
+// unsealDate reverses sealDate.
+func (h *Handler) unsealDate(ctx context.Context, value *string) (time.Time, error) {
+	s, err := h.unseal(ctx, "date_of_birth", value)
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing date_of_birth: %w", err)
+	}
+
+	return t.UTC(), nil
+}
+
+// unsealArtist decrypts the encryptable columns of an artist.
+func (h *Handler) unsealArtist(ctx context.Context, dob, pob, nationality, email *string) (Origin, string, error) {
+	var (
+		origin Origin
+		err    error
+	)
+
+	if origin.DateOfBirth, err = h.unsealDate(ctx, dob); err != nil {
+		return Origin{}, "", err
+	}
+
+	if origin.PlaceOfBirth, err = h.unseal(ctx, "place_of_birth", pob); err != nil {
+		return Origin{}, "", err
+	}
+
+	if origin.Nationality, err = h.unseal(ctx, "nationality", nationality); err != nil {
+		return Origin{}, "", err
+	}
+
+	plainEmail, err := h.unseal(ctx, "email", email)
+	if err != nil {
+		return Origin{}, "", err
+	}
+
+	return origin, plainEmail, nil
+}
+
+// emailIndex returns the blind index of email, or nil without encryption.
+func (h *Handler) emailIndex(email string) *string {
+	if h.enc == nil || email == "" {
+		return nil
+	}
+
+	index := h.enc.BlindIndex(email)
+	return &index
+}
+
+// Reencrypt encrypts all EncryptableColumns which are configured to be
+// encrypted with the current key, decrypts those which are not, and
+// recomputes the blind index. It runs in batches of batchSize artists and
+// returns how many artists were rewritten. Run it after rotating keys or
+// changing the encrypted columns.
+func (h *Handler) Reencrypt(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	var (
+		total int
+		after = "00000000-0000-0000-0000-000000000000"
+	)
+
+	for {
+		var (
+			n    int
+			last string
+		)
+
+		if err := core.RunInTx(ctx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+			var err error
+			n, last, err = h.reencryptBatch(ctx, tx, after, batchSize)
+			return err
+		}); err != nil {
+			return total, fmt.Errorf("re-encrypting artists after %s: %w", after, err)
+		}
+
+		total += n
+
+		if last == "" {
+			break
+		}
+
+		after = last
+	}
+
+	h.logger.Info("artists re-encrypted", zap.Int("count", total))
+
+	return total, nil
+}
+
+// reencryptBatch rewrites up to limit artists with an ID greater than after.
+// It returns the number of rewritten artists and the last ID of the batch,
+// which is empty if no artists were left.
+func (h *Handler) reencryptBatch(ctx context.Context, tx pgx.Tx, after string, limit int) (int, string, error) {
+	stmt := fmt.Sprintf(`
+		SELECT
+			id, email, email_index, date_of_birth, place_of_birth, nationality
+		FROM
+			%q
+		WHERE
+			id > $1
+		ORDER BY
+			id
+		LIMIT $2
+		FOR UPDATE`, core.TableArtists)
+
+	rows, err := tx.Query(ctx, stmt, after, limit)
+	if err != nil {
+		return 0, "", err
+	}
+
+	type row struct {
+		id                                       string
+		email, emailIndex, dob, pob, nationality *string
+	}
+
+	var batch []*row
+	for rows.Next() {
+		r := &row{}
+		if err := rows.Scan(&r.id, &r.email, &r.emailIndex, &r.dob, &r.pob, &r.nationality); err != nil {
+			rows.Close()
+			return 0, "", err
+		}
+
+		batch = append(batch, r)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+
+	if len(batch) == 0 {
+		return 0, "", nil
+	}
+
+	var n int
+	for _, r := range batch {
+		plainEmail, err := h.unseal(ctx, "email", r.email)
+		if err != nil {
+			return 0, "", fmt.Errorf("artist %s: %w", r.id, err)
+		}
+
+		changed := false
+
+		if index := h.emailIndex(plainEmail); conversion.String(index) != conversion.String(r.emailIndex) {
+			r.emailIndex = index
+			changed = true
+		}
+
+		for _, f := range []struct {
+			column string
+			value  **string
+		}{
+			{"email", &r.email},
+			{"date_of_birth", &r.dob},
+			{"place_of_birth", &r.pob},
+			{"nationality", &r.nationality},
+		} {
+			v, ok, err := h.rewrite(ctx, f.column, *f.value)
+			if err != nil {
+				return 0, "", fmt.Errorf("artist %s: %w", r.id, err)
+			}
+
+			if ok {
+				*f.value = v
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		stmt := fmt.Sprintf(`
+			UPDATE
+				%q
+			SET
+				email=$2,
+				email_index=$3,
+				date_of_birth=$4,
+				place_of_birth=$5,
+				nationality=$6
+			WHERE
+				id=$1`, core.TableArtists)
+
+		if _, err := tx.Exec(ctx, stmt, r.id, r.email, r.emailIndex, r.dob, r.pob, r.nationality); err != nil {
+			return 0, "", err
+		}
+
+		n++
+	}
+
+	return n, batch[len(batch)-1].id, nil
+}
+
+// rewrite returns the value of column as it should be stored and whether it
+// differs from value.
+func (h *Handler) rewrite(ctx context.Context, column string, value *string) (*string, bool, error) {
+	if conversion.String(value) == "" {
+		return value, false, nil
+	}
+
+	if h.encrypted[column] && h.enc.Current(*value) {
+		return value, false, nil
+	}
+
+	plaintext, err := h.unseal(ctx, column, value)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sealed, err := h.seal(ctx, column, plaintext)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &sealed, sealed != *value, nil
+}
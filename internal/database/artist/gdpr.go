@@ -0,0 +1,623 @@
+package artist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	// AnonymizedFirstName and AnonymizedLastName replace the names of
+	// anonymized artists, which still appear in historical events.
+	AnonymizedFirstName = "Anonymized"
+	AnonymizedLastName  = "Artist"
+)
+
+// ErrAnonymized is returned when modifying an anonymized Artist.
+var ErrAnonymized = errors.New("artist is anonymized")
+
+// PersonalData is everything stored about an Artist, including soft-deleted
+// data, as required for a data subject access request.
+type PersonalData struct {
+	ExportedAt  time.Time            `json:"exportedAt"`
+	Artist      ExportedArtist       `json:"artist"`
+	Invitations []ExportedInvitation `json:"invitations"`
+	Slots       []ExportedSlot       `json:"slots"`
+	Consents    []ExportedConsent    `json:"consents"`
+	// Changes are the recorded modifications of the Artist and their
+	// invitations which have not yet been cleaned up.
+	Changes           []ExportedChange   `json:"changes"`
+	WebhookDeliveries []ExportedDelivery `json:"webhookDeliveries"`
+}
+
+// ExportedArtist holds the personal fields of an Artist.
+type ExportedArtist struct {
+	ID           string     `json:"id"`
+	FirstName    string     `json:"firstName"`
+	LastName     string     `json:"lastName"`
+	ArtistName   string     `json:"artistName,omitempty"`
+	Pronouns     []string   `json:"pronouns,omitempty"`
+	DateOfBirth  *time.Time `json:"dateOfBirth,omitempty"`
+	PlaceOfBirth string     `json:"placeOfBirth,omitempty"`
+	Nationality  string     `json:"nationality,omitempty"`
+	Language     string     `json:"language,omitempty"`
+	Facebook     string     `json:"facebook,omitempty"`
+	Instagram    string     `json:"instagram,omitempty"`
+	Bandcamp     string     `json:"bandcamp,omitempty"`
+	BioGerman    string     `json:"bioGer,omitempty"`
+	BioEnglish   string     `json:"bioEn,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
+}
+
+// ExportedInvitation is an invitation of the Artist to an event.
+type ExportedInvitation struct {
+	EventID   string       `json:"eventID"`
+	EventName string       `json:"eventName"`
+	Start     *time.Time   `json:"start,omitempty"`
+	End       *time.Time   `json:"end,omitempty"`
+	Confirmed bool         `json:"confirmed"`
+	Fee       *ExportedFee `json:"fee,omitempty"`
+}
+
+// ExportedFee is the fee agreed with the Artist. Amounts are in minor units
+// of Currency.
+type ExportedFee struct {
+	Amount                 int64      `json:"amount"`
+	Currency               string     `json:"currency"`
+	Type                   string     `json:"type"`
+	Days                   int        `json:"days"`
+	TravelAllowance        int64      `json:"travelAllowance"`
+	AccommodationAllowance int64      `json:"accommodationAllowance"`
+	PaymentStatus          string     `json:"paymentStatus"`
+	DueDate                *time.Time `json:"dueDate,omitempty"`
+	PaidAt                 *time.Time `json:"paidAt,omitempty"`
+	ContractRefs           []string   `json:"contractRefs,omitempty"`
+}
+
+// ExportedSlot is a scheduled performance of the Artist.
+type ExportedSlot struct {
+	EventID string    `json:"eventID"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Stage   string    `json:"stage,omitempty"`
+}
+
+// ExportedConsent is a granted or withdrawn consent of the Artist.
+type ExportedConsent struct {
+	Purpose    string    `json:"purpose"`
+	Granted    bool      `json:"granted"`
+	Method     string    `json:"method"`
+	Note       string    `json:"note,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// ExportedChange is a recorded modification of the Artist or an invitation.
+type ExportedChange struct {
+	Entity       string     `json:"entity"`
+	Action       string     `json:"action"`
+	EventID      string     `json:"eventID,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	DispatchedAt *time.Time `json:"dispatchedAt,omitempty"`
+}
+
+// ExportedDelivery is an attempt to notify a webhook about a change of the
+// Artist.
+type ExportedDelivery struct {
+	WebhookID  string    `json:"webhookID"`
+	Entity     string    `json:"entity"`
+	Action     string    `json:"action"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Succeeded  bool      `json:"succeeded"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ExportPersonalData collects all data linked to an Artist, including
+// soft-deleted ones, from a consistent snapshot.
+func (h *Handler) ExportPersonalData(ctx context.Context, id string) (*PersonalData, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, core.ErrInvalidUUID
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.export")
+	defer span.End()
+
+	opts := core.DefaultTxOptions
+	opts.IsoLevel = pgx.RepeatableRead
+
+	var data *PersonalData
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, opts, func(ctx context.Context, tx pgx.Tx) error {
+		data = &PersonalData{
+			ExportedAt:        time.Now().UTC(),
+			Invitations:       []ExportedInvitation{},
+			Slots:             []ExportedSlot{},
+			Consents:          []ExportedConsent{},
+			Changes:           []ExportedChange{},
+			WebhookDeliveries: []ExportedDelivery{},
+		}
+
+		for _, fn := range []func(context.Context, pgx.Tx, string, *PersonalData) error{
+			h.exportArtist,
+			exportInvitations,
+			exportSlots,
+			exportConsents,
+			exportChanges,
+			exportDeliveries,
+		} {
+			if err := fn(ctx, tx, id, data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, core.ErrNotFound
+		}
+
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityArtist, "export")
+		return nil, fmt.Errorf("exporting personal data: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsRetrieved(1, entityArtist)
+	h.logger.Info("personal data exported",
+		zap.String("entity", entityArtist),
+		zap.String("id", id),
+	)
+
+	return data, nil
+}
+
+func (h *Handler) exportArtist(ctx context.Context, tx pgx.Tx, id string, data *PersonalData) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			id,
+			first_name,
+			last_name,
+			artist_name,
+			pronouns,
+			date_of_birth,
+			place_of_birth,
+			nationality,
+			language,
+			facebook,
+			instagram,
+			bandcamp,
+			bio_ger,
+			bio_en,
+			email,
+			created_at,
+			updated_at,
+			deleted_at,
+			anonymized_at
+		FROM
+			%q
+		WHERE
+			id=$1`, core.TableArtists)
+
+	var (
+		a                                            = &data.Artist
+		artistName, dob, pob, nationality, language  *string
+		facebook, instagram, bandcamp, bioGer, bioEn *string
+		email                                        *string
+		deletedAt, anonymizedAt                      *time.Time
+	)
+
+	if err := tx.QueryRow(ctx, stmt, id).Scan(
+		&a.ID,
+		&a.FirstName,
+		&a.LastName,
+		&artistName,
+		&a.Pronouns,
+		&dob,
+		&pob,
+		&nationality,
+		&language,
+		&facebook,
+		&instagram,
+		&bandcamp,
+		&bioGer,
+		&bioEn,
+		&email,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+		&deletedAt,
+		&anonymizedAt,
+	); err != nil {
+		return err
+	}
+
+	origin, plainEmail, err := h.unsealArtist(ctx, dob, pob, nationality, email)
+	if err != nil {
+		return err
+	}
+
+	if !origin.DateOfBirth.IsZero() {
+		a.DateOfBirth = &origin.DateOfBirth
+	}
+
+	a.ArtistName = conversion.String(artistName)
+	a.PlaceOfBirth = origin.PlaceOfBirth
+	a.Nationality = origin.Nationality
+	a.Language = conversion.String(language)
+	a.Facebook = conversion.String(facebook)
+	a.Instagram = conversion.String(instagram)
+	a.Bandcamp = conversion.String(bandcamp)
+	a.BioGerman = conversion.String(bioGer)
+	a.BioEnglish = conversion.String(bioEn)
+	a.Email = plainEmail
+	a.CreatedAt = a.CreatedAt.UTC()
+	a.UpdatedAt = a.UpdatedAt.UTC()
+	a.DeletedAt = utc(deletedAt)
+	a.AnonymizedAt = utc(anonymizedAt)
+
+	return nil
+}
+
+func exportInvitations(ctx context.Context, tx pgx.Tx, id string, data *PersonalData) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			e.id,
+			e.name,
+			e.start_time,
+			e.end_time,
+			ia.confirmed,
+			ia.fee_amount,
+			ia.fee_currency,
+			ia.fee_type,
+			ia.fee_days,
+			ia.travel_allowance,
+			ia.accommodation_allowance,
+			ia.payment_status,
+			ia.due_date,
+			ia.paid_at,
+			ia.contract_refs
+		FROM
+			%q ia
+		JOIN
+			%q e ON e.id = ia.event_id
+		WHERE
+			ia.artist_id=$1
+		ORDER BY
+			e.start_time NULLS LAST, e.id`, core.TableInvitedArtists, core.TableEvents)
+
+	rows, err := tx.Query(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("querying invitations: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			inv                              ExportedInvitation
+			confirmed                        *bool
+			start, end, dueDate, paidAt      *time.Time
+			amount, travel, accommodation    *int64
+			currency, feeType, paymentStatus *string
+			days                             *int
+			contractRefs                     []string
+		)
+
+		if err := rows.Scan(
+			&inv.EventID,
+			&inv.EventName,
+			&start,
+			&end,
+			&confirmed,
+			&amount,
+			&currency,
+			&feeType,
+			&days,
+			&travel,
+			&accommodation,
+			&paymentStatus,
+			&dueDate,
+			&paidAt,
+			&contractRefs,
+		); err != nil {
+			return fmt.Errorf("scanning invitation: %w", err)
+		}
+
+		inv.Start = utc(start)
+		inv.End = utc(end)
+		inv.Confirmed = confirmed != nil && *confirmed
+
+		if amount != nil {
+			inv.Fee = &ExportedFee{
+				Amount:                 *amount,
+				Currency:               conversion.String(currency),
+				Type:                   conversion.String(feeType),
+				Days:                   conversion.Int(days),
+				TravelAllowance:        conversion.Int64(travel),
+				AccommodationAllowance: conversion.Int64(accommodation),
+				PaymentStatus:          conversion.String(paymentStatus),
+				DueDate:                utc(dueDate),
+				PaidAt:                 utc(paidAt),
+				ContractRefs:           contractRefs,
+			}
+		}
+
+		data.Invitations = append(data.Invitations, inv)
+	}
+
+	return rows.Err()
+}
+
+func exportSlots(ctx context.Context, tx pgx.Tx, id string, data *PersonalData) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			event_id, start_time, end_time, stage
+		FROM
+			%q
+		WHERE
+			artist_id=$1
+		ORDER BY
+			start_time`, core.TableEventSlots)
+
+	rows, err := tx.Query(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("querying slots: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			s     ExportedSlot
+			stage *string
+		)
+
+		if err := rows.Scan(&s.EventID, &s.Start, &s.End, &stage); err != nil {
+			return fmt.Errorf("scanning slot: %w", err)
+		}
+
+		s.Start = s.Start.UTC()
+		s.End = s.End.UTC()
+		s.Stage = conversion.String(stage)
+
+		data.Slots = append(data.Slots, s)
+	}
+
+	return rows.Err()
+}
+
+func exportConsents(ctx context.Context, tx pgx.Tx, id string, data *PersonalData) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			purpose, granted, method, note, recorded_at
+		FROM
+			%q
+		WHERE
+			artist_id=$1
+		ORDER BY
+			recorded_at`, core.TableArtistConsents)
+
+	rows, err := tx.Query(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("querying consents: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			c    ExportedConsent
+			note *string
+		)
+
+		if err := rows.Scan(&c.Purpose, &c.Granted, &c.Method, &note, &c.RecordedAt); err != nil {
+			return fmt.Errorf("scanning consent: %w", err)
+		}
+
+		c.Note = conversion.String(note)
+		c.RecordedAt = c.RecordedAt.UTC()
+
+		data.Consents = append(data.Consents, c)
+	}
+
+	return rows.Err()
+}
+
+func exportChanges(ctx context.Context, tx pgx.Tx, id string, data *PersonalData) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			entity, action, event_id, created_at, dispatched_at
+		FROM
+			%q
+		WHERE
+			entity_id=$1 AND entity IN ($2, $3)
+		ORDER BY
+			seq`, core.TableOutbox)
+
+	rows, err := tx.Query(ctx, stmt, id, core.EntityArtist, core.EntityInvitation)
+	if err != nil {
+		return fmt.Errorf("querying changes: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			c            ExportedChange
+			eventID      *string
+			dispatchedAt *time.Time
+		)
+
+		if err := rows.Scan(&c.Entity, &c.Action, &eventID, &c.CreatedAt, &dispatchedAt); err != nil {
+			return fmt.Errorf("scanning change: %w", err)
+		}
+
+		c.EventID = conversion.String(eventID)
+		c.CreatedAt = c.CreatedAt.UTC()
+		c.DispatchedAt = utc(dispatchedAt)
+
+		data.Changes = append(data.Changes, c)
+	}
+
+	return rows.Err()
+}
+
+func exportDeliveries(ctx context.Context, tx pgx.Tx, id string, data *PersonalData) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			webhook_id, entity, action, attempt, status_code, succeeded, created_at
+		FROM
+			%q
+		WHERE
+			entity_id=$1 AND entity IN ($2, $3)
+		ORDER BY
+			created_at`, core.TableWebhookDeliveries)
+
+	rows, err := tx.Query(ctx, stmt, id, core.EntityArtist, core.EntityInvitation)
+	if err != nil {
+		return fmt.Errorf("querying webhook deliveries: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			d          ExportedDelivery
+			webhookID  *string
+			statusCode *int
+		)
+
+		if err := rows.Scan(&webhookID, &d.Entity, &d.Action, &d.Attempt, &statusCode, &d.Succeeded, &d.CreatedAt); err != nil {
+			return fmt.Errorf("scanning webhook delivery: %w", err)
+		}
+
+		d.WebhookID = conversion.String(webhookID)
+		d.StatusCode = conversion.Int(statusCode)
+		d.CreatedAt = d.CreatedAt.UTC()
+
+		data.WebhookDeliveries = append(data.WebhookDeliveries, d)
+	}
+
+	return rows.Err()
+}
+
+// Anonymize irreversibly scrubs the personal data of an Artist, including a
+// soft-deleted one. The Artist is kept with placeholder names, so historical
+// events keep their invitations, slots and fees; fees are subject to
+// accounting retention. Anonymizing an anonymized Artist does nothing.
+func (h *Handler) Anonymize(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return core.ErrInvalidUUID
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.anonymize")
+	defer span.End()
+
+	var anonymized bool
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		anonymized = false
+
+		stmt := fmt.Sprintf(`
+			SELECT
+				anonymized_at IS NOT NULL
+			FROM
+				%q
+			WHERE
+				id=$1
+			FOR UPDATE`, core.TableArtists)
+
+		var done bool
+		if err := tx.QueryRow(ctx, stmt, id).Scan(&done); err != nil {
+			return err
+		}
+
+		if done {
+			return nil
+		}
+
+		stmt = fmt.Sprintf(`
+			UPDATE
+				%q
+			SET
+				first_name=$2,
+				last_name=$3,
+				artist_name=NULL,
+				pronouns=NULL,
+				date_of_birth=NULL,
+				place_of_birth=NULL,
+				nationality=NULL,
+				language=NULL,
+				facebook=NULL,
+				instagram=NULL,
+				bandcamp=NULL,
+				bio_ger=NULL,
+				bio_en=NULL,
+				email=NULL,
+				email_index=NULL,
+				updated_at=$4,
+				anonymized_at=$4
+			WHERE
+				id=$1`, core.TableArtists)
+
+		if _, err := tx.Exec(ctx, stmt, id, AnonymizedFirstName, AnonymizedLastName, time.Now().UTC()); err != nil {
+			return err
+		}
+
+		// Consents are kept as proof, without free-form notes.
+		stmt = fmt.Sprintf(`
+			UPDATE
+				%q
+			SET
+				note=NULL
+			WHERE
+				artist_id=$1`, core.TableArtistConsents)
+
+		if _, err := tx.Exec(ctx, stmt, id); err != nil {
+			return err
+		}
+
+		anonymized = true
+
+		return core.WriteOutbox(ctx, tx, core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: id})
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return core.ErrNotFound
+		}
+
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityArtist, "anonymize")
+		return fmt.Errorf("anonymizing artist: %w", err)
+	}
+
+	if !anonymized {
+		return nil
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityArtist, "anonymize")
+	h.logger.Info("tuple modified",
+		zap.String("action", "anonymize"),
+		zap.String("entity", entityArtist),
+		zap.String("id", id),
+	)
+
+	return nil
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
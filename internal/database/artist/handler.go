@@ -15,6 +15,7 @@ import (
 
 	"github.com/obitech/artist-db/internal/conversion"
 	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/encryption"
 	"github.com/obitech/artist-db/internal/observability"
 )
 
@@ -27,64 +28,108 @@ type Handler struct {
 	conn   core.Connection
 	logger *zap.Logger
 	tracer otelTrace.TracerProvider
+
+	enc       *encryption.Encrypter
+	encrypted map[string]bool
 }
 
 // NewHandler returns a Handler.
-func NewHandler(conn core.Connection, logger *zap.Logger, tp otelTrace.TracerProvider) *Handler {
-	return &Handler{
+func NewHandler(conn core.Connection, logger *zap.Logger, tp otelTrace.TracerProvider, opts ...Option) *Handler {
+	h := &Handler{
 		conn:   conn,
 		logger: logger,
 		tracer: tp,
 	}
+
+	for _, fn := range opts {
+		fn(h)
+	}
+
+	return h
 }
 
 // Upsert creates or updates one or more artists in the database.
-// Multiple artists are inserted in the same transaction
+// Multiple artists are inserted in the same transaction, nothing is saved if
+// any of them fails.
 func (h *Handler) Upsert(ctx context.Context, artists ...*Artist) error {
-	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.upsert")
-	defer span.End()
-
-	tx, err := h.conn.Begin(spanCtx)
+	errs, err := h.UpsertBatch(ctx, core.BatchAtomic, artists...)
 	if err != nil {
-		return fmt.Errorf("creating tx failed: %w", err)
+		return err
 	}
 
-	defer core.RollbackAndLogError(spanCtx, tx, h.logger)
+	var mErr error
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, core.ErrRolledBack) {
+			mErr = multierr.Append(mErr, err)
+		}
+	}
+
+	return mErr
+}
+
+// UpsertBatch creates or updates artists like Upsert, but every artist is
+// saved in its own savepoint. It returns the errors of the artists, in order,
+// and nil for those which were saved. In partial mode, all artists which
+// succeed are saved.
+func (h *Handler) UpsertBatch(ctx context.Context, mode core.BatchMode, artists ...*Artist) ([]error, error) {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.upsert")
+	defer span.End()
 
-	var (
-		mErr           error
-		artistsChanged int
+	errs, err := core.RunBatch(spanCtx, h.conn, h.logger, mode, len(artists),
+		func(ctx context.Context, tx pgx.Tx, i int) error {
+			return h.upsertArtist(ctx, tx, artists[i])
+		},
+		func(i int) core.Change {
+			return core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: artists[i].ID}
+		},
 	)
-	for _, artist := range artists {
-		if err := h.upsertArtist(spanCtx, tx, artist); err != nil {
-			if errors.Is(err, pgx.ErrTxClosed) {
-				return fmt.Errorf("insert aborted, tx cancelled: %w", err)
-			}
+	if err != nil {
+		return nil, err
+	}
 
-			observability.Metrics.TrackObjectError(entityArtist, "upsert")
-			mErr = multierr.Append(mErr, err)
-		} else {
-			artistsChanged++
+	var changed int
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			changed++
 			h.logger.Info("tuple modified",
 				zap.String("action", "upsert"),
 				zap.String("entity", entityArtist),
-				zap.Object("artist", artist),
+				zap.Object("artist", artists[i]),
 			)
+		case !errors.Is(err, core.ErrRolledBack):
+			observability.Metrics.TrackObjectError(entityArtist, "upsert")
 		}
 	}
 
-	if err := tx.Commit(spanCtx); err != nil {
-		return fmt.Errorf("commiting tx failed: %w", err)
-	}
-
-	observability.Metrics.TrackObjectsChanged(artistsChanged, entityArtist, "upsert")
+	observability.Metrics.TrackObjectsChanged(changed, entityArtist, "upsert")
 
-	return mErr
+	return errs, nil
 }
 
 func (h *Handler) upsertArtist(ctx context.Context, tx pgx.Tx, artist *Artist) error {
 	start := time.Now().UTC()
 
+	dob, err := h.sealDate(ctx, artist.Origin.DateOfBirth)
+	if err != nil {
+		return err
+	}
+
+	pob, err := h.seal(ctx, "place_of_birth", artist.Origin.PlaceOfBirth)
+	if err != nil {
+		return err
+	}
+
+	nationality, err := h.seal(ctx, "nationality", artist.Origin.Nationality)
+	if err != nil {
+		return err
+	}
+
+	email, err := h.seal(ctx, "email", artist.Email)
+	if err != nil {
+		return err
+	}
+
 	stmt := fmt.Sprintf(`
 		INSERT INTO "%s"
 			(
@@ -104,10 +149,11 @@ func (h *Handler) upsertArtist(ctx context.Context, tx pgx.Tx, artist *Artist) e
 				artist_name,
 				created_at,
 				updated_at,
-				email
+				email,
+				email_index
 			)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		ON CONFLICT 
 			(id)
 		DO UPDATE SET
@@ -126,30 +172,40 @@ func (h *Handler) upsertArtist(ctx context.Context, tx pgx.Tx, artist *Artist) e
 			artist_name=$14,
 			updated_at=$16,
 			email=$17,
-			deleted_at=NULL`, core.TableArtists)
-
-	if _, err := tx.Exec(ctx, stmt,
-		artist.ID,                       // $1
-		artist.FirstName,                // $2
-		artist.LastName,                 // $3
-		artist.Pronouns,                 // $4
-		artist.Origin.DateOfBirth.UTC(), // $5
-		artist.Origin.PlaceOfBirth,      // $6
-		artist.Origin.Nationality,       // $7
-		artist.Language,                 // $8
-		artist.Socials.Facebook,         // $9
-		artist.Socials.Instagram,        // $10
-		artist.Socials.Bandcamp,         // $11
-		artist.BioGerman,                // $12
-		artist.BioEnglish,               // $13
-		artist.ArtistName,               // $14
-		start,                           // $15
-		start,                           // $16
-		artist.Email,                    // $17
-	); err != nil {
+			email_index=$18,
+			deleted_at=NULL
+		WHERE
+			"%[1]s".anonymized_at IS NULL`, core.TableArtists)
+
+	tag, err := tx.Exec(ctx, stmt,
+		artist.ID,                  // $1
+		artist.FirstName,           // $2
+		artist.LastName,            // $3
+		artist.Pronouns,            // $4
+		dob,                        // $5
+		pob,                        // $6
+		nationality,                // $7
+		artist.Language,            // $8
+		artist.Socials.Facebook,    // $9
+		artist.Socials.Instagram,   // $10
+		artist.Socials.Bandcamp,    // $11
+		artist.BioGerman,           // $12
+		artist.BioEnglish,          // $13
+		artist.ArtistName,          // $14
+		start,                      // $15
+		start,                      // $16
+		email,                      // $17
+		h.emailIndex(artist.Email), // $18
+	)
+	if err != nil {
 		return err
 	}
 
+	// Anonymization is irreversible.
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("upserting %q: %w", artist.ID, ErrAnonymized)
+	}
+
 	return nil
 }
 
@@ -170,6 +226,14 @@ func ByArtistName(firstName string) GetRequest {
 	}
 }
 
+// ByEmail requests Artists by email, compared case-insensitive. With
+// encryption, emails are looked up by their blind index.
+func ByEmail(email string) GetRequest {
+	return func() (string, string, string) {
+		return email, "lower(email)=lower($1)", "email"
+	}
+}
+
 // ByLastName requests Artists by last name.
 func ByLastName(lastName string) GetRequest {
 	return func() (string, string, string) {
@@ -177,6 +241,18 @@ func ByLastName(lastName string) GetRequest {
 	}
 }
 
+// After requests up to limit Artists ordered by ID, starting after the
+// Artist with the given ID. An empty ID starts with the first Artist.
+func After(id string, limit int) GetRequest {
+	return func() (string, string, string) {
+		if id == "" {
+			id = uuid.Nil.String()
+		}
+
+		return id, fmt.Sprintf("id > $1::uuid ORDER BY id LIMIT %d", limit), "after"
+	}
+}
+
 // Get retrieves Artists according to GetRequest, or an ErrNotFound.
 func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Artist, error) {
 	input, whereClause, reqType := request()
@@ -186,6 +262,10 @@ func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Artist, error
 
 	span.SetAttributes(attribute.String("type", reqType))
 
+	if reqType == "email" && h.enc != nil {
+		input, whereClause = h.enc.BlindIndex(input), "email_index=$1"
+	}
+
 	stmt := fmt.Sprintf(`
 		SELECT 
 				id,
@@ -231,7 +311,7 @@ func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Artist, error
 			lastName    string
 			email       *string
 			pronouns    []string
-			dob         *time.Time
+			dob         *string
 			pob         *string
 			nationality *string
 			language    *string
@@ -265,19 +345,22 @@ func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Artist, error
 			return nil, fmt.Errorf("scanning rows failed: %w", err)
 		}
 
+		origin, plainEmail, err := h.unsealArtist(spanCtx, dob, pob, nationality, email)
+		if err != nil {
+			span.RecordError(err)
+			observability.Metrics.TrackObjectError(entityArtist, "get")
+			return nil, fmt.Errorf("artist %s: %w", id, err)
+		}
+
 		artists = append(artists, &Artist{
 			ID:         id,
 			FirstName:  firstName,
 			LastName:   lastName,
-			Email:      conversion.String(email),
+			Email:      plainEmail,
 			ArtistName: conversion.String(artistName),
 			Pronouns:   pronouns,
-			Origin: Origin{
-				DateOfBirth:  conversion.Time(dob),
-				PlaceOfBirth: conversion.String(pob),
-				Nationality:  conversion.String(nationality),
-			},
-			Language: conversion.String(language),
+			Origin:     origin,
+			Language:   conversion.String(language),
 			Socials: Socials{
 				Instagram: conversion.String(instagram),
 				Facebook:  conversion.String(facebook),
@@ -320,7 +403,13 @@ func (h *Handler) DeleteByID(ctx context.Context, id string) error {
 			id`, core.TableArtists)
 
 	var deletedID string
-	if err := h.conn.QueryRow(spanCtx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+			return err
+		}
+
+		return core.WriteOutbox(ctx, tx, core.Change{Entity: core.EntityArtist, Action: core.ActionDelete, ID: id})
+	}); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return core.ErrNotFound
 		}
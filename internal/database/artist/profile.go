@@ -0,0 +1,131 @@
+package artist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// Profile is the part of an Artist which may be published.
+type Profile struct {
+	ID string
+	// Name is the artist name. The full name is only used with
+	// ConsentPublishProfile in effect.
+	Name string
+	// Socials are only set with ConsentPublishProfile in effect.
+	Socials Socials
+	// Biographies are only set with ConsentPublishBio in effect.
+	BioGerman  string
+	BioEnglish string
+}
+
+// Profiles returns the Profiles of the given Artists by ID. Deleted and
+// anonymized Artists are left out, as are Artists without an artist name who
+// didn't consent to publishing their full name. Emails and origins are never
+// read.
+func (h *Handler) Profiles(ctx context.Context, ids ...string) (map[string]*Profile, error) {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, core.ErrInvalidUUID
+		}
+	}
+
+	profiles := make(map[string]*Profile, len(ids))
+	if len(ids) == 0 {
+		return profiles, nil
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.profiles")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		WITH latest AS (
+			SELECT DISTINCT ON (artist_id, purpose)
+				artist_id, purpose, granted
+			FROM
+				%[1]q
+			WHERE
+				artist_id = ANY($1::uuid[])
+			ORDER BY
+				artist_id, purpose, recorded_at DESC, id
+		)
+		SELECT
+			a.id,
+			a.first_name,
+			a.last_name,
+			a.artist_name,
+			a.facebook,
+			a.instagram,
+			a.bandcamp,
+			a.bio_ger,
+			a.bio_en,
+			EXISTS (SELECT 1 FROM latest WHERE artist_id=a.id AND purpose=$2 AND granted),
+			EXISTS (SELECT 1 FROM latest WHERE artist_id=a.id AND purpose=$3 AND granted)
+		FROM
+			%[2]q a
+		WHERE
+			a.id = ANY($1::uuid[]) AND a.deleted_at IS NULL AND a.anonymized_at IS NULL`,
+		core.TableArtistConsents, core.TableArtists)
+
+	rows, err := h.conn.Query(spanCtx, stmt, ids, string(ConsentPublishProfile), string(ConsentPublishBio))
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityArtist, "profiles")
+		return nil, fmt.Errorf("querying profiles: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			p                             Profile
+			firstName, lastName           string
+			artistName                    *string
+			facebook, instagram, bandcamp *string
+			bioGerman, bioEnglish         *string
+			publishProfile, publishBio    bool
+		)
+
+		if err := rows.Scan(&p.ID, &firstName, &lastName, &artistName, &facebook, &instagram, &bandcamp, &bioGerman, &bioEnglish, &publishProfile, &publishBio); err != nil {
+			return nil, fmt.Errorf("scanning profile: %w", err)
+		}
+
+		p.Name = conversion.String(artistName)
+		if p.Name == "" && publishProfile {
+			p.Name = strings.TrimSpace(firstName + " " + lastName)
+		}
+
+		if p.Name == "" {
+			continue
+		}
+
+		if publishProfile {
+			p.Socials = Socials{
+				Facebook:  conversion.String(facebook),
+				Instagram: conversion.String(instagram),
+				Bandcamp:  conversion.String(bandcamp),
+			}
+		}
+
+		if publishBio {
+			p.BioGerman = conversion.String(bioGerman)
+			p.BioEnglish = conversion.String(bioEnglish)
+		}
+
+		profiles[p.ID] = &p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsRetrieved(len(profiles), entityArtist)
+
+	return profiles, nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// BatchMode decides what happens to a batch if some of its items fail.
+type BatchMode int
+
+const (
+	// BatchPartial saves all items which succeed.
+	BatchPartial BatchMode = iota
+
+	// BatchAtomic saves nothing if any item fails.
+	BatchAtomic
+)
+
+// ErrRolledBack is the error of items of an atomic batch which succeeded, but
+// were rolled back because another item failed.
+var ErrRolledBack = errors.New("rolled back because another item failed")
+
+// errBatchFailed rolls back the transaction of an atomic batch.
+var errBatchFailed = errors.New("batch failed")
+
+// BatchFunc saves the i-th item of a batch.
+type BatchFunc func(ctx context.Context, tx pgx.Tx, i int) error
+
+// RunBatch runs fn for each of n items in a transaction. Every item runs in
+// its own savepoint, so that a failed item doesn't abort the transaction.
+// The changes of saved items, as returned by change, are written to the
+// outbox.
+//
+// The returned errors are those of the items, in order, and nil for saved
+// items. The error is only set if the whole batch failed.
+func RunBatch(ctx context.Context, conn Connection, logger *zap.Logger, mode BatchMode, n int, fn BatchFunc, change func(i int) Change) ([]error, error) {
+	var errs []error
+
+	if err := RunInTx(ctx, conn, logger, DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		var (
+			changes []Change
+			failed  bool
+		)
+
+		errs = make([]error, n)
+
+		for i := 0; i < n; i++ {
+			itemErr, err := savepoint(ctx, tx, func(ctx context.Context, tx pgx.Tx) error {
+				return fn(ctx, tx, i)
+			})
+			if err != nil {
+				return err
+			}
+
+			if itemErr != nil {
+				errs[i], failed = itemErr, true
+				continue
+			}
+
+			changes = append(changes, change(i))
+		}
+
+		if failed && mode == BatchAtomic {
+			for i := range errs {
+				if errs[i] == nil {
+					errs[i] = ErrRolledBack
+				}
+			}
+
+			return errBatchFailed
+		}
+
+		return WriteOutbox(ctx, tx, changes...)
+	}); err != nil && !errors.Is(err, errBatchFailed) {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// savepoint runs fn in a savepoint of tx. If fn fails, its statements are
+// rolled back and its error is returned as itemErr, while tx stays usable.
+// err is set if tx can't be used anymore.
+func savepoint(ctx context.Context, tx pgx.Tx, fn TxFunc) (itemErr, err error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating savepoint failed: %w", err)
+	}
+
+	if itemErr := fn(ctx, sp); itemErr != nil {
+		if errors.Is(itemErr, pgx.ErrTxClosed) || IsRetryable(itemErr) {
+			return nil, itemErr
+		}
+
+		if err := sp.Rollback(ctx); err != nil {
+			return nil, fmt.Errorf("rolling back savepoint failed: %w", err)
+		}
+
+		return itemErr, nil
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("releasing savepoint failed: %w", err)
+	}
+
+	return nil, nil
+}
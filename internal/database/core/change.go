@@ -0,0 +1,33 @@
+package core
+
+import "context"
+
+const (
+	EntityArtist     = "artist"
+	EntityEvent      = "event"
+	EntityLocation   = "location"
+	EntityInvitation = "invitation"
+)
+
+const (
+	ActionUpsert = "upsert"
+	ActionDelete = "delete"
+)
+
+// Change describes a committed modification of an entity.
+type Change struct {
+	Entity string `json:"entity"`
+	Action string `json:"action"`
+	ID     string `json:"id"`
+
+	// EventID is only set for invitations, in which case ID is the ID of the
+	// invited artist.
+	EventID string `json:"eventID,omitempty"`
+	// Confirmed is only set for invitations.
+	Confirmed bool `json:"confirmed,omitempty"`
+}
+
+// Publisher is notified about changes after they have been committed.
+type Publisher interface {
+	Publish(ctx context.Context, changes ...Change)
+}
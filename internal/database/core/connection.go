@@ -2,11 +2,13 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
 	otelTrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/obitech/artist-db/internal/observability"
@@ -26,6 +28,11 @@ const (
 	TracingInstrumentationName = "database"
 )
 
+const (
+	// TargetPrimary labels the primary database, which receives all writes.
+	TargetPrimary = "primary"
+)
+
 // Connection abstracts a pgx Database Connection.
 type Connection interface {
 	// Ping checks if the database is reachable.
@@ -37,6 +44,9 @@ type Connection interface {
 	// Begin starts a new transaction.
 	Begin(ctx context.Context) (pgx.Tx, error)
 
+	// BeginTx starts a new transaction with the given options.
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+
 	// Query executes a query.
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 
@@ -45,43 +55,118 @@ type Connection interface {
 
 	// Exec executes an SQL statement.
 	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+
+	// Stat returns a snapshot of the connection pool statistics.
+	Stat() *pgxpool.Stat
 }
 
 // ConnectionPool wraps a pgxpool and implements the Connection interface.
 type ConnectionPool struct {
 	pool   *pgxpool.Pool
+	target string
 	tracer otelTrace.TracerProvider
 }
 
-func NewConnectionPool(ctx context.Context, connString string, tp otelTrace.TracerProvider) (*ConnectionPool, error) {
-	spanCtx, span := tp.Tracer(TracingInstrumentationName).Start(ctx, "pool.connect")
+// PoolConfig allows tuning the connection pool. Zero values keep the pgx
+// defaults.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// StatementTimeout aborts any statement that takes longer. It's set on
+	// every new connection.
+	StatementTimeout time.Duration
+}
+
+func (p PoolConfig) apply(cfg *pgxpool.Config) {
+	if p.MaxConns > 0 {
+		cfg.MaxConns = p.MaxConns
+	}
+
+	if p.MinConns > 0 {
+		cfg.MinConns = p.MinConns
+	}
+
+	if p.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = p.MaxConnLifetime
+	}
+
+	if p.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = p.MaxConnIdleTime
+	}
+
+	if p.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = p.HealthCheckPeriod
+	}
+
+	if p.StatementTimeout > 0 {
+		stmt := fmt.Sprintf("SET statement_timeout = %d", p.StatementTimeout.Milliseconds())
+
+		cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			if _, err := conn.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("setting statement timeout failed: %w", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// NewConnectionPool connects to the database. The target names the database
+// in metrics and traces, e.g. TargetPrimary.
+func NewConnectionPool(ctx context.Context, target, connString string, poolCfg PoolConfig, tp otelTrace.TracerProvider) (*ConnectionPool, error) {
+	spanCtx, span := tp.Tracer(TracingInstrumentationName).Start(ctx, "pool.connect", otelTrace.WithAttributes(targetAttribute(target)))
 	defer span.End()
 
-	conn, err := pgxpool.Connect(spanCtx, connString)
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackCommandError(target, commandConnect)
+		return nil, err
+	}
+
+	poolCfg.apply(cfg)
+
+	conn, err := pgxpool.ConnectConfig(spanCtx, cfg)
 	if err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandConnect)
+		observability.Metrics.TrackCommandError(target, commandConnect)
 		return nil, err
 	}
 
+	observability.Metrics.RegisterPoolStats(target, conn.Stat)
+
 	return &ConnectionPool{
 		pool:   conn,
+		target: target,
 		tracer: tp,
 	}, nil
 }
 
+func targetAttribute(target string) attribute.KeyValue {
+	return attribute.String("db.target", target)
+}
+
+// Target returns the name of the database the pool is connected to.
+func (c *ConnectionPool) Target() string {
+	return c.target
+}
+
 func (c *ConnectionPool) Ping(ctx context.Context) error {
 	start := time.Now()
-	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.ping")
+	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.ping", otelTrace.WithAttributes(targetAttribute(c.target)))
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandPing, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(c.target, commandPing, time.Since(s))
 	}(start)
 
 	if err := c.pool.Ping(spanCtx); err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandPing)
+		observability.Metrics.TrackCommandError(c.target, commandPing)
 		return err
 	}
 
@@ -89,44 +174,54 @@ func (c *ConnectionPool) Ping(ctx context.Context) error {
 }
 
 func (c *ConnectionPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return c.BeginTx(ctx, pgx.TxOptions{})
+}
+
+func (c *ConnectionPool) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
 	start := time.Now()
-	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.begin")
+	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.begin", otelTrace.WithAttributes(targetAttribute(c.target)))
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandBegin, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(c.target, commandBegin, time.Since(s))
 	}(start)
 
-	res, err := c.pool.Begin(spanCtx)
+	res, err := c.pool.BeginTx(spanCtx, opts)
 	if err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandBegin)
+		observability.Metrics.TrackCommandError(c.target, commandBegin)
 		return nil, err
 	}
 
 	return &Tx{
 		Tx:     res,
+		target: c.target,
 		tracer: c.tracer,
 	}, err
 }
 
 func (c *ConnectionPool) Close() {
+	observability.Metrics.UnregisterPoolStats(c.target)
 	c.pool.Close()
 }
 
+func (c *ConnectionPool) Stat() *pgxpool.Stat {
+	return c.pool.Stat()
+}
+
 func (c *ConnectionPool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
 	start := time.Now()
-	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.query")
+	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.query", otelTrace.WithAttributes(targetAttribute(c.target)))
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandQuery, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(c.target, commandQuery, time.Since(s))
 	}(start)
 
 	res, err := c.pool.Query(spanCtx, sql, args...)
 	if err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandQuery)
+		observability.Metrics.TrackCommandError(c.target, commandQuery)
 	}
 
 	return res, err
@@ -134,11 +229,11 @@ func (c *ConnectionPool) Query(ctx context.Context, sql string, args ...interfac
 
 func (c *ConnectionPool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
 	start := time.Now()
-	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.query.row")
+	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.query.row", otelTrace.WithAttributes(targetAttribute(c.target)))
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandQuery, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(c.target, commandQuery, time.Since(s))
 	}(start)
 
 	return c.pool.QueryRow(spanCtx, sql, args...)
@@ -146,17 +241,17 @@ func (c *ConnectionPool) QueryRow(ctx context.Context, sql string, args ...inter
 
 func (c *ConnectionPool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
 	start := time.Now()
-	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.exec")
+	spanCtx, span := c.tracer.Tracer(TracingInstrumentationName).Start(ctx, "pool.exec", otelTrace.WithAttributes(targetAttribute(c.target)))
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandExec, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(c.target, commandExec, time.Since(s))
 	}(start)
 
 	res, err := c.pool.Exec(spanCtx, sql, args...)
 	if err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandExec)
+		observability.Metrics.TrackCommandError(c.target, commandExec)
 	}
 
 	return res, err
@@ -1,8 +1,31 @@
 package core
 
-import "errors"
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+const sqlStateQueryCanceled = "57014"
 
 var (
 	ErrNotFound    = errors.New("resource not found")
 	ErrInvalidUUID = errors.New("id must be valid UUID")
+
+	// ErrInvalidInput is wrapped by errors of handlers which reject their
+	// input.
+	ErrInvalidInput = errors.New("invalid input")
 )
+
+// IsTimeout returns true if err was caused by an exceeded deadline, either of
+// the context or of the statement_timeout of the database.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == sqlStateQueryCanceled
+}
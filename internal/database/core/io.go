@@ -12,7 +12,7 @@ import (
 
 func RollbackAndLogError(ctx context.Context, tx pgx.Tx, logger *zap.Logger) {
 	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-		observability.Metrics.TrackCommandError(commandRollback)
+		observability.Metrics.TrackCommandError(TargetPrimary, commandRollback)
 		logger.Error("close failed", zap.Error(err))
 	}
 }
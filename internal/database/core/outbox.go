@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// WriteOutbox records changes in the outbox as part of tx. They are only
+// published by the relay once tx has been committed.
+func WriteOutbox(ctx context.Context, tx pgx.Tx, changes ...Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				entity,
+				action,
+				entity_id,
+				event_id,
+				confirmed,
+				created_at
+			)
+		VALUES
+			($1, $2, $3, $4, $5, $6)`, TableOutbox)
+
+	now := time.Now().UTC()
+
+	for _, change := range changes {
+		var eventID *string
+		if change.EventID != "" {
+			eventID = &change.EventID
+		}
+
+		if _, err := tx.Exec(ctx, stmt,
+			change.Entity,    // $1
+			change.Action,    // $2
+			change.ID,        // $3
+			eventID,          // $4
+			change.Confirmed, // $5
+			now,              // $6
+		); err != nil {
+			return fmt.Errorf("writing outbox failed: %w", err)
+		}
+	}
+
+	return nil
+}
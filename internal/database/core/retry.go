@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateAdminShutdown        = "57P01"
+	sqlStateCrashShutdown        = "57P02"
+	sqlStateCannotConnectNow     = "57P03"
+	sqlClassConnectionException  = "08"
+)
+
+const (
+	retryReasonSerialization = "serialization_failure"
+	retryReasonDeadlock      = "deadlock"
+	retryReasonConnection    = "connection"
+)
+
+// TxOptions configures a transaction started by RunInTx.
+type TxOptions struct {
+	// IsoLevel is the isolation level of the transaction.
+	IsoLevel pgx.TxIsoLevel
+
+	// MaxAttempts is the maximum number of times the transaction is run,
+	// including the first attempt.
+	MaxAttempts int
+
+	// BaseDelay is the upper bound of the first backoff. It doubles with
+	// every attempt until MaxDelay is reached.
+	BaseDelay time.Duration
+
+	// MaxDelay is the upper bound of any backoff.
+	MaxDelay time.Duration
+}
+
+// DefaultTxOptions retries a read committed transaction a few times within
+// roughly a second, which is enough to ride out a brief failover.
+var DefaultTxOptions = TxOptions{
+	IsoLevel:    pgx.ReadCommitted,
+	MaxAttempts: 5,
+	BaseDelay:   25 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// TxFunc is run inside a transaction. Returning an error rolls the
+// transaction back.
+type TxFunc func(ctx context.Context, tx pgx.Tx) error
+
+// RunInTx runs fn inside a transaction and commits it. If the transaction
+// fails because of a serialization failure, a deadlock or a transient
+// connection error, it's rolled back and fn is run again in a new transaction
+// after a jittered backoff. A failed commit is never retried, since it can't
+// be distinguished from a lost acknowledgement and fn would be applied twice.
+func RunInTx(ctx context.Context, conn Connection, logger *zap.Logger, opts TxOptions, fn TxFunc) error {
+	for attempt := 1; ; attempt++ {
+		committing, err := runTx(ctx, conn, logger, opts, fn)
+		if err == nil {
+			return nil
+		}
+
+		reason, retryable := retryReason(err)
+		if committing || !retryable || attempt >= opts.MaxAttempts {
+			return err
+		}
+
+		observability.Metrics.TrackTxRetry(reason)
+		logger.Warn("retrying transaction",
+			zap.String("reason", reason),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+			observability.TraceField(ctx),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for retry: %w", ctx.Err())
+		case <-time.After(opts.backoff(attempt)):
+		}
+	}
+}
+
+// runTx runs fn inside a single transaction. committing is true if the
+// commit failed.
+func runTx(ctx context.Context, conn Connection, logger *zap.Logger, opts TxOptions, fn TxFunc) (committing bool, err error) {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: opts.IsoLevel})
+	if err != nil {
+		return false, fmt.Errorf("creating tx failed: %w", err)
+	}
+
+	defer RollbackAndLogError(ctx, tx, logger)
+
+	if err := fn(ctx, tx); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return true, fmt.Errorf("commiting tx failed: %w", err)
+	}
+
+	return false, nil
+}
+
+// backoff returns a random delay between zero and the exponentially growing
+// upper bound for the given attempt.
+func (o TxOptions) backoff(attempt int) time.Duration {
+	limit := o.BaseDelay << (attempt - 1)
+	if limit <= 0 || limit > o.MaxDelay {
+		limit = o.MaxDelay
+	}
+
+	if limit <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(limit)))
+}
+
+// IsRetryable returns true if a transaction which failed with err can safely
+// be run again.
+func IsRetryable(err error) bool {
+	_, ok := retryReason(err)
+	return ok
+}
+
+func retryReason(err error) (string, bool) {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "", false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == sqlStateSerializationFailure:
+			return retryReasonSerialization, true
+		case pgErr.Code == sqlStateDeadlockDetected:
+			return retryReasonDeadlock, true
+		case pgErr.Code == sqlStateAdminShutdown,
+			pgErr.Code == sqlStateCrashShutdown,
+			pgErr.Code == sqlStateCannotConnectNow,
+			strings.HasPrefix(pgErr.Code, sqlClassConnectionException):
+			return retryReasonConnection, true
+		}
+
+		return "", false
+	}
+
+	var (
+		netErr  net.Error
+		safeErr interface{ SafeToRetry() bool }
+	)
+
+	if (errors.As(err, &safeErr) && safeErr.SafeToRetry()) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return retryReasonConnection, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"connection exception", &pgconn.PgError{Code: "08006"}, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"unexpected EOF", fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true},
+		{"context canceled", context.Canceled, false},
+		{"tx closed", pgx.ErrTxClosed, false},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestTxOptionsBackoff(t *testing.T) {
+	opts := TxOptions{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for attempt := 1; attempt < 100; attempt++ {
+		d := opts.backoff(attempt)
+
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.Less(t, d, opts.MaxDelay)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dial error", fmt.Errorf("connect: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{"cannot connect now", &pgconn.PgError{Code: "57P03"}, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, false},
+		{"syntax error", &pgconn.PgError{Code: "42601"}, false},
+		{"encoding error", errors.New("unable to encode 1 into binary format for uuid"), false},
+		{"context canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConnectionError(tt.err))
+		})
+	}
+}
+
+type fakeConn struct {
+	Connection
+	commitErr error
+}
+
+func (c *fakeConn) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	return &fakeTx{commitErr: c.commitErr}, nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+	commitErr error
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	return pgx.ErrTxClosed
+}
+
+func TestRunInTx(t *testing.T) {
+	opts := TxOptions{MaxAttempts: 3}
+
+	t.Run("failed transactions are retried", func(t *testing.T) {
+		var runs int
+		err := RunInTx(context.Background(), &fakeConn{}, zap.NewNop(), opts, func(context.Context, pgx.Tx) error {
+			runs++
+			if runs == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("failed commits aren't retried", func(t *testing.T) {
+		var runs int
+		err := RunInTx(context.Background(), &fakeConn{commitErr: io.ErrUnexpectedEOF}, zap.NewNop(), opts, func(context.Context, pgx.Tx) error {
+			runs++
+			return nil
+		})
+
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, 1, runs)
+	})
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	otelTrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type primaryKey struct{}
+
+// WithPrimary routes reads made with the returned context to the primary,
+// e.g. to read a change right after it was committed, which lagging replicas
+// may not have seen yet.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func readsPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// replica is a read-only database which is taken out of rotation while it is
+// unhealthy.
+type replica struct {
+	*ConnectionPool
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool) bool {
+	var v int32
+	if healthy {
+		v = 1
+	}
+
+	return atomic.SwapInt32(&r.healthy, v) != v
+}
+
+// RoutingConnection implements the Connection interface on top of a primary
+// and any number of read replicas. Queries outside of transactions are sent
+// to a healthy replica, everything else goes to the primary. If no replica is
+// healthy, or the context was returned by WithPrimary, queries go to the
+// primary.
+type RoutingConnection struct {
+	primary  *ConnectionPool
+	replicas []*replica
+	next     uint32
+
+	logger   *zap.Logger
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewRoutingConnection connects to the primary and every replica. Replicas are
+// health checked in the background at the given interval.
+func NewRoutingConnection(
+	ctx context.Context,
+	primaryConnString string,
+	replicaConnStrings []string,
+	poolCfg PoolConfig,
+	interval time.Duration,
+	logger *zap.Logger,
+	tp otelTrace.TracerProvider,
+) (*RoutingConnection, error) {
+	primary, err := NewConnectionPool(ctx, TargetPrimary, primaryConnString, poolCfg, tp)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to primary failed: %w", err)
+	}
+
+	rc := &RoutingConnection{
+		primary:  primary,
+		logger:   logger,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+
+	for i, connString := range replicaConnStrings {
+		pool, err := NewConnectionPool(ctx, fmt.Sprintf("replica-%d", i), connString, poolCfg, tp)
+		if err != nil {
+			rc.closePools()
+			return nil, fmt.Errorf("connecting to replica %d failed: %w", i, err)
+		}
+
+		rc.replicas = append(rc.replicas, &replica{ConnectionPool: pool, healthy: 1})
+	}
+
+	rc.checkReplicas(ctx)
+
+	rc.wg.Add(1)
+	go rc.watchReplicas()
+
+	return rc, nil
+}
+
+// watchReplicas periodically health checks all replicas until the connection
+// is closed.
+func (rc *RoutingConnection) watchReplicas() {
+	defer rc.wg.Done()
+
+	ticker := time.NewTicker(rc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rc.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), rc.interval)
+			rc.checkReplicas(ctx)
+			cancel()
+		}
+	}
+}
+
+func (rc *RoutingConnection) checkReplicas(ctx context.Context) {
+	for _, r := range rc.replicas {
+		err := r.Ping(ctx)
+		if !r.setHealthy(err == nil) {
+			continue
+		}
+
+		if err != nil {
+			rc.logger.Warn("replica unhealthy, routing queries elsewhere", zap.String("target", r.Target()), zap.Error(err))
+		} else {
+			rc.logger.Info("replica healthy again", zap.String("target", r.Target()))
+		}
+	}
+}
+
+// reader returns the pool which should serve a read-only query.
+func (rc *RoutingConnection) reader(ctx context.Context) *ConnectionPool {
+	n := len(rc.replicas)
+	if n == 0 || readsPrimary(ctx) {
+		return rc.primary
+	}
+
+	start := atomic.AddUint32(&rc.next, 1)
+	for i := 0; i < n; i++ {
+		r := rc.replicas[(int(start)+i)%n]
+		if r.isHealthy() {
+			return r.ConnectionPool
+		}
+	}
+
+	return rc.primary
+}
+
+// markUnhealthy takes a replica out of rotation after a connection error.
+func (rc *RoutingConnection) markUnhealthy(pool *ConnectionPool, err error) {
+	for _, r := range rc.replicas {
+		if r.ConnectionPool == pool && r.setHealthy(false) {
+			rc.logger.Warn("replica unhealthy, routing queries elsewhere", zap.String("target", r.Target()), zap.Error(err))
+		}
+	}
+}
+
+// Ping checks if the primary is reachable.
+func (rc *RoutingConnection) Ping(ctx context.Context) error {
+	return rc.primary.Ping(ctx)
+}
+
+// Close stops the health checks and closes all pools.
+func (rc *RoutingConnection) Close() {
+	close(rc.done)
+	rc.wg.Wait()
+
+	rc.closePools()
+}
+
+func (rc *RoutingConnection) closePools() {
+	for _, r := range rc.replicas {
+		r.Close()
+	}
+
+	rc.primary.Close()
+}
+
+// Begin starts a new transaction on the primary.
+func (rc *RoutingConnection) Begin(ctx context.Context) (pgx.Tx, error) {
+	return rc.primary.Begin(ctx)
+}
+
+// BeginTx starts a new transaction with the given options on the primary.
+func (rc *RoutingConnection) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	return rc.primary.BeginTx(ctx, opts)
+}
+
+// Query executes a query on a replica. If the replica can't be reached, the
+// query is retried on the primary.
+func (rc *RoutingConnection) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	pool := rc.reader(ctx)
+
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil && pool != rc.primary && isConnectionError(err) {
+		rc.markUnhealthy(pool, err)
+		return rc.primary.Query(ctx, sql, args...)
+	}
+
+	return rows, err
+}
+
+// QueryRow queries for a single row on a replica. If the replica can't be
+// reached, the query is retried on the primary.
+func (rc *RoutingConnection) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return &routedRow{rc: rc, ctx: ctx, sql: sql, args: args}
+}
+
+// routedRow runs its query once scanned, like the rows of pgx, so that it
+// can fail over like Query.
+type routedRow struct {
+	rc   *RoutingConnection
+	ctx  context.Context
+	sql  string
+	args []interface{}
+}
+
+func (r *routedRow) Scan(dest ...interface{}) error {
+	rows, err := r.rc.Query(r.ctx, r.sql, r.args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return pgx.ErrNoRows
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	rows.Close()
+
+	return rows.Err()
+}
+
+// Exec executes an SQL statement on the primary.
+func (rc *RoutingConnection) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return rc.primary.Exec(ctx, sql, args...)
+}
+
+// Stat returns a snapshot of the primary's connection pool statistics.
+func (rc *RoutingConnection) Stat() *pgxpool.Stat {
+	return rc.primary.Stat()
+}
+
+// isConnectionError returns true if the database couldn't be reached or
+// dropped the connection. Other errors, e.g. arguments which can't be
+// encoded, would fail on the primary as well.
+func isConnectionError(err error) bool {
+	reason, ok := retryReason(err)
+	return ok && reason == retryReasonConnection
+}
@@ -7,4 +7,13 @@ const (
 	TableInvitedArtists        = "artist_event"
 	TableArtworks              = "artworks"
 	TableArtworkEventLocations = "artwork_event_locations"
+	TableWebhooks              = "webhooks"
+	TableWebhookDeliveries     = "webhook_deliveries"
+	TableWebhookQueue          = "webhook_queue"
+	TableOutbox                = "outbox"
+	TableEventSlots            = "event_slots"
+	TableEventOverrides        = "event_overrides"
+	TableEventBudgetLines      = "event_budget_lines"
+	TableArtistConsents        = "artist_consents"
+	TablePersistedQueries      = "persisted_queries"
 )
@@ -12,6 +12,7 @@ import (
 
 type Tx struct {
 	pgx.Tx
+	target string
 	tracer otelTrace.TracerProvider
 }
 
@@ -23,12 +24,12 @@ func (t *Tx) Commit(ctx context.Context) error {
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandCommit, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(t.target, commandCommit, time.Since(s))
 	}(start)
 
 	if err := t.Tx.Commit(spanCtx); err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandCommit)
+		observability.Metrics.TrackCommandError(t.target, commandCommit)
 		return err
 	}
 
@@ -43,12 +44,12 @@ func (t *Tx) Rollback(ctx context.Context) error {
 
 	defer func(s time.Time) {
 		span.End()
-		observability.Metrics.ObserveCommandDuration(commandRollback, time.Since(s))
+		observability.Metrics.ObserveCommandDuration(t.target, commandRollback, time.Since(s))
 	}(start)
 
 	if err := t.Tx.Rollback(spanCtx); err != nil {
 		span.RecordError(err)
-		observability.Metrics.TrackCommandError(commandRollback)
+		observability.Metrics.TrackCommandError(t.target, commandRollback)
 		return err
 	}
 
@@ -5,6 +5,7 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -17,6 +18,10 @@ import (
 	"github.com/obitech/artist-db/internal/database/core"
 	"github.com/obitech/artist-db/internal/database/event"
 	"github.com/obitech/artist-db/internal/database/location"
+	"github.com/obitech/artist-db/internal/database/outbox"
+	"github.com/obitech/artist-db/internal/database/query"
+	"github.com/obitech/artist-db/internal/database/retention"
+	"github.com/obitech/artist-db/internal/database/webhook"
 )
 
 // Database allows interaction with the underlying Postgres.
@@ -24,10 +29,34 @@ type Database struct {
 	ArtistHandler   *artist.Handler
 	LocationHandler *location.Handler
 	EventHandler    *event.Handler
-
-	conn   core.Connection
-	logger *zap.Logger
-	tracer trace.TracerProvider
+	WebhookHandler  *webhook.Handler
+	QueryHandler    *query.Handler
+
+	conn        core.Connection
+	poolConfig  core.PoolConfig
+	logger      *zap.Logger
+	tracer      trace.TracerProvider
+	artistOpts  []artist.Option
+	webhookOpts []webhook.Option
+
+	replicaConnStrings   []string
+	replicaCheckInterval time.Duration
+
+	webhooks   bool
+	webhookCfg webhook.DispatcherConfig
+	dispatcher *webhook.Dispatcher
+
+	sinks    []outbox.Sink
+	relayCfg outbox.RelayConfig
+	relay    *outbox.Relay
+
+	retention bool
+	purgerCfg retention.PurgerConfig
+	purger    *retention.Purger
+
+	queryPruning bool
+	prunerCfg    query.PrunerConfig
+	pruner       *query.Pruner
 }
 
 // NewDatabase returns a database with an active connection pool.
@@ -35,6 +64,8 @@ func NewDatabase(ctx context.Context, connString string, opts ...Option) (*Datab
 	db := &Database{
 		logger: zap.NewNop(),
 		tracer: otel.GetTracerProvider(),
+
+		replicaCheckInterval: 5 * time.Second,
 	}
 
 	for _, fn := range opts {
@@ -43,25 +74,94 @@ func NewDatabase(ctx context.Context, connString string, opts ...Option) (*Datab
 		}
 	}
 
-	conn, err := core.NewConnectionPool(ctx, connString, db.tracer)
+	conn, err := db.connect(ctx, connString)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to DB failed: %w", err)
 	}
 
 	db.conn = conn
-	db.ArtistHandler = artist.NewHandler(conn, db.logger, db.tracer)
+	db.WebhookHandler = webhook.NewHandler(conn, db.logger, db.tracer, db.webhookOpts...)
+	db.QueryHandler = query.NewHandler(conn, db.logger, db.tracer)
+
+	db.ArtistHandler = artist.NewHandler(conn, db.logger, db.tracer, db.artistOpts...)
 	db.LocationHandler = location.NewHandler(conn, db.logger)
 	db.EventHandler = event.NewHandler(conn, db.logger, db.tracer)
 
+	if db.webhooks {
+		db.dispatcher = webhook.NewDispatcher(db.WebhookHandler, db.webhookCfg, db.logger)
+		db.sinks = append(db.sinks, db.dispatcher)
+	}
+
+	// Without sinks, changes stay in the outbox until a relay with sinks
+	// picks them up.
+	if len(db.sinks) > 0 {
+		db.relay = outbox.NewRelay(conn, db.relayCfg, db.logger, db.sinks...)
+	}
+
+	if db.retention {
+		db.purger = retention.NewPurger(conn, db.purgerCfg, db.logger)
+	}
+
+	if db.queryPruning {
+		db.pruner = query.NewPruner(db.QueryHandler, db.prunerCfg, db.logger)
+	}
+
 	return db, nil
 }
 
+// connect returns a plain connection pool, or a routing connection if read
+// replicas are configured.
+func (db *Database) connect(ctx context.Context, connString string) (core.Connection, error) {
+	if len(db.replicaConnStrings) == 0 {
+		return core.NewConnectionPool(ctx, core.TargetPrimary, connString, db.poolConfig, db.tracer)
+	}
+
+	return core.NewRoutingConnection(
+		ctx,
+		connString,
+		db.replicaConnStrings,
+		db.poolConfig,
+		db.replicaCheckInterval,
+		db.logger,
+		db.tracer,
+	)
+}
+
 // Ready returns nil if a connection to the database can be established.
 func (db *Database) Ready(ctx context.Context) error {
 	return db.conn.Ping(ctx)
 }
 
+// ErrRetentionDisabled is returned by Purge without WithRetention.
+var ErrRetentionDisabled = errors.New("retention is disabled")
+
+// Purge applies the retention policy immediately, instead of waiting for the
+// next scheduled purge.
+func (db *Database) Purge(ctx context.Context) (retention.Result, error) {
+	if db.purger == nil {
+		return retention.Result{}, ErrRetentionDisabled
+	}
+
+	return db.purger.Purge(ctx)
+}
+
 func (db *Database) Close() {
+	if db.purger != nil {
+		db.purger.Close()
+	}
+
+	if db.pruner != nil {
+		db.pruner.Close()
+	}
+
+	if db.relay != nil {
+		db.relay.Close()
+	}
+
+	if db.dispatcher != nil {
+		db.dispatcher.Close()
+	}
+
 	db.conn.Close()
 }
 
@@ -0,0 +1,395 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/money"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// BudgetCategory groups the costs of an Event.
+type BudgetCategory string
+
+const (
+	// BudgetArtistFees is derived from the fees of invited artists and can't be
+	// used for budget lines.
+	BudgetArtistFees    BudgetCategory = "artist_fees"
+	BudgetVenue         BudgetCategory = "venue"
+	BudgetShipping      BudgetCategory = "shipping"
+	BudgetTravel        BudgetCategory = "travel"
+	BudgetAccommodation BudgetCategory = "accommodation"
+	BudgetProduction    BudgetCategory = "production"
+	BudgetMarketing     BudgetCategory = "marketing"
+	BudgetStaff         BudgetCategory = "staff"
+	BudgetOther         BudgetCategory = "other"
+)
+
+// BudgetLine is a planned cost of an Event. Amounts are in minor units of
+// Currency.
+type BudgetLine struct {
+	ID          string
+	EventID     string
+	Category    BudgetCategory
+	Description string
+	Planned     int64
+	// Actual is nil until the cost is known.
+	Actual   *int64
+	Currency string
+}
+
+// Validate checks the BudgetLine and assigns an ID if it has none.
+func (l *BudgetLine) Validate() error {
+	if l.ID == "" {
+		l.ID = uuid.NewString()
+	}
+
+	for _, id := range []string{l.ID, l.EventID} {
+		if _, err := uuid.Parse(id); err != nil {
+			return core.ErrInvalidUUID
+		}
+	}
+
+	switch l.Category {
+	case BudgetVenue, BudgetShipping, BudgetTravel, BudgetAccommodation,
+		BudgetProduction, BudgetMarketing, BudgetStaff, BudgetOther:
+	case BudgetArtistFees:
+		return errors.New("artist fees are derived from invitations")
+	default:
+		return fmt.Errorf("invalid budget category %q", l.Category)
+	}
+
+	if !money.IsCurrency(l.Currency) {
+		return fmt.Errorf("invalid currency %q", l.Currency)
+	}
+
+	if l.Planned < 0 || (l.Actual != nil && *l.Actual < 0) {
+		return errors.New("negative amount")
+	}
+
+	return nil
+}
+
+// UpsertBudgetLines creates or updates budget lines in a single transaction.
+func (h *Handler) UpsertBudgetLines(ctx context.Context, lines ...*BudgetLine) error {
+	for _, l := range lines {
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("%w: invalid budget line: %v", core.ErrInvalidInput, err)
+		}
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.budget_upsert")
+	defer span.End()
+
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		for _, l := range lines {
+			if err := upsertBudgetLine(ctx, tx, l); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "budget_upsert")
+		return fmt.Errorf("upserting budget lines: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsChanged(len(lines), entityEvent, "budget_upsert")
+	for _, l := range lines {
+		h.logger.Info("tuple modified",
+			zap.String("action", "budget_upsert"),
+			zap.String("entity", entityEvent),
+			zap.String("id", l.EventID),
+			zap.String("lineID", l.ID),
+		)
+	}
+
+	return nil
+}
+
+// upsertBudgetLine writes a budget line of an existing Event. Lines can't be
+// moved to another Event.
+func upsertBudgetLine(ctx context.Context, tx pgx.Tx, l *BudgetLine) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				id,
+				event_id,
+				category,
+				description,
+				planned_amount,
+				actual_amount,
+				currency
+			)
+		SELECT
+			$1::uuid, id, $3::text, $4::text, $5::bigint, $6::bigint, $7::text
+		FROM
+			%q
+		WHERE
+			id=$2 AND deleted_at IS NULL
+		ON CONFLICT
+			(id)
+		DO UPDATE SET
+			category=$3,
+			description=$4,
+			planned_amount=$5,
+			actual_amount=$6,
+			currency=$7
+		WHERE
+			%[1]q.event_id=$2`, core.TableEventBudgetLines, core.TableEvents)
+
+	var description *string
+	if l.Description != "" {
+		description = &l.Description
+	}
+
+	tag, err := tx.Exec(ctx, stmt,
+		l.ID,
+		l.EventID,
+		string(l.Category),
+		description,
+		l.Planned,
+		l.Actual,
+		l.Currency,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The event doesn't exist or the line belongs to another event.
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("budget line %q: %w", l.ID, core.ErrNotFound)
+	}
+
+	return nil
+}
+
+// DeleteBudgetLine removes a budget line.
+func (h *Handler) DeleteBudgetLine(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return core.ErrInvalidUUID
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.budget_delete")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		DELETE FROM
+			%q
+		WHERE
+			id=$1`, core.TableEventBudgetLines)
+
+	tag, err := h.conn.Exec(spanCtx, stmt, id)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "budget_delete")
+		return fmt.Errorf("deleting budget line: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrNotFound
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityEvent, "budget_delete")
+	h.logger.Info("tuple modified",
+		zap.String("action", "budget_delete"),
+		zap.String("entity", entityEvent),
+		zap.String("lineID", id),
+	)
+
+	return nil
+}
+
+// BudgetLines returns the budget lines of the given events, or of all events
+// if none are given.
+func (h *Handler) BudgetLines(ctx context.Context, eventIDs ...string) ([]BudgetLine, error) {
+	ids, err := eventIDFilter(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.budget_lines")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			b.id, b.event_id, b.category, b.description, b.planned_amount, b.actual_amount, b.currency
+		FROM
+			%q b
+		JOIN
+			%q e ON e.id = b.event_id
+		WHERE
+			e.deleted_at IS NULL
+			AND (cardinality($1::uuid[]) = 0 OR b.event_id = ANY($1::uuid[]))
+		ORDER BY
+			b.event_id, b.category, b.id`, core.TableEventBudgetLines, core.TableEvents)
+
+	rows, err := h.conn.Query(spanCtx, stmt, ids)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "budget_lines")
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	var lines []BudgetLine
+	for rows.Next() {
+		var (
+			l                     BudgetLine
+			category, description *string
+		)
+
+		if err := rows.Scan(&l.ID, &l.EventID, &category, &description, &l.Planned, &l.Actual, &l.Currency); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		l.Category = BudgetCategory(conversion.String(category))
+		l.Description = conversion.String(description)
+
+		lines = append(lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsRetrieved(len(lines), entityEvent)
+
+	return lines, nil
+}
+
+// Cost sums the costs of an Event in a category and currency. Budget lines
+// without an actual amount don't count towards Actual. For artist fees,
+// Planned includes all fees and Actual the paid ones; their allowances are
+// counted as travel and accommodation. Cancelled fees are not included.
+type Cost struct {
+	EventID  string
+	Category BudgetCategory
+	Currency string
+	Planned  int64
+	Actual   int64
+}
+
+// Variance returns how much more than planned was spent.
+func (c Cost) Variance() int64 {
+	return c.Actual - c.Planned
+}
+
+// Costs aggregates budget lines and artist fees of the given events, or of
+// all events if none are given.
+func (h *Handler) Costs(ctx context.Context, eventIDs ...string) ([]Cost, error) {
+	ids, err := eventIDFilter(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.costs")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		WITH fees AS (
+			SELECT
+				event_id,
+				fee_currency AS currency,
+				CASE fee_type WHEN '%[4]s' THEN fee_amount * fee_days ELSE fee_amount END AS fee,
+				travel_allowance,
+				accommodation_allowance,
+				payment_status = '%[5]s' AS paid
+			FROM
+				%[2]q
+			WHERE
+				fee_amount IS NOT NULL
+				AND payment_status <> '%[6]s'
+		), costs AS (
+			SELECT
+				event_id, category, currency, planned_amount AS planned, COALESCE(actual_amount, 0) AS actual
+			FROM
+				%[1]q
+			UNION ALL
+			SELECT
+				f.event_id, c.category, f.currency, c.amount, CASE WHEN f.paid THEN c.amount ELSE 0 END
+			FROM
+				fees f
+			CROSS JOIN LATERAL (
+				VALUES
+					('%[7]s', f.fee),
+					('%[8]s', f.travel_allowance),
+					('%[9]s', f.accommodation_allowance)
+			) AS c (category, amount)
+			WHERE
+				c.category = '%[7]s' OR c.amount > 0
+		)
+		SELECT
+			c.event_id,
+			c.category,
+			c.currency,
+			SUM(c.planned)::bigint,
+			SUM(c.actual)::bigint
+		FROM
+			costs c
+		JOIN
+			%[3]q e ON e.id = c.event_id
+		WHERE
+			e.deleted_at IS NULL
+			AND (cardinality($1::uuid[]) = 0 OR c.event_id = ANY($1::uuid[]))
+		GROUP BY
+			c.event_id, c.category, c.currency
+		ORDER BY
+			c.event_id, c.category, c.currency`,
+		core.TableEventBudgetLines, core.TableInvitedArtists, core.TableEvents,
+		FeeDaily, PaymentPaid, PaymentCancelled,
+		BudgetArtistFees, BudgetTravel, BudgetAccommodation,
+	)
+
+	rows, err := h.conn.Query(spanCtx, stmt, ids)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "costs")
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	var costs []Cost
+	for rows.Next() {
+		var (
+			c        Cost
+			category string
+		)
+
+		if err := rows.Scan(&c.EventID, &category, &c.Currency, &c.Planned, &c.Actual); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		c.Category = BudgetCategory(category)
+
+		costs = append(costs, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	return costs, nil
+}
+
+// eventIDFilter validates event IDs for an "= ANY" filter. It never returns
+// nil, so the filter can check the cardinality.
+func eventIDFilter(ids []string) ([]string, error) {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, core.ErrInvalidUUID
+		}
+	}
+
+	return append([]string{}, ids...), nil
+}
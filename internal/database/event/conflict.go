@@ -0,0 +1,635 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+	"github.com/obitech/artist-db/internal/recurrence"
+)
+
+// scheduleLockID seeds the advisory lock keys taken by lockSchedule.
+const scheduleLockID = 0x61646273636865
+
+// ConflictKind describes what is double-booked.
+type ConflictKind string
+
+const (
+	// ConflictArtist is an artist invited to overlapping events, or scheduled
+	// in overlapping slots of the same event.
+	ConflictArtist ConflictKind = "artist"
+	// ConflictLocation is a location hosting overlapping events.
+	ConflictLocation ConflictKind = "location"
+	// ConflictStage is a stage with overlapping slots.
+	ConflictStage ConflictKind = "stage"
+)
+
+// Conflict is an overlap of two Events or Slots in the schedule.
+type Conflict struct {
+	Kind ConflictKind
+	// ResourceID is the ID of the double-booked Artist or Location. For a
+	// stage it is the ID of the Event the stage belongs to.
+	ResourceID string
+	Stage      string
+
+	EventID      string
+	OtherEventID string
+	// SlotID and OtherSlotID are only set if the conflict is between slots.
+	SlotID      string
+	OtherSlotID string
+
+	// Start and End are the overlapping time range.
+	Start time.Time
+	End   time.Time
+}
+
+func (c Conflict) String() string {
+	switch c.Kind {
+	case ConflictStage:
+		return fmt.Sprintf("stage %q of event %q is double-booked by slots %q and %q", c.Stage, c.EventID, c.SlotID, c.OtherSlotID)
+	case ConflictArtist:
+		if c.SlotID != "" {
+			return fmt.Sprintf("artist %q is double-booked by slots %q and %q", c.ResourceID, c.SlotID, c.OtherSlotID)
+		}
+	}
+
+	return fmt.Sprintf("%s %q is double-booked by events %q and %q", c.Kind, c.ResourceID, c.EventID, c.OtherEventID)
+}
+
+// ConflictError is returned if an upsert would double-book the schedule.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	msgs := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, c.String())
+	}
+
+	return "schedule conflict: " + strings.Join(msgs, "; ")
+}
+
+// Conflicts returns all conflicts overlapping the range [from, to). The
+// range may span at most MaxOccurrenceRange.
+func (h *Handler) Conflicts(ctx context.Context, from, to time.Time) ([]Conflict, error) {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.conflicts", otelTrace.WithAttributes(
+		attribute.String("from", from.String()),
+		attribute.String("to", to.String()),
+	))
+	defer span.End()
+
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: range ends before it starts", core.ErrInvalidInput)
+	}
+
+	if to.Sub(from) > MaxOccurrenceRange {
+		return nil, fmt.Errorf("%w: range exceeds %s", core.ErrInvalidInput, MaxOccurrenceRange)
+	}
+
+	conflicts, err := queryConflicts(spanCtx, h.conn, from.UTC(), to.UTC(), nil)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "conflicts")
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// checkConflicts returns all conflicts involving one of the events with the
+// given IDs. Occurrences of recurring events are checked up to
+// MaxOccurrenceRange from now. The events have to be locked with
+// lockSchedule.
+func checkConflicts(ctx context.Context, tx pgx.Tx, ids []string) ([]Conflict, error) {
+	now := time.Now().UTC()
+
+	return queryConflicts(ctx, tx, now, now.Add(MaxOccurrenceRange), ids)
+}
+
+// lockSchedule serializes conflict checks of concurrent writes to the same
+// events, locations or artists, which could otherwise miss each other under
+// read committed. The events with the given IDs are locked first, then their
+// stored locations and artists along with resources, the IDs of the
+// locations and artists they are written with. Both sets are locked in
+// order, so concurrent transactions don't deadlock.
+func lockSchedule(ctx context.Context, tx pgx.Tx, ids []string, resources ...string) error {
+	if err := lockKeys(ctx, tx, ids); err != nil {
+		return err
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			location_id::text
+		FROM
+			%q
+		WHERE
+			id = ANY($1::uuid[]) AND location_id IS NOT NULL
+		UNION
+		SELECT
+			artist_id::text
+		FROM
+			%q
+		WHERE
+			event_id = ANY($1::uuid[])`, core.TableEvents, core.TableInvitedArtists)
+
+	rows, err := tx.Query(ctx, stmt, ids)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+
+		resources = append(resources, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows: %w", err)
+	}
+
+	return lockKeys(ctx, tx, resources)
+}
+
+// lockKeys takes a transaction level advisory lock for each of keys, in
+// order.
+func lockKeys(ctx context.Context, tx pgx.Tx, keys []string) error {
+	seen := make(map[string]bool, len(keys))
+	sorted := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			sorted = append(sorted, k)
+		}
+	}
+
+	sort.Strings(sorted)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtextextended(k, $2)) FROM unnest($1::text[]) AS k", sorted, int64(scheduleLockID)); err != nil {
+		return fmt.Errorf("acquiring locks failed: %w", err)
+	}
+
+	return nil
+}
+
+// introduced returns the conflicts of after which aren't covered by a conflict
+// of before, i.e. which are new or overlap longer than before.
+func introduced(before, after []Conflict) []Conflict {
+	var out []Conflict
+
+	for _, c := range after {
+		covered := false
+		for _, b := range before {
+			if b.Kind == c.Kind && b.ResourceID == c.ResourceID && b.Stage == c.Stage &&
+				b.EventID == c.EventID && b.OtherEventID == c.OtherEventID &&
+				b.SlotID == c.SlotID && b.OtherSlotID == c.OtherSlotID &&
+				!c.Start.Before(b.Start) && !c.End.After(b.End) {
+				covered = true
+				break
+			}
+		}
+
+		if !covered {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// queryConflicts finds overlapping pairs of events and slots. Events without
+// an end are treated as a point in time, events without a start are never in
+// conflict. Recurring events are checked with their first occurrence, their
+// overrides and their occurrences within [from, to).
+//
+// If ids is nil, the conflicts overlapping [from, to) are returned. Otherwise
+// the conflicts involving one of the events with the given IDs are returned,
+// and only events overlapping those are considered.
+func queryConflicts(ctx context.Context, q querier, from, to time.Time, ids []string) ([]Conflict, error) {
+	var (
+		occs   []Occurrence
+		lo, hi = from, to
+		bounds = "[)"
+		err    error
+	)
+
+	if ids == nil {
+		occs, err = recurringOccurrences(ctx, q, from, to, "TRUE")
+	} else {
+		occs, err = recurringOccurrences(ctx, q, from, to, "id = ANY($1::uuid[])", ids)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("expanding occurrences failed: %w", err)
+	}
+
+	if ids != nil {
+		first, last, ok, err := scheduleBounds(ctx, q, ids, occs)
+		if err != nil {
+			return nil, fmt.Errorf("bounding schedule failed: %w", err)
+		}
+
+		// Without a schedule, only slots can conflict, so the range is left
+		// empty.
+		lo, hi = from, from
+
+		if ok {
+			lo, hi, bounds = first, last, "[]"
+
+			// Occurrences of other events are only expanded if they share a
+			// location or artist and can overlap.
+			others, err := recurringOccurrences(ctx, q, latest(from, lo), earliest(to, hi.Add(time.Microsecond)), fmt.Sprintf(`
+				NOT (id = ANY($1::uuid[])) AND (
+					location_id IN (SELECT location_id FROM %[1]q WHERE id = ANY($1::uuid[]))
+					OR id IN (
+						SELECT ib.event_id FROM %[2]q ia JOIN %[2]q ib ON ib.artist_id = ia.artist_id WHERE ia.event_id = ANY($1::uuid[])
+					)
+				)`, core.TableEvents, core.TableInvitedArtists), ids)
+			if err != nil {
+				return nil, fmt.Errorf("expanding occurrences failed: %w", err)
+			}
+
+			occs = append(occs, others...)
+		}
+	}
+
+	var (
+		occIDs = make([]string, 0, len(occs))
+		starts = make([]time.Time, 0, len(occs))
+		ends   = make([]*time.Time, 0, len(occs))
+	)
+
+	for _, o := range occs {
+		occIDs = append(occIDs, o.EventID)
+		starts = append(starts, o.StartTime)
+		ends = append(ends, o.EndTime)
+	}
+
+	// $1 restricts the pairs to the events with the given IDs, or is NULL.
+	// $2, $3 and $4 form the range events are considered in.
+	stmt := fmt.Sprintf(`
+		WITH ranged AS (
+			SELECT
+				e.id,
+				e.location_id,
+				tstzrange(e.start_time, COALESCE(e.end_time, e.start_time), CASE WHEN e.end_time IS NULL THEN '[]' ELSE '[)' END) AS during
+			FROM
+				%[1]q e
+			WHERE
+				e.deleted_at IS NULL AND e.start_time IS NOT NULL AND e.start_time <= $3
+				AND tstzrange(e.start_time, COALESCE(e.end_time, e.start_time), '[]') && tstzrange($2, $3, $4)
+				AND NOT (e.start_time = ANY(COALESCE(e.recurrence_exceptions, '{}')))
+				AND NOT EXISTS (SELECT 1 FROM %[4]q o WHERE o.event_id = e.id AND o.recurrence_id = e.start_time)
+			UNION ALL
+			SELECT
+				e.id,
+				e.location_id,
+				tstzrange(r.start_time, COALESCE(r.end_time, r.start_time), CASE WHEN r.end_time IS NULL THEN '[]' ELSE '[)' END)
+			FROM
+				unnest($5::uuid[], $6::timestamptz[], $7::timestamptz[]) AS r(event_id, start_time, end_time)
+			JOIN
+				%[1]q e ON e.id = r.event_id
+			UNION ALL
+			SELECT
+				e.id,
+				e.location_id,
+				tstzrange(o.start_time, COALESCE(o.end_time, o.start_time), CASE WHEN o.end_time IS NULL THEN '[]' ELSE '[)' END)
+			FROM
+				%[4]q o
+			JOIN
+				%[1]q e ON e.id = o.event_id
+			WHERE
+				e.deleted_at IS NULL AND o.start_time <= $3
+				AND tstzrange(o.start_time, COALESCE(o.end_time, o.start_time), '[]') && tstzrange($2, $3, $4)
+				AND NOT (o.recurrence_id = ANY(COALESCE(e.recurrence_exceptions, '{}')))
+		), slots AS (
+			SELECT
+				s.id,
+				s.event_id,
+				s.artist_id,
+				COALESCE(s.stage, '') AS stage,
+				tstzrange(s.start_time, s.end_time) AS during
+			FROM
+				%[2]q s
+			JOIN
+				%[1]q e ON e.id = s.event_id
+			WHERE
+				e.deleted_at IS NULL AND (
+					s.event_id = ANY($1::uuid[])
+					OR ($1::uuid[] IS NULL AND tstzrange(s.start_time, s.end_time) && tstzrange($2, $3, $4))
+				)
+		), conflicts AS (
+			SELECT
+				'location' AS kind, a.location_id AS resource_id, '' AS stage, a.id AS event_id, b.id AS other_event_id,
+				NULL::uuid AS slot_id, NULL::uuid AS other_slot_id, a.during * b.during AS overlap
+			FROM
+				ranged a
+			JOIN
+				ranged b ON a.location_id = b.location_id AND a.id < b.id AND a.during && b.during
+				AND ($1::uuid[] IS NULL OR a.id = ANY($1::uuid[]) OR b.id = ANY($1::uuid[]))
+			UNION ALL
+			SELECT
+				'artist', ia.artist_id, '', a.id, b.id, NULL, NULL, a.during * b.during
+			FROM
+				%[3]q ia
+			JOIN
+				%[3]q ib ON ia.artist_id = ib.artist_id AND ia.event_id < ib.event_id
+				AND ($1::uuid[] IS NULL OR ia.event_id = ANY($1::uuid[]) OR ib.event_id = ANY($1::uuid[]))
+			JOIN
+				ranged a ON a.id = ia.event_id
+			JOIN
+				ranged b ON b.id = ib.event_id AND a.during && b.during
+			UNION ALL
+			SELECT
+				'artist', a.artist_id, '', a.event_id, b.event_id, a.id, b.id, a.during * b.during
+			FROM
+				slots a
+			JOIN
+				slots b ON a.event_id = b.event_id AND a.artist_id = b.artist_id AND a.id < b.id AND a.during && b.during
+			UNION ALL
+			SELECT
+				'stage', a.event_id, a.stage, a.event_id, b.event_id, a.id, b.id, a.during * b.during
+			FROM
+				slots a
+			JOIN
+				slots b ON a.event_id = b.event_id AND a.stage = b.stage AND a.stage <> '' AND a.id < b.id AND a.during && b.during
+		)
+		SELECT
+			kind,
+			resource_id,
+			stage,
+			event_id,
+			other_event_id,
+			slot_id,
+			other_slot_id,
+			lower(overlap),
+			upper(overlap)
+		FROM
+			conflicts
+		ORDER BY
+			lower(overlap), kind, resource_id`, core.TableEvents, core.TableEventSlots, core.TableInvitedArtists, core.TableEventOverrides)
+
+	rows, err := q.Query(ctx, stmt, ids, lo, hi, bounds, occIDs, starts, ends)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	var conflicts []Conflict
+	for rows.Next() {
+		var (
+			c                   Conflict
+			kind                string
+			slotID, otherSlotID *string
+		)
+
+		if err := rows.Scan(
+			&kind,
+			&c.ResourceID,
+			&c.Stage,
+			&c.EventID,
+			&c.OtherEventID,
+			&slotID,
+			&otherSlotID,
+			&c.Start,
+			&c.End,
+		); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		c.Kind = ConflictKind(kind)
+		c.SlotID = conversion.String(slotID)
+		c.OtherSlotID = conversion.String(otherSlotID)
+
+		c.Start = c.Start.UTC()
+		c.End = c.End.UTC()
+
+		conflicts = append(conflicts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+// recurringOccurrences returns the occurrences of recurring events matching
+// whereClause within [from, to) which are neither their first occurrence nor
+// overridden. Those are stored as rows already.
+func recurringOccurrences(ctx context.Context, q querier, from, to time.Time, whereClause string, args ...interface{}) ([]Occurrence, error) {
+	if !to.After(from) {
+		return nil, nil
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			id,
+			start_time,
+			end_time,
+			timezone,
+			recurrence,
+			recurrence_exceptions
+		FROM
+			%q
+		WHERE
+			deleted_at IS NULL AND recurrence IS NOT NULL AND start_time < $%d AND (%s)`, core.TableEvents, len(args)+1, whereClause)
+
+	rows, err := q.Query(ctx, stmt, append(args, to.UTC())...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	var (
+		events []*Event
+		byID   = make(map[string]*Event)
+	)
+
+	for rows.Next() {
+		var (
+			ev       Event
+			timezone *string
+			rule     string
+		)
+
+		if err := rows.Scan(&ev.ID, &ev.StartTime, &ev.EndTime, &timezone, &rule, &ev.Exceptions); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		if ev.Recurrence, err = recurrence.Parse(rule); err != nil {
+			return nil, fmt.Errorf("parsing recurrence of %q: %w", ev.ID, err)
+		}
+
+		ev.Timezone = conversion.String(timezone)
+		events = append(events, &ev)
+		byID[ev.ID] = &ev
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+
+	// Only the recurrence IDs matter, overridden occurrences are skipped.
+	stmt = fmt.Sprintf(`
+		SELECT
+			event_id,
+			recurrence_id
+		FROM
+			%q
+		WHERE
+			event_id = ANY($1::uuid[])`, core.TableEventOverrides)
+
+	rows, err = q.Query(ctx, stmt, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id string
+			o  Override
+		)
+
+		if err := rows.Scan(&id, &o.RecurrenceID); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ev := byID[id]
+		ev.Overrides = append(ev.Overrides, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	var out []Occurrence
+	for _, ev := range events {
+		for _, occ := range ev.Occurrences(from, to) {
+			if !occ.Overridden && !occ.RecurrenceID.Equal(*ev.StartTime) {
+				out = append(out, occ)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// scheduleBounds returns the earliest start and latest end of the events with
+// the given IDs, their overrides and occs. ok is false if none of them is
+// scheduled.
+func scheduleBounds(ctx context.Context, q querier, ids []string, occs []Occurrence) (from, to time.Time, ok bool, err error) {
+	stmt := fmt.Sprintf(`
+		SELECT
+			min(start_time),
+			max(COALESCE(end_time, start_time))
+		FROM
+			(
+				SELECT
+					start_time,
+					end_time
+				FROM
+					%[1]q
+				WHERE
+					id = ANY($1::uuid[]) AND deleted_at IS NULL AND start_time IS NOT NULL
+				UNION ALL
+				SELECT
+					o.start_time,
+					o.end_time
+				FROM
+					%[2]q o
+				JOIN
+					%[1]q e ON e.id = o.event_id
+				WHERE
+					o.event_id = ANY($1::uuid[]) AND e.deleted_at IS NULL
+			) s`, core.TableEvents, core.TableEventOverrides)
+
+	rows, err := q.Query(ctx, stmt, ids)
+	if err != nil {
+		return from, to, false, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	var first, last *time.Time
+	for rows.Next() {
+		if err := rows.Scan(&first, &last); err != nil {
+			return from, to, false, fmt.Errorf("scan: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return from, to, false, fmt.Errorf("rows: %w", err)
+	}
+
+	if first != nil {
+		from, to, ok = first.UTC(), last.UTC(), true
+	}
+
+	for _, o := range occs {
+		end := o.StartTime
+		if o.EndTime != nil {
+			end = *o.EndTime
+		}
+
+		if !ok || o.StartTime.Before(from) {
+			from = o.StartTime
+		}
+
+		if !ok || end.After(to) {
+			to = end
+		}
+
+		ok = true
+	}
+
+	return from, to, ok, nil
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
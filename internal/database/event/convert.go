@@ -0,0 +1,84 @@
+package event
+
+import (
+	"time"
+
+	"github.com/obitech/artist-db/internal/money"
+)
+
+// Converter converts money between currencies at the rates valid at a time.
+type Converter interface {
+	Convert(m money.Money, to string, at time.Time) (money.Money, error)
+}
+
+// ConvertCosts converts costs into the currency to at the rates valid at at
+// and sums them per event and category. Costs have to be ordered as returned
+// by Costs.
+func ConvertCosts(costs []Cost, c Converter, to string, at time.Time) ([]Cost, error) {
+	var out []Cost
+
+	for _, cost := range costs {
+		amounts, err := convertAll(c, cost.Currency, to, at, cost.Planned, cost.Actual)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(out); n > 0 && out[n-1].EventID == cost.EventID && out[n-1].Category == cost.Category {
+			out[n-1].Planned += amounts[0]
+			out[n-1].Actual += amounts[1]
+			continue
+		}
+
+		out = append(out, Cost{
+			EventID:  cost.EventID,
+			Category: cost.Category,
+			Currency: to,
+			Planned:  amounts[0],
+			Actual:   amounts[1],
+		})
+	}
+
+	return out, nil
+}
+
+// ConvertFeeTotals converts totals into the currency to at the rates valid at
+// at and sums them per ID. Totals have to be ordered as returned by
+// FeeTotalsByEvent or FeeTotalsByArtist.
+func ConvertFeeTotals(totals []FeeTotal, c Converter, to string, at time.Time) ([]FeeTotal, error) {
+	var out []FeeTotal
+
+	for _, t := range totals {
+		amounts, err := convertAll(c, t.Currency, to, at, t.Fees, t.Allowances, t.Paid, t.Outstanding, t.Overdue)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(out); n == 0 || out[n-1].ID != t.ID {
+			out = append(out, FeeTotal{ID: t.ID, Currency: to})
+		}
+
+		sum := &out[len(out)-1]
+		sum.Fees += amounts[0]
+		sum.Allowances += amounts[1]
+		sum.Paid += amounts[2]
+		sum.Outstanding += amounts[3]
+		sum.Overdue += amounts[4]
+	}
+
+	return out, nil
+}
+
+func convertAll(c Converter, from, to string, at time.Time, amounts ...int64) ([]int64, error) {
+	out := make([]int64, 0, len(amounts))
+
+	for _, a := range amounts {
+		m, err := c.Convert(money.New(a, from), to, at)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, m.Amount)
+	}
+
+	return out, nil
+}
@@ -1,19 +1,45 @@
 package event
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/obitech/artist-db/internal/recurrence"
 )
 
 type Event struct {
-	ID             string
-	Name           string
-	StartTime      *time.Time
-	LocationID     *string
+	ID         string
+	Name       string
+	StartTime  *time.Time
+	EndTime    *time.Time
+	Timezone   string
+	LocationID *string
+
+	// InvitedArtists and Slots replace the stored ones on upsert. Nil keeps
+	// the stored ones, an empty list removes them.
 	InvitedArtists InvitedArtists
+	Slots          []Slot
+
+	// Recurrence repeats the Event starting at StartTime. Exceptions are
+	// cancelled occurrences, Overrides replace single occurrences. Upserting
+	// an Event with nil Overrides keeps the stored ones, as long as they
+	// still replace an occurrence.
+	Recurrence *recurrence.Rule
+	Exceptions []time.Time
+	Overrides  []Override
+
+	// Published Events are shown by the public API between PublishFrom and
+	// PublishUntil, either of which may be unset.
+	Published    bool
+	PublishFrom  *time.Time
+	PublishUntil *time.Time
+
+	// UpdatedAt is set by the database.
+	UpdatedAt time.Time
 }
 
 func (e *Event) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -32,6 +58,8 @@ type InvitedArtists []InvitedArtist
 type InvitedArtist struct {
 	ID        string
 	Confirmed bool
+	// Fee is left unchanged on upsert if nil.
+	Fee *Fee
 }
 
 func (ia InvitedArtists) MarshalLogArray(enc zapcore.ArrayEncoder) error {
@@ -50,6 +78,30 @@ func (i InvitedArtist) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	return nil
 }
 
+// Slot assigns an invited artist to a time range and stage within an Event,
+// e.g. a performance.
+type Slot struct {
+	ID        string
+	ArtistID  string
+	StartTime time.Time
+	EndTime   time.Time
+	Stage     string
+}
+
+// Location returns the time zone of the Event, which defaults to UTC.
+func (e *Event) Location() *time.Location {
+	if e.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
 type Option func(*Event) error
 
 func WithLocationID(id string) Option {
@@ -72,14 +124,131 @@ func WithStartTime(startTime time.Time) Option {
 	}
 }
 
+func WithEndTime(endTime time.Time) Option {
+	return func(e *Event) error {
+		t := endTime.UTC()
+		e.EndTime = &t
+
+		return nil
+	}
+}
+
+// WithTimezone sets the IANA time zone the Event takes place in.
+func WithTimezone(name string) Option {
+	return func(e *Event) error {
+		if _, err := time.LoadLocation(name); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", name, err)
+		}
+
+		e.Timezone = name
+		return nil
+	}
+}
+
+// WithSlots schedules invited artists within the Event.
+func WithSlots(slots ...Slot) Option {
+	return func(e *Event) error {
+		if e.Slots == nil {
+			e.Slots = []Slot{}
+		}
+
+		for _, s := range slots {
+			if s.ID == "" {
+				s.ID = uuid.New().String()
+			}
+
+			if _, err := uuid.Parse(s.ID); err != nil {
+				return fmt.Errorf("invalid UUID %q: %w", s.ID, err)
+			}
+
+			if _, err := uuid.Parse(s.ArtistID); err != nil {
+				return fmt.Errorf("invalid UUID %q: %w", s.ArtistID, err)
+			}
+
+			if !s.EndTime.After(s.StartTime) {
+				return fmt.Errorf("slot %q ends before it starts", s.ID)
+			}
+
+			s.StartTime = s.StartTime.UTC()
+			s.EndTime = s.EndTime.UTC()
+
+			e.Slots = append(e.Slots, s)
+		}
+
+		return nil
+	}
+}
+
+// WithRecurrence repeats the Event according to an RRULE, except for the
+// occurrences starting at one of exceptions.
+func WithRecurrence(rule string, exceptions ...time.Time) Option {
+	return func(e *Event) error {
+		r, err := recurrence.Parse(rule)
+		if err != nil {
+			return fmt.Errorf("invalid recurrence %q: %w", rule, err)
+		}
+
+		e.Recurrence = r
+
+		for _, t := range exceptions {
+			e.Exceptions = append(e.Exceptions, t.UTC())
+		}
+
+		return nil
+	}
+}
+
+// WithPublication publishes the Event between from and until. Nil bounds
+// leave the window open.
+func WithPublication(from, until *time.Time) Option {
+	return func(e *Event) error {
+		e.Published = true
+
+		if from != nil {
+			t := from.UTC()
+			e.PublishFrom = &t
+		}
+
+		if until != nil {
+			t := until.UTC()
+			e.PublishUntil = &t
+		}
+
+		return nil
+	}
+}
+
+// PublishedAt returns whether the Event is published at t.
+func (e *Event) PublishedAt(t time.Time) bool {
+	if !e.Published {
+		return false
+	}
+
+	if e.PublishFrom != nil && t.Before(*e.PublishFrom) {
+		return false
+	}
+
+	return e.PublishUntil == nil || t.Before(*e.PublishUntil)
+}
+
 // WithInvitedArtists allows assigning artists to an event.
 func WithInvitedArtists(artists ...InvitedArtist) Option {
 	return func(e *Event) error {
+		if e.InvitedArtists == nil {
+			e.InvitedArtists = InvitedArtists{}
+		}
+
 		for _, a := range artists {
 			if _, err := uuid.Parse(a.ID); err != nil {
 				return fmt.Errorf("invalid UUID %q: %w", a.ID, err)
 			}
 
+			if a.Fee != nil {
+				if err := a.Fee.Validate(); err != nil {
+					return fmt.Errorf("invalid fee of %q: %w", a.ID, err)
+				}
+			}
+
 			e.InvitedArtists = append(e.InvitedArtists, a)
 		}
 
@@ -99,5 +268,45 @@ func New(name string, options ...Option) (*Event, error) {
 		}
 	}
 
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+
 	return e, nil
 }
+
+// validate checks that the schedule of the Event is consistent.
+func (e *Event) validate() error {
+	if e.StartTime != nil && e.EndTime != nil && e.EndTime.Before(*e.StartTime) {
+		return errors.New("event ends before it starts")
+	}
+
+	if e.PublishFrom != nil && e.PublishUntil != nil && !e.PublishUntil.After(*e.PublishFrom) {
+		return errors.New("event is unpublished before it is published")
+	}
+
+	if e.StartTime == nil && (e.Recurrence != nil || len(e.Exceptions) > 0) {
+		return errors.New("recurring event has no start")
+	}
+
+	invited := make(map[string]bool, len(e.InvitedArtists))
+	for _, a := range e.InvitedArtists {
+		invited[a.ID] = true
+	}
+
+	for _, s := range e.Slots {
+		if !invited[s.ArtistID] {
+			return fmt.Errorf("artist %q of slot %q is not invited", s.ArtistID, s.ID)
+		}
+
+		if e.StartTime != nil && s.StartTime.Before(*e.StartTime) {
+			return fmt.Errorf("slot %q starts before the event", s.ID)
+		}
+
+		if e.EndTime != nil && s.EndTime.After(*e.EndTime) {
+			return fmt.Errorf("slot %q ends after the event", s.ID)
+		}
+	}
+
+	return nil
+}
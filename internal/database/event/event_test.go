@@ -0,0 +1,363 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/money"
+)
+
+func TestNew_Schedule(t *testing.T) {
+	var (
+		artistID = uuid.NewString()
+		start    = time.Date(2022, 7, 1, 16, 0, 0, 0, time.UTC)
+		end      = start.Add(48 * time.Hour)
+	)
+
+	slot := func(from, to time.Time) Slot {
+		return Slot{ArtistID: artistID, StartTime: from, EndTime: to}
+	}
+
+	t.Run("valid schedule", func(t *testing.T) {
+		ev, err := New("festival",
+			WithStartTime(start),
+			WithEndTime(end),
+			WithTimezone("Europe/Berlin"),
+			WithInvitedArtists(InvitedArtist{ID: artistID}),
+			WithSlots(slot(start.Add(time.Hour), start.Add(2*time.Hour))),
+		)
+		require.NoError(t, err)
+
+		require.Len(t, ev.Slots, 1)
+		assert.NotEmpty(t, ev.Slots[0].ID)
+		assert.Equal(t, "Europe/Berlin", ev.Location().String())
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		_, err := New("festival", WithTimezone("Mars/Olympus_Mons"))
+		assert.Error(t, err)
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		_, err := New("festival", WithStartTime(end), WithEndTime(start))
+		assert.Error(t, err)
+	})
+
+	t.Run("empty slot", func(t *testing.T) {
+		_, err := New("festival",
+			WithInvitedArtists(InvitedArtist{ID: artistID}),
+			WithSlots(slot(start, start)),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("slot of artist which isn't invited", func(t *testing.T) {
+		_, err := New("festival", WithSlots(slot(start, end)))
+		assert.Error(t, err)
+	})
+
+	t.Run("slot outside of event", func(t *testing.T) {
+		_, err := New("festival",
+			WithStartTime(start),
+			WithEndTime(end),
+			WithInvitedArtists(InvitedArtist{ID: artistID}),
+			WithSlots(slot(end, end.Add(time.Hour))),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("timezone defaults to UTC", func(t *testing.T) {
+		ev, err := New("festival")
+		require.NoError(t, err)
+
+		assert.Equal(t, time.UTC, ev.Location())
+	})
+}
+
+func TestEvent_Occurrences(t *testing.T) {
+	// A Thursday, in winter time.
+	start := time.Date(2023, 3, 16, 19, 0, 0, 0, time.UTC)
+
+	ev, err := New("open stage",
+		WithStartTime(start),
+		WithEndTime(start.Add(3*time.Hour)),
+		WithTimezone("Europe/Berlin"),
+		WithRecurrence("FREQ=WEEKLY;COUNT=4", start.AddDate(0, 0, 7)),
+	)
+	require.NoError(t, err)
+
+	t.Run("recurring event requires start", func(t *testing.T) {
+		_, err := New("no start", WithRecurrence("FREQ=DAILY"))
+		require.Error(t, err)
+	})
+
+	t.Run("expands in the event's time zone", func(t *testing.T) {
+		occs := ev.Occurrences(start, start.AddDate(0, 1, 0))
+
+		// The second week is an exception, clocks change before the third.
+		require.Len(t, occs, 3)
+		assert.Equal(t, start, occs[0].StartTime)
+		assert.Equal(t, start.AddDate(0, 0, 14).Add(-time.Hour), occs[1].StartTime)
+		assert.Equal(t, start.AddDate(0, 0, 21).Add(-time.Hour), occs[2].StartTime)
+		assert.Equal(t, occs[2].StartTime.Add(3*time.Hour), *occs[2].EndTime)
+	})
+
+	t.Run("occurrences overlapping the window are included", func(t *testing.T) {
+		occs := ev.Occurrences(start.Add(time.Hour), start.Add(2*time.Hour))
+
+		require.Len(t, occs, 1)
+		assert.Equal(t, start, occs[0].StartTime)
+	})
+
+	t.Run("overrides replace occurrences", func(t *testing.T) {
+		recurrenceID := start.AddDate(0, 0, 14).Add(-time.Hour)
+		require.True(t, ev.IsOccurrence(recurrenceID))
+		require.False(t, ev.IsOccurrence(recurrenceID.Add(time.Minute)))
+
+		moved := *ev
+		moved.Overrides = []Override{{
+			RecurrenceID: recurrenceID,
+			Name:         "open stage special",
+			StartTime:    recurrenceID.Add(24 * time.Hour),
+		}}
+
+		occs := moved.Occurrences(start, start.AddDate(0, 1, 0))
+
+		require.Len(t, occs, 3)
+		assert.Equal(t, recurrenceID, occs[1].RecurrenceID)
+		assert.Equal(t, recurrenceID.Add(24*time.Hour), occs[1].StartTime)
+		assert.Equal(t, "open stage special", occs[1].Name)
+		assert.Nil(t, occs[1].EndTime)
+		assert.True(t, occs[1].Overridden)
+	})
+
+	t.Run("single event has one occurrence", func(t *testing.T) {
+		single, err := New("single", WithStartTime(start))
+		require.NoError(t, err)
+
+		assert.Len(t, single.Occurrences(start, start.Add(time.Hour)), 1)
+		assert.Len(t, single.Occurrences(start.Add(time.Second), start.Add(time.Hour)), 0)
+	})
+
+	t.Run("occurrences are capped", func(t *testing.T) {
+		daily, err := New("daily", WithStartTime(start), WithRecurrence("FREQ=DAILY"))
+		require.NoError(t, err)
+
+		occs := daily.Occurrences(start, start.AddDate(5, 0, 0))
+
+		require.Len(t, occs, maxOccurrences)
+		assert.Equal(t, start, occs[0].StartTime)
+	})
+}
+
+func TestIntroduced(t *testing.T) {
+	start := time.Date(2023, 3, 16, 19, 0, 0, 0, time.UTC)
+
+	existing := Conflict{
+		Kind:         ConflictLocation,
+		ResourceID:   "location",
+		EventID:      "a",
+		OtherEventID: "b",
+		Start:        start,
+		End:          start.Add(2 * time.Hour),
+	}
+
+	shorter, longer, other := existing, existing, existing
+	shorter.End = start.Add(time.Hour)
+	longer.End = start.Add(3 * time.Hour)
+	other.OtherEventID = "c"
+
+	for _, tc := range []struct {
+		name   string
+		before []Conflict
+		after  []Conflict
+		want   []Conflict
+	}{
+		{name: "new conflict", after: []Conflict{existing}, want: []Conflict{existing}},
+		{name: "unchanged conflict", before: []Conflict{existing}, after: []Conflict{existing}},
+		{name: "shorter conflict", before: []Conflict{existing}, after: []Conflict{shorter}},
+		{name: "longer conflict", before: []Conflict{existing}, after: []Conflict{longer}, want: []Conflict{longer}},
+		{name: "conflict with another event", before: []Conflict{existing}, after: []Conflict{existing, other}, want: []Conflict{other}},
+		{name: "resolved conflict", before: []Conflict{existing}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, introduced(tc.before, tc.after))
+		})
+	}
+}
+
+func TestFee_Validate(t *testing.T) {
+	t.Run("defaults are set", func(t *testing.T) {
+		fee := Fee{Amount: 50000, Currency: "EUR"}
+		require.NoError(t, fee.Validate())
+
+		assert.Equal(t, FeeFlat, fee.Type)
+		assert.Equal(t, 1, fee.Days)
+		assert.Equal(t, PaymentPending, fee.Status)
+		assert.Nil(t, fee.PaidAt)
+	})
+
+	t.Run("paid fee gets payment date", func(t *testing.T) {
+		fee := Fee{Amount: 50000, Currency: "EUR", Status: PaymentPaid}
+		require.NoError(t, fee.Validate())
+
+		assert.NotNil(t, fee.PaidAt)
+	})
+
+	for name, fee := range map[string]Fee{
+		"invalid currency": {Amount: 1, Currency: "eur"},
+		"negative amount":  {Amount: -1, Currency: "EUR"},
+		"negative days":    {Amount: 1, Currency: "EUR", Type: FeeDaily, Days: -1},
+		"unknown type":     {Amount: 1, Currency: "EUR", Type: "hourly"},
+		"unknown status":   {Amount: 1, Currency: "EUR", Status: "waived"},
+	} {
+		fee := fee
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, fee.Validate())
+		})
+	}
+}
+
+func TestFee_Total(t *testing.T) {
+	fee := Fee{
+		Amount:                 20000,
+		Currency:               "EUR",
+		Type:                   FeeDaily,
+		Days:                   3,
+		TravelAllowance:        5000,
+		AccommodationAllowance: 12000,
+	}
+
+	assert.Equal(t, int64(77000), fee.Total())
+
+	fee.Type = FeeFlat
+	assert.Equal(t, int64(37000), fee.Total())
+}
+
+func TestBudgetLine_Validate(t *testing.T) {
+	eventID := uuid.NewString()
+
+	t.Run("ID is assigned", func(t *testing.T) {
+		l := BudgetLine{EventID: eventID, Category: BudgetShipping, Planned: 12000, Currency: "EUR"}
+		require.NoError(t, l.Validate())
+
+		assert.NotEmpty(t, l.ID)
+	})
+
+	negative := int64(-1)
+
+	for name, l := range map[string]BudgetLine{
+		"artist fees":      {EventID: eventID, Category: BudgetArtistFees, Currency: "EUR"},
+		"unknown category": {EventID: eventID, Category: "catering", Currency: "EUR"},
+		"invalid event ID": {EventID: "event", Category: BudgetVenue, Currency: "EUR"},
+		"invalid currency": {EventID: eventID, Category: BudgetVenue, Currency: "€"},
+		"negative actual":  {EventID: eventID, Category: BudgetVenue, Currency: "EUR", Actual: &negative},
+	} {
+		l := l
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, l.Validate())
+		})
+	}
+}
+
+type fixedRates map[string]int64
+
+// Convert converts at a fixed rate of minor units of to per minor unit.
+func (r fixedRates) Convert(m money.Money, to string, _ time.Time) (money.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	rate, ok := r[m.Currency]
+	if !ok {
+		return money.Money{}, money.ErrNoRate
+	}
+
+	return money.New(m.Amount*rate, to), nil
+}
+
+func TestConvertCosts(t *testing.T) {
+	rates := fixedRates{"USD": 2}
+	now := time.Now()
+
+	costs := []Cost{
+		{EventID: "1", Category: BudgetArtistFees, Currency: "EUR", Planned: 100, Actual: 50},
+		{EventID: "1", Category: BudgetArtistFees, Currency: "USD", Planned: 10, Actual: 5},
+		{EventID: "1", Category: BudgetVenue, Currency: "USD", Planned: 30},
+		{EventID: "2", Category: BudgetVenue, Currency: "EUR", Planned: 40},
+	}
+
+	got, err := ConvertCosts(costs, rates, "EUR", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Cost{
+		{EventID: "1", Category: BudgetArtistFees, Currency: "EUR", Planned: 120, Actual: 60},
+		{EventID: "1", Category: BudgetVenue, Currency: "EUR", Planned: 60},
+		{EventID: "2", Category: BudgetVenue, Currency: "EUR", Planned: 40},
+	}, got)
+
+	t.Run("unknown rate", func(t *testing.T) {
+		_, err := ConvertCosts(costs, rates, "GBP", now)
+		require.ErrorIs(t, err, money.ErrNoRate)
+	})
+}
+
+func TestConvertFeeTotals(t *testing.T) {
+	totals := []FeeTotal{
+		{ID: "1", Currency: "EUR", Fees: 100, Allowances: 10, Paid: 110},
+		{ID: "1", Currency: "USD", Fees: 50, Outstanding: 50, Overdue: 50},
+		{ID: "2", Currency: "USD", Fees: 5, Outstanding: 5},
+	}
+
+	got, err := ConvertFeeTotals(totals, fixedRates{"USD": 2}, "EUR", time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, []FeeTotal{
+		{ID: "1", Currency: "EUR", Fees: 200, Allowances: 10, Paid: 110, Outstanding: 100, Overdue: 100},
+		{ID: "2", Currency: "EUR", Fees: 10, Outstanding: 10},
+	}, got)
+}
+
+func TestEvent_PublishedAt(t *testing.T) {
+	var (
+		from  = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+		until = from.Add(30 * 24 * time.Hour)
+	)
+
+	unpublished, err := New("festival")
+	require.NoError(t, err)
+
+	open, err := New("festival", WithPublication(nil, nil))
+	require.NoError(t, err)
+
+	window, err := New("festival", WithPublication(&from, &until))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name string
+		ev   *Event
+		at   time.Time
+		want bool
+	}{
+		{"unpublished", unpublished, from, false},
+		{"open window", open, from, true},
+		{"before window", window, from.Add(-time.Second), false},
+		{"start of window", window, from, true},
+		{"end of window", window, until, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.ev.PublishedAt(tc.at))
+		})
+	}
+
+	t.Run("window ends before it starts", func(t *testing.T) {
+		_, err := New("festival", WithPublication(&until, &from))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,318 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/money"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// FeeType describes how the amount of a Fee is paid.
+type FeeType string
+
+const (
+	// FeeFlat is paid once.
+	FeeFlat FeeType = "flat"
+	// FeeDaily is paid for each of Days.
+	FeeDaily FeeType = "daily"
+)
+
+// PaymentStatus tracks the payment of a Fee.
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentInvoiced  PaymentStatus = "invoiced"
+	PaymentPaid      PaymentStatus = "paid"
+	PaymentCancelled PaymentStatus = "cancelled"
+)
+
+// Fee is the agreement with an invited artist. Amounts are in minor units of
+// Currency, e.g. cents.
+type Fee struct {
+	Amount   int64
+	Currency string
+	Type     FeeType
+	// Days is the number of days a daily fee is paid for.
+	Days                   int
+	TravelAllowance        int64
+	AccommodationAllowance int64
+
+	Status  PaymentStatus
+	DueDate *time.Time
+	PaidAt  *time.Time
+	// ContractRefs reference the contract documents, e.g. file paths or URLs.
+	ContractRefs []string
+}
+
+// Total returns the amount owed including allowances.
+func (f *Fee) Total() int64 {
+	amount := f.Amount
+	if f.Type == FeeDaily {
+		amount *= int64(f.Days)
+	}
+
+	return amount + f.TravelAllowance + f.AccommodationAllowance
+}
+
+// Validate checks the Fee and fills in defaults.
+func (f *Fee) Validate() error {
+	if !money.IsCurrency(f.Currency) {
+		return fmt.Errorf("invalid currency %q", f.Currency)
+	}
+
+	if f.Amount < 0 || f.TravelAllowance < 0 || f.AccommodationAllowance < 0 {
+		return errors.New("negative amount")
+	}
+
+	switch f.Type {
+	case "":
+		f.Type = FeeFlat
+	case FeeFlat, FeeDaily:
+	default:
+		return fmt.Errorf("invalid fee type %q", f.Type)
+	}
+
+	if f.Days == 0 {
+		f.Days = 1
+	}
+
+	if f.Days < 0 {
+		return fmt.Errorf("invalid number of days %d", f.Days)
+	}
+
+	switch f.Status {
+	case "":
+		f.Status = PaymentPending
+	case PaymentPending, PaymentInvoiced, PaymentPaid, PaymentCancelled:
+	default:
+		return fmt.Errorf("invalid payment status %q", f.Status)
+	}
+
+	if f.Status == PaymentPaid && f.PaidAt == nil {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		f.PaidAt = &now
+	}
+
+	return nil
+}
+
+// SetFee sets the fee of an invited artist. A nil fee removes it.
+func (h *Handler) SetFee(ctx context.Context, eventID, artistID string, fee *Fee) error {
+	for _, id := range []string{eventID, artistID} {
+		if _, err := uuid.Parse(id); err != nil {
+			return core.ErrInvalidUUID
+		}
+	}
+
+	if fee != nil {
+		if err := fee.Validate(); err != nil {
+			return fmt.Errorf("%w: invalid fee: %v", core.ErrInvalidInput, err)
+		}
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.fee")
+	defer span.End()
+
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		confirmed, err := setFee(ctx, tx, eventID, artistID, fee)
+		if err != nil {
+			return err
+		}
+
+		return core.WriteOutbox(ctx, tx, core.Change{
+			Entity:    core.EntityInvitation,
+			Action:    core.ActionUpsert,
+			ID:        artistID,
+			EventID:   eventID,
+			Confirmed: confirmed,
+		})
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return core.ErrNotFound
+		}
+
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "fee")
+		return fmt.Errorf("setting fee: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityEvent, "fee")
+	h.logger.Info("tuple modified",
+		zap.String("action", "fee"),
+		zap.String("entity", entityEvent),
+		zap.String("id", eventID),
+		zap.String("artistID", artistID),
+	)
+
+	return nil
+}
+
+// setFee writes the fee columns of an invitation and returns whether it is
+// confirmed.
+func setFee(ctx context.Context, tx pgx.Tx, eventID, artistID string, fee *Fee) (bool, error) {
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%q
+		SET
+			fee_amount=$3,
+			fee_currency=$4,
+			fee_type=$5,
+			fee_days=$6,
+			travel_allowance=$7,
+			accommodation_allowance=$8,
+			payment_status=$9,
+			due_date=$10,
+			paid_at=$11,
+			contract_refs=$12
+		WHERE
+			event_id=$1 AND artist_id=$2
+		RETURNING
+			confirmed`, core.TableInvitedArtists)
+
+	var args []interface{}
+	if fee != nil {
+		args = []interface{}{
+			fee.Amount,
+			fee.Currency,
+			string(fee.Type),
+			fee.Days,
+			fee.TravelAllowance,
+			fee.AccommodationAllowance,
+			string(fee.Status),
+			fee.DueDate,
+			fee.PaidAt,
+			fee.ContractRefs,
+		}
+	} else {
+		args = make([]interface{}, 10)
+	}
+
+	var confirmed bool
+	if err := tx.QueryRow(ctx, stmt, append([]interface{}{eventID, artistID}, args...)...).Scan(&confirmed); err != nil {
+		return false, err
+	}
+
+	return confirmed, nil
+}
+
+// FeeTotal sums the fees of an Event or Artist in a single currency.
+// Cancelled fees are not included.
+type FeeTotal struct {
+	// ID of the Event or Artist.
+	ID       string
+	Currency string
+
+	Fees       int64
+	Allowances int64
+	Paid       int64
+	// Outstanding is the unpaid part of Fees and Allowances, Overdue the part
+	// of it which is past its due date.
+	Outstanding int64
+	Overdue     int64
+}
+
+// Total returns the sum of fees and allowances.
+func (t FeeTotal) Total() int64 {
+	return t.Fees + t.Allowances
+}
+
+// FeeFilter limits fee totals to some events and artists. Empty fields
+// don't limit.
+type FeeFilter struct {
+	EventIDs  []string
+	ArtistIDs []string
+}
+
+// FeeTotalsByEvent returns the fee totals per event.
+func (h *Handler) FeeTotalsByEvent(ctx context.Context, filter FeeFilter) ([]FeeTotal, error) {
+	return h.feeTotals(ctx, "event_id", filter)
+}
+
+// FeeTotalsByArtist returns the fee totals per artist over all events.
+func (h *Handler) FeeTotalsByArtist(ctx context.Context, filter FeeFilter) ([]FeeTotal, error) {
+	return h.feeTotals(ctx, "artist_id", filter)
+}
+
+func (h *Handler) feeTotals(ctx context.Context, groupBy string, filter FeeFilter) ([]FeeTotal, error) {
+	// Never pass NULL, so the filters can check the cardinality.
+	eventIDs := append([]string{}, filter.EventIDs...)
+	artistIDs := append([]string{}, filter.ArtistIDs...)
+
+	for _, id := range append(append([]string{}, eventIDs...), artistIDs...) {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, core.ErrInvalidUUID
+		}
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.fee_totals")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		WITH fees AS (
+			SELECT
+				ia.%[3]s AS id,
+				ia.fee_currency AS currency,
+				CASE ia.fee_type WHEN '%[4]s' THEN ia.fee_amount * ia.fee_days ELSE ia.fee_amount END AS fee,
+				ia.travel_allowance + ia.accommodation_allowance AS allowance,
+				ia.payment_status = '%[5]s' AS paid,
+				ia.due_date < now() AS overdue
+			FROM
+				%[1]q ia
+			JOIN
+				%[2]q e ON e.id = ia.event_id
+			WHERE
+				e.deleted_at IS NULL
+				AND ia.fee_amount IS NOT NULL
+				AND ia.payment_status <> '%[6]s'
+				AND (cardinality($1::uuid[]) = 0 OR ia.event_id = ANY($1::uuid[]))
+				AND (cardinality($2::uuid[]) = 0 OR ia.artist_id = ANY($2::uuid[]))
+		)
+		SELECT
+			id,
+			currency,
+			SUM(fee)::bigint,
+			SUM(allowance)::bigint,
+			COALESCE(SUM(fee + allowance) FILTER (WHERE paid), 0)::bigint,
+			COALESCE(SUM(fee + allowance) FILTER (WHERE NOT paid), 0)::bigint,
+			COALESCE(SUM(fee + allowance) FILTER (WHERE NOT paid AND overdue), 0)::bigint
+		FROM
+			fees
+		GROUP BY
+			id, currency
+		ORDER BY
+			id, currency`, core.TableInvitedArtists, core.TableEvents, groupBy, FeeDaily, PaymentPaid, PaymentCancelled)
+
+	rows, err := h.conn.Query(spanCtx, stmt, eventIDs, artistIDs)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "fee_totals")
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	var totals []FeeTotal
+	for rows.Next() {
+		var t FeeTotal
+		if err := rows.Scan(&t.ID, &t.Currency, &t.Fees, &t.Allowances, &t.Paid, &t.Outstanding, &t.Overdue); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		totals = append(totals, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	return totals, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/obitech/artist-db/internal/conversion"
 	"github.com/obitech/artist-db/internal/database/core"
 	"github.com/obitech/artist-db/internal/observability"
+	"github.com/obitech/artist-db/internal/recurrence"
 )
 
 const (
@@ -39,51 +40,129 @@ func NewHandler(conn core.Connection, logger *zap.Logger, tp otelTrace.TracerPro
 }
 
 // Upsert creates or updates one or more events in the database.
-// Multiple events are inserted in the same transaction.
+// Multiple events are inserted in the same transaction. If the events would
+// double-book an artist, location or stage, nothing is written and a
+// *ConflictError is returned. Conflicts which existed before are kept, unless
+// the events make them overlap longer.
 func (h *Handler) Upsert(ctx context.Context, events ...*Event) error {
-	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.upsert")
-	defer span.End()
+	_, err := h.upsert(ctx, false, events...)
+	return err
+}
 
-	tx, err := h.conn.Begin(spanCtx)
-	if err != nil {
-		return fmt.Errorf("creating tx failed: %w", err)
-	}
+// UpsertAllowingConflicts is like Upsert, but writes the events regardless of
+// conflicts, which are returned instead.
+func (h *Handler) UpsertAllowingConflicts(ctx context.Context, events ...*Event) ([]Conflict, error) {
+	return h.upsert(ctx, true, events...)
+}
 
-	defer core.RollbackAndLogError(spanCtx, tx, h.logger)
+func (h *Handler) upsert(ctx context.Context, allowConflicts bool, events ...*Event) ([]Conflict, error) {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "artist.upsert")
+	defer span.End()
 
 	var (
-		mErr          error
-		eventsChanged int
+		changed   []*Event
+		conflicts []Conflict
 	)
-	for _, event := range events {
-		if err := h.upsertEvent(spanCtx, tx, event); err != nil {
-			if errors.Is(err, pgx.ErrTxClosed) {
-				return fmt.Errorf("insert aborted, tx cancelled: %w", err)
+
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		var (
+			mErr        error
+			invitations []core.Change
+			existing    []Conflict
+			ids         = make([]string, 0, len(events))
+			resources   []string
+		)
+		changed, conflicts = nil, nil
+
+		for _, event := range events {
+			ids = append(ids, event.ID)
+
+			if event.LocationID != nil {
+				resources = append(resources, *event.LocationID)
 			}
 
-			observability.Metrics.TrackObjectError(entityEvent, "upsert")
-			mErr = multierr.Append(mErr, err)
-		} else {
-			eventsChanged++
-			h.logger.Info("tuple modified",
-				zap.String("action", "upsert"),
-				zap.String("entity", entityEvent),
-				zap.Object("event", event),
-			)
+			for _, invited := range event.InvitedArtists {
+				resources = append(resources, invited.ID)
+			}
+		}
+
+		if err := lockSchedule(ctx, tx, ids, resources...); err != nil {
+			return fmt.Errorf("locking schedule: %w", err)
+		}
+
+		if !allowConflicts {
+			var err error
+			if existing, err = checkConflicts(ctx, tx, ids); err != nil {
+				return fmt.Errorf("checking conflicts: %w", err)
+			}
+		}
+
+		for _, event := range events {
+			if inv, err := h.upsertEvent(ctx, tx, event); err != nil {
+				if errors.Is(err, pgx.ErrTxClosed) {
+					return fmt.Errorf("insert aborted, tx cancelled: %w", err)
+				}
+
+				if core.IsRetryable(err) {
+					return err
+				}
+
+				observability.Metrics.TrackObjectError(entityEvent, "upsert")
+				mErr = multierr.Append(mErr, err)
+			} else {
+				changed = append(changed, event)
+				invitations = append(invitations, inv...)
+			}
+		}
+
+		// Earlier events may have been written, so the whole transaction is
+		// rolled back.
+		if mErr != nil {
+			return mErr
+		}
+
+		var err error
+		if conflicts, err = checkConflicts(ctx, tx, ids); err != nil {
+			return fmt.Errorf("checking conflicts: %w", err)
+		}
+
+		if added := introduced(existing, conflicts); len(added) > 0 && !allowConflicts {
+			observability.Metrics.TrackObjectError(entityEvent, "conflict")
+			return &ConflictError{Conflicts: added}
+		}
+
+		var changes []core.Change
+		for _, event := range changed {
+			changes = append(changes, core.Change{Entity: core.EntityEvent, Action: core.ActionUpsert, ID: event.ID})
 		}
+
+		return core.WriteOutbox(ctx, tx, append(changes, invitations...)...)
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, event := range changed {
+		h.logger.Info("tuple modified",
+			zap.String("action", "upsert"),
+			zap.String("entity", entityEvent),
+			zap.Object("event", event),
+		)
 	}
 
-	if err := tx.Commit(spanCtx); err != nil {
-		return fmt.Errorf("commiting tx failed: %w", err)
+	for _, c := range conflicts {
+		h.logger.Warn("schedule conflict", zap.Stringer("conflict", c))
 	}
 
-	observability.Metrics.TrackObjectsChanged(eventsChanged, entityEvent, "upsert")
+	observability.Metrics.TrackObjectsChanged(len(changed), entityEvent, "upsert")
 
-	return mErr
+	return conflicts, nil
 }
 
-func (h *Handler) upsertEvent(ctx context.Context, tx pgx.Tx, event *Event) error {
-	start := time.Now().UTC()
+// upsertEvent writes event and returns the changes of its invitations.
+func (h *Handler) upsertEvent(ctx context.Context, tx pgx.Tx, event *Event) ([]core.Change, error) {
+	// Postgres stores microseconds, truncate so UpdatedAt matches what's read
+	// back.
+	start := time.Now().UTC().Truncate(time.Microsecond)
 
 	stmt := fmt.Sprintf(`
 		INSERT INTO "%s"
@@ -93,16 +172,31 @@ func (h *Handler) upsertEvent(ctx context.Context, tx pgx.Tx, event *Event) erro
 				updated_at,
 				name,
 				start_time,
-				location_id
+				location_id,
+				end_time,
+				timezone,
+				recurrence,
+				recurrence_exceptions,
+				published,
+				publish_from,
+				publish_until
 			)
 		VALUES
-			($1, $2, $3, $4, $5, $6)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT
 			(id)
 		DO UPDATE SET
+			updated_at=$3,
 			name=$4,
 			start_time=$5,
 			location_id=$6,
+			end_time=$7,
+			timezone=$8,
+			recurrence=$9,
+			recurrence_exceptions=$10,
+			published=$11,
+			publish_from=$12,
+			publish_until=$13,
 			deleted_at=NULL`, core.TableEvents)
 
 	if event.StartTime != nil {
@@ -111,6 +205,22 @@ func (h *Handler) upsertEvent(ctx context.Context, tx pgx.Tx, event *Event) erro
 		event.StartTime = &t
 	}
 
+	if event.EndTime != nil {
+		t := event.EndTime.UTC()
+		event.EndTime = &t
+	}
+
+	var timezone *string
+	if event.Timezone != "" {
+		timezone = &event.Timezone
+	}
+
+	var rule *string
+	if event.Recurrence != nil {
+		r := event.Recurrence.String()
+		rule = &r
+	}
+
 	if _, err := tx.Exec(ctx, stmt,
 		event.ID,
 		start,
@@ -118,19 +228,233 @@ func (h *Handler) upsertEvent(ctx context.Context, tx pgx.Tx, event *Event) erro
 		event.Name,
 		event.StartTime,
 		event.LocationID,
+		event.EndTime,
+		timezone,
+		rule,
+		event.Exceptions,
+		event.Published,
+		event.PublishFrom,
+		event.PublishUntil,
 	); err != nil {
-		return fmt.Errorf("upserting event: %w", err)
+		return nil, fmt.Errorf("upserting event: %w", err)
+	}
+
+	event.UpdatedAt = start
+
+	invitations, err := h.inviteArtists(ctx, tx, event)
+	if err != nil {
+		return nil, fmt.Errorf("upsert invited artist: %w", err)
 	}
 
-	for _, invitedArtist := range event.InvitedArtists {
-		if err := h.inviteArtist(ctx, tx, event.ID, invitedArtist); err != nil {
-			return fmt.Errorf("upsert invited artist: %w", err)
+	for _, slot := range event.Slots {
+		if err := h.upsertSlot(ctx, tx, event.ID, slot); err != nil {
+			return nil, fmt.Errorf("upsert slot: %w", err)
 		}
 	}
 
+	if err := deleteRemovedSlots(ctx, tx, event); err != nil {
+		return nil, fmt.Errorf("delete slots: %w", err)
+	}
+
+	for _, override := range event.Overrides {
+		if !event.IsOccurrence(override.RecurrenceID.UTC()) {
+			return nil, fmt.Errorf("upsert override: occurrence %s: %w", override.RecurrenceID.UTC().Format(time.RFC3339), core.ErrNotFound)
+		}
+
+		if err := upsertOverride(ctx, tx, event.ID, override); err != nil {
+			return nil, fmt.Errorf("upsert override: %w", err)
+		}
+	}
+
+	if err := deleteStaleOverrides(ctx, tx, event); err != nil {
+		return nil, fmt.Errorf("delete overrides: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (h *Handler) upsertSlot(ctx context.Context, tx pgx.Tx, eventID string, slot Slot) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				id,
+				event_id,
+				artist_id,
+				start_time,
+				end_time,
+				stage
+			)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+		ON CONFLICT
+			(id)
+		DO UPDATE SET
+			artist_id=$3,
+			start_time=$4,
+			end_time=$5,
+			stage=$6
+		WHERE
+			%[1]q.event_id=$2`, core.TableEventSlots)
+
+	tag, err := tx.Exec(ctx, stmt,
+		slot.ID,
+		eventID,
+		slot.ArtistID,
+		slot.StartTime.UTC(),
+		slot.EndTime.UTC(),
+		slot.Stage,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The slot belongs to another event.
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("slot %q: %w", slot.ID, core.ErrNotFound)
+	}
+
 	return nil
 }
 
+// deleteRemovedSlots deletes the slots of event which are not in event.Slots,
+// unless it is nil.
+func deleteRemovedSlots(ctx context.Context, tx pgx.Tx, event *Event) error {
+	if event.Slots == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(event.Slots))
+	for _, s := range event.Slots {
+		ids = append(ids, s.ID)
+	}
+
+	stmt := fmt.Sprintf(`
+		DELETE FROM
+			%q
+		WHERE
+			event_id=$1 AND NOT (id = ANY($2::uuid[]))`, core.TableEventSlots)
+
+	_, err := tx.Exec(ctx, stmt, event.ID, ids)
+
+	return err
+}
+
+// inviteArtists writes the invitations of event and deletes those which are
+// not in event.InvitedArtists, unless it is nil. Changes are returned for new
+// and removed invitations, and those whose confirmation changed.
+func (h *Handler) inviteArtists(ctx context.Context, tx pgx.Tx, event *Event) ([]core.Change, error) {
+	if event.InvitedArtists == nil {
+		return nil, nil
+	}
+
+	stored, err := confirmations(ctx, tx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		changes []core.Change
+		ids     = make([]string, 0, len(event.InvitedArtists))
+	)
+
+	for _, invited := range event.InvitedArtists {
+		if err := h.inviteArtist(ctx, tx, event.ID, invited); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, invited.ID)
+
+		if confirmed, ok := stored[invited.ID]; ok && confirmed == invited.Confirmed {
+			continue
+		}
+
+		changes = append(changes, core.Change{
+			Entity:    core.EntityInvitation,
+			Action:    core.ActionUpsert,
+			ID:        invited.ID,
+			EventID:   event.ID,
+			Confirmed: invited.Confirmed,
+		})
+	}
+
+	// Slots of removed artists are deleted along with their invitation.
+	stmt := fmt.Sprintf(`
+		DELETE FROM
+			%q
+		WHERE
+			event_id=$1 AND NOT (artist_id = ANY($2::uuid[]))
+		RETURNING
+			artist_id`, core.TableInvitedArtists)
+
+	rows, err := tx.Query(ctx, stmt, event.ID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("delete: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		changes = append(changes, core.Change{
+			Entity:  core.EntityInvitation,
+			Action:  core.ActionDelete,
+			ID:      id,
+			EventID: event.ID,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return changes, nil
+}
+
+// confirmations returns whether the artists invited to an event confirmed,
+// by artist ID. The invitations are locked until tx ends.
+func confirmations(ctx context.Context, tx pgx.Tx, eventID string) (map[string]bool, error) {
+	stmt := fmt.Sprintf(`
+		SELECT
+			artist_id,
+			confirmed
+		FROM
+			%q
+		WHERE
+			event_id=$1
+		FOR UPDATE`, core.TableInvitedArtists)
+
+	rows, err := tx.Query(ctx, stmt, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	confirmed := make(map[string]bool)
+	for rows.Next() {
+		var (
+			id string
+			c  bool
+		)
+
+		if err := rows.Scan(&id, &c); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		confirmed[id] = c
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return confirmed, nil
+}
+
 func (h *Handler) inviteArtist(ctx context.Context, tx pgx.Tx, eventID string, invitedArtist InvitedArtist) error {
 	stmt := fmt.Sprintf(`
 		INSERT INTO %q
@@ -154,7 +478,13 @@ func (h *Handler) inviteArtist(ctx context.Context, tx pgx.Tx, eventID string, i
 		return err
 	}
 
-	return nil
+	if invitedArtist.Fee == nil {
+		return nil
+	}
+
+	_, err := setFee(ctx, tx, eventID, invitedArtist.ID, invitedArtist.Fee)
+
+	return err
 }
 
 // GetRequest specifies the input for an Event query against the database.
@@ -174,6 +504,50 @@ func ByName(name string) GetRequest {
 	}
 }
 
+// ByLocationID requests all Events at a Location.
+func ByLocationID(id string) GetRequest {
+	return func() (string, string, string) {
+		return id, fmt.Sprintf("%s.location_id=$1", core.TableEvents), "location_id"
+	}
+}
+
+// ByArtistID requests all Events an Artist is invited to.
+func ByArtistID(id string) GetRequest {
+	return func() (string, string, string) {
+		return id, fmt.Sprintf("%s.id IN (SELECT event_id FROM %q WHERE artist_id=$1)", core.TableEvents, core.TableInvitedArtists), "artist_id"
+	}
+}
+
+// PublishedAt requests all Events published at t.
+func PublishedAt(t time.Time) GetRequest {
+	return func() (string, string, string) {
+		return t.UTC().Format(time.RFC3339Nano), fmt.Sprintf(
+			"%[1]s.published AND (%[1]s.publish_from IS NULL OR %[1]s.publish_from <= $1::timestamptz) AND (%[1]s.publish_until IS NULL OR %[1]s.publish_until > $1::timestamptz)",
+			core.TableEvents,
+		), "published"
+	}
+}
+
+// After requests up to limit Events ordered by ID, starting after the Event
+// with the given ID. An empty ID starts with the first Event.
+func After(id string, limit int) GetRequest {
+	return func() (string, string, string) {
+		if id == "" {
+			id = uuid.Nil.String()
+		}
+
+		return id, fmt.Sprintf("%[1]s.id > $1::uuid ORDER BY %[1]s.id LIMIT %[2]d", core.TableEvents, limit), "after"
+	}
+}
+
+// All requests every Event.
+func All() GetRequest {
+	return func() (string, string, string) {
+		// The input is always passed, so it has to be referenced.
+		return "", "$1::text = ''", "all"
+	}
+}
+
 func (h *Handler) Get(ctx context.Context, req GetRequest) ([]*Event, error) {
 	input, whereClause, reqType := req()
 
@@ -185,7 +559,15 @@ func (h *Handler) Get(ctx context.Context, req GetRequest) ([]*Event, error) {
 			id,
 			name,
 			start_time,
-			location_id
+			location_id,
+			updated_at,
+			end_time,
+			timezone,
+			recurrence,
+			recurrence_exceptions,
+			published,
+			publish_from,
+			publish_until
 		FROM "%s"
 		WHERE 
 			deleted_at IS NULL AND `, core.TableEvents) + whereClause
@@ -210,9 +592,17 @@ func (h *Handler) Get(ctx context.Context, req GetRequest) ([]*Event, error) {
 			name       string
 			startTime  *time.Time
 			locationID *string
+			updatedAt  time.Time
+			endTime    *time.Time
+			timezone   *string
+			rule       *string
+			exceptions []time.Time
+			published  bool
+			from       *time.Time
+			until      *time.Time
 		)
 
-		if err := rows.Scan(&id, &name, &startTime, &locationID); err != nil {
+		if err := rows.Scan(&id, &name, &startTime, &locationID, &updatedAt, &endTime, &timezone, &rule, &exceptions, &published, &from, &until); err != nil {
 			span.RecordError(err)
 			observability.Metrics.TrackObjectError(entityEvent, "get")
 			return nil, fmt.Errorf("scan failed: %w", err)
@@ -225,24 +615,83 @@ func (h *Handler) Get(ctx context.Context, req GetRequest) ([]*Event, error) {
 			startTime = &t
 		}
 
-		invited, err := h.invitedArtists(ctx, id)
-		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("retrieving invtied artists: %w", err)
+		if endTime != nil {
+			t := endTime.UTC()
+			endTime = &t
+		}
+
+		var recurrenceRule *recurrence.Rule
+		if rule != nil {
+			if recurrenceRule, err = recurrence.Parse(*rule); err != nil {
+				return nil, fmt.Errorf("parsing recurrence of %q: %w", id, err)
+			}
+		}
+
+		for i := range exceptions {
+			exceptions[i] = exceptions[i].UTC()
+		}
+
+		for _, t := range []**time.Time{&from, &until} {
+			if *t != nil {
+				u := (*t).UTC()
+				*t = &u
+			}
 		}
 
 		events = append(events, &Event{
-			ID:             id,
-			Name:           name,
-			StartTime:      startTime,
-			LocationID:     locationID,
-			InvitedArtists: invited,
+			ID:           id,
+			Name:         name,
+			StartTime:    startTime,
+			LocationID:   locationID,
+			EndTime:      endTime,
+			Timezone:     conversion.String(timezone),
+			Recurrence:   recurrenceRule,
+			Exceptions:   exceptions,
+			Published:    published,
+			PublishFrom:  from,
+			PublishUntil: until,
+			UpdatedAt:    updatedAt.UTC(),
 		})
 	}
 
+	if err := rows.Err(); err != nil {
+		observability.Metrics.TrackObjectError(entityEvent, "get")
+		return nil, fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	// The connection is released before the dependent rows are read.
+	rows.Close()
+
 	if len(events) == 0 {
 		return nil, core.ErrNotFound
 	}
 
+	ids := make([]string, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+
+	invited, err := h.invitedArtists(spanCtx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving invited artists: %w", err)
+	}
+
+	slots, err := h.slots(spanCtx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving slots: %w", err)
+	}
+
+	overrides, err := h.overrides(spanCtx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving overrides: %w", err)
+	}
+
+	for _, ev := range events {
+		ev.InvitedArtists = invited[ev.ID]
+		ev.Slots = slots[ev.ID]
+		ev.Overrides = overrides[ev.ID]
+	}
+
 	observability.Metrics.TrackObjectsRetrieved(len(events), entityEvent)
 
 	return events, nil
@@ -268,7 +717,13 @@ func (h *Handler) DeleteByID(ctx context.Context, id string) error {
 			id`, core.TableEvents)
 
 	var deletedID string
-	if err := h.conn.QueryRow(spanCtx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+			return err
+		}
+
+		return core.WriteOutbox(ctx, tx, core.Change{Entity: core.EntityEvent, Action: core.ActionDelete, ID: id})
+	}); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return core.ErrNotFound
 		}
@@ -292,38 +747,133 @@ func (h *Handler) DeleteByID(ctx context.Context, id string) error {
 	return nil
 }
 
-func (h *Handler) invitedArtists(ctx context.Context, eventID string) ([]InvitedArtist, error) {
+// invitedArtists returns the invitations of the events with the given IDs, by
+// event ID.
+func (h *Handler) invitedArtists(ctx context.Context, eventIDs []string) (map[string]InvitedArtists, error) {
 	stmt := fmt.Sprintf(`
 		SELECT
-			artist_id, confirmed
+			event_id,
+			artist_id,
+			confirmed,
+			fee_amount,
+			fee_currency,
+			fee_type,
+			fee_days,
+			travel_allowance,
+			accommodation_allowance,
+			payment_status,
+			due_date,
+			paid_at,
+			contract_refs
 		FROM
 			%q
 		WHERE
-			event_id=$1`, core.TableInvitedArtists)
+			event_id = ANY($1::uuid[])`, core.TableInvitedArtists)
 
-	rows, err := h.conn.Query(ctx, stmt, eventID)
+	rows, err := h.conn.Query(ctx, stmt, eventIDs)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 
 	defer rows.Close()
 
-	var invited []InvitedArtist
+	invited := make(map[string]InvitedArtists)
 	for rows.Next() {
 		var (
-			id        string
-			confirmed bool
+			eventID, id               string
+			confirmed                 bool
+			amount                    *int64
+			currency, feeType, status *string
+			days                      *int
+			travel, accommodation     *int64
+			dueDate, paidAt           *time.Time
+			contractRefs              []string
 		)
 
-		if err := rows.Scan(&id, &confirmed); err != nil {
+		if err := rows.Scan(&eventID, &id, &confirmed, &amount, &currency, &feeType, &days, &travel, &accommodation, &status, &dueDate, &paidAt, &contractRefs); err != nil {
 			return nil, fmt.Errorf("scan: %w", err)
 		}
 
-		invited = append(invited, InvitedArtist{
+		ia := InvitedArtist{
 			ID:        id,
 			Confirmed: confirmed,
-		})
+		}
+
+		if amount != nil {
+			ia.Fee = &Fee{
+				Amount:                 *amount,
+				Currency:               conversion.String(currency),
+				Type:                   FeeType(conversion.String(feeType)),
+				Days:                   conversion.Int(days),
+				TravelAllowance:        conversion.Int64(travel),
+				AccommodationAllowance: conversion.Int64(accommodation),
+				Status:                 PaymentStatus(conversion.String(status)),
+				DueDate:                utc(dueDate),
+				PaidAt:                 utc(paidAt),
+				ContractRefs:           contractRefs,
+			}
+		}
+
+		invited[eventID] = append(invited[eventID], ia)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
 	}
 
 	return invited, nil
 }
+
+// slots returns the slots of the events with the given IDs, by event ID.
+func (h *Handler) slots(ctx context.Context, eventIDs []string) (map[string][]Slot, error) {
+	stmt := fmt.Sprintf(`
+		SELECT
+			event_id, id, artist_id, start_time, end_time, stage
+		FROM
+			%q
+		WHERE
+			event_id = ANY($1::uuid[])
+		ORDER BY
+			event_id, start_time, stage`, core.TableEventSlots)
+
+	rows, err := h.conn.Query(ctx, stmt, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	slots := make(map[string][]Slot)
+	for rows.Next() {
+		var (
+			eventID string
+			s       Slot
+			stage   *string
+		)
+
+		if err := rows.Scan(&eventID, &s.ID, &s.ArtistID, &s.StartTime, &s.EndTime, &stage); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		s.StartTime = s.StartTime.UTC()
+		s.EndTime = s.EndTime.UTC()
+		s.Stage = conversion.String(stage)
+
+		slots[eventID] = append(slots[eventID], s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return slots, nil
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,525 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	// MaxOccurrenceRange is the longest range occurrences are expanded in.
+	MaxOccurrenceRange = 2 * 366 * 24 * time.Hour
+	// maxOccurrences caps the occurrences of a single Event within a range.
+	maxOccurrences = 1000
+)
+
+// Override replaces a single occurrence of a recurring Event.
+type Override struct {
+	// RecurrenceID is the start of the replaced occurrence according to the
+	// recurrence rule.
+	RecurrenceID time.Time
+	// Name replaces the name of the Event if not empty.
+	Name      string
+	StartTime time.Time
+	EndTime   *time.Time
+}
+
+// Occurrence is a single instance of an Event.
+type Occurrence struct {
+	EventID string
+	// RecurrenceID identifies the occurrence within a recurring Event. It
+	// equals StartTime unless the occurrence has been overridden.
+	RecurrenceID time.Time
+	Name         string
+	StartTime    time.Time
+	EndTime      *time.Time
+	Overridden   bool
+}
+
+// Occurrences expands e into its occurrences overlapping [from, to), ordered
+// by start. Cancelled occurrences are skipped, overridden ones replaced. At
+// most the first maxOccurrences are returned.
+func (e *Event) Occurrences(from, to time.Time) []Occurrence {
+	if e.StartTime == nil {
+		return nil
+	}
+
+	var (
+		out       []Occurrence
+		duration  = e.duration()
+		overrides = make(map[int64]Override, len(e.Overrides))
+	)
+
+	for _, o := range e.Overrides {
+		overrides[o.RecurrenceID.UnixNano()] = o
+	}
+
+	for _, start := range e.starts(from.Add(-duration), to) {
+		if duration > 0 && !start.Add(duration).After(from) {
+			continue
+		}
+
+		if _, ok := overrides[start.UnixNano()]; ok || e.isException(start) {
+			continue
+		}
+
+		occ := Occurrence{
+			EventID:      e.ID,
+			RecurrenceID: start,
+			Name:         e.Name,
+			StartTime:    start,
+		}
+
+		if e.EndTime != nil {
+			end := start.Add(duration)
+			occ.EndTime = &end
+		}
+
+		out = append(out, occ)
+	}
+
+	for _, o := range e.Overrides {
+		if e.isException(o.RecurrenceID) || !overlaps(o.StartTime, o.EndTime, from, to) {
+			continue
+		}
+
+		name := o.Name
+		if name == "" {
+			name = e.Name
+		}
+
+		out = append(out, Occurrence{
+			EventID:      e.ID,
+			RecurrenceID: o.RecurrenceID,
+			Name:         name,
+			StartTime:    o.StartTime,
+			EndTime:      o.EndTime,
+			Overridden:   true,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartTime.Before(out[j].StartTime) })
+
+	if len(out) > maxOccurrences {
+		out = out[:maxOccurrences]
+	}
+
+	return out
+}
+
+// IsOccurrence returns true if an occurrence of e starts at t according to
+// its recurrence rule, regardless of exceptions and overrides.
+func (e *Event) IsOccurrence(t time.Time) bool {
+	starts := e.starts(t, t.Add(time.Nanosecond))
+
+	return len(starts) == 1 && starts[0].Equal(t)
+}
+
+// starts returns the start times of all occurrences within [from, to) in UTC.
+func (e *Event) starts(from, to time.Time) []time.Time {
+	if e.StartTime == nil {
+		return nil
+	}
+
+	if e.Recurrence == nil {
+		if e.StartTime.Before(from) || !e.StartTime.Before(to) {
+			return nil
+		}
+
+		return []time.Time{*e.StartTime}
+	}
+
+	// Expand in the time zone of the event to keep its wall clock time.
+	starts := e.Recurrence.Between(e.StartTime.In(e.Location()), from, to)
+	for i := range starts {
+		starts[i] = starts[i].UTC()
+	}
+
+	return starts
+}
+
+func (e *Event) duration() time.Duration {
+	if e.StartTime == nil || e.EndTime == nil {
+		return 0
+	}
+
+	return e.EndTime.Sub(*e.StartTime)
+}
+
+func (e *Event) isException(t time.Time) bool {
+	for _, ex := range e.Exceptions {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overlaps returns true if [start, end) overlaps [from, to). Without an end,
+// start has to be within the range.
+func overlaps(start time.Time, end *time.Time, from, to time.Time) bool {
+	if !start.Before(to) {
+		return false
+	}
+
+	if end == nil {
+		return !start.Before(from)
+	}
+
+	return end.After(from)
+}
+
+// Overlapping requests all Events which may have occurrences overlapping the
+// range [from, to).
+func Overlapping(from, to time.Time) GetRequest {
+	return func() (string, string, string) {
+		// The end of the range is part of the statement, since only a single
+		// input can be passed. Formatted times are safe to embed.
+		return from.UTC().Format(time.RFC3339Nano), fmt.Sprintf(
+			`((%[1]s.start_time < '%[2]s'::timestamptz AND (%[1]s.recurrence IS NOT NULL OR COALESCE(%[1]s.end_time, %[1]s.start_time) >= $1::timestamptz))
+				OR %[1]s.id IN (SELECT event_id FROM %[3]q WHERE start_time < '%[2]s'::timestamptz AND COALESCE(end_time, start_time) >= $1::timestamptz))`,
+			core.TableEvents, to.UTC().Format(time.RFC3339Nano), core.TableEventOverrides,
+		), "overlapping"
+	}
+}
+
+// Occurrences returns the occurrences of all Events overlapping the range
+// [from, to), ordered by start. The range may span at most
+// MaxOccurrenceRange.
+func (h *Handler) Occurrences(ctx context.Context, from, to time.Time) ([]Occurrence, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: range ends before it starts", core.ErrInvalidInput)
+	}
+
+	if to.Sub(from) > MaxOccurrenceRange {
+		return nil, fmt.Errorf("%w: range exceeds %s", core.ErrInvalidInput, MaxOccurrenceRange)
+	}
+
+	events, err := h.Get(ctx, Overlapping(from, to))
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var out []Occurrence
+	for _, ev := range events {
+		out = append(out, ev.Occurrences(from, to)...)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartTime.Before(out[j].StartTime) })
+
+	return out, nil
+}
+
+// OverrideOccurrence replaces a single occurrence of a recurring Event. If
+// the occurrence would double-book an artist, location or stage which wasn't
+// double-booked before, nothing is written and a *ConflictError is returned.
+func (h *Handler) OverrideOccurrence(ctx context.Context, eventID string, o Override) error {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.override")
+	defer span.End()
+
+	ev, err := h.occurrenceEvent(spanCtx, eventID, o.RecurrenceID)
+	if err != nil {
+		return err
+	}
+
+	if ev.isException(o.RecurrenceID) {
+		return fmt.Errorf("%w: occurrence %s is cancelled", core.ErrInvalidInput, o.RecurrenceID.UTC().Format(time.RFC3339))
+	}
+
+	if o.EndTime != nil && o.EndTime.Before(o.StartTime) {
+		return fmt.Errorf("%w: occurrence ends before it starts", core.ErrInvalidInput)
+	}
+
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		if err := lockSchedule(ctx, tx, []string{ev.ID}); err != nil {
+			return fmt.Errorf("locking schedule: %w", err)
+		}
+
+		existing, err := checkConflicts(ctx, tx, []string{ev.ID})
+		if err != nil {
+			return fmt.Errorf("checking conflicts: %w", err)
+		}
+
+		if err := upsertOverride(ctx, tx, eventID, o); err != nil {
+			return err
+		}
+
+		conflicts, err := checkConflicts(ctx, tx, []string{ev.ID})
+		if err != nil {
+			return fmt.Errorf("checking conflicts: %w", err)
+		}
+
+		if added := introduced(existing, conflicts); len(added) > 0 {
+			observability.Metrics.TrackObjectError(entityEvent, "conflict")
+			return &ConflictError{Conflicts: added}
+		}
+
+		return touchEvent(ctx, tx, eventID)
+	}); err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "override")
+		return fmt.Errorf("overriding occurrence: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityEvent, "override")
+	h.logger.Info("tuple modified",
+		zap.String("action", "override"),
+		zap.String("entity", entityEvent),
+		zap.String("id", eventID),
+		zap.Time("recurrenceID", o.RecurrenceID),
+	)
+
+	return nil
+}
+
+// CancelOccurrence cancels a single occurrence of an Event by adding it to
+// its exceptions. An override of the occurrence is removed.
+func (h *Handler) CancelOccurrence(ctx context.Context, eventID string, recurrenceID time.Time) error {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "event.cancel")
+	defer span.End()
+
+	if _, err := h.occurrenceEvent(spanCtx, eventID, recurrenceID); err != nil {
+		return err
+	}
+
+	recurrenceID = recurrenceID.UTC()
+
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		stmt := fmt.Sprintf(`
+			UPDATE
+				%q
+			SET
+				recurrence_exceptions=array_append(recurrence_exceptions, $2)
+			WHERE
+				id=$1 AND NOT ($2 = ANY(COALESCE(recurrence_exceptions, '{}')))`, core.TableEvents)
+
+		if _, err := tx.Exec(ctx, stmt, eventID, recurrenceID); err != nil {
+			return err
+		}
+
+		stmt = fmt.Sprintf(`
+			DELETE FROM
+				%q
+			WHERE
+				event_id=$1 AND recurrence_id=$2`, core.TableEventOverrides)
+
+		if _, err := tx.Exec(ctx, stmt, eventID, recurrenceID); err != nil {
+			return err
+		}
+
+		return touchEvent(ctx, tx, eventID)
+	}); err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityEvent, "cancel")
+		return fmt.Errorf("cancelling occurrence: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityEvent, "cancel")
+	h.logger.Info("tuple modified",
+		zap.String("action", "cancel"),
+		zap.String("entity", entityEvent),
+		zap.String("id", eventID),
+		zap.Time("recurrenceID", recurrenceID),
+	)
+
+	return nil
+}
+
+// occurrenceEvent returns the Event with an occurrence at recurrenceID.
+func (h *Handler) occurrenceEvent(ctx context.Context, eventID string, recurrenceID time.Time) (*Event, error) {
+	if _, err := uuid.Parse(eventID); err != nil {
+		return nil, core.ErrInvalidUUID
+	}
+
+	// The Event is read to validate a write, so it must be current.
+	events, err := h.Get(core.WithPrimary(ctx), ByID(eventID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !events[0].IsOccurrence(recurrenceID.UTC()) {
+		return nil, fmt.Errorf("occurrence %s: %w", recurrenceID.UTC().Format(time.RFC3339), core.ErrNotFound)
+	}
+
+	return events[0], nil
+}
+
+// touchEvent marks an Event as updated and records the change.
+func touchEvent(ctx context.Context, tx pgx.Tx, eventID string) error {
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%q
+		SET
+			updated_at=$2
+		WHERE
+			id=$1`, core.TableEvents)
+
+	if _, err := tx.Exec(ctx, stmt, eventID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return core.WriteOutbox(ctx, tx, core.Change{Entity: core.EntityEvent, Action: core.ActionUpsert, ID: eventID})
+}
+
+func upsertOverride(ctx context.Context, tx pgx.Tx, eventID string, o Override) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				event_id,
+				recurrence_id,
+				name,
+				start_time,
+				end_time
+			)
+		VALUES
+			($1, $2, $3, $4, $5)
+		ON CONFLICT
+			(event_id, recurrence_id)
+		DO UPDATE SET
+			name=$3,
+			start_time=$4,
+			end_time=$5`, core.TableEventOverrides)
+
+	var name *string
+	if o.Name != "" {
+		name = &o.Name
+	}
+
+	var end *time.Time
+	if o.EndTime != nil {
+		t := o.EndTime.UTC()
+		end = &t
+	}
+
+	_, err := tx.Exec(ctx, stmt, eventID, o.RecurrenceID.UTC(), name, o.StartTime.UTC(), end)
+
+	return err
+}
+
+// overrides returns the overrides of the events with the given IDs, by event
+// ID.
+func (h *Handler) overrides(ctx context.Context, eventIDs []string) (map[string][]Override, error) {
+	stmt := fmt.Sprintf(`
+		SELECT
+			event_id, recurrence_id, name, start_time, end_time
+		FROM
+			%q
+		WHERE
+			event_id = ANY($1::uuid[])
+		ORDER BY
+			event_id, recurrence_id`, core.TableEventOverrides)
+
+	rows, err := h.conn.Query(ctx, stmt, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	overrides := make(map[string][]Override)
+	for rows.Next() {
+		var (
+			eventID string
+			o       Override
+			name    *string
+		)
+
+		if err := rows.Scan(&eventID, &o.RecurrenceID, &name, &o.StartTime, &o.EndTime); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		o.RecurrenceID = o.RecurrenceID.UTC()
+		o.StartTime = o.StartTime.UTC()
+		o.Name = conversion.String(name)
+
+		if o.EndTime != nil {
+			t := o.EndTime.UTC()
+			o.EndTime = &t
+		}
+
+		overrides[eventID] = append(overrides[eventID], o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// deleteStaleOverrides deletes the overrides of event which no longer replace
+// one of its occurrences, e.g. after its recurrence rule changed. If
+// event.Overrides is not nil, overrides missing from it are deleted as well.
+func deleteStaleOverrides(ctx context.Context, tx pgx.Tx, event *Event) error {
+	stmt := fmt.Sprintf(`
+		SELECT
+			recurrence_id
+		FROM
+			%q
+		WHERE
+			event_id=$1
+		FOR UPDATE`, core.TableEventOverrides)
+
+	rows, err := tx.Query(ctx, stmt, event.ID)
+	if err != nil {
+		return err
+	}
+
+	var stored []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			rows.Close()
+			return err
+		}
+
+		stored = append(stored, t.UTC())
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := make(map[int64]bool, len(event.Overrides))
+	for _, o := range event.Overrides {
+		kept[o.RecurrenceID.UnixNano()] = true
+	}
+
+	var stale []time.Time
+	for _, t := range stored {
+		if !event.IsOccurrence(t) || event.isException(t) || (event.Overrides != nil && !kept[t.UnixNano()]) {
+			stale = append(stale, t)
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	stmt = fmt.Sprintf(`
+		DELETE FROM
+			%q
+		WHERE
+			event_id=$1 AND recurrence_id = ANY($2)`, core.TableEventOverrides)
+
+	_, err = tx.Exec(ctx, stmt, event.ID, stale)
+
+	return err
+}
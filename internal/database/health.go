@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/johejo/golang-migrate-extra/source/iofs"
+)
+
+const tableSchemaMigrations = "schema_migrations"
+
+// MigrationStatus describes the schema version of the database compared to
+// the migrations embedded in the binary.
+type MigrationStatus struct {
+	// Current is the version currently applied to the database.
+	Current uint
+	// Expected is the latest version embedded in the binary.
+	Expected uint
+	// Dirty is true if the last migration failed halfway through.
+	Dirty bool
+}
+
+// UpToDate returns true if the database runs the expected schema version.
+func (s MigrationStatus) UpToDate() bool {
+	return !s.Dirty && s.Current == s.Expected
+}
+
+// MigrationStatus returns the applied and expected migration version.
+func (db *Database) MigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	expected, err := latestMigration()
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded migrations failed: %w", err)
+	}
+
+	status := &MigrationStatus{Expected: expected}
+
+	stmt := fmt.Sprintf(`SELECT version, dirty FROM %q LIMIT 1`, tableSchemaMigrations)
+
+	var version int64
+	if err := db.conn.QueryRow(ctx, stmt).Scan(&version, &status.Dirty); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status, nil
+		}
+
+		return nil, fmt.Errorf("querying migration version failed: %w", err)
+	}
+
+	status.Current = uint(version)
+
+	return status, nil
+}
+
+// PoolStat returns a snapshot of the connection pool statistics.
+func (db *Database) PoolStat() *pgxpool.Stat {
+	return db.conn.Stat()
+}
+
+// latestMigration returns the highest migration version embedded in the binary.
+func latestMigration() (uint, error) {
+	d, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return 0, err
+	}
+
+	defer d.Close()
+
+	version, err := d.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := d.Next(version)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return version, nil
+			}
+
+			return 0, err
+		}
+
+		version = next
+	}
+}
@@ -37,7 +37,13 @@ func (h *Handler) DeleteByID(ctx context.Context, id string) error {
 			id`, core.TableLocations)
 
 	var deletedID string
-	if err := h.conn.QueryRow(ctx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+	if err := core.RunInTx(ctx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+			return err
+		}
+
+		return core.WriteOutbox(ctx, tx, core.Change{Entity: core.EntityLocation, Action: core.ActionDelete, ID: id})
+	}); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return core.ErrNotFound
 		}
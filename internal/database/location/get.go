@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 
+	"github.com/obitech/artist-db/internal/conversion"
 	"github.com/obitech/artist-db/internal/database/core"
 	"github.com/obitech/artist-db/internal/observability"
 )
@@ -28,13 +30,29 @@ func ByName(name string) GetRequest {
 	}
 }
 
+// After requests up to limit Locations ordered by ID, starting after the
+// Location with the given ID. An empty ID starts with the first Location.
+func After(id string, limit int) GetRequest {
+	return func() (string, string) {
+		if id == "" {
+			id = uuid.Nil.String()
+		}
+
+		return id, fmt.Sprintf("id > $1::uuid ORDER BY id LIMIT %d", limit)
+	}
+}
+
 func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Location, error) {
 	input, whereClause := request()
 
 	stmt := fmt.Sprintf(`
 		SELECT
 			id,
-			name
+			name,
+			street,
+			zip,
+			city,
+			country
 		FROM 
 			"%s"
 		WHERE deleted_at IS NULL AND `, core.TableLocations,
@@ -58,13 +76,21 @@ func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Location, err
 
 	for rows.Next() {
 		var (
-			id   string
-			name string
+			id      string
+			name    string
+			street  *string
+			zip     *string
+			city    *string
+			country *string
 		)
 
 		if err := rows.Scan(
 			&id,
 			&name,
+			&street,
+			&zip,
+			&city,
+			&country,
 		); err != nil {
 			observability.Metrics.TrackObjectError(entityLocation, "get")
 			return nil, fmt.Errorf("scanning rows failed: %w", err)
@@ -73,6 +99,12 @@ func (h *Handler) Get(ctx context.Context, request GetRequest) ([]*Location, err
 		locations = append(locations, &Location{
 			ID:   id,
 			Name: name,
+			Address: Address{
+				Street:  conversion.String(street),
+				Zip:     conversion.String(zip),
+				City:    conversion.String(city),
+				Country: conversion.String(country),
+			},
 		})
 	}
 
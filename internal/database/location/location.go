@@ -1,13 +1,43 @@
 package location
 
 import (
+	"strings"
+
 	"github.com/google/uuid"
 	"go.uber.org/zap/zapcore"
 )
 
 type Location struct {
-	ID   string
-	Name string
+	ID      string
+	Name    string
+	Address Address
+}
+
+// Address is the postal address of a Location.
+type Address struct {
+	Street  string
+	Zip     string
+	City    string
+	Country string
+}
+
+// String returns the address on a single line, omitting empty parts.
+func (a Address) String() string {
+	var parts []string
+
+	if a.Street != "" {
+		parts = append(parts, a.Street)
+	}
+
+	if city := strings.TrimSpace(a.Zip + " " + a.City); city != "" {
+		parts = append(parts, city)
+	}
+
+	if a.Country != "" {
+		parts = append(parts, a.Country)
+	}
+
+	return strings.Join(parts, ", ")
 }
 
 func (l Location) MarshalLogObject(enc zapcore.ObjectEncoder) error {
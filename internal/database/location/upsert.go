@@ -16,28 +16,47 @@ import (
 
 // Upsert inserts or updates Locations.
 func (h *Handler) Upsert(ctx context.Context, locations ...*Location) error {
-	tx, err := h.conn.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("creating tx failed: %w", err)
-	}
+	var changed []*Location
+
+	if err := core.RunInTx(ctx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		var mErr error
+		changed = nil
+
+		for _, location := range locations {
+			if err := h.upsert(ctx, tx, location); err != nil {
+				if errors.Is(err, pgx.ErrTxClosed) {
+					return fmt.Errorf("insert aborted, tx cancelled: %w", err)
+				}
+
+				if core.IsRetryable(err) {
+					return err
+				}
 
-	defer core.RollbackAndLogError(ctx, tx, h.logger)
+				observability.Metrics.TrackObjectError(entityLocation, "upsert")
 
-	var (
-		mErr    error
-		changed int
-	)
-	for _, location := range locations {
-		if err := h.upsert(ctx, tx, location); err != nil {
-			if errors.Is(err, pgx.ErrTxClosed) {
-				return fmt.Errorf("insert aborted, tx cancelled: %w", err)
+				mErr = multierr.Append(mErr, err)
+			} else {
+				changed = append(changed, location)
 			}
+		}
 
-			observability.Metrics.TrackObjectError(entityLocation, "upsert")
+		// Earlier locations may have been written, so the whole transaction
+		// is rolled back.
+		if mErr != nil {
+			return mErr
+		}
 
-			mErr = multierr.Append(mErr, err)
+		changes := make([]core.Change, 0, len(changed))
+		for _, location := range changed {
+			changes = append(changes, core.Change{Entity: core.EntityLocation, Action: core.ActionUpsert, ID: location.ID})
 		}
-		changed++
+
+		return core.WriteOutbox(ctx, tx, changes...)
+	}); err != nil {
+		return err
+	}
+
+	for _, location := range changed {
 		h.logger.Info("tuple modified",
 			zap.String("action", "upsert"),
 			zap.String("entity", entityLocation),
@@ -45,13 +64,9 @@ func (h *Handler) Upsert(ctx context.Context, locations ...*Location) error {
 		)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commiting tx failed: %w", err)
-	}
-
-	observability.Metrics.TrackObjectsChanged(changed, entityLocation, "upsert")
+	observability.Metrics.TrackObjectsChanged(len(changed), entityLocation, "upsert")
 
-	return mErr
+	return nil
 }
 
 func (h *Handler) upsert(ctx context.Context, tx pgx.Tx, location *Location) error {
@@ -63,22 +78,34 @@ func (h *Handler) upsert(ctx context.Context, tx pgx.Tx, location *Location) err
 				id,
 				created_at,
 				updated_at,
-				name
+				name,
+				street,
+				zip,
+				city,
+				country
 			)
 		VALUES 
-			($1, $2, $3, $4)
+			($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT 
 			(id)
 		DO UPDATE SET
 			updated_at=$3,
 			name=$4,
+			street=$5,
+			zip=$6,
+			city=$7,
+			country=$8,
 			deleted_at=NULL`, core.TableLocations)
 
 	if _, err := tx.Exec(ctx, stmt,
-		location.ID,   // $1
-		start,         // $2
-		start,         // $3
-		location.Name, // $4
+		location.ID,              // $1
+		start,                    // $2
+		start,                    // $3
+		location.Name,            // $4
+		location.Address.Street,  // $5
+		location.Address.Zip,     // $6
+		location.Address.City,    // $7
+		location.Address.Country, // $8
 	); err != nil {
 		return err
 	}
@@ -58,8 +58,10 @@ CREATE TABLE IF NOT EXISTS artist_event (
 --                                        synopsis_ger        TEXT,
 --                                        pictures            TEXT[],
 --                                        material_demands    TEXT,
---                                        insurance_amount    MONEY,
---                                        sales_val           MONEY,
+--                                        insurance_amount    BIGINT,     -- minor units of insurance_currency, MONEY depends on the locale
+--                                        insurance_currency  CHAR(3),
+--                                        sales_val           BIGINT,
+--                                        sales_currency      CHAR(3),
 --                                        height              FLOAT,
 --                                        length              FLOAT,
 --                                        width               FLOAT,
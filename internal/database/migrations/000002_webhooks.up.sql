@@ -0,0 +1,30 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+                                       id              UUID PRIMARY KEY,
+                                       created_at      TIMESTAMPTZ NOT NULL,
+                                       updated_at      TIMESTAMPTZ NOT NULL,
+                                       deleted_at      TIMESTAMPTZ,
+                                       url             TEXT NOT NULL,
+                                       secret          TEXT NOT NULL,
+                                       entities        TEXT[] NOT NULL DEFAULT '{}',         -- empty matches every entity
+                                       actions         TEXT[] NOT NULL DEFAULT '{}'          -- empty matches every action
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                 id              UUID PRIMARY KEY,
+                                                 delivery_id     UUID NOT NULL,                -- shared by all attempts of a delivery
+                                                 webhook_id      UUID REFERENCES webhooks ON DELETE CASCADE,
+                                                 entity          TEXT NOT NULL,
+                                                 action          TEXT NOT NULL,
+                                                 entity_id       TEXT NOT NULL,
+                                                 attempt         INTEGER NOT NULL,
+                                                 status_code     INTEGER,
+                                                 error           TEXT,
+                                                 succeeded       BOOL NOT NULL,
+                                                 created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
+
+COMMIT;
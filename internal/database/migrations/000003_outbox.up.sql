@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS outbox (
+                                     seq             BIGSERIAL PRIMARY KEY,          -- dispatch order
+                                     entity          TEXT NOT NULL,
+                                     action          TEXT NOT NULL,
+                                     entity_id       TEXT NOT NULL,
+                                     event_id        TEXT,                           -- only set for invitations
+                                     confirmed       BOOL NOT NULL DEFAULT false,    -- only set for invitations
+                                     created_at      TIMESTAMPTZ NOT NULL,
+                                     dispatched_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (seq) WHERE dispatched_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE locations
+    DROP COLUMN IF EXISTS street,
+    DROP COLUMN IF EXISTS zip,
+    DROP COLUMN IF EXISTS city,
+    DROP COLUMN IF EXISTS country;
+
+COMMIT;
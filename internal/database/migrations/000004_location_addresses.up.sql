@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE locations
+    ADD COLUMN IF NOT EXISTS street     TEXT,
+    ADD COLUMN IF NOT EXISTS zip        TEXT,
+    ADD COLUMN IF NOT EXISTS city       TEXT,
+    ADD COLUMN IF NOT EXISTS country    TEXT;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS event_slots CASCADE;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_end_after_start,
+    DROP COLUMN IF EXISTS end_time,
+    DROP COLUMN IF EXISTS timezone;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS end_time   TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS timezone   TEXT,                   -- IANA name, e.g. Europe/Berlin
+    ADD CONSTRAINT events_end_after_start CHECK (end_time >= start_time);
+
+CREATE TABLE IF NOT EXISTS event_slots (
+                                          id              UUID PRIMARY KEY,
+                                          event_id        UUID NOT NULL,
+                                          artist_id       UUID NOT NULL,
+                                          start_time      TIMESTAMPTZ NOT NULL,
+                                          end_time        TIMESTAMPTZ NOT NULL,
+                                          stage           TEXT,
+                                          CONSTRAINT      event_slots_end_after_start CHECK (end_time > start_time),
+                                          -- only invited artists can perform
+                                          CONSTRAINT      fk_artist_event
+                                             FOREIGN KEY (artist_id, event_id)
+                                                 REFERENCES artist_event(artist_id, event_id)
+                                                 ON UPDATE CASCADE
+                                                 ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS event_slots_event_id_idx ON event_slots (event_id, start_time);
+
+COMMIT;
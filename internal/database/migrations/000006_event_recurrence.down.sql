@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS event_overrides CASCADE;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS recurrence,
+    DROP COLUMN IF EXISTS recurrence_exceptions;
+
+COMMIT;
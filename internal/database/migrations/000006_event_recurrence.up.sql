@@ -0,0 +1,17 @@
+BEGIN;
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS recurrence             TEXT,           -- RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=TH
+    ADD COLUMN IF NOT EXISTS recurrence_exceptions  TIMESTAMPTZ[];  -- cancelled occurrences
+
+CREATE TABLE IF NOT EXISTS event_overrides (
+                                              event_id        UUID NOT NULL REFERENCES events ON UPDATE CASCADE ON DELETE CASCADE,
+                                              recurrence_id   TIMESTAMPTZ NOT NULL,   -- original start of the occurrence
+                                              name            TEXT,
+                                              start_time      TIMESTAMPTZ NOT NULL,
+                                              end_time        TIMESTAMPTZ,
+                                              CONSTRAINT      event_overrides_pk PRIMARY KEY (event_id, recurrence_id),
+                                              CONSTRAINT      event_overrides_end_after_start CHECK (end_time >= start_time)
+);
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+ALTER TABLE artist_event
+    DROP CONSTRAINT IF EXISTS artist_event_fee_valid,
+    DROP COLUMN IF EXISTS fee_amount,
+    DROP COLUMN IF EXISTS fee_currency,
+    DROP COLUMN IF EXISTS fee_type,
+    DROP COLUMN IF EXISTS fee_days,
+    DROP COLUMN IF EXISTS travel_allowance,
+    DROP COLUMN IF EXISTS accommodation_allowance,
+    DROP COLUMN IF EXISTS payment_status,
+    DROP COLUMN IF EXISTS due_date,
+    DROP COLUMN IF EXISTS paid_at,
+    DROP COLUMN IF EXISTS contract_refs;
+
+COMMIT;
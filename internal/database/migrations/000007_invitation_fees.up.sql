@@ -0,0 +1,28 @@
+BEGIN;
+
+-- Amounts are in minor units of fee_currency, e.g. cents. An invitation
+-- without fee_amount has no fee agreement.
+ALTER TABLE artist_event
+    ADD COLUMN IF NOT EXISTS fee_amount                 BIGINT,
+    ADD COLUMN IF NOT EXISTS fee_currency               CHAR(3),                -- ISO 4217, e.g. EUR
+    ADD COLUMN IF NOT EXISTS fee_type                   TEXT,                   -- flat, daily
+    ADD COLUMN IF NOT EXISTS fee_days                   INT,
+    ADD COLUMN IF NOT EXISTS travel_allowance           BIGINT,
+    ADD COLUMN IF NOT EXISTS accommodation_allowance    BIGINT,
+    ADD COLUMN IF NOT EXISTS payment_status             TEXT,                   -- pending, invoiced, paid, cancelled
+    ADD COLUMN IF NOT EXISTS due_date                   TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS paid_at                    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS contract_refs              TEXT[],
+    ADD CONSTRAINT artist_event_fee_valid CHECK (
+        fee_amount IS NULL OR (
+            fee_amount >= 0
+            AND fee_currency IS NOT NULL
+            AND fee_type IN ('flat', 'daily')
+            AND fee_days > 0
+            AND travel_allowance >= 0
+            AND accommodation_allowance >= 0
+            AND payment_status IN ('pending', 'invoiced', 'paid', 'cancelled')
+        )
+    );
+
+COMMIT;
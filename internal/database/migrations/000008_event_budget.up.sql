@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS event_budget_lines (
+                                                 id              UUID PRIMARY KEY,
+                                                 event_id        UUID NOT NULL REFERENCES events ON UPDATE CASCADE ON DELETE CASCADE,
+                                                 category        TEXT NOT NULL,          -- e.g. venue, shipping, travel
+                                                 description     TEXT,
+                                                 planned_amount  BIGINT NOT NULL DEFAULT 0,  -- minor units of currency
+                                                 actual_amount   BIGINT,                 -- unknown until paid
+                                                 currency        CHAR(3) NOT NULL,       -- ISO 4217
+                                                 CONSTRAINT      event_budget_lines_amounts_valid CHECK (planned_amount >= 0 AND actual_amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS event_budget_lines_event_id_idx ON event_budget_lines (event_id);
+
+COMMIT;
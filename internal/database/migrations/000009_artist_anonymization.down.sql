@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE artists
+    DROP COLUMN IF EXISTS anonymized_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE artists
+    ADD COLUMN IF NOT EXISTS anonymized_at  TIMESTAMPTZ;            -- personal data was scrubbed, irreversibly
+
+COMMIT;
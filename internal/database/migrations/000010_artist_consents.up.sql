@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS artist_consents (
+                                              id              UUID PRIMARY KEY,
+                                              artist_id       UUID NOT NULL REFERENCES artists ON UPDATE CASCADE ON DELETE CASCADE,
+                                              purpose         TEXT NOT NULL,          -- e.g. store_data, publish_bio
+                                              granted         BOOL NOT NULL,          -- false records a withdrawal
+                                              method          TEXT NOT NULL,          -- how consent was given, e.g. form, email
+                                              note            TEXT,
+                                              recorded_at     TIMESTAMPTZ NOT NULL
+);
+
+-- consents are append-only, the latest record per purpose is in effect
+CREATE INDEX IF NOT EXISTS artist_consents_artist_id_idx ON artist_consents (artist_id, purpose, recorded_at);
+
+COMMIT;
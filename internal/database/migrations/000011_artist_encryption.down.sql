@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX IF EXISTS artists_email_index_idx;
+
+-- encrypted dates of birth are lost, decrypt them first by re-encrypting without encrypted columns
+ALTER TABLE artists
+    DROP COLUMN IF EXISTS email_index,
+    ALTER COLUMN date_of_birth TYPE TIMESTAMPTZ
+        USING CASE
+            WHEN date_of_birth LIKE 'enc:%' THEN NULL
+            ELSE date_of_birth::TIMESTAMPTZ
+        END;
+
+COMMIT;
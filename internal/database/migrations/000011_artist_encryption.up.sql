@@ -0,0 +1,14 @@
+BEGIN;
+
+-- encrypted values don't fit a TIMESTAMPTZ, dates of birth are stored as RFC 3339
+ALTER TABLE artists
+    ALTER COLUMN date_of_birth TYPE TEXT
+        USING CASE
+            WHEN date_of_birth > '0001-01-01 00:00:00+00'
+                THEN to_char(date_of_birth AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        END,
+    ADD COLUMN IF NOT EXISTS email_index    TEXT;           -- blind index of the email, for lookups of encrypted emails
+
+CREATE INDEX IF NOT EXISTS artists_email_index_idx ON artists (email_index);
+
+COMMIT;
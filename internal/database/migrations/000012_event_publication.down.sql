@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS events_published_idx;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_publish_window,
+    DROP COLUMN IF EXISTS published,
+    DROP COLUMN IF EXISTS publish_from,
+    DROP COLUMN IF EXISTS publish_until;
+
+COMMIT;
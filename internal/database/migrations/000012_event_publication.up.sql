@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS published      BOOL NOT NULL DEFAULT false,    -- shown by the public API
+    ADD COLUMN IF NOT EXISTS publish_from   TIMESTAMPTZ,                    -- published from, immediately if NULL
+    ADD COLUMN IF NOT EXISTS publish_until  TIMESTAMPTZ,                    -- published until, indefinitely if NULL
+    ADD CONSTRAINT events_publish_window CHECK (publish_until > publish_from);
+
+CREATE INDEX IF NOT EXISTS events_published_idx ON events (published) WHERE published;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS persisted_queries;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS persisted_queries (
+                                                 hash            TEXT PRIMARY KEY,       -- hex SHA-256 of query
+                                                 query           TEXT NOT NULL,
+                                                 created_at      TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS webhook_queue (
+                                            id              UUID PRIMARY KEY,             -- delivery ID, shared by all attempts
+                                            webhook_id      UUID NOT NULL REFERENCES webhooks ON DELETE CASCADE,
+                                            body            BYTEA NOT NULL,               -- signed and sent as is
+                                            attempt         INTEGER NOT NULL DEFAULT 0,   -- attempts made so far
+                                            next_attempt_at TIMESTAMPTZ NOT NULL,         -- also pushed back while an attempt is running
+                                            created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_queue_next_attempt_at_idx ON webhook_queue (next_attempt_at);
+
+COMMIT;
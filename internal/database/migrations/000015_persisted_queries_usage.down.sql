@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS persisted_queries_last_used_at_idx;
+
+ALTER TABLE persisted_queries
+    DROP COLUMN IF EXISTS last_used_at;
+
+COMMIT;
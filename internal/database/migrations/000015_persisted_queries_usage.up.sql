@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE persisted_queries
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ;      -- least recently used queries are pruned first
+
+UPDATE persisted_queries SET last_used_at = created_at WHERE last_used_at IS NULL;
+
+ALTER TABLE persisted_queries
+    ALTER COLUMN last_used_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS persisted_queries_last_used_at_idx ON persisted_queries (last_used_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS artist_event_event_id_idx;
+DROP INDEX IF EXISTS events_location_id_idx;
+DROP INDEX IF EXISTS events_start_time_idx;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Conflict checks only consider events overlapping the checked ones.
+CREATE INDEX IF NOT EXISTS events_start_time_idx ON events (start_time) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS events_location_id_idx ON events (location_id);
+CREATE INDEX IF NOT EXISTS artist_event_event_id_idx ON artist_event (event_id);
+
+COMMIT;
@@ -2,8 +2,18 @@ package database
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/outbox"
+	"github.com/obitech/artist-db/internal/database/query"
+	"github.com/obitech/artist-db/internal/database/retention"
+	"github.com/obitech/artist-db/internal/database/webhook"
+	"github.com/obitech/artist-db/internal/encryption"
 )
 
 // Option allows customization of the default Database.
@@ -20,3 +30,138 @@ func WithLogger(logger *zap.Logger) Option {
 		return nil
 	}
 }
+
+// WithPoolConfig tunes the connection pool.
+func WithPoolConfig(cfg core.PoolConfig) Option {
+	return func(db *Database) error {
+		if cfg.MaxConns < 0 || cfg.MinConns < 0 {
+			return errors.New("connection limits must not be negative")
+		}
+
+		if cfg.MaxConns > 0 && cfg.MinConns > cfg.MaxConns {
+			return errors.New("min connections exceed max connections")
+		}
+
+		db.poolConfig = cfg
+		return nil
+	}
+}
+
+// WithReplicas routes read-only queries to the given replicas. Replicas are
+// health checked at the given interval and skipped while unhealthy.
+func WithReplicas(checkInterval time.Duration, connStrings ...string) Option {
+	return func(db *Database) error {
+		if checkInterval <= 0 {
+			return errors.New("replica check interval must be positive")
+		}
+
+		db.replicaConnStrings = connStrings
+		db.replicaCheckInterval = checkInterval
+		return nil
+	}
+}
+
+// WithPublisher notifies publisher about every committed change.
+func WithPublisher(publisher core.Publisher) Option {
+	return func(db *Database) error {
+		if publisher == nil {
+			return errors.New("publisher is nil")
+		}
+
+		db.sinks = append(db.sinks, outbox.PublisherSink("publisher", publisher))
+		return nil
+	}
+}
+
+// WithOutboxSinks relays every committed change to sinks, e.g. a message
+// broker.
+func WithOutboxSinks(sinks ...outbox.Sink) Option {
+	return func(db *Database) error {
+		for _, sink := range sinks {
+			if sink == nil {
+				return errors.New("sink is nil")
+			}
+		}
+
+		db.sinks = append(db.sinks, sinks...)
+		return nil
+	}
+}
+
+// WithOutboxRelay tunes the relay which publishes committed changes. Unset
+// fields of cfg fall back to outbox.DefaultRelayConfig.
+func WithOutboxRelay(cfg outbox.RelayConfig) Option {
+	return func(db *Database) error {
+		db.relayCfg = cfg
+		return nil
+	}
+}
+
+// WithWebhooks delivers every committed change to all matching webhooks.
+// Unset fields of cfg fall back to webhook.DefaultDispatcherConfig.
+func WithWebhooks(cfg webhook.DispatcherConfig) Option {
+	return func(db *Database) error {
+		db.webhooks = true
+		db.webhookCfg = cfg
+		return nil
+	}
+}
+
+// WithRetention periodically purges expired personal data and long
+// soft-deleted rows. Unset fields of cfg fall back to
+// retention.DefaultPurgerConfig.
+func WithRetention(cfg retention.PurgerConfig) Option {
+	return func(db *Database) error {
+		if err := cfg.Policy.Validate(); err != nil {
+			return fmt.Errorf("invalid retention policy: %w", err)
+		}
+
+		db.retention = true
+		db.purgerCfg = cfg
+		return nil
+	}
+}
+
+// WithQueryPruning periodically deletes the least recently used persisted
+// queries. Unset fields of cfg fall back to query.DefaultPrunerConfig.
+func WithQueryPruning(cfg query.PrunerConfig) Option {
+	return func(db *Database) error {
+		if cfg.Keep < 0 {
+			return errors.New("number of kept queries must not be negative")
+		}
+
+		db.queryPruning = true
+		db.prunerCfg = cfg
+		return nil
+	}
+}
+
+// WithEncryption encrypts columns of artists at rest, which must be
+// artist.EncryptableColumns, and the signing secrets of webhooks.
+func WithEncryption(enc *encryption.Encrypter, columns ...string) Option {
+	return func(db *Database) error {
+		if enc == nil {
+			return errors.New("encrypter is nil")
+		}
+
+		for _, c := range columns {
+			if c != "" && !contains(artist.EncryptableColumns, c) {
+				return fmt.Errorf("column %q can't be encrypted", c)
+			}
+		}
+
+		db.artistOpts = append(db.artistOpts, artist.WithEncryption(enc, columns...))
+		db.webhookOpts = append(db.webhookOpts, webhook.WithEncryption(enc))
+		return nil
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
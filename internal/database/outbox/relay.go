@@ -0,0 +1,267 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// relayLockID is the advisory lock which ensures only a single relay
+// dispatches at a time, even with many API replicas.
+const relayLockID = 0x61646272656c6179
+
+// RelayConfig tunes the Relay.
+type RelayConfig struct {
+	// Interval between polls of the outbox.
+	Interval time.Duration
+	// BatchSize is the maximum number of entries dispatched at once.
+	BatchSize int
+	// Retention is how long dispatched entries are kept.
+	Retention time.Duration
+}
+
+// DefaultRelayConfig is used for all unset fields of a RelayConfig.
+var DefaultRelayConfig = RelayConfig{
+	Interval:  200 * time.Millisecond,
+	BatchSize: 100,
+	Retention: 24 * time.Hour,
+}
+
+func (c RelayConfig) withDefaults() RelayConfig {
+	if c.Interval <= 0 {
+		c.Interval = DefaultRelayConfig.Interval
+	}
+
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultRelayConfig.BatchSize
+	}
+
+	if c.Retention <= 0 {
+		c.Retention = DefaultRelayConfig.Retention
+	}
+
+	return c
+}
+
+// Relay publishes committed outbox entries in order to all Sinks and marks
+// them as dispatched. Entries are ordered by the time they were written,
+// which may differ from commit order for concurrent transactions.
+type Relay struct {
+	conn   core.Connection
+	sinks  []Sink
+	cfg    RelayConfig
+	logger *zap.Logger
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewRelay starts a Relay which runs until Close is called.
+func NewRelay(conn core.Connection, cfg RelayConfig, logger *zap.Logger, sinks ...Sink) *Relay {
+	r := &Relay{
+		conn:   conn,
+		sinks:  sinks,
+		cfg:    cfg.withDefaults(),
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.run()
+
+	return r
+}
+
+// Close stops polling and dispatches all remaining entries.
+func (r *Relay) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := r.Flush(ctx); err != nil {
+			r.logger.Error("flushing outbox failed", zap.Error(err))
+		}
+	})
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	lastPurge := time.Now()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*r.cfg.Interval+5*time.Second)
+
+		if err := r.Flush(ctx); err != nil {
+			r.logger.Error("dispatching outbox failed", zap.Error(err))
+		}
+
+		if time.Since(lastPurge) > time.Hour {
+			if err := r.purge(ctx); err != nil {
+				r.logger.Error("purging outbox failed", zap.Error(err))
+			}
+
+			lastPurge = time.Now()
+		}
+
+		cancel()
+	}
+}
+
+// Flush dispatches batches until no pending entries are left or another
+// relay is dispatching.
+func (r *Relay) Flush(ctx context.Context) error {
+	for {
+		n, err := r.dispatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if n < r.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// dispatch sends a single batch of pending entries to all sinks and returns
+// the number of entries dispatched.
+func (r *Relay) dispatch(ctx context.Context) (int, error) {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return 0, fmt.Errorf("creating tx failed: %w", err)
+	}
+
+	defer core.RollbackAndLogError(ctx, tx, r.logger)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", relayLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("acquiring lock failed: %w", err)
+	}
+
+	if !locked {
+		return 0, nil
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			seq,
+			entity,
+			action,
+			entity_id,
+			event_id,
+			confirmed
+		FROM
+			%q
+		WHERE
+			dispatched_at IS NULL
+		ORDER BY
+			seq
+		LIMIT $1`, core.TableOutbox)
+
+	rows, err := tx.Query(ctx, stmt, r.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("query failed: %w", err)
+	}
+
+	var (
+		seqs    []int64
+		changes []core.Change
+	)
+
+	for rows.Next() {
+		var (
+			seq     int64
+			change  core.Change
+			eventID *string
+		)
+
+		if err := rows.Scan(&seq, &change.Entity, &change.Action, &change.ID, &eventID, &change.Confirmed); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning rows failed: %w", err)
+		}
+
+		if eventID != nil {
+			change.EventID = *eventID
+		}
+
+		seqs = append(seqs, seq)
+		changes = append(changes, change)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	for _, sink := range r.sinks {
+		if err := sink.Send(ctx, changes...); err != nil {
+			observability.Metrics.TrackOutboxSinkFailure(sink.Name())
+			return 0, fmt.Errorf("sending to sink %q failed: %w", sink.Name(), err)
+		}
+	}
+
+	stmt = fmt.Sprintf(`
+		UPDATE
+			%q
+		SET
+			dispatched_at=$1
+		WHERE
+			seq=ANY($2)`, core.TableOutbox)
+
+	if _, err := tx.Exec(ctx, stmt, time.Now().UTC(), seqs); err != nil {
+		return 0, fmt.Errorf("marking entries dispatched failed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commiting tx failed: %w", err)
+	}
+
+	observability.Metrics.TrackOutboxDispatched(len(changes))
+
+	return len(changes), nil
+}
+
+// purge deletes entries which were dispatched longer than the retention ago.
+func (r *Relay) purge(ctx context.Context) error {
+	stmt := fmt.Sprintf(`
+		DELETE FROM
+			%q
+		WHERE
+			dispatched_at < $1`, core.TableOutbox)
+
+	tag, err := r.conn.Exec(ctx, stmt, time.Now().UTC().Add(-r.cfg.Retention))
+	if err != nil {
+		return err
+	}
+
+	if n := tag.RowsAffected(); n > 0 {
+		r.logger.Info("purged dispatched outbox entries", zap.Int64("count", n))
+	}
+
+	return nil
+}
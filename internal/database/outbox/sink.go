@@ -0,0 +1,76 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+// Sink receives committed changes from the Relay. Changes are delivered at
+// least once: if Send fails, the whole batch is sent again to every Sink.
+type Sink interface {
+	// Name identifies the Sink in logs and metrics.
+	Name() string
+	Send(ctx context.Context, changes ...core.Change) error
+}
+
+type publisherSink struct {
+	name      string
+	publisher core.Publisher
+}
+
+// PublisherSink adapts a core.Publisher, e.g. the change broker. Publishers
+// can't fail, so neither does the Sink. Use it only for best-effort
+// consumers, which may miss changes.
+func PublisherSink(name string, publisher core.Publisher) Sink {
+	return &publisherSink{name: name, publisher: publisher}
+}
+
+func (s *publisherSink) Name() string {
+	return s.name
+}
+
+func (s *publisherSink) Send(ctx context.Context, changes ...core.Change) error {
+	s.publisher.Publish(ctx, changes...)
+	return nil
+}
+
+// Producer writes a message to a topic of a message broker. It can be
+// implemented on top of NATS, Kafka or any compatible client.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+type messageSink struct {
+	name     string
+	topic    string
+	producer Producer
+}
+
+// MessageSink sends every change as JSON message to topic. Messages are keyed
+// by entity and ID, so brokers which partition by key keep the changes of a
+// single entity in order.
+func MessageSink(name, topic string, producer Producer) Sink {
+	return &messageSink{name: name, topic: topic, producer: producer}
+}
+
+func (s *messageSink) Name() string {
+	return s.name
+}
+
+func (s *messageSink) Send(ctx context.Context, changes ...core.Change) error {
+	for _, change := range changes {
+		value, err := json.Marshal(change)
+		if err != nil {
+			return fmt.Errorf("marshalling change failed: %w", err)
+		}
+
+		if err := s.producer.Produce(ctx, s.topic, []byte(change.Entity+":"+change.ID), value); err != nil {
+			return fmt.Errorf("producing message failed: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+type message struct {
+	topic string
+	key   string
+	value []byte
+}
+
+type fakeProducer struct {
+	messages []message
+	err      error
+}
+
+func (p *fakeProducer) Produce(_ context.Context, topic string, key, value []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	p.messages = append(p.messages, message{topic: topic, key: string(key), value: value})
+	return nil
+}
+
+func TestMessageSink(t *testing.T) {
+	t.Run("produces keyed messages in order", func(t *testing.T) {
+		p := &fakeProducer{}
+		sink := MessageSink("kafka", "changes", p)
+
+		changes := []core.Change{
+			{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: "a"},
+			{Entity: core.EntityInvitation, Action: core.ActionUpsert, ID: "a", EventID: "e", Confirmed: true},
+		}
+
+		require.NoError(t, sink.Send(context.Background(), changes...))
+		require.Len(t, p.messages, 2)
+
+		for i, msg := range p.messages {
+			assert.Equal(t, "changes", msg.topic)
+			assert.Equal(t, changes[i].Entity+":"+changes[i].ID, msg.key)
+
+			var got core.Change
+			require.NoError(t, json.Unmarshal(msg.value, &got))
+			assert.Equal(t, changes[i], got)
+		}
+	})
+
+	t.Run("returns producer errors", func(t *testing.T) {
+		errProduce := errors.New("broker down")
+		sink := MessageSink("nats", "changes", &fakeProducer{err: errProduce})
+
+		err := sink.Send(context.Background(), core.Change{Entity: core.EntityEvent, ID: "e"})
+		assert.ErrorIs(t, err, errProduce)
+	})
+}
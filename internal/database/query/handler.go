@@ -0,0 +1,131 @@
+// Package query stores GraphQL queries by hash, so clients can send the hash
+// instead of the whole query.
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	otelTrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	entityQuery = "persisted_query"
+)
+
+// Handler is a DB Handler which operates on persisted queries.
+type Handler struct {
+	conn   core.Connection
+	logger *zap.Logger
+	tracer otelTrace.TracerProvider
+}
+
+// NewHandler returns a Handler.
+func NewHandler(conn core.Connection, logger *zap.Logger, tp otelTrace.TracerProvider) *Handler {
+	return &Handler{
+		conn:   conn,
+		logger: logger,
+		tracer: tp,
+	}
+}
+
+// Get returns the query stored under hash, or ErrNotFound.
+func (h *Handler) Get(ctx context.Context, hash string) (string, error) {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "query.get")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			query
+		FROM
+			%q
+		WHERE
+			hash=$1`, core.TablePersistedQueries)
+
+	var query string
+	if err := h.conn.QueryRow(spanCtx, stmt, hash).Scan(&query); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", core.ErrNotFound
+		}
+
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityQuery, "get")
+		return "", fmt.Errorf("query failed: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsRetrieved(1, entityQuery)
+
+	return query, nil
+}
+
+// Add stores query under hash and marks it as used. Queries which are
+// already stored are left unchanged, the caller has to make sure hash matches
+// query.
+func (h *Handler) Add(ctx context.Context, hash, query string) error {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "query.add")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				hash,
+				query,
+				created_at,
+				last_used_at
+			)
+		VALUES
+			($1, $2, $3, $3)
+		ON CONFLICT
+			(hash)
+		DO UPDATE SET
+			last_used_at=EXCLUDED.last_used_at`, core.TablePersistedQueries)
+
+	tag, err := h.conn.Exec(spanCtx, stmt, hash, query, time.Now().UTC())
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityQuery, "add")
+		return fmt.Errorf("inserting query failed: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsChanged(int(tag.RowsAffected()), entityQuery, "add")
+
+	return nil
+}
+
+// Prune deletes all but the keep most recently used queries and returns how
+// many were deleted.
+func (h *Handler) Prune(ctx context.Context, keep int) (int, error) {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "query.prune")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		DELETE FROM %[1]q
+		WHERE
+			hash IN (
+				SELECT
+					hash
+				FROM
+					%[1]q
+				ORDER BY
+					last_used_at DESC
+				OFFSET $1
+			)`, core.TablePersistedQueries)
+
+	tag, err := h.conn.Exec(spanCtx, stmt, keep)
+	if err != nil {
+		span.RecordError(err)
+		observability.Metrics.TrackObjectError(entityQuery, "prune")
+		return 0, fmt.Errorf("pruning queries failed: %w", err)
+	}
+
+	observability.Metrics.TrackObjectsChanged(int(tag.RowsAffected()), entityQuery, "prune")
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PrunerConfig tunes the Pruner.
+type PrunerConfig struct {
+	// Interval between prunes.
+	Interval time.Duration
+	// Keep is the number of most recently used queries which are kept.
+	Keep int
+}
+
+// DefaultPrunerConfig is used for all unset fields of a PrunerConfig.
+var DefaultPrunerConfig = PrunerConfig{
+	Interval: 10 * time.Minute,
+	Keep:     10000,
+}
+
+func (c PrunerConfig) withDefaults() PrunerConfig {
+	if c.Interval <= 0 {
+		c.Interval = DefaultPrunerConfig.Interval
+	}
+
+	if c.Keep <= 0 {
+		c.Keep = DefaultPrunerConfig.Keep
+	}
+
+	return c
+}
+
+// Pruner periodically deletes the least recently used queries, so requests
+// which store new queries don't have to.
+type Pruner struct {
+	queries *Handler
+	cfg     PrunerConfig
+	logger  *zap.Logger
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewPruner starts a Pruner which runs until Close is called.
+func NewPruner(queries *Handler, cfg PrunerConfig, logger *zap.Logger) *Pruner {
+	p := &Pruner{
+		queries: queries,
+		cfg:     cfg.withDefaults(),
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	return p
+}
+
+// Close stops pruning.
+func (p *Pruner) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+	})
+}
+
+func (p *Pruner) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+
+		if n, err := p.queries.Prune(ctx, p.cfg.Keep); err != nil {
+			p.logger.Error("pruning persisted queries failed", zap.Error(err))
+		} else if n > 0 {
+			p.logger.Info("pruned persisted queries", zap.Int("count", n))
+		}
+
+		cancel()
+	}
+}
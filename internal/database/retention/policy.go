@@ -0,0 +1,112 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Category groups personal fields of artists which are kept equally long.
+type Category string
+
+const (
+	// CategoryContact covers email and social media.
+	CategoryContact Category = "contact"
+	// CategoryOrigin covers date and place of birth and nationality.
+	CategoryOrigin Category = "origin"
+	// CategoryProfile covers pronouns, language and biographies.
+	CategoryProfile Category = "profile"
+)
+
+// categoryColumns are the columns of the artists table per Category. Names
+// are never purged, anonymization removes them.
+var categoryColumns = map[Category][]string{
+	CategoryContact: {"email", "email_index", "facebook", "instagram", "bandcamp"},
+	CategoryOrigin:  {"date_of_birth", "place_of_birth", "nationality"},
+	CategoryProfile: {"pronouns", "language", "bio_ger", "bio_en"},
+}
+
+const year = 365 * 24 * time.Hour
+
+// Policy defines how long data is kept.
+type Policy struct {
+	// Periods is how long the fields of a Category are kept after the last
+	// activity of an artist, which is their last event or last update,
+	// whichever is later. Categories without a positive period are kept.
+	Periods map[Category]time.Duration
+	// DeletedAfter is how long soft-deleted artists, events and locations
+	// are kept before they are deleted for good. Artists still invited to
+	// events are anonymized instead. 0 keeps them.
+	DeletedAfter time.Duration
+}
+
+// DefaultPolicy keeps contact data and origin for 3 years after the last
+// activity and soft-deleted rows for 90 days.
+var DefaultPolicy = Policy{
+	Periods: map[Category]time.Duration{
+		CategoryContact: 3 * year,
+		CategoryOrigin:  3 * year,
+	},
+	DeletedAfter: 90 * 24 * time.Hour,
+}
+
+// Validate returns an error for unknown categories or negative periods.
+func (p Policy) Validate() error {
+	for c, d := range p.Periods {
+		if _, ok := categoryColumns[c]; !ok {
+			return fmt.Errorf("unknown category %q", c)
+		}
+
+		if d < 0 {
+			return fmt.Errorf("negative retention period for %q", c)
+		}
+	}
+
+	if p.DeletedAfter < 0 {
+		return fmt.Errorf("negative retention period for deleted rows")
+	}
+
+	return nil
+}
+
+// categories returns the categories with a period, in stable order.
+func (p Policy) categories() []Category {
+	var cs []Category
+	for c, d := range p.Periods {
+		if d > 0 {
+			cs = append(cs, c)
+		}
+	}
+
+	sort.Slice(cs, func(i, j int) bool { return cs[i] < cs[j] })
+
+	return cs
+}
+
+// present returns an SQL condition which is true if column holds data.
+// Artists without a value store empty strings.
+func present(column string) string {
+	switch column {
+	case "pronouns":
+		return "cardinality(pronouns) > 0"
+	default:
+		return fmt.Sprintf("NULLIF(%s, '') IS NOT NULL", column)
+	}
+}
+
+// purgeSets returns the SET and WHERE clauses which null the columns of c.
+func purgeSets(c Category) (set, where string) {
+	var (
+		columns = categoryColumns[c]
+		sets    = make([]string, 0, len(columns))
+		conds   = make([]string, 0, len(columns))
+	)
+
+	for _, column := range columns {
+		sets = append(sets, column+"=NULL")
+		conds = append(conds, present(column))
+	}
+
+	return strings.Join(sets, ", "), strings.Join(conds, " OR ")
+}
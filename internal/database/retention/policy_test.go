@@ -0,0 +1,73 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			policy: DefaultPolicy,
+		},
+		{
+			name: "unknown category",
+			policy: Policy{
+				Periods: map[Category]time.Duration{"artworks": time.Hour},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative period",
+			policy: Policy{
+				Periods: map[Category]time.Duration{CategoryContact: -time.Hour},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negative deletion period",
+			policy:  Policy{DeletedAfter: -time.Hour},
+			wantErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPolicy_categories(t *testing.T) {
+	p := Policy{
+		Periods: map[Category]time.Duration{
+			CategoryProfile: time.Hour,
+			CategoryOrigin:  0,
+			CategoryContact: time.Hour,
+		},
+	}
+
+	assert.Equal(t, []Category{CategoryContact, CategoryProfile}, p.categories())
+}
+
+func TestPurgeSets(t *testing.T) {
+	set, where := purgeSets(CategoryOrigin)
+
+	assert.Equal(t, "date_of_birth=NULL, place_of_birth=NULL, nationality=NULL", set)
+	assert.Equal(t,
+		"NULLIF(date_of_birth, '') IS NOT NULL OR NULLIF(place_of_birth, '') IS NOT NULL OR NULLIF(nationality, '') IS NOT NULL",
+		where,
+	)
+}
@@ -0,0 +1,398 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// purgeLockID is the advisory lock which ensures only a single purger runs
+// at a time, even with many API replicas.
+const purgeLockID = 0x6164627075726765
+
+// PurgerConfig tunes the Purger.
+type PurgerConfig struct {
+	// Interval between purges.
+	Interval time.Duration
+	// Policy defines what is purged.
+	Policy Policy
+}
+
+// DefaultPurgerConfig is used for all unset fields of a PurgerConfig.
+var DefaultPurgerConfig = PurgerConfig{
+	Interval: 24 * time.Hour,
+	Policy:   DefaultPolicy,
+}
+
+func (c PurgerConfig) withDefaults() PurgerConfig {
+	if c.Interval <= 0 {
+		c.Interval = DefaultPurgerConfig.Interval
+	}
+
+	if c.Policy.Periods == nil && c.Policy.DeletedAfter == 0 {
+		c.Policy = DefaultPurgerConfig.Policy
+	}
+
+	return c
+}
+
+// Result is what a purge did.
+type Result struct {
+	// Purged are the IDs of artists whose fields were nulled, per Category.
+	Purged map[Category][]string
+	// AnonymizedArtists are the IDs of long soft-deleted artists which were
+	// anonymized instead of deleted, since they are still invited to events.
+	AnonymizedArtists []string
+	DeletedArtists    int64
+	DeletedEvents     int64
+	DeletedLocations  int64
+}
+
+// Purger periodically nulls expired personal fields of artists and deletes
+// long soft-deleted rows according to a Policy.
+type Purger struct {
+	conn   core.Connection
+	cfg    PurgerConfig
+	logger *zap.Logger
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewPurger starts a Purger which runs until Close is called. The Policy of
+// cfg must be valid.
+func NewPurger(conn core.Connection, cfg PurgerConfig, logger *zap.Logger) *Purger {
+	p := &Purger{
+		conn:   conn,
+		cfg:    cfg.withDefaults(),
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	return p
+}
+
+// Close stops purging.
+func (p *Purger) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+	})
+}
+
+func (p *Purger) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+
+		if _, err := p.Purge(ctx); err != nil {
+			p.logger.Error("purging expired data failed", zap.Error(err))
+		}
+
+		cancel()
+	}
+}
+
+// Purge applies the Policy once. It does nothing if another purger is
+// running.
+func (p *Purger) Purge(ctx context.Context) (Result, error) {
+	var (
+		res = Result{Purged: make(map[Category][]string)}
+		now = time.Now().UTC()
+	)
+
+	if err := core.RunInTx(ctx, p.conn, p.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		res = Result{Purged: make(map[Category][]string)}
+
+		var locked bool
+		if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", purgeLockID).Scan(&locked); err != nil {
+			return fmt.Errorf("acquiring lock failed: %w", err)
+		}
+
+		if !locked {
+			return nil
+		}
+
+		var changes []core.Change
+		seen := make(map[string]bool)
+
+		for _, c := range p.cfg.Policy.categories() {
+			ids, err := purgeCategory(ctx, tx, c, now.Add(-p.cfg.Policy.Periods[c]))
+			if err != nil {
+				return fmt.Errorf("purging %s: %w", c, err)
+			}
+
+			if len(ids) > 0 {
+				res.Purged[c] = ids
+			}
+
+			for _, id := range ids {
+				if !seen[id] {
+					seen[id] = true
+					changes = append(changes, core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: id})
+				}
+			}
+		}
+
+		if d := p.cfg.Policy.DeletedAfter; d > 0 {
+			cutoff := now.Add(-d)
+
+			// Events are deleted first, which releases their artists.
+			for _, del := range []struct {
+				table string
+				count *int64
+			}{
+				{core.TableEvents, &res.DeletedEvents},
+				{core.TableLocations, &res.DeletedLocations},
+			} {
+				n, err := deleteSoftDeleted(ctx, tx, del.table, cutoff)
+				if err != nil {
+					return fmt.Errorf("deleting from %s: %w", del.table, err)
+				}
+
+				*del.count = n
+			}
+
+			ids, err := anonymizeInvited(ctx, tx, cutoff, now)
+			if err != nil {
+				return fmt.Errorf("anonymizing artists: %w", err)
+			}
+
+			res.AnonymizedArtists = ids
+
+			for _, id := range ids {
+				if !seen[id] {
+					seen[id] = true
+					changes = append(changes, core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: id})
+				}
+			}
+
+			if res.DeletedArtists, err = deleteUninvitedArtists(ctx, tx, cutoff); err != nil {
+				return fmt.Errorf("deleting from %s: %w", core.TableArtists, err)
+			}
+		}
+
+		return core.WriteOutbox(ctx, tx, changes...)
+	}); err != nil {
+		return Result{}, err
+	}
+
+	p.log(res)
+
+	return res, nil
+}
+
+func (p *Purger) log(res Result) {
+	for _, c := range p.cfg.Policy.categories() {
+		if ids := res.Purged[c]; len(ids) > 0 {
+			observability.Metrics.TrackObjectsChanged(len(ids), core.EntityArtist, "purge")
+			p.logger.Info("purged expired personal data",
+				zap.String("category", string(c)),
+				zap.Strings("artistIDs", ids),
+			)
+		}
+	}
+
+	if ids := res.AnonymizedArtists; len(ids) > 0 {
+		observability.Metrics.TrackObjectsChanged(len(ids), core.EntityArtist, "anonymize")
+		p.logger.Info("anonymized soft-deleted artists",
+			zap.Strings("artistIDs", ids),
+		)
+	}
+
+	for _, del := range []struct {
+		entity string
+		count  int64
+	}{
+		{core.EntityArtist, res.DeletedArtists},
+		{core.EntityEvent, res.DeletedEvents},
+		{core.EntityLocation, res.DeletedLocations},
+	} {
+		if del.count > 0 {
+			observability.Metrics.TrackObjectsChanged(int(del.count), del.entity, "hard_delete")
+			p.logger.Info("deleted soft-deleted tuples",
+				zap.String("entity", del.entity),
+				zap.Int64("count", del.count),
+			)
+		}
+	}
+}
+
+// purgeCategory nulls the fields of c of all artists whose last activity was
+// before cutoff and returns their IDs.
+func purgeCategory(ctx context.Context, tx pgx.Tx, c Category, cutoff time.Time) ([]string, error) {
+	set, where := purgeSets(c)
+
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%[1]q a
+		SET
+			%[4]s
+		WHERE
+			(%[5]s)
+			AND GREATEST(
+				a.updated_at,
+				(
+					SELECT
+						max(COALESCE(e.end_time, e.start_time))
+					FROM
+						%[2]q ia
+					JOIN
+						%[3]q e ON e.id = ia.event_id
+					WHERE
+						ia.artist_id = a.id
+				)
+			) < $1
+		RETURNING
+			a.id`, core.TableArtists, core.TableInvitedArtists, core.TableEvents, set, where)
+
+	rows, err := tx.Query(ctx, stmt, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// deleteSoftDeleted deletes all rows of table which were soft-deleted before
+// cutoff. Dependent rows are deleted by cascade.
+func deleteSoftDeleted(ctx context.Context, tx pgx.Tx, table string, cutoff time.Time) (int64, error) {
+	stmt := fmt.Sprintf(`
+		DELETE FROM
+			%q
+		WHERE
+			deleted_at < $1`, table)
+
+	tag, err := tx.Exec(ctx, stmt, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// anonymizeInvited anonymizes the artists which were soft-deleted before
+// cutoff and are still invited to events, and returns their IDs. Like
+// artist.Handler.Anonymize, the rows are kept since fees are subject to
+// accounting retention.
+func anonymizeInvited(ctx context.Context, tx pgx.Tx, cutoff, now time.Time) ([]string, error) {
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%[1]q a
+		SET
+			first_name=$2,
+			last_name=$3,
+			artist_name=NULL,
+			pronouns=NULL,
+			date_of_birth=NULL,
+			place_of_birth=NULL,
+			nationality=NULL,
+			language=NULL,
+			facebook=NULL,
+			instagram=NULL,
+			bandcamp=NULL,
+			bio_ger=NULL,
+			bio_en=NULL,
+			email=NULL,
+			email_index=NULL,
+			updated_at=$4,
+			anonymized_at=$4
+		WHERE
+			a.deleted_at < $1
+			AND a.anonymized_at IS NULL
+			AND EXISTS (SELECT 1 FROM %[2]q ia WHERE ia.artist_id = a.id)
+		RETURNING
+			a.id`, core.TableArtists, core.TableInvitedArtists)
+
+	rows, err := tx.Query(ctx, stmt, cutoff, artist.AnonymizedFirstName, artist.AnonymizedLastName, now)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// Consents are kept as proof, without free-form notes.
+	stmt = fmt.Sprintf(`
+		UPDATE
+			%q
+		SET
+			note=NULL
+		WHERE
+			artist_id = ANY($1::uuid[])`, core.TableArtistConsents)
+
+	if _, err := tx.Exec(ctx, stmt, ids); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// deleteUninvitedArtists deletes the artists which were soft-deleted before
+// cutoff and aren't invited to any event.
+func deleteUninvitedArtists(ctx context.Context, tx pgx.Tx, cutoff time.Time) (int64, error) {
+	stmt := fmt.Sprintf(`
+		DELETE FROM
+			%[1]q a
+		WHERE
+			a.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM %[2]q ia WHERE ia.artist_id = a.id)`, core.TableArtists, core.TableInvitedArtists)
+
+	tag, err := tx.Exec(ctx, stmt, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,434 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+const (
+	HeaderEvent     = "X-ArtistDB-Event"
+	HeaderDelivery  = "X-ArtistDB-Delivery"
+	HeaderSignature = "X-ArtistDB-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Store looks up webhooks, queues deliveries and records delivery attempts.
+type Store interface {
+	Matching(ctx context.Context, entity, action string) ([]*Webhook, error)
+	LogDelivery(ctx context.Context, d *Delivery) error
+
+	Enqueue(ctx context.Context, deliveries ...*QueuedDelivery) error
+	Due(ctx context.Context, limit int, lease time.Duration) ([]*QueuedDelivery, error)
+	Reschedule(ctx context.Context, id string, attempt int, at time.Time) error
+	Dequeue(ctx context.Context, id string) error
+}
+
+// DispatcherConfig tunes the delivery of webhooks.
+type DispatcherConfig struct {
+	// Timeout of a single delivery attempt.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts per delivery, including the first.
+	MaxAttempts int
+	// BaseDelay is the initial backoff between attempts, doubled every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts.
+	MaxDelay time.Duration
+	// Workers is the number of concurrent deliveries.
+	Workers int
+	// PollInterval is how often idle workers look for due deliveries, e.g.
+	// retries or deliveries queued by another instance.
+	PollInterval time.Duration
+	// AllowInternalTargets allows deliveries to loopback, link-local and
+	// private addresses, e.g. for local development.
+	AllowInternalTargets bool
+}
+
+// DefaultDispatcherConfig is used for all unset fields of a DispatcherConfig.
+var DefaultDispatcherConfig = DispatcherConfig{
+	Timeout:      5 * time.Second,
+	MaxAttempts:  5,
+	BaseDelay:    time.Second,
+	MaxDelay:     time.Minute,
+	Workers:      4,
+	PollInterval: time.Second,
+}
+
+func (c DispatcherConfig) withDefaults() DispatcherConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultDispatcherConfig.Timeout
+	}
+
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultDispatcherConfig.MaxAttempts
+	}
+
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultDispatcherConfig.BaseDelay
+	}
+
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultDispatcherConfig.MaxDelay
+	}
+
+	if c.Workers <= 0 {
+		c.Workers = DefaultDispatcherConfig.Workers
+	}
+
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultDispatcherConfig.PollInterval
+	}
+
+	return c
+}
+
+// lease is how long a delivery is reserved for an attempt, which covers the
+// request and recording its outcome.
+func (c DispatcherConfig) lease() time.Duration {
+	return 3 * c.Timeout
+}
+
+// Payload is the JSON body sent to webhooks.
+type Payload struct {
+	ID         string    `json:"id"`
+	Entity     string    `json:"entity"`
+	Action     string    `json:"action"`
+	EntityID   string    `json:"entityID"`
+	EventID    string    `json:"eventID,omitempty"`
+	Confirmed  bool      `json:"confirmed,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// Dispatcher implements outbox.Sink and delivers changes to all matching
+// webhooks in the background. Deliveries are queued in the Store, so they
+// survive restarts. Failed attempts are rescheduled with exponential backoff
+// instead of blocking a worker, and every attempt is recorded in the Store.
+// Receivers at internal addresses are refused unless AllowInternalTargets is
+// set.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	cfg    DispatcherConfig
+	logger *zap.Logger
+
+	wake      chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewDispatcher starts a Dispatcher which runs until Close is called.
+func NewDispatcher(store Store, cfg DispatcherConfig, logger *zap.Logger) *Dispatcher {
+	cfg = cfg.withDefaults()
+
+	// Receivers are checked when connecting, so a proxy would bypass the
+	// check.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowInternalTargets {
+		dialer := &net.Dialer{Timeout: cfg.Timeout, Control: refuseInternal}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+
+	d := &Dispatcher{
+		store:  store,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		cfg:    cfg,
+		logger: logger,
+		wake:   make(chan struct{}, cfg.Workers),
+		done:   make(chan struct{}),
+	}
+
+	d.wg.Add(cfg.Workers)
+
+	for i := 0; i < cfg.Workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+// Name implements outbox.Sink.
+func (d *Dispatcher) Name() string {
+	return "webhooks"
+}
+
+// Send implements outbox.Sink and queues changes for delivery. If they can't
+// be queued, the error is returned so the relay sends them again.
+func (d *Dispatcher) Send(ctx context.Context, changes ...core.Change) error {
+	if err := d.enqueue(ctx, changes...); err != nil {
+		return fmt.Errorf("queueing webhook deliveries failed: %w", err)
+	}
+
+	return nil
+}
+
+// Close stops all workers. Queued deliveries are attempted after a restart.
+func (d *Dispatcher) Close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+	})
+}
+
+// enqueue queues a delivery of each change to every webhook interested in
+// it, and wakes up idle workers.
+func (d *Dispatcher) enqueue(ctx context.Context, changes ...core.Change) error {
+	var queued []*QueuedDelivery
+
+	for _, change := range changes {
+		webhooks, err := d.store.Matching(ctx, change.Entity, change.Action)
+		if err != nil {
+			return fmt.Errorf("looking up webhooks failed: %w", err)
+		}
+
+		for _, w := range webhooks {
+			payload := Payload{
+				ID:         uuid.New().String(),
+				Entity:     change.Entity,
+				Action:     change.Action,
+				EntityID:   change.ID,
+				EventID:    change.EventID,
+				Confirmed:  change.Confirmed,
+				OccurredAt: time.Now().UTC(),
+			}
+
+			body, err := json.Marshal(payload)
+			if err != nil {
+				return fmt.Errorf("marshalling payload failed: %w", err)
+			}
+
+			queued = append(queued, &QueuedDelivery{ID: payload.ID, Webhook: w, Body: body})
+		}
+	}
+
+	if len(queued) == 0 {
+		return nil
+	}
+
+	if err := d.store.Enqueue(ctx, queued...); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(queued) && i < d.cfg.Workers; i++ {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// work attempts due deliveries until there are none, then waits for new
+// deliveries or the next poll.
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for d.running() && d.attemptDue() {
+		}
+
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+func (d *Dispatcher) running() bool {
+	select {
+	case <-d.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// attemptDue attempts a single due delivery. It returns false if none was
+// due.
+func (d *Dispatcher) attemptDue() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+
+	due, err := d.store.Due(ctx, 1, d.cfg.lease())
+	if err != nil {
+		d.logger.Error("looking up due webhook deliveries failed", zap.Error(err))
+		return false
+	}
+
+	if len(due) == 0 {
+		return false
+	}
+
+	d.attempt(due[0])
+
+	return true
+}
+
+// attempt delivers q once. Afterwards q is either removed from the queue or
+// rescheduled.
+func (d *Dispatcher) attempt(q *QueuedDelivery) {
+	var payload Payload
+	if err := json.Unmarshal(q.Body, &payload); err != nil {
+		d.logger.Error("unmarshalling payload failed", zap.String("delivery", q.ID), zap.Error(err))
+		d.dequeue(q)
+		return
+	}
+
+	attempt := q.Attempt + 1
+	statusCode, err := d.post(q.Webhook, payload, q.Body)
+
+	record := &Delivery{
+		ID:         uuid.New().String(),
+		DeliveryID: q.ID,
+		WebhookID:  q.Webhook.ID,
+		Entity:     payload.Entity,
+		Action:     payload.Action,
+		EntityID:   payload.EntityID,
+		Attempt:    attempt,
+		StatusCode: statusCode,
+		Succeeded:  err == nil,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	d.log(record)
+
+	switch {
+	case err == nil || !retryable(statusCode) || errors.Is(err, ErrForbiddenTarget):
+		d.dequeue(q)
+	case attempt >= d.cfg.MaxAttempts:
+		d.logger.Warn("webhook delivery failed, giving up",
+			zap.String("webhook", q.Webhook.ID),
+			zap.String("delivery", q.ID),
+			zap.Int("attempts", attempt),
+		)
+		d.dequeue(q)
+	default:
+		d.reschedule(q, attempt, time.Now().Add(backoff(d.cfg.BaseDelay, d.cfg.MaxDelay, attempt)))
+	}
+}
+
+func (d *Dispatcher) post(w *Webhook, payload Payload, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("creating request failed: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, payload.Entity+"."+payload.Action)
+	req.Header.Set(HeaderDelivery, payload.ID)
+	req.Header.Set(HeaderSignature, Sign(w.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) log(record *Delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+
+	if err := d.store.LogDelivery(ctx, record); err != nil {
+		d.logger.Error("recording webhook delivery failed",
+			zap.String("webhook", record.WebhookID),
+			zap.String("delivery", record.DeliveryID),
+			zap.Error(err),
+		)
+	}
+}
+
+// dequeue removes q from the queue. If that fails, q is attempted again once
+// its lease is over.
+func (d *Dispatcher) dequeue(q *QueuedDelivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+
+	if err := d.store.Dequeue(ctx, q.ID); err != nil {
+		d.logger.Error("dequeueing webhook delivery failed", zap.String("delivery", q.ID), zap.Error(err))
+	}
+}
+
+func (d *Dispatcher) reschedule(q *QueuedDelivery, attempt int, at time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+
+	if err := d.store.Reschedule(ctx, q.ID, attempt, at); err != nil {
+		d.logger.Error("rescheduling webhook delivery failed", zap.String("delivery", q.ID), zap.Error(err))
+	}
+}
+
+// Sign returns the signature header value of body, an HMAC-SHA256 keyed with
+// secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature is a valid signature of body.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// retryable returns true if a delivery which failed with statusCode should
+// be retried. A zero statusCode means the receiver couldn't be reached.
+func retryable(statusCode int) bool {
+	switch {
+	case statusCode == 0,
+		statusCode == http.StatusRequestTimeout,
+		statusCode == http.StatusTooManyRequests,
+		statusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns a jittered, exponentially growing delay before the next
+// attempt.
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,329 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	webhooks   []*Webhook
+	deliveries []*Delivery
+	queue      []*queued
+	enqueueErr error
+}
+
+type queued struct {
+	*QueuedDelivery
+	next time.Time
+}
+
+func (s *fakeStore) Matching(_ context.Context, entity, action string) ([]*Webhook, error) {
+	var out []*Webhook
+	for _, w := range s.webhooks {
+		if w.Matches(entity, action) {
+			out = append(out, w)
+		}
+	}
+
+	return out, nil
+}
+
+func (s *fakeStore) LogDelivery(_ context.Context, d *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, d)
+	return nil
+}
+
+func (s *fakeStore) Enqueue(_ context.Context, deliveries ...*QueuedDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.enqueueErr != nil {
+		return s.enqueueErr
+	}
+
+	for _, d := range deliveries {
+		s.queue = append(s.queue, &queued{QueuedDelivery: d, next: time.Now()})
+	}
+
+	return nil
+}
+
+func (s *fakeStore) Due(_ context.Context, limit int, lease time.Duration) ([]*QueuedDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*QueuedDelivery
+	for _, q := range s.queue {
+		if len(due) < limit && !q.next.After(time.Now()) {
+			q.next = time.Now().Add(lease)
+			due = append(due, &QueuedDelivery{ID: q.ID, Webhook: q.Webhook, Body: q.Body, Attempt: q.Attempt})
+		}
+	}
+
+	return due, nil
+}
+
+func (s *fakeStore) Reschedule(_ context.Context, id string, attempt int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, q := range s.queue {
+		if q.ID == id {
+			q.Attempt, q.next = attempt, at
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStore) Dequeue(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, q := range s.queue {
+		if q.ID == id {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStore) queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.queue)
+}
+
+func (s *fakeStore) logged() []*Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Delivery(nil), s.deliveries...)
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("delivers signed payload", func(t *testing.T) {
+		const secret = "s3cr3t"
+
+		received := make(chan Payload, 1)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			if !Verify(secret, body, r.Header.Get(HeaderSignature)) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			var p Payload
+			require.NoError(t, json.Unmarshal(body, &p))
+			assert.Equal(t, p.ID, r.Header.Get(HeaderDelivery))
+			assert.Equal(t, "artist.upsert", r.Header.Get(HeaderEvent))
+
+			received <- p
+		}))
+		defer srv.Close()
+
+		store := &fakeStore{webhooks: []*Webhook{{ID: "1", URL: srv.URL, Secret: secret}}}
+
+		d := NewDispatcher(store, DispatcherConfig{AllowInternalTargets: true}, zap.NewNop())
+		defer d.Close()
+
+		require.NoError(t, d.Send(context.Background(), core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: "a"}))
+
+		select {
+		case p := <-received:
+			assert.Equal(t, core.EntityArtist, p.Entity)
+			assert.Equal(t, "a", p.EntityID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not delivered")
+		}
+
+		require.Eventually(t, func() bool { return len(store.logged()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.True(t, store.logged()[0].Succeeded)
+		assert.Equal(t, http.StatusOK, store.logged()[0].StatusCode)
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		var calls int32
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer srv.Close()
+
+		store := &fakeStore{webhooks: []*Webhook{{ID: "1", URL: srv.URL}}}
+
+		d := NewDispatcher(store, DispatcherConfig{AllowInternalTargets: true, BaseDelay: time.Millisecond, PollInterval: 10 * time.Millisecond}, zap.NewNop())
+		defer d.Close()
+
+		require.NoError(t, d.Send(context.Background(), core.Change{Entity: core.EntityEvent, Action: core.ActionDelete, ID: "e"}))
+
+		require.Eventually(t, func() bool { return len(store.logged()) == 3 }, 5*time.Second, 10*time.Millisecond)
+
+		logged := store.logged()
+		assert.False(t, logged[0].Succeeded)
+		assert.Equal(t, http.StatusServiceUnavailable, logged[0].StatusCode)
+		assert.True(t, logged[2].Succeeded)
+		assert.Equal(t, 3, logged[2].Attempt)
+		assert.Equal(t, logged[0].DeliveryID, logged[2].DeliveryID)
+		assert.Zero(t, store.queued())
+	})
+
+	t.Run("failing receivers don't hold up others", func(t *testing.T) {
+		dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer dead.Close()
+
+		received := make(chan struct{}, 1)
+
+		alive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- struct{}{}
+		}))
+		defer alive.Close()
+
+		store := &fakeStore{webhooks: []*Webhook{
+			{ID: "1", URL: dead.URL, Entities: []string{core.EntityArtist}},
+			{ID: "2", URL: alive.URL, Entities: []string{core.EntityEvent}},
+		}}
+
+		// A single worker and a long backoff would stall the second delivery
+		// if the worker waited for the retry.
+		d := NewDispatcher(store, DispatcherConfig{AllowInternalTargets: true, Workers: 1, BaseDelay: time.Hour, MaxDelay: time.Hour}, zap.NewNop())
+		defer d.Close()
+
+		require.NoError(t, d.Send(context.Background(), core.Change{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: "a"}))
+		require.NoError(t, d.Send(context.Background(), core.Change{Entity: core.EntityEvent, Action: core.ActionUpsert, ID: "e"}))
+
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not delivered")
+		}
+
+		// The retry of the failing delivery stays queued.
+		require.Eventually(t, func() bool { return store.queued() == 1 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer srv.Close()
+
+		store := &fakeStore{webhooks: []*Webhook{{ID: "1", URL: srv.URL}}}
+
+		d := NewDispatcher(store, DispatcherConfig{AllowInternalTargets: true, BaseDelay: time.Millisecond}, zap.NewNop())
+		defer d.Close()
+
+		require.NoError(t, d.Send(context.Background(), core.Change{Entity: core.EntityEvent, Action: core.ActionUpsert, ID: "e"}))
+
+		require.Eventually(t, func() bool { return len(store.logged()) == 1 }, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, store.logged(), 1)
+		assert.Zero(t, store.queued())
+	})
+
+	t.Run("refuses internal targets", func(t *testing.T) {
+		var called bool
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer srv.Close()
+
+		store := &fakeStore{webhooks: []*Webhook{{ID: "1", URL: srv.URL}}}
+
+		d := NewDispatcher(store, DispatcherConfig{BaseDelay: time.Millisecond}, zap.NewNop())
+		defer d.Close()
+
+		require.NoError(t, d.Send(context.Background(), core.Change{Entity: core.EntityEvent, Action: core.ActionUpsert, ID: "e"}))
+
+		require.Eventually(t, func() bool { return len(store.logged()) == 1 }, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+
+		logged := store.logged()
+		require.Len(t, logged, 1)
+		assert.False(t, logged[0].Succeeded)
+		assert.Contains(t, logged[0].Error, ErrForbiddenTarget.Error())
+		assert.Zero(t, store.queued())
+		assert.False(t, called)
+	})
+
+	t.Run("returns errors queueing deliveries", func(t *testing.T) {
+		errDown := errors.New("database down")
+		store := &fakeStore{webhooks: []*Webhook{{ID: "1", URL: "https://hooks.example.com"}}, enqueueErr: errDown}
+
+		d := NewDispatcher(store, DispatcherConfig{}, zap.NewNop())
+		defer d.Close()
+
+		err := d.Send(context.Background(), core.Change{Entity: core.EntityEvent, Action: core.ActionUpsert, ID: "e"})
+		assert.ErrorIs(t, err, errDown)
+	})
+
+	t.Run("skips non-matching webhooks", func(t *testing.T) {
+		w := &Webhook{Entities: []string{core.EntityArtist}, Actions: []string{core.ActionDelete}}
+
+		assert.True(t, w.Matches(core.EntityArtist, core.ActionDelete))
+		assert.False(t, w.Matches(core.EntityArtist, core.ActionUpsert))
+		assert.False(t, w.Matches(core.EntityEvent, core.ActionDelete))
+	})
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		url  string
+		err  error
+	}{
+		{name: "public host", url: "https://hooks.example.com/artist-db"},
+		{name: "public IP", url: "http://203.0.113.10:8080/hook"},
+		{name: "loopback", url: "http://127.0.0.1:8080/hook", err: ErrForbiddenTarget},
+		{name: "IPv6 loopback", url: "http://[::1]/hook", err: ErrForbiddenTarget},
+		{name: "localhost", url: "http://localhost/hook", err: ErrForbiddenTarget},
+		{name: "private", url: "http://10.0.0.5/hook", err: ErrForbiddenTarget},
+		{name: "link-local metadata", url: "http://169.254.169.254/latest/meta-data", err: ErrForbiddenTarget},
+		{name: "unspecified", url: "http://0.0.0.0/hook", err: ErrForbiddenTarget},
+		{name: "shared address space", url: "http://100.64.1.1/hook", err: ErrForbiddenTarget},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckURL(tt.url)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("invalid URLs are rejected", func(t *testing.T) {
+		for _, u := range []string{"ftp://example.com", "http://", "::"} {
+			assert.Error(t, CheckURL(u), u)
+		}
+	})
+}
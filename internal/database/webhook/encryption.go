@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/encryption"
+)
+
+// secretField binds encrypted secrets to the column they are stored in.
+const secretField = "webhook_secret"
+
+// Option customizes a Handler.
+type Option func(h *Handler)
+
+// WithEncryption encrypts signing secrets on upsert and decrypts them on
+// retrieval.
+func WithEncryption(enc *encryption.Encrypter) Option {
+	return func(h *Handler) {
+		h.enc = enc
+	}
+}
+
+// seal encrypts secret if encryption is configured.
+func (h *Handler) seal(ctx context.Context, secret string) (string, error) {
+	if h.enc == nil || secret == "" {
+		return secret, nil
+	}
+
+	sealed, err := h.enc.Encrypt(ctx, secretField, secret)
+	if err != nil {
+		return "", fmt.Errorf("encrypting secret: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// unseal decrypts secret if it is encrypted. Secrets stored before
+// encryption was configured are returned as they are.
+func (h *Handler) unseal(ctx context.Context, secret string) (string, error) {
+	if !encryption.IsEncrypted(secret) {
+		return secret, nil
+	}
+
+	if h.enc == nil {
+		return "", fmt.Errorf("secret is encrypted but encryption is not configured")
+	}
+
+	plaintext, err := h.enc.Decrypt(ctx, secretField, secret)
+	if err != nil {
+		return "", fmt.Errorf("decrypting secret: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// ReencryptSecrets encrypts all secrets which are stored in plaintext or
+// with an old key, and returns how many were rewritten. Without encryption,
+// secrets are left as they are. Run it after rotating keys.
+func (h *Handler) ReencryptSecrets(ctx context.Context) (int, error) {
+	if h.enc == nil {
+		return 0, nil
+	}
+
+	var n int
+
+	if err := core.RunInTx(ctx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		n = 0
+
+		rows, err := tx.Query(ctx, fmt.Sprintf(`SELECT id, secret FROM %q FOR UPDATE`, core.TableWebhooks))
+		if err != nil {
+			return err
+		}
+
+		stale := make(map[string]string)
+		for rows.Next() {
+			var id, secret string
+			if err := rows.Scan(&id, &secret); err != nil {
+				rows.Close()
+				return err
+			}
+
+			if secret != "" && !h.enc.Current(secret) {
+				stale[id] = secret
+			}
+		}
+
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, secret := range stale {
+			plaintext, err := h.unseal(ctx, secret)
+			if err != nil {
+				return fmt.Errorf("webhook %s: %w", id, err)
+			}
+
+			sealed, err := h.seal(ctx, plaintext)
+			if err != nil {
+				return fmt.Errorf("webhook %s: %w", id, err)
+			}
+
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`UPDATE %q SET secret=$1 WHERE id=$2`, core.TableWebhooks), sealed, id); err != nil {
+				return err
+			}
+
+			n++
+		}
+
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("re-encrypting webhook secrets: %w", err)
+	}
+
+	h.logger.Info("webhook secrets re-encrypted", zap.Int("count", n))
+
+	return n, nil
+}
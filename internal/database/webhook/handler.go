@@ -0,0 +1,405 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	otelTrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/encryption"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	entityWebhook = "webhook"
+)
+
+// Handler is a DB Handler which operates on Webhooks and their deliveries.
+type Handler struct {
+	conn   core.Connection
+	logger *zap.Logger
+	tracer otelTrace.TracerProvider
+
+	enc *encryption.Encrypter
+}
+
+// NewHandler returns a Handler.
+func NewHandler(conn core.Connection, logger *zap.Logger, tp otelTrace.TracerProvider, opts ...Option) *Handler {
+	h := &Handler{
+		conn:   conn,
+		logger: logger,
+		tracer: tp,
+	}
+
+	for _, fn := range opts {
+		fn(h)
+	}
+
+	return h
+}
+
+// Upsert creates or updates one or more webhooks in the database.
+func (h *Handler) Upsert(ctx context.Context, webhooks ...*Webhook) error {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "webhook.upsert")
+	defer span.End()
+
+	var changed []*Webhook
+
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		var mErr error
+		changed = nil
+
+		for _, webhook := range webhooks {
+			if err := h.upsertWebhook(ctx, tx, webhook); err != nil {
+				if errors.Is(err, pgx.ErrTxClosed) {
+					return fmt.Errorf("insert aborted, tx cancelled: %w", err)
+				}
+
+				if core.IsRetryable(err) {
+					return err
+				}
+
+				observability.Metrics.TrackObjectError(entityWebhook, "upsert")
+				mErr = multierr.Append(mErr, err)
+			} else {
+				changed = append(changed, webhook)
+			}
+		}
+
+		// Earlier webhooks may have been written, so the whole transaction
+		// is rolled back.
+		return mErr
+	}); err != nil {
+		return err
+	}
+
+	for _, webhook := range changed {
+		h.logger.Info("tuple modified",
+			zap.String("action", "upsert"),
+			zap.String("entity", entityWebhook),
+			zap.Object("webhook", webhook),
+		)
+	}
+
+	observability.Metrics.TrackObjectsChanged(len(changed), entityWebhook, "upsert")
+
+	return nil
+}
+
+func (h *Handler) upsertWebhook(ctx context.Context, tx pgx.Tx, webhook *Webhook) error {
+	start := time.Now().UTC()
+
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				id,
+				created_at,
+				updated_at,
+				url,
+				secret,
+				entities,
+				actions
+			)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT
+			(id)
+		DO UPDATE SET
+			updated_at=$3,
+			url=$4,
+			secret=$5,
+			entities=$6,
+			actions=$7,
+			deleted_at=NULL`, core.TableWebhooks)
+
+	entities := webhook.Entities
+	if entities == nil {
+		entities = []string{}
+	}
+
+	actions := webhook.Actions
+	if actions == nil {
+		actions = []string{}
+	}
+
+	secret, err := h.seal(ctx, webhook.Secret)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, stmt,
+		webhook.ID,  // $1
+		start,       // $2
+		start,       // $3
+		webhook.URL, // $4
+		secret,      // $5
+		entities,    // $6
+		actions,     // $7
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get retrieves all Webhooks, or an ErrNotFound.
+func (h *Handler) Get(ctx context.Context) ([]*Webhook, error) {
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "webhook.get")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			id,
+			url,
+			secret,
+			entities,
+			actions
+		FROM
+			%q
+		WHERE
+			deleted_at IS NULL
+		ORDER BY
+			created_at`, core.TableWebhooks)
+
+	rows, err := h.conn.Query(spanCtx, stmt)
+	if err != nil {
+		observability.Metrics.TrackObjectError(entityWebhook, "get")
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	var webhooks []*Webhook
+
+	for rows.Next() {
+		var w Webhook
+
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &w.Entities, &w.Actions); err != nil {
+			span.RecordError(err)
+			observability.Metrics.TrackObjectError(entityWebhook, "get")
+			return nil, fmt.Errorf("scanning rows failed: %w", err)
+		}
+
+		if w.Secret, err = h.unseal(spanCtx, w.Secret); err != nil {
+			span.RecordError(err)
+			observability.Metrics.TrackObjectError(entityWebhook, "get")
+			return nil, fmt.Errorf("webhook %s: %w", w.ID, err)
+		}
+
+		webhooks = append(webhooks, &w)
+	}
+
+	if len(webhooks) == 0 {
+		return nil, core.ErrNotFound
+	}
+
+	observability.Metrics.TrackObjectsRetrieved(len(webhooks), entityWebhook)
+
+	return webhooks, nil
+}
+
+// Matching returns all Webhooks interested in action on entity.
+func (h *Handler) Matching(ctx context.Context, entity, action string) ([]*Webhook, error) {
+	webhooks, err := h.Get(ctx)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var matching []*Webhook
+	for _, w := range webhooks {
+		if w.Matches(entity, action) {
+			matching = append(matching, w)
+		}
+	}
+
+	return matching, nil
+}
+
+// DeleteByID deletes a Webhook by ID. Returns ErrNotFound if the Webhook
+// did not exist beforehand.
+func (h *Handler) DeleteByID(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return core.ErrInvalidUUID
+	}
+
+	spanCtx, span := h.tracer.Tracer(core.TracingInstrumentationName).Start(ctx, "webhook.delete")
+	defer span.End()
+
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%q
+		SET
+			deleted_at=$1,
+			updated_at=$1
+		WHERE
+			id=$2
+		RETURNING
+			id`, core.TableWebhooks)
+
+	// The statement writes, so it has to run in a transaction on the primary.
+	if err := core.RunInTx(spanCtx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		var deletedID string
+		if err := tx.QueryRow(ctx, stmt, conversion.TimeP(time.Now().UTC()), id).Scan(&deletedID); err != nil {
+			return err
+		}
+
+		// Deliveries of deleted webhooks are never attempted, so they're
+		// dropped.
+		_, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %q WHERE webhook_id=$1`, core.TableWebhookQueue), id)
+
+		return err
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return core.ErrNotFound
+		}
+
+		observability.Metrics.TrackObjectError(entityWebhook, "delete")
+		span.RecordError(err)
+		return err
+	}
+
+	observability.Metrics.TrackObjectsChanged(1, entityWebhook, "delete")
+	h.logger.Info("tuple modified",
+		zap.String("action", "delete"),
+		zap.String("entity", entityWebhook),
+		zap.String("id", id),
+	)
+
+	return nil
+}
+
+// LogDelivery records a delivery attempt.
+func (h *Handler) LogDelivery(ctx context.Context, d *Delivery) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				id,
+				delivery_id,
+				webhook_id,
+				entity,
+				action,
+				entity_id,
+				attempt,
+				status_code,
+				error,
+				succeeded,
+				created_at
+			)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, core.TableWebhookDeliveries)
+
+	var statusCode *int
+	if d.StatusCode != 0 {
+		statusCode = &d.StatusCode
+	}
+
+	var errMsg *string
+	if d.Error != "" {
+		errMsg = &d.Error
+	}
+
+	if _, err := h.conn.Exec(ctx, stmt,
+		d.ID,              // $1
+		d.DeliveryID,      // $2
+		d.WebhookID,       // $3
+		d.Entity,          // $4
+		d.Action,          // $5
+		d.EntityID,        // $6
+		d.Attempt,         // $7
+		statusCode,        // $8
+		errMsg,            // $9
+		d.Succeeded,       // $10
+		d.CreatedAt.UTC(), // $11
+	); err != nil {
+		observability.Metrics.TrackObjectError(entityWebhook, "log_delivery")
+		return fmt.Errorf("logging delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// Deliveries returns the most recent delivery attempts of a Webhook.
+func (h *Handler) Deliveries(ctx context.Context, webhookID string, limit int) ([]*Delivery, error) {
+	if _, err := uuid.Parse(webhookID); err != nil {
+		return nil, core.ErrInvalidUUID
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			id,
+			delivery_id,
+			webhook_id,
+			entity,
+			action,
+			entity_id,
+			attempt,
+			status_code,
+			error,
+			succeeded,
+			created_at
+		FROM
+			%q
+		WHERE
+			webhook_id=$1
+		ORDER BY
+			created_at DESC
+		LIMIT $2`, core.TableWebhookDeliveries)
+
+	rows, err := h.conn.Query(ctx, stmt, webhookID, limit)
+	if err != nil {
+		observability.Metrics.TrackObjectError(entityWebhook, "get_deliveries")
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	defer rows.Close()
+
+	var deliveries []*Delivery
+
+	for rows.Next() {
+		var (
+			d          Delivery
+			statusCode *int
+			errMsg     *string
+		)
+
+		if err := rows.Scan(
+			&d.ID,
+			&d.DeliveryID,
+			&d.WebhookID,
+			&d.Entity,
+			&d.Action,
+			&d.EntityID,
+			&d.Attempt,
+			&statusCode,
+			&errMsg,
+			&d.Succeeded,
+			&d.CreatedAt,
+		); err != nil {
+			observability.Metrics.TrackObjectError(entityWebhook, "get_deliveries")
+			return nil, fmt.Errorf("scanning rows failed: %w", err)
+		}
+
+		if statusCode != nil {
+			d.StatusCode = *statusCode
+		}
+
+		d.Error = conversion.String(errMsg)
+		d.CreatedAt = d.CreatedAt.UTC()
+
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, nil
+}
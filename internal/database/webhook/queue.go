@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// QueuedDelivery is a delivery waiting for its next attempt. Attempt is the
+// number of attempts made so far.
+type QueuedDelivery struct {
+	ID      string
+	Webhook *Webhook
+	Body    []byte
+	Attempt int
+}
+
+// Enqueue persists deliveries, which are due immediately. Either all or none
+// of them are queued.
+func (h *Handler) Enqueue(ctx context.Context, deliveries ...*QueuedDelivery) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %q
+			(
+				id,
+				webhook_id,
+				body,
+				attempt,
+				next_attempt_at,
+				created_at
+			)
+		VALUES
+			($1, $2, $3, 0, $4, $4)
+		ON CONFLICT
+			(id)
+		DO NOTHING`, core.TableWebhookQueue)
+
+	return core.RunInTx(ctx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		now := time.Now().UTC()
+
+		for _, d := range deliveries {
+			if _, err := tx.Exec(ctx, stmt, d.ID, d.Webhook.ID, d.Body, now); err != nil {
+				observability.Metrics.TrackObjectError(entityWebhook, "enqueue")
+				return fmt.Errorf("queueing delivery failed: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Due returns up to limit deliveries whose next attempt is due, and pushes
+// their next attempt back by lease. If the process dies during an attempt,
+// the delivery is attempted again once the lease is over.
+func (h *Handler) Due(ctx context.Context, limit int, lease time.Duration) ([]*QueuedDelivery, error) {
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%[1]q q
+		SET
+			next_attempt_at=$1
+		FROM
+			%[2]q w
+		WHERE
+			w.id=q.webhook_id
+			AND q.id IN (
+				SELECT
+					due.id
+				FROM
+					%[1]q due
+				JOIN
+					%[2]q active ON active.id=due.webhook_id
+				WHERE
+					due.next_attempt_at<=$2
+					AND active.deleted_at IS NULL
+				ORDER BY
+					due.next_attempt_at
+				LIMIT $3
+				FOR UPDATE OF due SKIP LOCKED
+			)
+		RETURNING
+			q.id,
+			q.body,
+			q.attempt,
+			w.id,
+			w.url,
+			w.secret`, core.TableWebhookQueue, core.TableWebhooks)
+
+	var due []*QueuedDelivery
+
+	// The statement writes, so it has to run on the primary.
+	if err := core.RunInTx(ctx, h.conn, h.logger, core.DefaultTxOptions, func(ctx context.Context, tx pgx.Tx) error {
+		due = nil
+		now := time.Now().UTC()
+
+		rows, err := tx.Query(ctx, stmt, now.Add(lease), now, limit)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			d := &QueuedDelivery{Webhook: &Webhook{}}
+
+			if err := rows.Scan(&d.ID, &d.Body, &d.Attempt, &d.Webhook.ID, &d.Webhook.URL, &d.Webhook.Secret); err != nil {
+				return fmt.Errorf("scanning rows failed: %w", err)
+			}
+
+			if d.Webhook.Secret, err = h.unseal(ctx, d.Webhook.Secret); err != nil {
+				return fmt.Errorf("webhook %s: %w", d.Webhook.ID, err)
+			}
+
+			due = append(due, d)
+		}
+
+		return rows.Err()
+	}); err != nil {
+		observability.Metrics.TrackObjectError(entityWebhook, "due")
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// Reschedule records that attempt failed and schedules the next one at at.
+func (h *Handler) Reschedule(ctx context.Context, id string, attempt int, at time.Time) error {
+	stmt := fmt.Sprintf(`
+		UPDATE
+			%q
+		SET
+			attempt=$1,
+			next_attempt_at=$2
+		WHERE
+			id=$3`, core.TableWebhookQueue)
+
+	if _, err := h.conn.Exec(ctx, stmt, attempt, at.UTC(), id); err != nil {
+		observability.Metrics.TrackObjectError(entityWebhook, "reschedule")
+		return fmt.Errorf("rescheduling delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// Dequeue removes a delivery which succeeded or won't be attempted again.
+func (h *Handler) Dequeue(ctx context.Context, id string) error {
+	stmt := fmt.Sprintf(`DELETE FROM %q WHERE id=$1`, core.TableWebhookQueue)
+
+	if _, err := h.conn.Exec(ctx, stmt, id); err != nil {
+		observability.Metrics.TrackObjectError(entityWebhook, "dequeue")
+		return fmt.Errorf("dequeueing delivery failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned for webhook URLs which point at loopback,
+// link-local, private or otherwise internal addresses.
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range, which IsPrivate doesn't
+// cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// CheckURL validates a webhook URL. Hosts which are IP addresses must be
+// public. Host names are resolved on delivery, which refuses to connect to
+// internal addresses.
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook URL %q", raw)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %q", ErrForbiddenTarget, raw)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && internal(ip) {
+		return fmt.Errorf("%w: %q", ErrForbiddenTarget, raw)
+	}
+
+	return nil
+}
+
+// internal returns true if ip is not a public unicast address.
+func internal(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// refuseInternal is a net.Dialer Control function which refuses connections
+// to internal addresses. It runs after host names are resolved, so it also
+// covers host names which resolve to internal addresses.
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || internal(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap/zapcore"
+)
+
+// Webhook is an HTTP endpoint which is notified about changes. Empty Entities
+// or Actions match every entity or action.
+type Webhook struct {
+	ID       string
+	URL      string
+	Secret   string
+	Entities []string
+	Actions  []string
+}
+
+// New returns a Webhook with an initialized ID.
+func New() *Webhook {
+	return &Webhook{ID: uuid.New().String()}
+}
+
+func (w Webhook) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", w.ID)
+	enc.AddString("url", w.URL)
+
+	return nil
+}
+
+// Matches returns true if the Webhook is interested in action on entity.
+func (w *Webhook) Matches(entity, action string) bool {
+	return matches(w.Entities, entity) && matches(w.Actions, action)
+}
+
+func matches(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	for _, f := range filter {
+		if f == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery is a single attempt to deliver a change to a Webhook.
+type Delivery struct {
+	ID         string
+	DeliveryID string
+	WebhookID  string
+	Entity     string
+	Action     string
+	EntityID   string
+	Attempt    int
+	StatusCode int
+	Error      string
+	Succeeded  bool
+	CreatedAt  time.Time
+}
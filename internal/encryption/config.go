@@ -0,0 +1,52 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/obitech/artist-db/internal/config"
+)
+
+// EnvKeys is the environment variable the env provider reads keys from.
+const EnvKeys = "ADB_ENCRYPTION_KEYS"
+
+// NewFromConfig returns an Encrypter for cfg, or nil if encryption is
+// disabled.
+func NewFromConfig(cfg config.EncryptionConfig) (*Encrypter, error) {
+	var (
+		keys KeyProvider
+		err  error
+	)
+
+	switch cfg.Provider {
+	case "", "none":
+		return nil, nil
+	case "file":
+		keys, err = LoadKeyFile(cfg.KeyFile)
+	case "env":
+		keys, err = KeysFromEnv(EnvKeys)
+	case "kms":
+		if cfg.KMSKeyID == "" {
+			return nil, errors.New("KMS key ID is not set")
+		}
+
+		var local *Keyring
+		if local, err = LoadKeyFile(cfg.KeyFile); err == nil {
+			keys = NewKMSKeyProvider(NewLocalKMS(local), cfg.KMSKeyID)
+		}
+	default:
+		return nil, fmt.Errorf("unknown key provider %q", cfg.Provider)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("loading keys: %w", err)
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(cfg.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding index key: %w", err)
+	}
+
+	return New(keys, indexKey)
+}
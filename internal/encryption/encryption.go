@@ -0,0 +1,156 @@
+// Package encryption implements envelope encryption of single values. Every
+// value is encrypted with its own data key, which is wrapped by a key
+// encryption key of a KeyProvider and stored next to the value.
+package encryption
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks encrypted values, which are
+// enc:v1:<key ID>:<base64 wrapped data key>:<base64 ciphertext>.
+const prefix = "enc:v1:"
+
+// ErrInvalidCiphertext is returned for encrypted values which are malformed
+// or were tampered with.
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Encrypter encrypts and decrypts values and computes blind indexes.
+type Encrypter struct {
+	keys     KeyProvider
+	indexKey []byte
+}
+
+// New returns an Encrypter. indexKey keys the blind index and must be kept
+// as secret as the key encryption keys.
+func New(keys KeyProvider, indexKey []byte) (*Encrypter, error) {
+	if keys == nil {
+		return nil, errors.New("key provider is nil")
+	}
+
+	if len(indexKey) < KeySize {
+		return nil, fmt.Errorf("index key has %d bytes, want at least %d", len(indexKey), KeySize)
+	}
+
+	return &Encrypter{keys: keys, indexKey: indexKey}, nil
+}
+
+// Encrypt encrypts plaintext with a new data key. The ciphertext is bound to
+// field, e.g. the column it is stored in, and only decrypts for the same
+// field.
+func (e *Encrypter) Encrypt(ctx context.Context, field, plaintext string) (string, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("generating data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(aead, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", err
+	}
+
+	keyID, wrapped, err := e.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("wrapping data key: %w", err)
+	}
+
+	return prefix + strings.Join([]string{
+		keyID,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt for the same field. Values
+// which are not encrypted are returned as they are.
+func (e *Encrypter) Decrypt(ctx context.Context, field, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, wrapped, ciphertext, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := e.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("unwrapping data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(aead, ciphertext, []byte(field))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Current returns true if value is encrypted with the current key encryption
+// key, so it needs no re-encryption.
+func (e *Encrypter) Current(value string) bool {
+	if !IsEncrypted(value) {
+		return false
+	}
+
+	keyID, _, _, err := parse(value)
+
+	return err == nil && keyID == e.keys.CurrentKeyID()
+}
+
+// BlindIndex returns a keyed hash of s, which allows to look up encrypted
+// values by equality without decrypting them. s is compared case-insensitive.
+func (e *Encrypter) BlindIndex(s string) string {
+	mac := hmac.New(sha256.New, e.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(s))))
+
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted returns true if value was returned by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// parse splits an encrypted value. Key IDs may contain colons, e.g. KMS
+// ARNs, so the value is split from the right.
+func parse(value string) (keyID string, wrapped, ciphertext []byte, err error) {
+	rest := strings.TrimPrefix(value, prefix)
+
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	j := strings.LastIndex(rest[:i], ":")
+	if j <= 0 {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	if wrapped, err = base64.RawStdEncoding.DecodeString(rest[j+1 : i]); err != nil {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	if ciphertext, err = base64.RawStdEncoding.DecodeString(rest[i+1:]); err != nil {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	return rest[:j], wrapped, ciphertext, nil
+}
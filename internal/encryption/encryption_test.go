@@ -0,0 +1,142 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func testKeyring(t *testing.T, ids ...string) *Keyring {
+	k := NewKeyring()
+	for i, id := range ids {
+		require.NoError(t, k.Add(id, testKey(byte(i+1))))
+	}
+
+	return k
+}
+
+func TestEncrypter(t *testing.T) {
+	ctx := context.Background()
+
+	enc, err := New(testKeyring(t, "k1"), testKey(9))
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		ciphertext, err := enc.Encrypt(ctx, "email", "foo@bar.com")
+		require.NoError(t, err)
+
+		assert.True(t, IsEncrypted(ciphertext))
+		assert.NotContains(t, ciphertext, "foo@bar.com")
+		assert.True(t, enc.Current(ciphertext))
+
+		plaintext, err := enc.Decrypt(ctx, "email", ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "foo@bar.com", plaintext)
+	})
+
+	t.Run("every value has its own data key", func(t *testing.T) {
+		a, err := enc.Encrypt(ctx, "email", "foo@bar.com")
+		require.NoError(t, err)
+
+		b, err := enc.Encrypt(ctx, "email", "foo@bar.com")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("plaintext is returned as is", func(t *testing.T) {
+		plaintext, err := enc.Decrypt(ctx, "email", "foo@bar.com")
+		require.NoError(t, err)
+		assert.Equal(t, "foo@bar.com", plaintext)
+		assert.False(t, enc.Current("foo@bar.com"))
+	})
+
+	t.Run("ciphertext is bound to its field", func(t *testing.T) {
+		ciphertext, err := enc.Encrypt(ctx, "email", "foo@bar.com")
+		require.NoError(t, err)
+
+		_, err = enc.Decrypt(ctx, "place_of_birth", ciphertext)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("tampering is detected", func(t *testing.T) {
+		ciphertext, err := enc.Encrypt(ctx, "email", "foo@bar.com")
+		require.NoError(t, err)
+
+		i := strings.LastIndex(ciphertext, ":")
+		raw, err := base64.RawStdEncoding.DecodeString(ciphertext[i+1:])
+		require.NoError(t, err)
+
+		raw[len(raw)-1] ^= 1
+		tampered := ciphertext[:i+1] + base64.RawStdEncoding.EncodeToString(raw)
+
+		_, err = enc.Decrypt(ctx, "email", tampered)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := enc.Decrypt(ctx, "email", prefix+"k1:abc")
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("blind index ignores case", func(t *testing.T) {
+		assert.Equal(t, enc.BlindIndex("Foo@Bar.com "), enc.BlindIndex("foo@bar.com"))
+		assert.NotEqual(t, enc.BlindIndex("foo@bar.com"), enc.BlindIndex("bar@foo.com"))
+	})
+
+	t.Run("index key is required", func(t *testing.T) {
+		_, err := New(testKeyring(t, "k1"), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestEncrypter_rotation(t *testing.T) {
+	ctx := context.Background()
+
+	old, err := New(testKeyring(t, "k1"), testKey(9))
+	require.NoError(t, err)
+
+	ciphertext, err := old.Encrypt(ctx, "email", "foo@bar.com")
+	require.NoError(t, err)
+
+	rotated, err := New(testKeyring(t, "k1", "k2"), testKey(9))
+	require.NoError(t, err)
+
+	assert.False(t, rotated.Current(ciphertext))
+
+	plaintext, err := rotated.Decrypt(ctx, "email", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "foo@bar.com", plaintext)
+
+	ciphertext, err = rotated.Encrypt(ctx, "email", plaintext)
+	require.NoError(t, err)
+	assert.True(t, rotated.Current(ciphertext))
+
+	_, err = old.Decrypt(ctx, "email", ciphertext)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	keyID := "arn:aws:kms:eu-central-1:123456789012:key/artist-db"
+
+	enc, err := New(NewKMSKeyProvider(NewLocalKMS(testKeyring(t, keyID)), keyID), testKey(9))
+	require.NoError(t, err)
+
+	ciphertext, err := enc.Encrypt(ctx, "email", "foo@bar.com")
+	require.NoError(t, err)
+	assert.True(t, enc.Current(ciphertext))
+
+	plaintext, err := enc.Decrypt(ctx, "email", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "foo@bar.com", plaintext)
+}
@@ -0,0 +1,307 @@
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// KeySize is the size of all keys in bytes, for AES-256.
+const KeySize = 32
+
+// ErrUnknownKey is returned when data was encrypted with a key which is not
+// available.
+var ErrUnknownKey = errors.New("unknown key")
+
+// KeyProvider wraps and unwraps data keys with key encryption keys, which
+// never leave the provider.
+type KeyProvider interface {
+	// WrapKey encrypts a data key with the current key encryption key and
+	// returns the ID of that key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped with the key encryption key
+	// keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+	// CurrentKeyID returns the ID of the key WrapKey uses.
+	CurrentKeyID() string
+}
+
+// Keyring is a KeyProvider holding key encryption keys in memory. The last
+// key added is current, older keys are kept to decrypt existing data.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	current string
+}
+
+// NewKeyring returns an empty Keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]cipher.AEAD)}
+}
+
+// Add adds a key and makes it current.
+func (k *Keyring) Add(id string, key []byte) error {
+	if id == "" || strings.ContainsAny(id, ", \t") {
+		return fmt.Errorf("invalid key ID %q", id)
+	}
+
+	if len(key) != KeySize {
+		return fmt.Errorf("key %q has %d bytes, want %d", id, len(key), KeySize)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	k.keys[id] = aead
+	k.current = id
+
+	return nil
+}
+
+// ParseKeys returns a Keyring of comma-separated keys in the form
+// <id>:<base64 key>. The last key is current.
+func ParseKeys(s string) (*Keyring, error) {
+	k := NewKeyring()
+
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		if err := k.parseKey(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if k.current == "" {
+		return nil, errors.New("no keys")
+	}
+
+	return k, nil
+}
+
+// KeysFromEnv returns a Keyring of the keys in the environment variable name,
+// in the format of ParseKeys.
+func KeysFromEnv(name string) (*Keyring, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("%s is not set", name)
+	}
+
+	k, err := ParseKeys(s)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return k, nil
+}
+
+// LoadKeyFile returns a Keyring of the keys in a file, one <id>:<base64 key>
+// per line. Lines starting with # are ignored and the last key is current.
+func LoadKeyFile(path string) (*Keyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening key file: %w", err)
+	}
+
+	defer f.Close()
+
+	k, err := ReadKeys(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return k, nil
+}
+
+// ReadKeys reads keys in the format of LoadKeyFile.
+func ReadKeys(r io.Reader) (*Keyring, error) {
+	var (
+		k       = NewKeyring()
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if err := k.parseKey(entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if k.current == "" {
+		return nil, errors.New("no keys")
+	}
+
+	return k, nil
+}
+
+func (k *Keyring) parseKey(entry string) error {
+	// IDs may contain colons, base64 doesn't.
+	i := strings.LastIndex(entry, ":")
+	if i < 0 {
+		return errors.New("key must be <id>:<base64 key>")
+	}
+
+	id := entry[:i]
+
+	key, err := base64.StdEncoding.DecodeString(entry[i+1:])
+	if err != nil {
+		return fmt.Errorf("decoding key %q: %w", id, err)
+	}
+
+	return k.Add(id, key)
+}
+
+// CurrentKeyID implements KeyProvider.
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// WrapKey implements KeyProvider.
+func (k *Keyring) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	if k.current == "" {
+		return "", nil, errors.New("no keys")
+	}
+
+	wrapped, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return k.current, wrapped, nil
+}
+
+// UnwrapKey implements KeyProvider.
+func (k *Keyring) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	return open(aead, wrapped, []byte(keyID))
+}
+
+// KMS is the subset of a key management service API used for envelope
+// encryption, as offered by e.g. AWS KMS or Google Cloud KMS.
+type KMS interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KMSKeyProvider is a KeyProvider whose key encryption keys are held by a
+// KMS. Keys rotated within the KMS keep their ID.
+type KMSKeyProvider struct {
+	kms   KMS
+	keyID string
+}
+
+// NewKMSKeyProvider returns a KMSKeyProvider which wraps data keys with
+// keyID.
+func NewKMSKeyProvider(kms KMS, keyID string) *KMSKeyProvider {
+	return &KMSKeyProvider{kms: kms, keyID: keyID}
+}
+
+// CurrentKeyID implements KeyProvider.
+func (p *KMSKeyProvider) CurrentKeyID() string {
+	return p.keyID
+}
+
+// WrapKey implements KeyProvider.
+func (p *KMSKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := p.kms.Encrypt(ctx, p.keyID, dataKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("kms encrypt: %w", err)
+	}
+
+	return p.keyID, wrapped, nil
+}
+
+// UnwrapKey implements KeyProvider.
+func (p *KMSKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	dataKey, err := p.kms.Decrypt(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+// LocalKMS is a KMS stand-in for development and tests, backed by a Keyring.
+type LocalKMS struct {
+	keys *Keyring
+}
+
+// NewLocalKMS returns a LocalKMS.
+func NewLocalKMS(keys *Keyring) *LocalKMS {
+	return &LocalKMS{keys: keys}
+}
+
+// Encrypt implements KMS.
+func (l *LocalKMS) Encrypt(_ context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	aead, ok := l.keys.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	return seal(aead, plaintext, []byte(keyID))
+}
+
+// Decrypt implements KMS.
+func (l *LocalKMS) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	return l.keys.UnwrapKey(ctx, keyID, ciphertext)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext and prepends a random nonce.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open reverses seal.
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	n := aead.NonceSize()
+
+	plaintext, err := aead.Open(nil, ciphertext[:n], ciphertext[n:], additionalData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
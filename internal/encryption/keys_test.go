@@ -0,0 +1,64 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+
+	t.Run("last key is current", func(t *testing.T) {
+		k, err := ParseKeys("k1:" + k1 + ", k2:" + k2)
+		require.NoError(t, err)
+
+		assert.Equal(t, "k2", k.CurrentKeyID())
+		assert.Len(t, k.keys, 2)
+	})
+
+	t.Run("IDs may contain colons", func(t *testing.T) {
+		k, err := ParseKeys("arn:aws:kms:key/1:" + k1)
+		require.NoError(t, err)
+
+		assert.Equal(t, "arn:aws:kms:key/1", k.CurrentKeyID())
+	})
+
+	for _, tc := range []struct {
+		name string
+		keys string
+	}{
+		{name: "empty", keys: " , "},
+		{name: "missing ID", keys: k1},
+		{name: "invalid base64", keys: "k1:not base64"},
+		{name: "short key", keys: "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseKeys(tc.keys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	file := strings.Join([]string{
+		"# rotated 2026-10-01",
+		"k1:" + base64.StdEncoding.EncodeToString(testKey(1)),
+		"",
+		"k2:" + base64.StdEncoding.EncodeToString(testKey(2)),
+	}, "\n")
+
+	k, err := ReadKeys(strings.NewReader(file))
+	require.NoError(t, err)
+
+	assert.Equal(t, "k2", k.CurrentKeyID())
+	assert.Len(t, k.keys, 2)
+
+	_, err = ReadKeys(strings.NewReader("# no keys\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Package money handles amounts of money in multiple currencies.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// zeroDecimalCurrencies have no minor units.
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true, "KMF": true, "KRW": true,
+	"PYG": true, "RWF": true, "UGX": true, "VND": true, "VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+// threeDecimalCurrencies have a thousandth as minor unit.
+var threeDecimalCurrencies = map[string]bool{
+	"BHD": true, "IQD": true, "JOD": true, "KWD": true, "LYD": true, "OMR": true, "TND": true,
+}
+
+// Money is an amount in minor units of an ISO 4217 currency, e.g. cents.
+// Unlike Postgres' MONEY, it doesn't depend on the locale.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New returns an amount in minor units of currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// IsCurrency reports whether code looks like an ISO 4217 currency code.
+func IsCurrency(code string) bool {
+	return currencyPattern.MatchString(code)
+}
+
+// Exponent returns the number of decimal places of the minor unit of
+// currency.
+func Exponent(currency string) int {
+	switch {
+	case zeroDecimalCurrencies[currency]:
+		return 0
+	case threeDecimalCurrencies[currency]:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// Parse parses an amount in major units followed by the currency, e.g.
+// "10.50 EUR".
+func Parse(s string) (Money, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Money{}, fmt.Errorf("invalid amount %q: expected amount and currency", s)
+	}
+
+	amount, currency := fields[0], strings.ToUpper(fields[1])
+	if !IsCurrency(currency) {
+		return Money{}, fmt.Errorf("invalid currency %q", fields[1])
+	}
+
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(amount, "-")
+
+	whole, frac := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		whole, frac = amount[:i], amount[i+1:]
+	}
+
+	exp := Exponent(currency)
+	if len(frac) > exp {
+		return Money{}, fmt.Errorf("invalid amount %q: %s has %d decimal places", s, currency, exp)
+	}
+
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+	if whole == "" || strings.ContainsAny(digits, "+-") {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return New(minor, currency), nil
+}
+
+// Decimal formats the amount in major units without the currency, e.g. 10.50.
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+
+	sign, minor := "", m.Amount
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+
+	s := strconv.FormatInt(minor, 10)
+	if exp == 0 {
+		return sign + s
+	}
+
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// String formats the amount in major units followed by the currency, e.g.
+// "10.50 EUR". It is the inverse of Parse.
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// MarshalGQL writes the amount as GraphQL string.
+func (m Money) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(m.String()))
+}
+
+// UnmarshalGQL parses an amount from a GraphQL string.
+func (m *Money) UnmarshalGQL(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return errors.New("money must be a string, e.g. \"10.50 EUR\"")
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
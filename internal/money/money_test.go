@@ -0,0 +1,47 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]Money{
+		"10.50 EUR":  {Amount: 1050, Currency: "EUR"},
+		"10 eur":     {Amount: 1000, Currency: "EUR"},
+		"0.5 USD":    {Amount: 50, Currency: "USD"},
+		"-3.20 GBP":  {Amount: -320, Currency: "GBP"},
+		"1000 JPY":   {Amount: 1000, Currency: "JPY"},
+		"1.234 KWD":  {Amount: 1234, Currency: "KWD"},
+		"  7.00 CHF": {Amount: 700, Currency: "CHF"},
+	} {
+		got, err := Parse(in)
+		require.NoError(t, err, in)
+
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "10.50", "EUR 10.50", "10.505 EUR", "1.5 JPY", "1.2.3 EUR", "- EUR", "--1 EUR", "10,50 EUR", "10 EURO"} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	for want, m := range map[string]Money{
+		"0.00 EUR":    New(0, "EUR"),
+		"0.05 EUR":    New(5, "EUR"),
+		"1234.56 USD": New(123456, "USD"),
+		"-10.50 EUR":  New(-1050, "EUR"),
+		"1000 JPY":    New(1000, "JPY"),
+		"0.007 BHD":   New(7, "BHD"),
+	} {
+		assert.Equal(t, want, m.String())
+
+		parsed, err := Parse(want)
+		require.NoError(t, err)
+		assert.Equal(t, m, parsed)
+	}
+}
@@ -0,0 +1,211 @@
+package money
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+// ErrNoRate is returned if no exchange rate is known for a currency and date.
+var ErrNoRate = errors.New("no exchange rate")
+
+type datedRate struct {
+	date time.Time
+	rate *big.Rat
+}
+
+// Rates is a table of dated exchange rates against a base currency, e.g. the
+// reference rates of the European Central Bank. A rate is valid from its date
+// until the next one.
+type Rates struct {
+	base  string
+	rates map[string][]datedRate
+}
+
+// NewRates returns an empty table with rates against base.
+func NewRates(base string) (*Rates, error) {
+	if !IsCurrency(base) {
+		return nil, fmt.Errorf("invalid base currency %q", base)
+	}
+
+	return &Rates{base: base, rates: make(map[string][]datedRate)}, nil
+}
+
+// LoadRates reads a table from a CSV file with the columns date, currency and
+// rate, where rate is the amount of currency one unit of base buys on date:
+//
+//	date,currency,rate
+//	2023-01-02,USD,1.0679
+//
+// Lines starting with # are ignored.
+func LoadRates(path, base string) (*Rates, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening exchange rates: %w", err)
+	}
+
+	defer f.Close()
+
+	return ReadRates(f, base)
+}
+
+// ReadRates reads a table in the format of LoadRates.
+func ReadRates(r io.Reader, base string) (*Rates, error) {
+	rates, err := NewRates(base)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange rates: %w", err)
+	}
+
+	for i, rec := range records {
+		if i == 0 && strings.EqualFold(rec[0], "date") {
+			continue
+		}
+
+		date, err := time.Parse(dateFormat, rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date: %w", i+1, err)
+		}
+
+		rate, ok := new(big.Rat).SetString(rec[2])
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("line %d: invalid rate %q", i+1, rec[2])
+		}
+
+		if err := rates.Add(date, rec[1], rate); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	return rates, nil
+}
+
+// Base returns the currency all rates are against.
+func (r *Rates) Base() string {
+	return r.base
+}
+
+// Add adds the rate of currency against the base, valid from date. An
+// existing rate of the same date is replaced.
+func (r *Rates) Add(date time.Time, currency string, rate *big.Rat) error {
+	if !IsCurrency(currency) {
+		return fmt.Errorf("invalid currency %q", currency)
+	}
+
+	if rate.Sign() <= 0 {
+		return fmt.Errorf("invalid rate %s", rate.RatString())
+	}
+
+	date = truncateDay(date)
+	rates := r.rates[currency]
+
+	i := sort.Search(len(rates), func(i int) bool { return !rates[i].date.Before(date) })
+	if i < len(rates) && rates[i].date.Equal(date) {
+		rates[i].rate = rate
+		return nil
+	}
+
+	rates = append(rates, datedRate{})
+	copy(rates[i+1:], rates[i:])
+	rates[i] = datedRate{date: date, rate: rate}
+	r.rates[currency] = rates
+
+	return nil
+}
+
+// rate returns how much of currency one unit of the base buys at t.
+func (r *Rates) rate(currency string, t time.Time) (*big.Rat, error) {
+	if currency == r.base {
+		return big.NewRat(1, 1), nil
+	}
+
+	t = truncateDay(t)
+	rates := r.rates[currency]
+
+	// The first rate after t, the one before is valid.
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].date.After(t) })
+	if i == 0 {
+		return nil, fmt.Errorf("%w for %s on %s", ErrNoRate, currency, t.Format(dateFormat))
+	}
+
+	return rates[i-1].rate, nil
+}
+
+// Convert converts m to the currency to at the rates valid at t. The result
+// is rounded half away from zero to the minor unit of to.
+func (r *Rates) Convert(m Money, to string, t time.Time) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	if r == nil {
+		return Money{}, fmt.Errorf("%w for %s: no exchange rates configured", ErrNoRate, m.Currency)
+	}
+
+	from, err := r.rate(m.Currency, t)
+	if err != nil {
+		return Money{}, err
+	}
+
+	into, err := r.rate(to, t)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// minor(to) = minor(from) / 10^exp(from) / rate(from) * rate(to) * 10^exp(to)
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, into)
+	v.Quo(v, from)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(Exponent(to)), pow10(Exponent(m.Currency))))
+
+	amount, err := round(v)
+	if err != nil {
+		return Money{}, fmt.Errorf("converting %s to %s: %w", m, to, err)
+	}
+
+	return New(amount, to), nil
+}
+
+// round rounds v half away from zero.
+func round(v *big.Rat) (int64, error) {
+	num := new(big.Int).Abs(v.Num())
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, v.Denom())
+
+	q := num.Quo(num, new(big.Int).Mul(v.Denom(), big.NewInt(2)))
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+
+	if !q.IsInt64() {
+		return 0, errors.New("amount out of range")
+	}
+
+	return q.Int64(), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,82 @@
+package money
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRates = `# 1 EUR in currency
+date,currency,rate
+2023-01-02,USD,1.0679
+2023-01-02,JPY,141.30
+2023-02-01,USD,1.0900
+`
+
+func TestRates_Convert(t *testing.T) {
+	rates, err := ReadRates(strings.NewReader(testRates), "EUR")
+	require.NoError(t, err)
+
+	jan := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+	feb := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		in   Money
+		to   string
+		at   time.Time
+		want Money
+	}{
+		"same currency":        {New(1050, "GBP"), "GBP", jan, New(1050, "GBP")},
+		"from base":            {New(10000, "EUR"), "USD", jan, New(10679, "USD")},
+		"to base":              {New(10679, "USD"), "EUR", jan, New(10000, "EUR")},
+		"later rate is used":   {New(10000, "EUR"), "USD", feb, New(10900, "USD")},
+		"between currencies":   {New(10000, "USD"), "JPY", jan, New(13232, "JPY")},
+		"negative amount":      {New(-10000, "EUR"), "USD", jan, New(-10679, "USD")},
+		"rounds half away":     {New(1, "JPY"), "EUR", jan, New(1, "EUR")},
+		"zero decimal to base": {New(14130, "JPY"), "EUR", jan, New(10000, "EUR")},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			got, err := rates.Convert(tc.in, tc.to, tc.at)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("unknown currency", func(t *testing.T) {
+		_, err := rates.Convert(New(100, "EUR"), "CHF", jan)
+		require.ErrorIs(t, err, ErrNoRate)
+	})
+
+	t.Run("before first rate", func(t *testing.T) {
+		_, err := rates.Convert(New(100, "EUR"), "USD", jan.AddDate(-1, 0, 0))
+		require.ErrorIs(t, err, ErrNoRate)
+	})
+
+	t.Run("no rates configured", func(t *testing.T) {
+		var none *Rates
+
+		_, err := none.Convert(New(100, "EUR"), "USD", jan)
+		require.ErrorIs(t, err, ErrNoRate)
+	})
+}
+
+func TestReadRates(t *testing.T) {
+	for name, in := range map[string]string{
+		"invalid date":     "2023-13-01,USD,1.1\n",
+		"invalid currency": "2023-01-01,usd,1.1\n",
+		"invalid rate":     "2023-01-01,USD,abc\n",
+		"zero rate":        "2023-01-01,USD,0\n",
+		"missing column":   "2023-01-01,USD\n",
+	} {
+		_, err := ReadRates(strings.NewReader(in), "EUR")
+		assert.Error(t, err, name)
+	}
+
+	_, err := ReadRates(strings.NewReader(testRates), "euro")
+	assert.Error(t, err)
+}
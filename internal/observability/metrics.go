@@ -1,8 +1,10 @@
 package observability
 
 import (
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 
@@ -10,13 +12,19 @@ import (
 )
 
 const (
-	subSystemDB     = "database"
-	subSystemServer = "server"
+	subSystemDB      = "database"
+	subSystemServer  = "server"
+	subSystemGraphQL = "graphql"
+	subSystemOutbox  = "outbox"
+	subSystemGRPC    = "grpc"
 )
 
 var (
+	dbCommandLabels   = []string{"target", "command"}
+	dbPoolLabels      = []string{"target"}
 	serverLabels      = []string{"method", "route", "code"}
 	serverSizeBuckets = []float64{50, 150, 300, 800, 1_200, 5_000, 8_000, 10_000, 20_000}
+	grpcLabels        = []string{"service", "method", "code"}
 )
 
 func init() {
@@ -54,16 +62,58 @@ type collector struct {
 	serverRequestDurations *prometheus.HistogramVec
 	serverRequestSize      *prometheus.HistogramVec
 	serverResponseSize     *prometheus.HistogramVec
+	serverRateLimited      prometheus.Counter
+
+	graphQLTimeouts           *prometheus.CounterVec
+	graphQLRejectedOperations *prometheus.CounterVec
 
 	dbObjectsChanged   *prometheus.CounterVec
 	dbObjectsRetrieved *prometheus.CounterVec
 	dbObjectErrors     *prometheus.CounterVec
+	dbTxRetries        *prometheus.CounterVec
+
+	outboxDispatched   prometheus.Counter
+	outboxSinkFailures *prometheus.CounterVec
+
+	grpcRequestDurations *prometheus.HistogramVec
+	grpcStreamMsgsSent   *prometheus.CounterVec
+
+	poolStatsMu sync.RWMutex
+	poolStats   map[string]func() *pgxpool.Stat
+
+	dbPoolAcquiredConns        *prometheus.Desc
+	dbPoolIdleConns            *prometheus.Desc
+	dbPoolTotalConns           *prometheus.Desc
+	dbPoolMaxConns             *prometheus.Desc
+	dbPoolAcquireCount         *prometheus.Desc
+	dbPoolAcquireDuration      *prometheus.Desc
+	dbPoolCanceledAcquireCount *prometheus.Desc
+	dbPoolEmptyAcquireCount    *prometheus.Desc
 }
 
 func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	// Pool statistics are only collected once a pool has been registered, so
+	// they have to be described explicitly.
+	for _, desc := range c.poolDescs() {
+		ch <- desc
+	}
+
 	prometheus.DescribeByCollect(c, ch)
 }
 
+func (c *collector) poolDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.dbPoolAcquiredConns,
+		c.dbPoolIdleConns,
+		c.dbPoolTotalConns,
+		c.dbPoolMaxConns,
+		c.dbPoolAcquireCount,
+		c.dbPoolAcquireDuration,
+		c.dbPoolCanceledAcquireCount,
+		c.dbPoolEmptyAcquireCount,
+	}
+}
+
 func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	c.dbCommandDuration.Collect(ch)
 	c.dbCommandErrors.Collect(ch)
@@ -71,28 +121,62 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	c.serverRequestDurations.Collect(ch)
 	c.serverRequestSize.Collect(ch)
 	c.serverResponseSize.Collect(ch)
+	c.serverRateLimited.Collect(ch)
+
+	c.graphQLTimeouts.Collect(ch)
+	c.graphQLRejectedOperations.Collect(ch)
 
 	c.dbObjectsChanged.Collect(ch)
 	c.dbObjectsRetrieved.Collect(ch)
 	c.dbObjectErrors.Collect(ch)
+	c.dbTxRetries.Collect(ch)
+
+	c.outboxDispatched.Collect(ch)
+	c.outboxSinkFailures.Collect(ch)
+
+	c.grpcRequestDurations.Collect(ch)
+	c.grpcStreamMsgsSent.Collect(ch)
+
+	c.collectPoolStats(ch)
+}
+
+func (c *collector) collectPoolStats(ch chan<- prometheus.Metric) {
+	c.poolStatsMu.RLock()
+	defer c.poolStatsMu.RUnlock()
+
+	for target, fn := range c.poolStats {
+		c.collectPoolStat(ch, target, fn())
+	}
+}
+
+func (c *collector) collectPoolStat(ch chan<- prometheus.Metric, target string, stat *pgxpool.Stat) {
+	ch <- prometheus.MustNewConstMetric(c.dbPoolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolAcquireCount, prometheus.CounterValue, float64(stat.AcquireCount()), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolAcquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds(), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolCanceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()), target)
+	ch <- prometheus.MustNewConstMetric(c.dbPoolEmptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()), target)
 }
 
 func newCollector() *collector {
 	return &collector{
+		poolStats: make(map[string]func() *pgxpool.Stat),
 		dbCommandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: internal.Name,
 			Subsystem: subSystemDB,
 			Name:      "command_duration_seconds",
 			Help:      "Observation of command durations against the database.",
 			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
-		}, []string{"command"}),
+		}, dbCommandLabels),
 		dbCommandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   internal.Name,
 			Subsystem:   subSystemDB,
 			Name:        "command_errors_total",
 			Help:        "Total number of errors that occurred from DB commands.",
 			ConstLabels: nil,
-		}, []string{"command"}),
+		}, dbCommandLabels),
 		serverRequestDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: internal.Name,
 			Subsystem: subSystemServer,
@@ -113,6 +197,24 @@ func newCollector() *collector {
 			Help:      "Size of HTTP responses.",
 			Buckets:   serverSizeBuckets,
 		}, serverLabels),
+		serverRateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemServer,
+			Name:      "rate_limited_requests_total",
+			Help:      "Total number of HTTP requests rejected for exceeding the rate limit.",
+		}),
+		graphQLTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemGraphQL,
+			Name:      "timeouts_total",
+			Help:      "Total number of GraphQL operations that ran into a timeout.",
+		}, []string{"source"}),
+		graphQLRejectedOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemGraphQL,
+			Name:      "rejected_operations_total",
+			Help:      "Total number of GraphQL operations rejected before execution.",
+		}, []string{"reason"}),
 		dbObjectsChanged: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   internal.Name,
 			Subsystem:   subSystemDB,
@@ -134,11 +236,99 @@ func newCollector() *collector {
 			Help:        "Total number of errors that occurred during interacting with objects",
 			ConstLabels: nil,
 		}, []string{"entity", "operation"}),
+		dbTxRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemDB,
+			Name:      "tx_retries_total",
+			Help:      "Total number of transactions that were retried",
+		}, []string{"reason"}),
+		outboxDispatched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemOutbox,
+			Name:      "dispatched_total",
+			Help:      "Total number of outbox entries published to all sinks.",
+		}),
+		outboxSinkFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemOutbox,
+			Name:      "sink_failures_total",
+			Help:      "Total number of failed attempts to publish outbox entries to a sink.",
+		}, []string{"sink"}),
+		grpcRequestDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemGRPC,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of gRPC calls. Streams are observed once they end.",
+		}, grpcLabels),
+		grpcStreamMsgsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: internal.Name,
+			Subsystem: subSystemGRPC,
+			Name:      "stream_messages_sent_total",
+			Help:      "Total number of messages sent on server streams.",
+		}, []string{"service", "method"}),
+		dbPoolAcquiredConns: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_acquired_connections"),
+			"Number of currently acquired connections in the pool.",
+			dbPoolLabels, nil,
+		),
+		dbPoolIdleConns: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_idle_connections"),
+			"Number of currently idle connections in the pool.",
+			dbPoolLabels, nil,
+		),
+		dbPoolTotalConns: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_total_connections"),
+			"Total number of connections currently in the pool.",
+			dbPoolLabels, nil,
+		),
+		dbPoolMaxConns: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_max_connections"),
+			"Maximum size of the pool.",
+			dbPoolLabels, nil,
+		),
+		dbPoolAcquireCount: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_acquires_total"),
+			"Total number of successful acquires from the pool.",
+			dbPoolLabels, nil,
+		),
+		dbPoolAcquireDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_acquire_duration_seconds_total"),
+			"Total time spent waiting for successful acquires from the pool.",
+			dbPoolLabels, nil,
+		),
+		dbPoolCanceledAcquireCount: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_canceled_acquires_total"),
+			"Total number of acquires from the pool that were canceled by a context.",
+			dbPoolLabels, nil,
+		),
+		dbPoolEmptyAcquireCount: prometheus.NewDesc(
+			prometheus.BuildFQName(internal.Name, subSystemDB, "pool_empty_acquires_total"),
+			"Total number of acquires that had to wait for a connection because the pool was empty.",
+			dbPoolLabels, nil,
+		),
 	}
 }
 
-func (c *collector) ObserveCommandDuration(commandName string, duration time.Duration) {
-	c.dbCommandDuration.WithLabelValues(commandName).Observe(duration.Seconds())
+// RegisterPoolStats sets the source for connection pool statistics of a
+// database target. A previously registered source is replaced.
+func (c *collector) RegisterPoolStats(target string, fn func() *pgxpool.Stat) {
+	c.poolStatsMu.Lock()
+	defer c.poolStatsMu.Unlock()
+
+	c.poolStats[target] = fn
+}
+
+// UnregisterPoolStats removes the source for connection pool statistics of a
+// database target.
+func (c *collector) UnregisterPoolStats(target string) {
+	c.poolStatsMu.Lock()
+	defer c.poolStatsMu.Unlock()
+
+	delete(c.poolStats, target)
+}
+
+func (c *collector) ObserveCommandDuration(target, commandName string, duration time.Duration) {
+	c.dbCommandDuration.WithLabelValues(target, commandName).Observe(duration.Seconds())
 }
 
 func (c *collector) ObserveRequestDuration(method, route, code string, duration time.Duration) {
@@ -153,6 +343,18 @@ func (c *collector) ObserveResponseSize(method, route, code string, size float64
 	c.serverResponseSize.WithLabelValues(method, route, code).Observe(size)
 }
 
+func (c *collector) TrackRateLimited() {
+	c.serverRateLimited.Inc()
+}
+
+func (c *collector) TrackTimeout(source string) {
+	c.graphQLTimeouts.WithLabelValues(source).Inc()
+}
+
+func (c *collector) TrackRejectedOperation(reason string) {
+	c.graphQLRejectedOperations.WithLabelValues(reason).Inc()
+}
+
 func (c *collector) TrackObjectsChanged(amount int, entity string, operation string) {
 	c.dbObjectsChanged.WithLabelValues(entity, operation).Add(float64(amount))
 }
@@ -165,6 +367,26 @@ func (c *collector) TrackObjectError(entity string, operation string) {
 	c.dbObjectErrors.WithLabelValues(entity, operation).Inc()
 }
 
-func (c *collector) TrackCommandError(commandName string) {
-	c.dbCommandErrors.WithLabelValues(commandName).Inc()
+func (c *collector) TrackTxRetry(reason string) {
+	c.dbTxRetries.WithLabelValues(reason).Inc()
+}
+
+func (c *collector) TrackOutboxDispatched(amount int) {
+	c.outboxDispatched.Add(float64(amount))
+}
+
+func (c *collector) TrackOutboxSinkFailure(sink string) {
+	c.outboxSinkFailures.WithLabelValues(sink).Inc()
+}
+
+func (c *collector) ObserveGRPCDuration(service, method, code string, duration time.Duration) {
+	c.grpcRequestDurations.WithLabelValues(service, method, code).Observe(duration.Seconds())
+}
+
+func (c *collector) TrackGRPCStreamMsgSent(service, method string) {
+	c.grpcStreamMsgsSent.WithLabelValues(service, method).Inc()
+}
+
+func (c *collector) TrackCommandError(target, commandName string) {
+	c.dbCommandErrors.WithLabelValues(target, commandName).Inc()
 }
@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshaler     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler     = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	componentsRefBase = "#/components/schemas/"
+)
+
+// Schemas are the named schemas of a document. Named structs are added as
+// they are referenced.
+type Schemas map[string]*Schema
+
+// Enum adds the string type of values, which must be a slice of all values
+// of that type.
+func (s Schemas) Enum(values interface{}) {
+	v := reflect.ValueOf(values)
+
+	enum := &Schema{Type: "string"}
+	for i := 0; i < v.Len(); i++ {
+		enum.Enum = append(enum.Enum, v.Index(i).String())
+	}
+
+	s[v.Type().Elem().Name()] = enum
+}
+
+// Of returns the schema of the JSON encoding of v. Named structs and enums
+// are referenced, structs which haven't been seen yet are added to s.
+func (s Schemas) Of(v interface{}) *Schema {
+	return s.of(reflect.TypeOf(v))
+}
+
+func (s Schemas) of(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := s.of(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+
+		return schema
+	}
+
+	if t.Name() != "" {
+		if _, ok := s[t.Name()]; ok {
+			return &Schema{Ref: componentsRefBase + t.Name()}
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(jsonMarshaler), t.Implements(textMarshaler):
+		// Custom encodings are strings, e.g. amounts of money.
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+
+		// Register the name first, so recursive types end up as references.
+		s[t.Name()] = &Schema{}
+		*s[t.Name()] = *s.object(t)
+
+		return &Schema{Ref: componentsRefBase + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// object returns the schema of a struct. Fields which are neither pointers
+// nor slices are required.
+func (s Schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			if j := strings.Index(tag, ","); j >= 0 {
+				name, opts = tag[:j], tag[j:]
+			} else {
+				name = tag
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+		}
+
+		schema.Properties[name] = s.of(f.Type)
+
+		switch f.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		default:
+			if !strings.Contains(opts, "omitempty") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	return schema
+}
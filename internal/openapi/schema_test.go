@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type color string
+
+type node struct {
+	Name     string    `json:"name"`
+	Color    color     `json:"color"`
+	Created  time.Time `json:"created"`
+	Note     *string   `json:"note"`
+	Count    int64     `json:"count,omitempty"`
+	Children []*node   `json:"children"`
+	Ignored  string    `json:"-"`
+	internal string
+}
+
+func TestSchemas_Of(t *testing.T) {
+	s := Schemas{}
+	s.Enum([]color{"red", "green"})
+
+	ref := s.Of(&node{})
+
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/node"}, ref)
+	assert.Equal(t, &Schema{Type: "string", Enum: []string{"red", "green"}}, s["color"])
+	assert.Equal(t, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":     {Type: "string"},
+			"color":    {Ref: "#/components/schemas/color"},
+			"created":  {Type: "string", Format: "date-time"},
+			"note":     {Type: "string", Nullable: true},
+			"count":    {Type: "integer", Format: "int64"},
+			"children": {Type: "array", Items: &Schema{Ref: "#/components/schemas/node"}},
+		},
+		Required: []string{"name", "color", "created"},
+	}, s["node"])
+
+	t.Run("anonymous structs are inlined", func(t *testing.T) {
+		got := s.Of(struct {
+			Items []node `json:"items"`
+		}{})
+
+		assert.Equal(t, "object", got.Type)
+		assert.Equal(t, "#/components/schemas/node", got.Properties["items"].Items.Ref)
+	})
+}
@@ -0,0 +1,498 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules
+// (RRULE) needed for a venue programme.
+//
+// Supported are FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL,
+// BYDAY, BYMONTHDAY and BYMONTH. Weeks always start on Monday.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	untilFormat     = "20060102T150405Z"
+	untilDateFormat = "20060102"
+
+	// maxEmptyPeriods bounds the expansion of rules which never match, e.g.
+	// FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30.
+	maxEmptyPeriods = 1000
+)
+
+// Frequency is the unit a Rule repeats in.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Weekday is a BYDAY entry. N selects the nth weekday within the month,
+// counting from the end if negative. Zero selects every such weekday.
+type Weekday struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w Weekday) String() string {
+	day := strings.ToUpper(w.Day.String()[:2])
+	if w.N == 0 {
+		return day
+	}
+
+	return strconv.Itoa(w.N) + day
+}
+
+// Rule is a recurrence rule.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// Count limits the number of occurrences, including the first one.
+	Count int
+	// Until is the last possible occurrence.
+	Until      *time.Time
+	ByDay      []Weekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// Parse parses an RRULE value like "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10". A
+// leading "RRULE:" is ignored.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	r := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				return nil, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(value); err != nil || r.Interval < 1 {
+				return nil, fmt.Errorf("invalid interval %q", value)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(value); err != nil || r.Count < 1 {
+				return nil, fmt.Errorf("invalid count %q", value)
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+
+			r.Until = &until
+		case "BYDAY":
+			if r.ByDay, err = parseByDay(value); err != nil {
+				return nil, err
+			}
+		case "BYMONTHDAY":
+			if r.ByMonthDay, err = parseInts(value, -31, 31); err != nil {
+				return nil, fmt.Errorf("invalid month day: %w", err)
+			}
+		case "BYMONTH":
+			months, err := parseInts(value, 1, 12)
+			if err != nil {
+				return nil, fmt.Errorf("invalid month: %w", err)
+			}
+
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("unsupported week start %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, errors.New("missing frequency")
+	}
+
+	if r.Count > 0 && r.Until != nil {
+		return nil, errors.New("count and until are mutually exclusive")
+	}
+
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, fmt.Errorf("ordinal weekday %q requires a monthly or yearly rule", d)
+		}
+	}
+
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, errors.New("month days are not supported by weekly rules")
+	}
+
+	if r.Freq == Yearly && len(r.ByDay) > 0 && len(r.ByMonth) == 0 {
+		return nil, errors.New("weekdays of a yearly rule require a month")
+	}
+
+	return r, nil
+}
+
+func parseUntil(s string) (time.Time, error) {
+	if t, err := time.Parse(untilFormat, s); err == nil {
+		return t, nil
+	}
+
+	// A date includes the whole day.
+	t, err := time.Parse(untilDateFormat, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid until %q", s)
+	}
+
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+func parseByDay(s string) ([]Weekday, error) {
+	var out []Weekday
+
+	for _, v := range strings.Split(s, ",") {
+		if len(v) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", v)
+		}
+
+		day, ok := weekdays[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", v)
+		}
+
+		wd := Weekday{Day: day}
+
+		if n := v[:len(v)-2]; n != "" {
+			i, err := strconv.Atoi(n)
+			if err != nil || i == 0 || i < -5 || i > 5 {
+				return nil, fmt.Errorf("invalid weekday %q", v)
+			}
+
+			wd.N = i
+		}
+
+		out = append(out, wd)
+	}
+
+	return out, nil
+}
+
+func parseInts(s string, min, max int) ([]int, error) {
+	var out []int
+
+	for _, v := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(v)
+		if err != nil || i == 0 || i < min || i > max {
+			return nil, fmt.Errorf("%q out of range", v)
+		}
+
+		out = append(out, i)
+	}
+
+	return out, nil
+}
+
+// String returns the RRULE value of r.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilFormat))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			days = append(days, d.String())
+		}
+
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonth) > 0 {
+		months := make([]string, 0, len(r.ByMonth))
+		for _, m := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(m)))
+		}
+
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of r starting at dtstart within [from, to).
+// dtstart is always the first occurrence. Occurrences keep the wall clock
+// time of dtstart in its location, also across daylight saving changes.
+func (r *Rule) Between(dtstart, from, to time.Time) []time.Time {
+	var (
+		out   []time.Time
+		count int
+	)
+
+	// emit returns false once no further occurrences are possible.
+	emit := func(t time.Time) bool {
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+
+		if !t.Before(to) {
+			return false
+		}
+
+		count++
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+
+		return r.Count == 0 || count < r.Count
+	}
+
+	if !emit(dtstart) {
+		return out
+	}
+
+	for period, empty := 0, 0; empty < maxEmptyPeriods; period++ {
+		candidates := r.candidates(dtstart, period)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+
+		empty = 0
+
+		for _, t := range candidates {
+			if !t.After(dtstart) {
+				continue
+			}
+
+			if !emit(t) {
+				return out
+			}
+		}
+	}
+
+	return out
+}
+
+// candidates returns the sorted occurrences within the nth period after
+// dtstart.
+func (r *Rule) candidates(dtstart time.Time, n int) []time.Time {
+	var (
+		y, m, d = dtstart.Date()
+		step    = n * r.Interval
+		out     []time.Time
+	)
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+	}
+
+	switch r.Freq {
+	case Daily:
+		out = append(out, at(y, m, d+step))
+	case Weekly:
+		// Days since Monday.
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := d - offset + 7*step
+
+		if len(r.ByDay) == 0 {
+			out = append(out, at(y, m, d+7*step))
+		}
+
+		for _, wd := range r.ByDay {
+			out = append(out, at(y, m, monday+(int(wd.Day)+6)%7))
+		}
+	case Monthly:
+		out = r.inMonth(dtstart, at, y, m+time.Month(step))
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{m}
+		}
+
+		for _, month := range months {
+			out = append(out, r.inMonth(dtstart, at, y+step, month)...)
+		}
+	}
+
+	var filtered []time.Time
+	for _, t := range out {
+		if r.matches(t) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Before(filtered[j]) })
+
+	return dedupe(filtered)
+}
+
+// inMonth expands BYMONTHDAY and BYDAY within a month, defaulting to the day
+// of dtstart.
+func (r *Rule) inMonth(dtstart time.Time, at func(int, time.Month, int) time.Time, year int, month time.Month) []time.Time {
+	// Normalize overflowing months.
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	year, month = first.Year(), first.Month()
+	days := daysIn(year, month)
+
+	var out []time.Time
+
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = days + md + 1
+		}
+
+		if md >= 1 && md <= days {
+			out = append(out, at(year, month, md))
+		}
+	}
+
+	// BYDAY only limits the days if BYMONTHDAY is given as well.
+	if len(r.ByMonthDay) > 0 {
+		return out
+	}
+
+	for _, wd := range r.ByDay {
+		offset := (int(wd.Day) - int(first.Weekday()) + 7) % 7
+
+		var matching []int
+		for day := 1 + offset; day <= days; day += 7 {
+			matching = append(matching, day)
+		}
+
+		switch {
+		case wd.N == 0:
+			for _, day := range matching {
+				out = append(out, at(year, month, day))
+			}
+		case wd.N > 0 && wd.N <= len(matching):
+			out = append(out, at(year, month, matching[wd.N-1]))
+		case wd.N < 0 && -wd.N <= len(matching):
+			out = append(out, at(year, month, matching[len(matching)+wd.N]))
+		}
+	}
+
+	if len(r.ByDay) == 0 && dtstart.Day() <= days {
+		out = append(out, at(year, month, dtstart.Day()))
+	}
+
+	return out
+}
+
+// matches applies the filters which don't expand the period.
+func (r *Rule) matches(t time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, t.Month()) {
+		return false
+	}
+
+	if r.Freq == Daily && len(r.ByMonthDay) > 0 {
+		days := daysIn(t.Year(), t.Month())
+
+		var ok bool
+		for _, md := range r.ByMonthDay {
+			if md == t.Day() || days+md+1 == t.Day() {
+				ok = true
+			}
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	// Weekdays expand weekly and monthly rules, but limit daily ones and
+	// monthly ones with explicit days.
+	if len(r.ByDay) > 0 && (r.Freq == Daily || len(r.ByMonthDay) > 0) {
+		var ok bool
+		for _, wd := range r.ByDay {
+			if wd.Day == t.Weekday() {
+				ok = true
+			}
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsMonth(months []time.Month, m time.Month) bool {
+	for _, month := range months {
+		if month == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dedupe(times []time.Time) []time.Time {
+	var out []time.Time
+
+	for i, t := range times {
+		if i > 0 && t.Equal(times[i-1]) {
+			continue
+		}
+
+		out = append(out, t)
+	}
+
+	return out
+}
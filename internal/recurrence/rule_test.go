@@ -0,0 +1,157 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, s := range []string{
+			"FREQ=DAILY",
+			"FREQ=WEEKLY;INTERVAL=2;COUNT=10;BYDAY=TU,TH",
+			"FREQ=MONTHLY;UNTIL=20231231T230000Z;BYDAY=-1FR",
+			"FREQ=YEARLY;BYMONTHDAY=1;BYMONTH=1,7",
+		} {
+			r, err := Parse(s)
+			require.NoError(t, err, s)
+			assert.Equal(t, s, r.String())
+		}
+	})
+
+	t.Run("prefix and case are ignored", func(t *testing.T) {
+		r, err := Parse("RRULE:freq=weekly;byday=mo")
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", r.String())
+	})
+
+	t.Run("until date includes the whole day", func(t *testing.T) {
+		r, err := Parse("FREQ=DAILY;UNTIL=20231231")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), *r.Until)
+	})
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"BYDAY=MO",
+			"FREQ=HOURLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;COUNT=2;UNTIL=20231231",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=WEEKLY;BYMONTHDAY=1",
+			"FREQ=MONTHLY;BYDAY=XX",
+			"FREQ=MONTHLY;BYMONTHDAY=32",
+			"FREQ=YEARLY;BYDAY=MO",
+			"FREQ=DAILY;BYSETPOS=1",
+			"FREQ=DAILY;WKST=SU",
+		} {
+			_, err := Parse(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func TestRule_Between(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	between := func(t *testing.T, rule string, dtstart, from, to time.Time) []time.Time {
+		r, err := Parse(rule)
+		require.NoError(t, err)
+
+		return r.Between(dtstart, from, to)
+	}
+
+	t.Run("weekly keeps wall clock time across DST", func(t *testing.T) {
+		// Thursday before the switch to summer time.
+		start := time.Date(2023, 3, 23, 20, 0, 0, 0, berlin)
+
+		got := between(t, "FREQ=WEEKLY;COUNT=3", start, start, start.AddDate(1, 0, 0))
+
+		require.Len(t, got, 3)
+		for i, occ := range got {
+			assert.Equal(t, 20, occ.Hour())
+			assert.True(t, occ.Equal(start.AddDate(0, 0, 7*i)))
+		}
+
+		_, offset := got[0].Zone()
+		_, summerOffset := got[1].Zone()
+		assert.NotEqual(t, offset, summerOffset)
+	})
+
+	t.Run("weekly on several days", func(t *testing.T) {
+		// Monday.
+		start := time.Date(2023, 1, 2, 19, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", start, start, start.AddDate(0, 0, 21))
+
+		assert.Equal(t, []time.Time{
+			start,
+			start.AddDate(0, 0, 2),
+			start.AddDate(0, 0, 14),
+			start.AddDate(0, 0, 16),
+		}, got)
+	})
+
+	t.Run("monthly on the last friday", func(t *testing.T) {
+		start := time.Date(2023, 1, 27, 21, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=MONTHLY;BYDAY=-1FR", start, start, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, []time.Time{
+			time.Date(2023, 1, 27, 21, 0, 0, 0, time.UTC),
+			time.Date(2023, 2, 24, 21, 0, 0, 0, time.UTC),
+			time.Date(2023, 3, 31, 21, 0, 0, 0, time.UTC),
+			time.Date(2023, 4, 28, 21, 0, 0, 0, time.UTC),
+		}, got)
+	})
+
+	t.Run("monthly skips months without the day", func(t *testing.T) {
+		start := time.Date(2023, 1, 31, 12, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=MONTHLY;COUNT=3", start, start, start.AddDate(1, 0, 0))
+
+		assert.Equal(t, []time.Time{
+			start,
+			time.Date(2023, 3, 31, 12, 0, 0, 0, time.UTC),
+			time.Date(2023, 5, 31, 12, 0, 0, 0, time.UTC),
+		}, got)
+	})
+
+	t.Run("daily limited to weekdays", func(t *testing.T) {
+		// Friday.
+		start := time.Date(2023, 1, 6, 10, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=DAILY;BYDAY=MO,FR", start, start, start.AddDate(0, 0, 8))
+
+		assert.Equal(t, []time.Time{start, start.AddDate(0, 0, 3), start.AddDate(0, 0, 7)}, got)
+	})
+
+	t.Run("window and until limit occurrences", func(t *testing.T) {
+		start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=DAILY;UNTIL=20230110T100000Z", start, start.AddDate(0, 0, 8), start.AddDate(1, 0, 0))
+
+		assert.Equal(t, []time.Time{start.AddDate(0, 0, 8), start.AddDate(0, 0, 9)}, got)
+	})
+
+	t.Run("count includes occurrences before the window", func(t *testing.T) {
+		start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=DAILY;COUNT=5", start, start.AddDate(0, 0, 3), start.AddDate(1, 0, 0))
+
+		assert.Equal(t, []time.Time{start.AddDate(0, 0, 3), start.AddDate(0, 0, 4)}, got)
+	})
+
+	t.Run("rule which never matches terminates", func(t *testing.T) {
+		start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		got := between(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", start, start, start.AddDate(100, 0, 0))
+
+		assert.Equal(t, []time.Time{start}, got)
+	})
+}
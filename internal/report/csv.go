@@ -0,0 +1,91 @@
+// Package report renders cost reports for funding bodies.
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+
+	"github.com/obitech/artist-db/internal/money"
+)
+
+// ContentType is the media type of a CSV report.
+const ContentType = "text/csv; charset=utf-8"
+
+// CategoryTotal is the category of the rows summing up an event.
+const CategoryTotal = "total"
+
+var header = []string{"event_id", "event", "category", "currency", "planned", "actual", "variance"}
+
+// Row is the cost of an event in a category. Amounts are in minor units of
+// Currency.
+type Row struct {
+	EventID  string
+	Event    string
+	Category string
+	Currency string
+	Planned  int64
+	Actual   int64
+}
+
+// WriteCSV writes rows as CSV with amounts in major units. Rows of an event
+// have to be adjacent; each event is followed by its totals per currency.
+func WriteCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(rows); {
+		j := i
+		totals := make(map[string]*Row)
+
+		for ; j < len(rows) && rows[j].EventID == rows[i].EventID; j++ {
+			r := rows[j]
+			if err := cw.Write(record(r)); err != nil {
+				return err
+			}
+
+			total, ok := totals[r.Currency]
+			if !ok {
+				total = &Row{EventID: r.EventID, Event: r.Event, Category: CategoryTotal, Currency: r.Currency}
+				totals[r.Currency] = total
+			}
+
+			total.Planned += r.Planned
+			total.Actual += r.Actual
+		}
+
+		currencies := make([]string, 0, len(totals))
+		for c := range totals {
+			currencies = append(currencies, c)
+		}
+
+		sort.Strings(currencies)
+
+		for _, c := range currencies {
+			if err := cw.Write(record(*totals[c])); err != nil {
+				return err
+			}
+		}
+
+		i = j
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func record(r Row) []string {
+	return []string{
+		r.EventID,
+		r.Event,
+		r.Category,
+		r.Currency,
+		money.New(r.Planned, r.Currency).Decimal(),
+		money.New(r.Actual, r.Currency).Decimal(),
+		money.New(r.Actual-r.Planned, r.Currency).Decimal(),
+	}
+}
@@ -0,0 +1,32 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	rows := []Row{
+		{EventID: "1", Event: "festival, day one", Category: "artist_fees", Currency: "EUR", Planned: 50000, Actual: 10000},
+		{EventID: "1", Event: "festival, day one", Category: "shipping", Currency: "EUR", Planned: 12000, Actual: 13050},
+		{EventID: "1", Event: "festival, day one", Category: "venue", Currency: "JPY", Planned: 30000},
+		{EventID: "2", Event: "opening", Category: "venue", Currency: "EUR", Planned: 800},
+	}
+
+	var sb strings.Builder
+	require.NoError(t, WriteCSV(&sb, rows))
+
+	assert.Equal(t, strings.Join([]string{
+		"event_id,event,category,currency,planned,actual,variance",
+		`1,"festival, day one",artist_fees,EUR,500.00,100.00,-400.00`,
+		`1,"festival, day one",shipping,EUR,120.00,130.50,10.50`,
+		`1,"festival, day one",venue,JPY,30000,0,-30000`,
+		`1,"festival, day one",total,EUR,620.00,230.50,-389.50`,
+		`1,"festival, day one",total,JPY,30000,0,-30000`,
+		"2,opening,venue,EUR,8.00,0.00,-8.00",
+		"2,opening,total,EUR,8.00,0.00,-8.00",
+	}, "\n")+"\n", sb.String())
+}
@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+	"github.com/obitech/artist-db/internal/broker"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+type artistService struct {
+	artistdbv1.UnimplementedArtistServiceServer
+	*Server
+}
+
+func (s *artistService) GetArtist(ctx context.Context, req *artistdbv1.GetRequest) (*artistdbv1.Artist, error) {
+	id, err := parseID(req.GetId(), false)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	artists, err := s.db.ArtistHandler.Get(ctx, artist.ByID(id))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return protoArtist(artists[0]), nil
+}
+
+func (s *artistService) UpsertArtist(ctx context.Context, req *artistdbv1.UpsertArtistRequest) (*artistdbv1.Artist, error) {
+	a, err := databaseArtist(req.GetArtist())
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if err := s.db.ArtistHandler.Upsert(ctx, a); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return protoArtist(a), nil
+}
+
+func (s *artistService) DeleteArtist(ctx context.Context, req *artistdbv1.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.db.ArtistHandler.DeleteByID(ctx, req.GetId()); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *artistService) ListArtists(req *artistdbv1.ListRequest, stream artistdbv1.ArtistService_ListArtistsServer) error {
+	ctx := stream.Context()
+	limit := batchSize(req.GetBatchSize())
+
+	for after := ""; ; {
+		artists, err := s.db.ArtistHandler.Get(ctx, artist.After(after, limit))
+		if errors.Is(err, core.ErrNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return s.statusError(ctx, err)
+		}
+
+		for _, a := range artists {
+			if err := stream.Send(protoArtist(a)); err != nil {
+				return err
+			}
+		}
+
+		if len(artists) < limit {
+			return nil
+		}
+
+		after = artists[len(artists)-1].ID
+	}
+}
+
+func (s *artistService) WatchArtists(req *artistdbv1.WatchRequest, stream artistdbv1.ArtistService_WatchArtistsServer) error {
+	// Changes are looked up right after they were committed, which lagging
+	// replicas may not have seen yet.
+	ctx := core.WithPrimary(stream.Context())
+
+	filter := broker.ForEntity(core.EntityArtist)
+	if req.GetId() != "" {
+		id, err := parseID(req.GetId(), false)
+		if err != nil {
+			return invalidArgument(err)
+		}
+
+		filter = broker.ForID(core.EntityArtist, id)
+	}
+
+	for change := range s.subscriber.Subscribe(ctx, filter) {
+		ac := &artistdbv1.ArtistChange{
+			Action: changeActions[change.Action],
+			Id:     change.ID,
+		}
+
+		if change.Action == core.ActionUpsert {
+			artists, err := s.db.ArtistHandler.Get(ctx, artist.ByID(change.ID))
+			if errors.Is(err, core.ErrNotFound) {
+				// Deleted in the meantime, the deletion follows.
+				continue
+			}
+
+			if err != nil {
+				return s.statusError(ctx, err)
+			}
+
+			ac.Artist = protoArtist(artists[0])
+		}
+
+		if err := stream.Send(ac); err != nil {
+			return err
+		}
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
@@ -0,0 +1,408 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/location"
+)
+
+var (
+	feeTypes = map[event.FeeType]artistdbv1.FeeType{
+		event.FeeFlat:  artistdbv1.FeeType_FEE_TYPE_FLAT,
+		event.FeeDaily: artistdbv1.FeeType_FEE_TYPE_DAILY,
+	}
+
+	paymentStatuses = map[event.PaymentStatus]artistdbv1.PaymentStatus{
+		event.PaymentPending:   artistdbv1.PaymentStatus_PAYMENT_STATUS_PENDING,
+		event.PaymentInvoiced:  artistdbv1.PaymentStatus_PAYMENT_STATUS_INVOICED,
+		event.PaymentPaid:      artistdbv1.PaymentStatus_PAYMENT_STATUS_PAID,
+		event.PaymentCancelled: artistdbv1.PaymentStatus_PAYMENT_STATUS_CANCELLED,
+	}
+
+	changeActions = map[string]artistdbv1.ChangeAction{
+		core.ActionUpsert: artistdbv1.ChangeAction_CHANGE_ACTION_UPSERT,
+		core.ActionDelete: artistdbv1.ChangeAction_CHANGE_ACTION_DELETE,
+	}
+)
+
+// parseID returns id if it is a valid UUID. An empty id is replaced by a new
+// one if generate is set.
+func parseID(id string, generate bool) (string, error) {
+	if id == "" && generate {
+		return uuid.NewString(), nil
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("%w: %q", core.ErrInvalidUUID, id)
+	}
+
+	return id, nil
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+// optionalTime returns nil for unset timestamps.
+func optionalTime(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+
+	if err := ts.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	t := ts.AsTime()
+
+	return &t, nil
+}
+
+func protoArtist(a *artist.Artist) *artistdbv1.Artist {
+	out := &artistdbv1.Artist{
+		Id:         a.ID,
+		FirstName:  a.FirstName,
+		LastName:   a.LastName,
+		ArtistName: a.ArtistName,
+		Pronouns:   a.Pronouns,
+		Origin: &artistdbv1.Origin{
+			PlaceOfBirth: a.Origin.PlaceOfBirth,
+			Nationality:  a.Origin.Nationality,
+		},
+		Language: a.Language,
+		Socials: &artistdbv1.Socials{
+			Instagram: a.Socials.Instagram,
+			Facebook:  a.Socials.Facebook,
+			Bandcamp:  a.Socials.Bandcamp,
+		},
+		BioGerman:  a.BioGerman,
+		BioEnglish: a.BioEnglish,
+		Email:      a.Email,
+	}
+
+	if !a.Origin.DateOfBirth.IsZero() {
+		out.Origin.DateOfBirth = timestamppb.New(a.Origin.DateOfBirth)
+	}
+
+	return out
+}
+
+func databaseArtist(a *artistdbv1.Artist) (*artist.Artist, error) {
+	if a == nil {
+		return nil, errors.New("artist is missing")
+	}
+
+	id, err := parseID(a.GetId(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	dob, err := optionalTime(a.GetOrigin().GetDateOfBirth())
+	if err != nil {
+		return nil, fmt.Errorf("invalid date of birth: %w", err)
+	}
+
+	out := &artist.Artist{
+		ID:         id,
+		FirstName:  a.GetFirstName(),
+		LastName:   a.GetLastName(),
+		ArtistName: a.GetArtistName(),
+		Pronouns:   a.GetPronouns(),
+		Origin: artist.Origin{
+			PlaceOfBirth: a.GetOrigin().GetPlaceOfBirth(),
+			Nationality:  a.GetOrigin().GetNationality(),
+		},
+		Language: a.GetLanguage(),
+		Socials: artist.Socials{
+			Instagram: a.GetSocials().GetInstagram(),
+			Facebook:  a.GetSocials().GetFacebook(),
+			Bandcamp:  a.GetSocials().GetBandcamp(),
+		},
+		BioGerman:  a.GetBioGerman(),
+		BioEnglish: a.GetBioEnglish(),
+		Email:      a.GetEmail(),
+	}
+
+	if dob != nil {
+		out.Origin.DateOfBirth = dob.UTC()
+	}
+
+	return out, nil
+}
+
+func protoLocation(l *location.Location) *artistdbv1.Location {
+	return &artistdbv1.Location{
+		Id:   l.ID,
+		Name: l.Name,
+		Address: &artistdbv1.Address{
+			Street:  l.Address.Street,
+			Zip:     l.Address.Zip,
+			City:    l.Address.City,
+			Country: l.Address.Country,
+		},
+	}
+}
+
+func databaseLocation(l *artistdbv1.Location) (*location.Location, error) {
+	if l == nil {
+		return nil, errors.New("location is missing")
+	}
+
+	id, err := parseID(l.GetId(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &location.Location{
+		ID:   id,
+		Name: l.GetName(),
+		Address: location.Address{
+			Street:  l.GetAddress().GetStreet(),
+			Zip:     l.GetAddress().GetZip(),
+			City:    l.GetAddress().GetCity(),
+			Country: l.GetAddress().GetCountry(),
+		},
+	}, nil
+}
+
+func protoEvent(e *event.Event) *artistdbv1.Event {
+	out := &artistdbv1.Event{
+		Id:        e.ID,
+		Name:      e.Name,
+		StartTime: timestamp(e.StartTime),
+		EndTime:   timestamp(e.EndTime),
+		Timezone:  e.Location().String(),
+		UpdatedAt: timestamppb.New(e.UpdatedAt),
+	}
+
+	if e.LocationID != nil {
+		out.LocationId = *e.LocationID
+	}
+
+	for _, ia := range e.InvitedArtists {
+		out.Invitations = append(out.Invitations, protoInvitation(ia))
+	}
+
+	for _, s := range e.Slots {
+		out.Slots = append(out.Slots, &artistdbv1.Slot{
+			Id:        s.ID,
+			ArtistId:  s.ArtistID,
+			StartTime: timestamppb.New(s.StartTime),
+			EndTime:   timestamppb.New(s.EndTime),
+			Stage:     s.Stage,
+		})
+	}
+
+	if e.Recurrence != nil {
+		out.Recurrence = e.Recurrence.String()
+	}
+
+	for _, t := range e.Exceptions {
+		out.Exceptions = append(out.Exceptions, timestamppb.New(t))
+	}
+
+	if e.Published {
+		out.Publication = &artistdbv1.Publication{
+			From:  timestamp(e.PublishFrom),
+			Until: timestamp(e.PublishUntil),
+		}
+	}
+
+	return out
+}
+
+func protoInvitation(ia event.InvitedArtist) *artistdbv1.Invitation {
+	return &artistdbv1.Invitation{
+		ArtistId:  ia.ID,
+		Confirmed: ia.Confirmed,
+		Fee:       protoFee(ia.Fee),
+	}
+}
+
+func protoFee(f *event.Fee) *artistdbv1.Fee {
+	if f == nil {
+		return nil
+	}
+
+	return &artistdbv1.Fee{
+		Amount:                 f.Amount,
+		Currency:               f.Currency,
+		Type:                   feeTypes[f.Type],
+		Days:                   int32(f.Days),
+		TravelAllowance:        f.TravelAllowance,
+		AccommodationAllowance: f.AccommodationAllowance,
+		Status:                 paymentStatuses[f.Status],
+		DueDate:                timestamp(f.DueDate),
+		PaidAt:                 timestamp(f.PaidAt),
+		ContractRefs:           f.ContractRefs,
+		Total:                  f.Total(),
+	}
+}
+
+func databaseFee(f *artistdbv1.Fee) (*event.Fee, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	out := &event.Fee{
+		Amount:                 f.GetAmount(),
+		Currency:               f.GetCurrency(),
+		Days:                   int(f.GetDays()),
+		TravelAllowance:        f.GetTravelAllowance(),
+		AccommodationAllowance: f.GetAccommodationAllowance(),
+		ContractRefs:           f.GetContractRefs(),
+	}
+
+	// Unspecified enums are left empty, so validation fills in defaults.
+	for k, v := range feeTypes {
+		if v == f.GetType() {
+			out.Type = k
+		}
+	}
+
+	for k, v := range paymentStatuses {
+		if v == f.GetStatus() {
+			out.Status = k
+		}
+	}
+
+	var err error
+
+	if out.DueDate, err = optionalTime(f.GetDueDate()); err != nil {
+		return nil, fmt.Errorf("invalid due date: %w", err)
+	}
+
+	if out.PaidAt, err = optionalTime(f.GetPaidAt()); err != nil {
+		return nil, fmt.Errorf("invalid paid at: %w", err)
+	}
+
+	return out, nil
+}
+
+// databaseEvent validates e and converts it to an Event.
+func databaseEvent(e *artistdbv1.Event) (*event.Event, error) {
+	if e == nil {
+		return nil, errors.New("event is missing")
+	}
+
+	if e.GetName() == "" {
+		return nil, errors.New("empty name")
+	}
+
+	id, err := parseID(e.GetId(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []event.Option
+
+	start, err := optionalTime(e.GetStartTime())
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time: %w", err)
+	}
+
+	if start != nil {
+		opts = append(opts, event.WithStartTime(*start))
+	}
+
+	end, err := optionalTime(e.GetEndTime())
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time: %w", err)
+	}
+
+	if end != nil {
+		opts = append(opts, event.WithEndTime(*end))
+	}
+
+	if e.GetTimezone() != "" {
+		opts = append(opts, event.WithTimezone(e.GetTimezone()))
+	}
+
+	if e.GetLocationId() != "" {
+		opts = append(opts, event.WithLocationID(e.GetLocationId()))
+	}
+
+	// Proto3 can't tell unset from empty lists, so empty invitations and
+	// slots keep the stored ones.
+	for _, inv := range e.GetInvitations() {
+		fee, err := databaseFee(inv.GetFee())
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee of %q: %w", inv.GetArtistId(), err)
+		}
+
+		opts = append(opts, event.WithInvitedArtists(event.InvitedArtist{
+			ID:        inv.GetArtistId(),
+			Confirmed: inv.GetConfirmed(),
+			Fee:       fee,
+		}))
+	}
+
+	for _, s := range e.GetSlots() {
+		if err := s.GetStartTime().CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid start time of slot %q: %w", s.GetId(), err)
+		}
+
+		if err := s.GetEndTime().CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid end time of slot %q: %w", s.GetId(), err)
+		}
+
+		opts = append(opts, event.WithSlots(event.Slot{
+			ID:        s.GetId(),
+			ArtistID:  s.GetArtistId(),
+			StartTime: s.GetStartTime().AsTime(),
+			EndTime:   s.GetEndTime().AsTime(),
+			Stage:     s.GetStage(),
+		}))
+	}
+
+	if e.GetRecurrence() != "" {
+		var exceptions []time.Time
+		for _, ts := range e.GetExceptions() {
+			if err := ts.CheckValid(); err != nil {
+				return nil, fmt.Errorf("invalid exception: %w", err)
+			}
+
+			exceptions = append(exceptions, ts.AsTime())
+		}
+
+		opts = append(opts, event.WithRecurrence(e.GetRecurrence(), exceptions...))
+	} else if len(e.GetExceptions()) > 0 {
+		return nil, errors.New("exceptions require a recurrence")
+	}
+
+	if p := e.GetPublication(); p != nil {
+		from, err := optionalTime(p.GetFrom())
+		if err != nil {
+			return nil, fmt.Errorf("invalid publication start: %w", err)
+		}
+
+		until, err := optionalTime(p.GetUntil())
+		if err != nil {
+			return nil, fmt.Errorf("invalid publication end: %w", err)
+		}
+
+		opts = append(opts, event.WithPublication(from, until))
+	}
+
+	out, err := event.New(e.GetName(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating event: %w", err)
+	}
+
+	out.ID = id
+
+	return out, nil
+}
@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+	"github.com/obitech/artist-db/internal/database/event"
+)
+
+func TestDatabaseEvent(t *testing.T) {
+	var (
+		artistID = "3f6b0c8a-3ad9-4c5e-8a3e-0c5b7c2a9d11"
+		start    = time.Date(2022, 6, 2, 20, 0, 0, 0, time.UTC)
+		end      = start.Add(4 * time.Hour)
+	)
+
+	valid := func() *artistdbv1.Event {
+		return &artistdbv1.Event{
+			Name:      "event",
+			StartTime: timestamppb.New(start),
+			EndTime:   timestamppb.New(end),
+			Timezone:  "Europe/Berlin",
+			Invitations: []*artistdbv1.Invitation{{
+				ArtistId:  artistID,
+				Confirmed: true,
+				Fee: &artistdbv1.Fee{
+					Amount:   50_000,
+					Currency: "EUR",
+					Type:     artistdbv1.FeeType_FEE_TYPE_DAILY,
+					Days:     2,
+				},
+			}},
+			Slots: []*artistdbv1.Slot{{
+				ArtistId:  artistID,
+				StartTime: timestamppb.New(start.Add(time.Hour)),
+				EndTime:   timestamppb.New(start.Add(2 * time.Hour)),
+			}},
+			Recurrence: "FREQ=WEEKLY;COUNT=4",
+			Exceptions: []*timestamppb.Timestamp{timestamppb.New(start.AddDate(0, 0, 7))},
+			Publication: &artistdbv1.Publication{
+				Until: timestamppb.New(end),
+			},
+		}
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		in := valid()
+
+		e, err := databaseEvent(in)
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, e.ID)
+		assert.True(t, e.Published)
+		assert.Equal(t, event.FeeDaily, e.InvitedArtists[0].Fee.Type)
+		assert.Equal(t, event.PaymentPending, e.InvitedArtists[0].Fee.Status)
+
+		out := protoEvent(e)
+		assert.Equal(t, e.ID, out.Id)
+		assert.Equal(t, in.StartTime.AsTime(), out.StartTime.AsTime())
+		assert.Equal(t, in.Timezone, out.Timezone)
+		assert.Equal(t, in.Recurrence, out.Recurrence)
+		assert.Nil(t, out.Publication.From)
+		assert.Equal(t, end, out.Publication.Until.AsTime())
+		assert.Equal(t, artistdbv1.PaymentStatus_PAYMENT_STATUS_PENDING, out.Invitations[0].Fee.Status)
+		assert.Equal(t, int64(100_000), out.Invitations[0].Fee.Total)
+	})
+
+	for _, tc := range []struct {
+		name   string
+		modify func(e *artistdbv1.Event)
+	}{
+		{name: "missing", modify: nil},
+		{name: "empty name", modify: func(e *artistdbv1.Event) { e.Name = "" }},
+		{name: "invalid id", modify: func(e *artistdbv1.Event) { e.Id = "foo" }},
+		{name: "ends before start", modify: func(e *artistdbv1.Event) { e.EndTime = timestamppb.New(start.Add(-time.Hour)) }},
+		{name: "invalid timestamp", modify: func(e *artistdbv1.Event) { e.StartTime = &timestamppb.Timestamp{Nanos: -1} }},
+		{name: "exceptions without recurrence", modify: func(e *artistdbv1.Event) { e.Recurrence = "" }},
+		{name: "slot of uninvited artist", modify: func(e *artistdbv1.Event) { e.Invitations = nil }},
+		{name: "invalid currency", modify: func(e *artistdbv1.Event) { e.Invitations[0].Fee.Currency = "XXXX" }},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var in *artistdbv1.Event
+			if tc.modify != nil {
+				in = valid()
+				tc.modify(in)
+			}
+
+			_, err := databaseEvent(in)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDatabaseArtist(t *testing.T) {
+	dob := time.Date(1990, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	a, err := databaseArtist(&artistdbv1.Artist{
+		FirstName: "first",
+		LastName:  "last",
+		Origin:    &artistdbv1.Origin{DateOfBirth: timestamppb.New(dob)},
+	})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, a.ID)
+	assert.Equal(t, dob, a.Origin.DateOfBirth)
+
+	out := protoArtist(a)
+	assert.Equal(t, a.ID, out.Id)
+	assert.Equal(t, dob, out.Origin.DateOfBirth.AsTime())
+
+	a.Origin.DateOfBirth = time.Time{}
+	assert.Nil(t, protoArtist(a).Origin.DateOfBirth)
+
+	_, err = databaseArtist(&artistdbv1.Artist{Id: "foo"})
+	assert.Error(t, err)
+}
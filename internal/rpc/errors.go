@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// statusError converts errors returned by the database into status errors.
+// Unexpected errors are logged and their message is not revealed.
+func (s *Server) statusError(ctx context.Context, err error) error {
+	var conflictErr *event.ConflictError
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case core.IsTimeout(err):
+		return status.Error(codes.DeadlineExceeded, "operation timed out")
+	case errors.Is(err, core.ErrInvalidUUID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &conflictErr), errors.Is(err, artist.ErrAnonymized):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		s.logger.Error("call failed", zap.Error(err), observability.TraceField(ctx))
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// invalidArgument returns an error for malformed requests.
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
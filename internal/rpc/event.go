@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+	"github.com/obitech/artist-db/internal/broker"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+)
+
+type eventService struct {
+	artistdbv1.UnimplementedEventServiceServer
+	*Server
+}
+
+func (s *eventService) GetEvent(ctx context.Context, req *artistdbv1.GetRequest) (*artistdbv1.Event, error) {
+	id, err := parseID(req.GetId(), false)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	events, err := s.db.EventHandler.Get(ctx, event.ByID(id))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return protoEvent(events[0]), nil
+}
+
+func (s *eventService) UpsertEvent(ctx context.Context, req *artistdbv1.UpsertEventRequest) (*artistdbv1.Event, error) {
+	e, err := databaseEvent(req.GetEvent())
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if req.GetAllowConflicts() {
+		_, err = s.db.EventHandler.UpsertAllowingConflicts(ctx, e)
+	} else {
+		err = s.db.EventHandler.Upsert(ctx, e)
+	}
+
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	// Read back, so fees omitted from the request are included.
+	events, err := s.db.EventHandler.Get(ctx, event.ByID(e.ID))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return protoEvent(events[0]), nil
+}
+
+func (s *eventService) DeleteEvent(ctx context.Context, req *artistdbv1.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.db.EventHandler.DeleteByID(ctx, req.GetId()); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *eventService) ListEvents(req *artistdbv1.ListRequest, stream artistdbv1.EventService_ListEventsServer) error {
+	ctx := stream.Context()
+	limit := batchSize(req.GetBatchSize())
+
+	for after := ""; ; {
+		events, err := s.db.EventHandler.Get(ctx, event.After(after, limit))
+		if errors.Is(err, core.ErrNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return s.statusError(ctx, err)
+		}
+
+		for _, e := range events {
+			if err := stream.Send(protoEvent(e)); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < limit {
+			return nil
+		}
+
+		after = events[len(events)-1].ID
+	}
+}
+
+func (s *eventService) ListInvitations(req *artistdbv1.ListInvitationsRequest, stream artistdbv1.EventService_ListInvitationsServer) error {
+	ctx := stream.Context()
+
+	id, err := parseID(req.GetEventId(), false)
+	if err != nil {
+		return invalidArgument(err)
+	}
+
+	events, err := s.db.EventHandler.Get(ctx, event.ByID(id))
+	if err != nil {
+		return s.statusError(ctx, err)
+	}
+
+	for _, ia := range events[0].InvitedArtists {
+		if err := stream.Send(protoInvitation(ia)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *eventService) WatchEvents(req *artistdbv1.WatchRequest, stream artistdbv1.EventService_WatchEventsServer) error {
+	// Changes are looked up right after they were committed, which lagging
+	// replicas may not have seen yet.
+	ctx := core.WithPrimary(stream.Context())
+
+	filter := broker.ForEntity(core.EntityEvent)
+	if req.GetId() != "" {
+		id, err := parseID(req.GetId(), false)
+		if err != nil {
+			return invalidArgument(err)
+		}
+
+		filter = broker.ForID(core.EntityEvent, id)
+	}
+
+	for change := range s.subscriber.Subscribe(ctx, filter) {
+		ec := &artistdbv1.EventChange{
+			Action: changeActions[change.Action],
+			Id:     change.ID,
+		}
+
+		if change.Action == core.ActionUpsert {
+			events, err := s.db.EventHandler.Get(ctx, event.ByID(change.ID))
+			if errors.Is(err, core.ErrNotFound) {
+				// Deleted in the meantime, the deletion follows.
+				continue
+			}
+
+			if err != nil {
+				return s.statusError(ctx, err)
+			}
+
+			ec.Event = protoEvent(events[0])
+		}
+
+		if err := stream.Send(ec); err != nil {
+			return err
+		}
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
@@ -0,0 +1,213 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	tracingInstrumentationName = "grpc"
+
+	authorizationHeader = "authorization"
+	bearerPrefix        = "bearer "
+)
+
+// splitMethod splits a full method name, e.g.
+// /artistdb.v1.ArtistService/GetArtist, into service and method.
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return "unknown", fullMethod
+}
+
+func metricsUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	service, method := splitMethod(info.FullMethod)
+	observability.Metrics.ObserveGRPCDuration(service, method, status.Code(err).String(), time.Since(start))
+
+	return resp, err
+}
+
+func metricsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	service, method := splitMethod(info.FullMethod)
+
+	err := handler(srv, &countingStream{ServerStream: ss, service: service, method: method})
+	observability.Metrics.ObserveGRPCDuration(service, method, status.Code(err).String(), time.Since(start))
+
+	return err
+}
+
+// countingStream counts the messages sent on a stream.
+type countingStream struct {
+	grpc.ServerStream
+	service, method string
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	observability.Metrics.TrackGRPCStreamMsgSent(s.service, s.method)
+
+	return nil
+}
+
+// metadataCarrier propagates trace context through gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// startSpan starts a server span continuing the trace of the caller, if it
+// sent one.
+func startSpan(ctx context.Context, tp trace.TracerProvider, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	service, method := splitMethod(fullMethod)
+
+	return tp.Tracer(tracingInstrumentationName).Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("grpc"),
+			semconv.RPCServiceKey.String(service),
+			semconv.RPCMethodKey.String(method),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelCodes.Error, code.String())
+	}
+
+	span.End()
+}
+
+func tracingUnaryInterceptor(tp trace.TracerProvider) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startSpan(ctx, tp, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+
+		return resp, err
+	}
+}
+
+func tracingStreamInterceptor(tp trace.TracerProvider) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(ss.Context(), tp, info.FullMethod)
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		endSpan(span, err)
+
+		return err
+	}
+}
+
+// contextStream replaces the context of a stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate checks that ctx carries one of tokens as bearer token. All
+// calls are allowed if there are no tokens.
+func authenticate(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	if len(values[0]) < len(bearerPrefix) || !strings.EqualFold(values[0][:len(bearerPrefix)], bearerPrefix) {
+		return status.Error(codes.Unauthenticated, "authorization is not a bearer token")
+	}
+
+	given := []byte(values[0][len(bearerPrefix):])
+
+	// Compare against all tokens, so the time taken doesn't reveal which
+	// one matched.
+	var ok int
+	for _, token := range tokens {
+		ok |= subtle.ConstantTimeCompare(given, []byte(token))
+	}
+
+	if ok != 1 {
+		return status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+
+	return nil
+}
+
+func authUnaryInterceptor(tokens []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authenticate(ctx, tokens); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func authStreamInterceptor(tokens []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authenticate(ss.Context(), tokens); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+)
+
+func TestSplitMethod(t *testing.T) {
+	service, method := splitMethod("/artistdb.v1.ArtistService/GetArtist")
+	assert.Equal(t, "artistdb.v1.ArtistService", service)
+	assert.Equal(t, "GetArtist", method)
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokens := []string{"first", "second"}
+
+	for _, tc := range []struct {
+		name     string
+		tokens   []string
+		header   []string
+		wantCode codes.Code
+	}{
+		{name: "no tokens configured", wantCode: codes.OK},
+		{name: "missing", tokens: tokens, wantCode: codes.Unauthenticated},
+		{name: "not bearer", tokens: tokens, header: []string{"Basic second"}, wantCode: codes.Unauthenticated},
+		{name: "invalid", tokens: tokens, header: []string{"Bearer third"}, wantCode: codes.Unauthenticated},
+		{name: "prefix of token", tokens: tokens, header: []string{"Bearer sec"}, wantCode: codes.Unauthenticated},
+		{name: "valid", tokens: tokens, header: []string{"Bearer second"}, wantCode: codes.OK},
+		{name: "case insensitive scheme", tokens: tokens, header: []string{"bearer first"}, wantCode: codes.OK},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			md := metadata.MD{}
+			if tc.header != nil {
+				md.Set(authorizationHeader, tc.header...)
+			}
+
+			err := authenticate(metadata.NewIncomingContext(context.Background(), md), tc.tokens)
+			assert.Equal(t, tc.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv, err := NewServer(nil, WithTokens("secret"), WithTracerProvider(trace.NewNoopTracerProvider()))
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := artistdbv1.NewArtistServiceClient(conn)
+	ctx := context.Background()
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := client.GetArtist(ctx, &artistdbv1.GetRequest{Id: "foo"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		stream, err := client.WatchArtists(ctx, &artistdbv1.WatchRequest{})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, "Bearer secret")
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := client.GetArtist(authCtx, &artistdbv1.GetRequest{Id: "foo"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid artist", func(t *testing.T) {
+		_, err := client.UpsertArtist(authCtx, &artistdbv1.UpsertArtistRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+	"github.com/obitech/artist-db/internal/broker"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/location"
+)
+
+type locationService struct {
+	artistdbv1.UnimplementedLocationServiceServer
+	*Server
+}
+
+func (s *locationService) GetLocation(ctx context.Context, req *artistdbv1.GetRequest) (*artistdbv1.Location, error) {
+	id, err := parseID(req.GetId(), false)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	locations, err := s.db.LocationHandler.Get(ctx, location.ByID(id))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return protoLocation(locations[0]), nil
+}
+
+func (s *locationService) UpsertLocation(ctx context.Context, req *artistdbv1.UpsertLocationRequest) (*artistdbv1.Location, error) {
+	l, err := databaseLocation(req.GetLocation())
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if err := s.db.LocationHandler.Upsert(ctx, l); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return protoLocation(l), nil
+}
+
+func (s *locationService) DeleteLocation(ctx context.Context, req *artistdbv1.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.db.LocationHandler.DeleteByID(ctx, req.GetId()); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *locationService) ListLocations(req *artistdbv1.ListRequest, stream artistdbv1.LocationService_ListLocationsServer) error {
+	ctx := stream.Context()
+	limit := batchSize(req.GetBatchSize())
+
+	for after := ""; ; {
+		locations, err := s.db.LocationHandler.Get(ctx, location.After(after, limit))
+		if errors.Is(err, core.ErrNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return s.statusError(ctx, err)
+		}
+
+		for _, l := range locations {
+			if err := stream.Send(protoLocation(l)); err != nil {
+				return err
+			}
+		}
+
+		if len(locations) < limit {
+			return nil
+		}
+
+		after = locations[len(locations)-1].ID
+	}
+}
+
+func (s *locationService) WatchLocations(req *artistdbv1.WatchRequest, stream artistdbv1.LocationService_WatchLocationsServer) error {
+	// Changes are looked up right after they were committed, which lagging
+	// replicas may not have seen yet.
+	ctx := core.WithPrimary(stream.Context())
+
+	filter := broker.ForEntity(core.EntityLocation)
+	if req.GetId() != "" {
+		id, err := parseID(req.GetId(), false)
+		if err != nil {
+			return invalidArgument(err)
+		}
+
+		filter = broker.ForID(core.EntityLocation, id)
+	}
+
+	for change := range s.subscriber.Subscribe(ctx, filter) {
+		lc := &artistdbv1.LocationChange{
+			Action: changeActions[change.Action],
+			Id:     change.ID,
+		}
+
+		if change.Action == core.ActionUpsert {
+			locations, err := s.db.LocationHandler.Get(ctx, location.ByID(change.ID))
+			if errors.Is(err, core.ErrNotFound) {
+				// Deleted in the meantime, the deletion follows.
+				continue
+			}
+
+			if err != nil {
+				return s.statusError(ctx, err)
+			}
+
+			lc.Location = protoLocation(locations[0])
+		}
+
+		if err := stream.Send(lc); err != nil {
+			return err
+		}
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
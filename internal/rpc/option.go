@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph"
+)
+
+// Option allows customization of the default Server.
+type Option func(s *Server) error
+
+// WithLogger adds a zap logger.
+func WithLogger(logger *zap.Logger) Option {
+	return func(s *Server) error {
+		if logger == nil {
+			return errors.New("logger is nil")
+		}
+
+		s.logger = logger
+		return nil
+	}
+}
+
+// WithTracerProvider sets the provider of the tracer spans of calls are
+// started with.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Server) error {
+		if tp == nil {
+			return errors.New("tracer provider is nil")
+		}
+
+		s.tracer = tp
+		return nil
+	}
+}
+
+// WithSubscriber sets where Watch calls receive changes from. It has to
+// receive the changes published by the database.
+func WithSubscriber(subscriber graph.Subscriber) Option {
+	return func(s *Server) error {
+		if subscriber == nil {
+			return errors.New("subscriber is nil")
+		}
+
+		s.subscriber = subscriber
+		return nil
+	}
+}
+
+// WithTokens requires calls to authenticate with one of tokens as bearer
+// token.
+func WithTokens(tokens ...string) Option {
+	return func(s *Server) error {
+		for _, token := range tokens {
+			if token == "" {
+				return errors.New("token is empty")
+			}
+		}
+
+		s.tokens = append(s.tokens, tokens...)
+		return nil
+	}
+}
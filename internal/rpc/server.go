@@ -0,0 +1,113 @@
+// Package rpc serves the gRPC API defined in api/artistdb/v1 for internal
+// services.
+package rpc
+
+import (
+	"fmt"
+	"net"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	artistdbv1 "github.com/obitech/artist-db/api/artistdb/v1"
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/internal/broker"
+	"github.com/obitech/artist-db/internal/database"
+)
+
+const (
+	defaultBatchSize = 100
+	maxBatchSize     = 1000
+)
+
+// Server serves the artist, location and event services.
+type Server struct {
+	grpc       *grpc.Server
+	db         *database.Database
+	subscriber graph.Subscriber
+	logger     *zap.Logger
+	tracer     trace.TracerProvider
+	tokens     []string
+}
+
+// NewServer returns a Server. Calls are traced, measured and, if tokens are
+// configured, authenticated.
+func NewServer(db *database.Database, opts ...Option) (*Server, error) {
+	srv := &Server{
+		db:         db,
+		subscriber: broker.New(zap.NewNop()),
+		logger:     zap.NewNop(),
+		tracer:     otel.GetTracerProvider(),
+	}
+
+	for _, fn := range opts {
+		if err := fn(srv); err != nil {
+			return nil, fmt.Errorf("applying option failed: %w", err)
+		}
+	}
+
+	if len(srv.tokens) == 0 {
+		srv.logger.Warn("no gRPC tokens configured, calls are not authenticated")
+	}
+
+	// Interceptors run in order, so calls are measured and traced even if
+	// they fail to authenticate.
+	srv.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			metricsUnaryInterceptor,
+			tracingUnaryInterceptor(srv.tracer),
+			authUnaryInterceptor(srv.tokens),
+		),
+		grpc.ChainStreamInterceptor(
+			metricsStreamInterceptor,
+			tracingStreamInterceptor(srv.tracer),
+			authStreamInterceptor(srv.tokens),
+		),
+	)
+
+	artistdbv1.RegisterArtistServiceServer(srv.grpc, &artistService{Server: srv})
+	artistdbv1.RegisterLocationServiceServer(srv.grpc, &locationService{Server: srv})
+	artistdbv1.RegisterEventServiceServer(srv.grpc, &eventService{Server: srv})
+
+	return srv, nil
+}
+
+// ListenAndServe serves calls on listenAddr until the server is stopped.
+func (s *Server) ListenAndServe(listenAddr string) error {
+	lis, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("listening failed: %w", err)
+	}
+
+	return s.Serve(lis)
+}
+
+// Serve serves calls on lis until the server is stopped.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// GracefulStop stops accepting calls and waits for running calls to finish.
+// Watch calls only finish once their clients cancel them.
+func (s *Server) GracefulStop() {
+	s.grpc.GracefulStop()
+}
+
+// Stop cancels all running calls and closes all connections.
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+// batchSize returns the number of records List calls read at once.
+func batchSize(requested int32) int {
+	switch {
+	case requested <= 0:
+		return defaultBatchSize
+	case requested > maxBatchSize:
+		return maxBatchSize
+	default:
+		return int(requested)
+	}
+}
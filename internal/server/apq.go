@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/query"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	errCodeQueryNotAllowed = "PERSISTED_QUERY_NOT_ALLOWED"
+
+	rejectedNotAllowed = "not_allowed"
+
+	// maxPersistedQuerySize is the size in bytes up to which queries are
+	// stored in Postgres.
+	maxPersistedQuerySize = 16 << 10
+)
+
+// queryStore stores persisted queries, see query.Handler.
+type queryStore interface {
+	Get(ctx context.Context, hash string) (string, error)
+	Add(ctx context.Context, hash, query string) error
+}
+
+// postgresQueryCache stores persisted queries in Postgres, so they survive
+// restarts and are shared between instances. Recently used queries are kept
+// in memory.
+//
+// Queries sent by clients are only kept in memory at first. They are stored
+// in Postgres once they passed validation and the operation limits, which is
+// why the cache is also an extension which has to run after those. Stored
+// queries are pruned by a query.Pruner.
+type postgresQueryCache struct {
+	recent *lru.LRU
+	// persisted holds the hashes this instance stored or marked as used.
+	persisted *lru.LRU
+	queries   queryStore
+	logger    *zap.Logger
+}
+
+var _ interface {
+	graphql.Cache
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = &postgresQueryCache{}
+
+// NewPostgresQueryCache returns a cache for automatic persisted queries
+// backed by Postgres, keeping size queries in memory.
+func NewPostgresQueryCache(queries *query.Handler, size int, logger *zap.Logger) graphql.Cache {
+	return &postgresQueryCache{
+		recent:    lru.New(size),
+		persisted: lru.New(size),
+		queries:   queries,
+		logger:    logger,
+	}
+}
+
+func (c *postgresQueryCache) Get(ctx context.Context, hash string) (interface{}, bool) {
+	if q, ok := c.recent.Get(ctx, hash); ok {
+		return q, true
+	}
+
+	q, err := c.queries.Get(ctx, hash)
+	if err != nil {
+		if !errors.Is(err, core.ErrNotFound) {
+			c.logger.Error("getting persisted query failed", zap.Error(err), observability.TraceField(ctx))
+		}
+
+		// The client sends the whole query on a miss, so failures aren't
+		// fatal.
+		return nil, false
+	}
+
+	c.recent.Add(ctx, hash, q)
+
+	return q, true
+}
+
+// Add keeps q in memory only, it is stored in Postgres by
+// MutateOperationContext once it is known to be valid.
+func (c *postgresQueryCache) Add(ctx context.Context, hash string, q interface{}) {
+	c.recent.Add(ctx, hash, q)
+}
+
+func (*postgresQueryCache) ExtensionName() string {
+	return "PostgresQueryCache"
+}
+
+func (*postgresQueryCache) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext stores the persisted query of a valid operation in
+// Postgres, or marks it as used if it is stored already. Errors are logged,
+// the operation is never rejected.
+func (c *postgresQueryCache) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	stats := extension.GetApqStats(ctx)
+	if stats == nil || len(rc.RawQuery) > maxPersistedQuerySize {
+		return nil
+	}
+
+	hash := stats.Hash
+
+	if _, ok := c.persisted.Get(ctx, hash); ok {
+		return nil
+	}
+
+	if err := c.queries.Add(ctx, hash, rc.RawQuery); err != nil {
+		c.logger.Error("adding persisted query failed", zap.Error(err), observability.TraceField(ctx))
+		return nil
+	}
+
+	c.persisted.Add(ctx, hash, struct{}{})
+
+	return nil
+}
+
+// Allowlist maps the hex SHA-256 hashes of known queries to the queries.
+type Allowlist map[string]string
+
+// LoadAllowlist reads an Allowlist from a JSON object of hashes to queries,
+// as generated by the frontend build.
+func LoadAllowlist(path string) (Allowlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading allowlist failed: %w", err)
+	}
+
+	var list Allowlist
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decoding allowlist failed: %w", err)
+	}
+
+	for hash, q := range list {
+		if queryHash(q) != hash {
+			return nil, fmt.Errorf("hash %q does not match its query", hash)
+		}
+	}
+
+	return list, nil
+}
+
+func queryHash(q string) string {
+	sum := sha256.Sum256([]byte(q))
+	return hex.EncodeToString(sum[:])
+}
+
+// allowlist resolves hashes of known queries without a round trip. In strict
+// mode, all other queries are rejected. It has to run before
+// extension.AutomaticPersistedQuery.
+type allowlist struct {
+	queries Allowlist
+	strict  bool
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = allowlist{}
+
+func (allowlist) ExtensionName() string {
+	return "Allowlist"
+}
+
+func (allowlist) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (a allowlist) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	hash := persistedQueryHash(rawParams)
+
+	if rawParams.Query == "" {
+		if q, ok := a.queries[hash]; ok {
+			rawParams.Query = q
+			return nil
+		}
+	} else {
+		hash = queryHash(rawParams.Query)
+	}
+
+	if _, ok := a.queries[hash]; a.strict && !ok {
+		observability.Metrics.TrackRejectedOperation(rejectedNotAllowed)
+
+		err := gqlerror.Errorf("query is not allowed")
+		errcode.Set(err, errCodeQueryNotAllowed)
+
+		return err
+	}
+
+	return nil
+}
+
+// persistedQueryHash returns the hash sent in the APQ extension, if any.
+func persistedQueryHash(rawParams *graphql.RawParams) string {
+	ext, ok := rawParams.Extensions["persistedQuery"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	hash, _ := ext["sha256Hash"].(string)
+
+	return hash
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+func TestLoadAllowlist(t *testing.T) {
+	const q = "{ getArtists { id } }"
+
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"`+queryHash(q)+`": "`+q+`"}`), 0o600))
+
+	list, err := LoadAllowlist(valid)
+	require.NoError(t, err)
+	assert.Equal(t, Allowlist{queryHash(q): q}, list)
+
+	mismatch := filepath.Join(dir, "mismatch.json")
+	require.NoError(t, os.WriteFile(mismatch, []byte(`{"`+queryHash("other")+`": "`+q+`"}`), 0o600))
+
+	_, err = LoadAllowlist(mismatch)
+	assert.Error(t, err)
+
+	_, err = LoadAllowlist(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestAllowlist(t *testing.T) {
+	const (
+		known   = "{ getArtists { id } }"
+		unknown = "{ getLocations { id } }"
+	)
+
+	queries := Allowlist{queryHash(known): known}
+
+	persisted := func(hash string) map[string]interface{} {
+		return map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": float64(1), "sha256Hash": hash},
+		}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		strict    bool
+		params    graphql.RawParams
+		wantQuery string
+		wantErr   bool
+	}{
+		{name: "known hash is resolved", params: graphql.RawParams{Extensions: persisted(queryHash(known))}, wantQuery: known},
+		{name: "unknown hash is left to APQ", params: graphql.RawParams{Extensions: persisted(queryHash(unknown))}},
+		{name: "unknown query", params: graphql.RawParams{Query: unknown}, wantQuery: unknown},
+		{name: "strict known hash", strict: true, params: graphql.RawParams{Extensions: persisted(queryHash(known))}, wantQuery: known},
+		{name: "strict known query", strict: true, params: graphql.RawParams{Query: known}, wantQuery: known},
+		{name: "strict unknown hash", strict: true, params: graphql.RawParams{Extensions: persisted(queryHash(unknown))}, wantErr: true},
+		{name: "strict unknown query", strict: true, params: graphql.RawParams{Query: unknown}, wantErr: true},
+		{name: "strict unknown query with known hash", strict: true, params: graphql.RawParams{Query: unknown, Extensions: persisted(queryHash(known))}, wantErr: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := allowlist{queries: queries, strict: tc.strict}
+
+			err := a.MutateOperationParameters(context.Background(), &tc.params)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				assert.Equal(t, errCodeQueryNotAllowed, err.Extensions["code"])
+				return
+			}
+
+			require.Nil(t, err)
+			assert.Equal(t, tc.wantQuery, tc.params.Query)
+		})
+	}
+}
+
+type fakeQueryStore struct {
+	queries map[string]string
+	added   int
+}
+
+func (f *fakeQueryStore) Get(_ context.Context, hash string) (string, error) {
+	q, ok := f.queries[hash]
+	if !ok {
+		return "", core.ErrNotFound
+	}
+
+	return q, nil
+}
+
+func (f *fakeQueryStore) Add(_ context.Context, hash, query string) error {
+	f.queries[hash] = query
+	f.added++
+	return nil
+}
+
+func TestPostgresQueryCache(t *testing.T) {
+	const q = "{ getArtists { id } }"
+
+	newCache := func() (*postgresQueryCache, *fakeQueryStore) {
+		store := &fakeQueryStore{queries: map[string]string{}}
+		return &postgresQueryCache{
+			recent:    lru.New(10),
+			persisted: lru.New(10),
+			queries:   store,
+			logger:    zap.NewNop(),
+		}, store
+	}
+
+	// request runs the APQ extension like the handler does and returns the
+	// context the operation context mutators get.
+	request := func(t *testing.T, c *postgresQueryCache, params *graphql.RawParams) (context.Context, *graphql.OperationContext) {
+		rc := &graphql.OperationContext{}
+		ctx := graphql.WithOperationContext(context.Background(), rc)
+
+		require.Nil(t, extension.AutomaticPersistedQuery{Cache: c}.MutateOperationParameters(ctx, params))
+		rc.RawQuery = params.Query
+
+		return ctx, rc
+	}
+
+	persisted := map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": float64(1), "sha256Hash": queryHash(q)},
+	}
+
+	t.Run("queries are only stored once valid", func(t *testing.T) {
+		c, store := newCache()
+
+		ctx, rc := request(t, c, &graphql.RawParams{Query: q, Extensions: persisted})
+		assert.Empty(t, store.queries)
+
+		require.Nil(t, c.MutateOperationContext(ctx, rc))
+		assert.Equal(t, map[string]string{queryHash(q): q}, store.queries)
+	})
+
+	t.Run("queries are marked as used once per instance", func(t *testing.T) {
+		c, store := newCache()
+		store.queries[queryHash(q)] = q
+
+		for i := 0; i < 3; i++ {
+			ctx, rc := request(t, c, &graphql.RawParams{Extensions: persisted})
+			assert.Equal(t, q, rc.RawQuery)
+			require.Nil(t, c.MutateOperationContext(ctx, rc))
+		}
+
+		assert.Equal(t, 1, store.added)
+	})
+
+	t.Run("large queries are not stored", func(t *testing.T) {
+		c, store := newCache()
+
+		large := "{ getArtists { id " + strings.Repeat("firstName ", maxPersistedQuerySize/10) + "} }"
+		ext := map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": float64(1), "sha256Hash": queryHash(large)},
+		}
+
+		ctx, rc := request(t, c, &graphql.RawParams{Query: large, Extensions: ext})
+		require.Nil(t, c.MutateOperationContext(ctx, rc))
+		assert.Empty(t, store.queries)
+	})
+
+	t.Run("queries without hash are not stored", func(t *testing.T) {
+		c, store := newCache()
+
+		ctx, rc := request(t, c, &graphql.RawParams{Query: q})
+		require.Nil(t, c.MutateOperationContext(ctx, rc))
+		assert.Empty(t, store.queries)
+	})
+}
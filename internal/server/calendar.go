@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal"
+	"github.com/obitech/artist-db/internal/calendar"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/location"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+func (s *Server) calendarRoutes(r chi.Router) {
+	r.Get("/events.ics", s.eventsCalendar)
+	r.Get("/locations/{id}.ics", s.locationCalendar)
+	r.Get("/artists/{id}.ics", s.artistCalendar)
+}
+
+// eventsCalendar serves all events.
+func (s *Server) eventsCalendar(w http.ResponseWriter, r *http.Request) {
+	s.serveCalendar(w, r, "events", internal.Name, event.All())
+}
+
+// locationCalendar serves all events at a location.
+func (s *Server) locationCalendar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, core.ErrInvalidUUID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	locations, err := s.db.LocationHandler.Get(r.Context(), location.ByID(id))
+	if err != nil {
+		s.calendarError(w, r, err)
+		return
+	}
+
+	s.serveCalendar(w, r, "location-"+id, locations[0].Name, event.ByLocationID(id))
+}
+
+// artistCalendar serves all events an artist is invited to.
+func (s *Server) artistCalendar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, core.ErrInvalidUUID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	artists, err := s.db.ArtistHandler.Get(r.Context(), artist.ByID(id))
+	if err != nil {
+		s.calendarError(w, r, err)
+		return
+	}
+
+	s.serveCalendar(w, r, "artist-"+id, artistName(artists[0]), event.ByArtistID(id))
+}
+
+func (s *Server) serveCalendar(w http.ResponseWriter, r *http.Request, filename, name string, req event.GetRequest) {
+	ctx := r.Context()
+
+	events, err := s.db.EventHandler.Get(ctx, req)
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		s.calendarError(w, r, err)
+		return
+	}
+
+	cal, err := s.calendar(ctx, name, events)
+	if err != nil {
+		s.calendarError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", calendar.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename+".ics"))
+
+	if err := cal.Encode(w); err != nil {
+		s.logger.Error("writing calendar failed", zap.Error(err), observability.TraceField(ctx))
+	}
+}
+
+// calendar converts events to a Calendar. Events without a start time are
+// skipped.
+func (s *Server) calendar(ctx context.Context, name string, events []*event.Event) (*calendar.Calendar, error) {
+	var (
+		cal       = &calendar.Calendar{Name: name}
+		locations = make(map[string]string)
+		artists   = make(map[string]string)
+	)
+
+	for _, ev := range events {
+		if ev.StartTime == nil {
+			continue
+		}
+
+		ce := calendar.Event{
+			UID:          ev.ID + "@" + internal.Name,
+			Summary:      ev.Name,
+			Start:        *ev.StartTime,
+			End:          ev.EndTime,
+			LastModified: ev.UpdatedAt,
+		}
+
+		if ev.LocationID != nil {
+			loc, err := s.calendarLocation(ctx, locations, *ev.LocationID)
+			if err != nil {
+				return nil, err
+			}
+
+			ce.Location = loc
+		}
+
+		lineUp, err := s.lineUp(ctx, artists, ev.InvitedArtists)
+		if err != nil {
+			return nil, err
+		}
+
+		schedule, err := s.schedule(ctx, artists, ev)
+		if err != nil {
+			return nil, err
+		}
+
+		ce.Description = strings.TrimSpace(lineUp + "\n\n" + schedule)
+
+		if ev.Recurrence != nil {
+			ce.TZ = ev.Location()
+			ce.RRule = ev.Recurrence.String()
+			ce.ExDates = ev.Exceptions
+		}
+
+		cal.Events = append(cal.Events, ce)
+		cal.Events = append(cal.Events, overrides(ce, ev.Overrides)...)
+	}
+
+	sort.SliceStable(cal.Events, func(i, j int) bool {
+		return cal.Events[i].Start.Before(cal.Events[j].Start)
+	})
+
+	return cal, nil
+}
+
+// overrides returns an entry for each overridden occurrence of a recurring
+// event.
+func overrides(recurring calendar.Event, overrides []event.Override) []calendar.Event {
+	var out []calendar.Event
+
+	for _, o := range overrides {
+		recurrenceID := o.RecurrenceID
+
+		ce := recurring
+		ce.RRule, ce.ExDates = "", nil
+		ce.RecurrenceID = &recurrenceID
+		ce.Start = o.StartTime
+		ce.End = o.EndTime
+
+		if o.Name != "" {
+			ce.Summary = o.Name
+		}
+
+		out = append(out, ce)
+	}
+
+	return out
+}
+
+// calendarLocation returns the name and address of a location. Results are
+// cached in seen.
+func (s *Server) calendarLocation(ctx context.Context, seen map[string]string, id string) (string, error) {
+	if loc, ok := seen[id]; ok {
+		return loc, nil
+	}
+
+	locations, err := s.db.LocationHandler.Get(ctx, location.ByID(id))
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			seen[id] = ""
+			return "", nil
+		}
+
+		return "", fmt.Errorf("retrieving location failed: %w", err)
+	}
+
+	loc := locations[0].Name
+	if addr := locations[0].Address.String(); addr != "" {
+		loc += ", " + addr
+	}
+
+	seen[id] = loc
+
+	return loc, nil
+}
+
+// lineUp describes the invited artists of an event. Names are cached in
+// seen.
+func (s *Server) lineUp(ctx context.Context, seen map[string]string, invited event.InvitedArtists) (string, error) {
+	if len(invited) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Line-up:")
+
+	for _, ia := range invited {
+		name, err := s.calendarArtist(ctx, seen, ia.ID)
+		if err != nil {
+			return "", err
+		}
+
+		if name == "" {
+			continue
+		}
+
+		b.WriteString("\n- " + name)
+
+		if !ia.Confirmed {
+			b.WriteString(" (unconfirmed)")
+		}
+	}
+
+	return b.String(), nil
+}
+
+// schedule describes the slots of an event in its time zone. Names are cached
+// in seen.
+func (s *Server) schedule(ctx context.Context, seen map[string]string, ev *event.Event) (string, error) {
+	if len(ev.Slots) == 0 {
+		return "", nil
+	}
+
+	tz := ev.Location()
+
+	var b strings.Builder
+	b.WriteString("Schedule:")
+
+	for _, slot := range ev.Slots {
+		name, err := s.calendarArtist(ctx, seen, slot.ArtistID)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(fmt.Sprintf("\n- %s-%s %s",
+			slot.StartTime.In(tz).Format("Mon 02 Jan 15:04"),
+			slot.EndTime.In(tz).Format("15:04"),
+			name,
+		))
+
+		if slot.Stage != "" {
+			b.WriteString(" @ " + slot.Stage)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// calendarArtist returns the display name of an artist. Results are cached in
+// seen.
+func (s *Server) calendarArtist(ctx context.Context, seen map[string]string, id string) (string, error) {
+	if name, ok := seen[id]; ok {
+		return name, nil
+	}
+
+	artists, err := s.db.ArtistHandler.Get(ctx, artist.ByID(id))
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		return "", fmt.Errorf("retrieving artist failed: %w", err)
+	}
+
+	var name string
+	if len(artists) > 0 {
+		name = artistName(artists[0])
+	}
+
+	seen[id] = name
+
+	return name, nil
+}
+
+func (s *Server) calendarError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, core.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	msg := "building calendar failed"
+	s.logger.Error(msg, zap.Error(err), observability.TraceField(r.Context()))
+
+	http.Error(w, msg, http.StatusInternalServerError)
+}
+
+func artistName(a *artist.Artist) string {
+	if a.ArtistName != "" {
+		return a.ArtistName
+	}
+
+	return strings.TrimSpace(a.FirstName + " " + a.LastName)
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/jackc/pgconn"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/money"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	errCodeTimeout  = "TIMEOUT"
+	errCodeConflict = "SCHEDULE_CONFLICT"
+
+	errCodeBadRequest        = "BAD_REQUEST"
+	errCodeInvalidUUID       = "INVALID_UUID"
+	errCodeNotFound          = "NOT_FOUND"
+	errCodeAlreadyExists     = "ALREADY_EXISTS"
+	errCodeReferenceNotFound = "REFERENCE_NOT_FOUND"
+	errCodeNoExchangeRate    = "NO_EXCHANGE_RATE"
+	errCodeAnonymized        = "ANONYMIZED"
+	errCodeInternal          = "INTERNAL"
+	errCodeRateLimited       = "RATE_LIMITED"
+)
+
+const (
+	timeoutSourceOperation = "operation"
+	timeoutSourceStatement = "statement"
+)
+
+const (
+	sqlStateForeignKeyViolation = "23503"
+	sqlStateUniqueViolation     = "23505"
+	sqlStateCheckViolation      = "23514"
+)
+
+// constraintFields maps constraints of the database to the input fields they
+// validate, relative to the input object of the mutation.
+var constraintFields = map[string]string{
+	"fk_locations":                     "locationID",
+	"artist_event_artist_id_fkey":      "invitedArtists.id",
+	"fk_artist_event":                  "slots.artistID",
+	"event_budget_lines_event_id_fkey": "eventID",
+	"artist_consents_artist_id_fkey":   "artistID",
+	"events_end_after_start":           "end",
+	"events_publish_window":            "publishUntil",
+	"event_slots_end_after_start":      "slots.end",
+	"event_overrides_end_after_start":  "end",
+	"artist_event_fee_valid":           "invitedArtists.fee",
+	"event_budget_lines_amounts_valid": "planned",
+}
+
+// apiError is what clients get to see of an error.
+type apiError struct {
+	status    int
+	code      string
+	message   string
+	field     string
+	traceID   string
+	conflicts []map[string]interface{}
+}
+
+// internalError hides an unexpected error behind the trace ID of ctx.
+func internalError(ctx context.Context) apiError {
+	return apiError{
+		status:  http.StatusInternalServerError,
+		code:    errCodeInternal,
+		message: "internal error",
+		traceID: observability.ExtractTraceID(ctx),
+	}
+}
+
+// classify returns the apiError matching err. It returns false for
+// unexpected errors, whose message must not be revealed.
+func classify(err error) (apiError, bool) {
+	var (
+		inputErr    *graph.InputError
+		conflictErr *event.ConflictError
+		pgErr       *pgconn.PgError
+	)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidUUID):
+		return apiError{status: http.StatusBadRequest, code: errCodeInvalidUUID, message: core.ErrInvalidUUID.Error()}, true
+	case errors.As(err, &inputErr):
+		return apiError{status: http.StatusBadRequest, code: errCodeBadRequest, message: inputErr.Error()}, true
+	case errors.Is(err, core.ErrInvalidInput):
+		return apiError{status: http.StatusBadRequest, code: errCodeBadRequest, message: from(err, core.ErrInvalidInput)}, true
+	case errors.Is(err, core.ErrNotFound):
+		return apiError{status: http.StatusNotFound, code: errCodeNotFound, message: core.ErrNotFound.Error()}, true
+	case errors.As(err, &conflictErr):
+		return apiError{
+			status:    http.StatusConflict,
+			code:      errCodeConflict,
+			message:   conflictErr.Error(),
+			conflicts: graph.ConflictExtensions(conflictErr.Conflicts...),
+		}, true
+	case errors.Is(err, artist.ErrAnonymized):
+		return apiError{status: http.StatusConflict, code: errCodeAnonymized, message: artist.ErrAnonymized.Error()}, true
+	case errors.Is(err, money.ErrNoRate):
+		return apiError{status: http.StatusUnprocessableEntity, code: errCodeNoExchangeRate, message: from(err, money.ErrNoRate)}, true
+	case errors.Is(err, errRateLimited):
+		return apiError{status: http.StatusTooManyRequests, code: errCodeRateLimited, message: errRateLimited.Error()}, true
+	case core.IsTimeout(err):
+		return apiError{status: http.StatusGatewayTimeout, code: errCodeTimeout, message: "operation timed out"}, true
+	case errors.As(err, &pgErr):
+		return classifyConstraint(pgErr)
+	default:
+		return apiError{}, false
+	}
+}
+
+// classifyConstraint returns the apiError matching a violated constraint.
+// Other errors of the database are unexpected.
+func classifyConstraint(pgErr *pgconn.PgError) (apiError, bool) {
+	e := apiError{field: constraintFields[pgErr.ConstraintName]}
+
+	switch pgErr.Code {
+	case sqlStateForeignKeyViolation:
+		e.status, e.code, e.message = http.StatusUnprocessableEntity, errCodeReferenceNotFound, "referenced resource not found"
+	case sqlStateUniqueViolation:
+		e.status, e.code, e.message = http.StatusConflict, errCodeAlreadyExists, "resource already exists"
+	case sqlStateCheckViolation:
+		e.status, e.code, e.message = http.StatusBadRequest, errCodeBadRequest, "invalid input"
+	default:
+		return apiError{}, false
+	}
+
+	if e.field != "" {
+		e.message += ": " + e.field
+	}
+
+	return e, true
+}
+
+// from returns the message of err starting at the message of target, which
+// drops the context added by wrapping but keeps details added after target.
+func from(err, target error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, target.Error()); i >= 0 {
+		return msg[i:]
+	}
+
+	return target.Error()
+}
+
+// presentError converts errors returned by resolvers into GraphQL errors.
+// Unexpected errors are replaced by an internal error carrying the trace ID,
+// so that they can be looked up in the logs.
+func (s *Server) presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	// Errors of extensions already carry a code.
+	if _, ok := gqlErr.Extensions["code"]; ok {
+		return gqlErr
+	}
+
+	if core.IsTimeout(err) {
+		source := timeoutSourceStatement
+		if errors.Is(err, context.DeadlineExceeded) {
+			source = timeoutSourceOperation
+		}
+
+		observability.Metrics.TrackTimeout(source)
+		s.logger.Warn("operation timed out",
+			zap.String("source", source),
+			zap.Error(err),
+			observability.TraceField(ctx),
+		)
+	}
+
+	apiErr, ok := classify(err)
+	if !ok {
+		// Errors of gqlgen, e.g. of arguments which couldn't be parsed, only
+		// describe the request.
+		var parsed *gqlerror.Error
+		if errors.As(err, &parsed) {
+			return gqlErr
+		}
+
+		s.logger.Error("operation failed", zap.Error(err), observability.TraceField(ctx))
+		apiErr = internalError(ctx)
+	}
+
+	gqlErr.Message = apiErr.message
+	errcode.Set(gqlErr, apiErr.code)
+
+	if apiErr.field != "" {
+		gqlErr.Extensions["field"] = apiErr.field
+	}
+
+	if apiErr.traceID != "" {
+		gqlErr.Extensions["traceID"] = apiErr.traceID
+	}
+
+	if apiErr.conflicts != nil {
+		gqlErr.Extensions["conflicts"] = apiErr.conflicts
+	}
+
+	return gqlErr
+}
+
+// jsonError is the body of failed requests to the JSON APIs.
+type jsonError struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      string                   `json:"code"`
+	Message   string                   `json:"message"`
+	Field     string                   `json:"field,omitempty"`
+	TraceID   string                   `json:"traceID,omitempty"`
+	Conflicts []map[string]interface{} `json:"conflicts,omitempty"`
+}
+
+// writeJSONError responds with the status and code matching err. Unexpected
+// errors are logged and their message is not revealed.
+func (s *Server) writeJSONError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr, ok := classify(err)
+	if !ok {
+		s.logger.Error("request failed", zap.Error(err), observability.TraceField(r.Context()))
+		apiErr = internalError(r.Context())
+	}
+
+	body := errorBody{
+		Code:      apiErr.code,
+		Message:   apiErr.message,
+		Field:     apiErr.field,
+		TraceID:   apiErr.traceID,
+		Conflicts: apiErr.conflicts,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.status)
+
+	if err := json.NewEncoder(w).Encode(jsonError{Error: body}); err != nil {
+		s.logger.Error("writing error failed", zap.Error(err), observability.TraceField(r.Context()))
+	}
+}
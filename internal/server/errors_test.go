@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/money"
+)
+
+func TestPresentError(t *testing.T) {
+	s := &Server{logger: zap.NewNop()}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode interface{}
+	}{
+		{"operation deadline", fmt.Errorf("query failed: %w", context.DeadlineExceeded), errCodeTimeout},
+		{"statement timeout", fmt.Errorf("query failed: %w", &pgconn.PgError{Code: "57014"}), errCodeTimeout},
+		{"schedule conflict", fmt.Errorf("upsert failed: %w", &event.ConflictError{Conflicts: []event.Conflict{{Kind: event.ConflictStage}}}), errCodeConflict},
+		{"not found", fmt.Errorf("get failed: %w", core.ErrNotFound), errCodeNotFound},
+		{"invalid input", &graph.InputError{Err: errors.New("empty name")}, errCodeBadRequest},
+		{"rejected input", fmt.Errorf("override failed: %w: occurrence ends before it starts", core.ErrInvalidInput), errCodeBadRequest},
+		{"missing reference", fmt.Errorf("upsert failed: %w", &pgconn.PgError{Code: "23503", ConstraintName: "fk_locations"}), errCodeReferenceNotFound},
+		{"no exchange rate", fmt.Errorf("get failed: %w", money.ErrNoRate), errCodeNoExchangeRate},
+		{"other error", errors.New("boom"), errCodeInternal},
+		{"error of gqlgen", gqlerror.Errorf("cannot parse argument"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gqlErr := s.presentError(context.Background(), tt.err)
+
+			assert.Equal(t, tt.wantCode, gqlErr.Extensions["code"])
+		})
+	}
+
+	t.Run("codes of extensions are kept", func(t *testing.T) {
+		err := gqlerror.Errorf("too complex")
+		errcode.Set(err, "COMPLEXITY_LIMIT_EXCEEDED")
+
+		gqlErr := s.presentError(context.Background(), err)
+
+		assert.Equal(t, "COMPLEXITY_LIMIT_EXCEEDED", gqlErr.Extensions["code"])
+		assert.Equal(t, "too complex", gqlErr.Message)
+	})
+
+	t.Run("wrapped messages are not revealed", func(t *testing.T) {
+		gqlErr := s.presentError(context.Background(), fmt.Errorf("fetching artist %q: %w", "secret", core.ErrNotFound))
+
+		assert.Equal(t, core.ErrNotFound.Error(), gqlErr.Message)
+	})
+
+	t.Run("violated constraints name the field", func(t *testing.T) {
+		err := &pgconn.PgError{Code: "23503", ConstraintName: "fk_artist_event", Detail: `Key (artist_id)=(...) is not present`}
+
+		gqlErr := s.presentError(context.Background(), fmt.Errorf("upsert failed: %w", err))
+
+		assert.Equal(t, "slots.artistID", gqlErr.Extensions["field"])
+		assert.NotContains(t, gqlErr.Message, "artist_id")
+	})
+
+	t.Run("rejected input of handlers is a bad request", func(t *testing.T) {
+		ctx := context.Background()
+		h := event.NewHandler(nil, zap.NewNop(), otel.GetTracerProvider())
+		id := uuid.NewString()
+		now := time.Now()
+
+		_, occurrencesErr := h.Occurrences(ctx, now, now.Add(-time.Hour))
+		_, conflictsErr := h.Conflicts(ctx, now, now)
+
+		for name, tt := range map[string]struct {
+			err     error
+			message string
+		}{
+			"fee":         {h.SetFee(ctx, id, id, &event.Fee{Amount: -1}), "invalid input: invalid fee:"},
+			"budget line": {h.UpsertBudgetLines(ctx, &event.BudgetLine{Category: "bribes"}), "invalid input: invalid budget line:"},
+			"occurrences": {occurrencesErr, "invalid input: range ends before it starts"},
+			"conflicts":   {conflictsErr, "invalid input: range ends before it starts"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				require.ErrorIs(t, tt.err, core.ErrInvalidInput)
+
+				gqlErr := s.presentError(ctx, fmt.Errorf("upsert failed: %w", tt.err))
+
+				assert.Equal(t, errCodeBadRequest, gqlErr.Extensions["code"])
+				assert.True(t, strings.HasPrefix(gqlErr.Message, tt.message), gqlErr.Message)
+			})
+		}
+	})
+
+	t.Run("internal errors are hidden behind the trace ID", func(t *testing.T) {
+		gqlErr := s.presentError(context.Background(), errors.New("connection refused"))
+
+		assert.Equal(t, "internal error", gqlErr.Message)
+		assert.Contains(t, gqlErr.Extensions, "traceID")
+	})
+}
+
+func TestWriteJSONError(t *testing.T) {
+	s := &Server{logger: zap.NewNop()}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid UUID", fmt.Errorf("get failed: %w", core.ErrInvalidUUID), http.StatusBadRequest, errCodeInvalidUUID},
+		{"invalid input", &graph.InputError{Err: errors.New("empty name")}, http.StatusBadRequest, errCodeBadRequest},
+		{"not found", fmt.Errorf("get failed: %w", core.ErrNotFound), http.StatusNotFound, errCodeNotFound},
+		{"schedule conflict", &event.ConflictError{}, http.StatusConflict, errCodeConflict},
+		{"anonymized", fmt.Errorf("upsert failed: %w", artist.ErrAnonymized), http.StatusConflict, errCodeAnonymized},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, errCodeTimeout},
+		{"no exchange rate", fmt.Errorf("get failed: %w", money.ErrNoRate), http.StatusUnprocessableEntity, errCodeNoExchangeRate},
+		{"missing reference", &pgconn.PgError{Code: "23503"}, http.StatusUnprocessableEntity, errCodeReferenceNotFound},
+		{"duplicate", &pgconn.PgError{Code: "23505"}, http.StatusConflict, errCodeAlreadyExists},
+		{"check violation", &pgconn.PgError{Code: "23514", ConstraintName: "events_end_after_start"}, http.StatusBadRequest, errCodeBadRequest},
+		{"other database error", &pgconn.PgError{Code: "42P01"}, http.StatusInternalServerError, errCodeInternal},
+		{"other error", errors.New("connection refused"), http.StatusInternalServerError, errCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.writeJSONError(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+
+			var body jsonError
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantCode, body.Error.Code)
+		})
+	}
+
+	t.Run("internal errors are hidden", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.writeJSONError(w, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("connection refused"))
+
+		assert.NotContains(t, w.Body.String(), "connection refused")
+		assert.Contains(t, w.Body.String(), "traceID")
+	})
+
+	t.Run("violated constraints name the field", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.writeJSONError(w, httptest.NewRequest(http.MethodGet, "/", nil), &pgconn.PgError{Code: "23514", ConstraintName: "events_publish_window"})
+
+		var body jsonError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+
+		assert.Equal(t, "publishUntil", body.Error.Field)
+	})
+}
+
+func TestFrom(t *testing.T) {
+	err := fmt.Errorf("get failed: %w", fmt.Errorf("%w for USD", money.ErrNoRate))
+
+	assert.Equal(t, "no exchange rate for USD", from(err, money.ErrNoRate))
+	assert.Equal(t, core.ErrNotFound.Error(), from(err, core.ErrNotFound))
+}
@@ -1,8 +1,142 @@
 package server
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	checkStatusOK   = "ok"
+	checkStatusFail = "fail"
+)
+
+var errMigrationsOutdated = errors.New("database schema is not up to date")
+
+// checkResult is the outcome of a single readiness check.
+type checkResult struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Latency string                 `json:"latency"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// readinessReport is returned by the readiness endpoint.
+type readinessReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
 
 // health is a simple healthcheck which returns OK and 200.
 func (s *Server) health(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("OK"))
 }
+
+// livez reports whether the process is alive. It doesn't check any
+// dependencies, so a failing database won't get the process restarted.
+func (s *Server) livez(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("OK"))
+}
+
+// readyz reports whether the service is able to serve traffic. Every
+// dependency is checked and returns a JSON breakdown. If any check fails,
+// 503 is returned.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.readinessTimeout)
+	defer cancel()
+
+	report := readinessReport{
+		Status: checkStatusOK,
+		Checks: map[string]checkResult{
+			"database":   runCheck(ctx, s.checkDatabase),
+			"migrations": runCheck(ctx, s.checkMigrations),
+			"pool":       runCheck(ctx, s.checkPool),
+		},
+	}
+
+	code := http.StatusOK
+	for name, res := range report.Checks {
+		if res.Status != checkStatusOK {
+			report.Status = checkStatusFail
+			code = http.StatusServiceUnavailable
+
+			s.logger.Warn("readiness check failed", zap.String("check", name), zap.String("error", res.Error))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		s.logger.Error("writing readiness report failed", zap.Error(err))
+	}
+}
+
+type checkFunc func(ctx context.Context) (map[string]interface{}, error)
+
+func runCheck(ctx context.Context, fn checkFunc) checkResult {
+	start := time.Now()
+	details, err := fn(ctx)
+
+	res := checkResult{
+		Status:  checkStatusOK,
+		Latency: time.Since(start).String(),
+		Details: details,
+	}
+
+	if err != nil {
+		res.Status = checkStatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func (s *Server) checkDatabase(ctx context.Context) (map[string]interface{}, error) {
+	return nil, s.db.Ready(ctx)
+}
+
+func (s *Server) checkMigrations(ctx context.Context) (map[string]interface{}, error) {
+	status, err := s.db.MigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"current":  status.Current,
+		"expected": status.Expected,
+		"dirty":    status.Dirty,
+	}
+
+	if !status.UpToDate() {
+		return details, errMigrationsOutdated
+	}
+
+	return details, nil
+}
+
+// checkPool reports the saturation of the connection pool. It never fails,
+// since under load every replica saturates at once and none would be left
+// to serve traffic.
+func (s *Server) checkPool(_ context.Context) (map[string]interface{}, error) {
+	stat := s.db.PoolStat()
+
+	var saturation float64
+	if stat.MaxConns() > 0 {
+		saturation = float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+	}
+
+	details := map[string]interface{}{
+		"acquired":   stat.AcquiredConns(),
+		"idle":       stat.IdleConns(),
+		"total":      stat.TotalConns(),
+		"max":        stat.MaxConns(),
+		"saturation": saturation,
+	}
+
+	return details, nil
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	errCodeComplexity = "COMPLEXITY_LIMIT_EXCEEDED"
+	errCodeDepth      = "DEPTH_LIMIT_EXCEEDED"
+
+	rejectedComplexity = "complexity"
+	rejectedDepth      = "depth"
+
+	costDirective = "cost"
+)
+
+// fieldCost is the @cost directive of a field.
+type fieldCost struct {
+	weight      int
+	multipliers []string
+	listSize    int
+}
+
+// costSchema computes field complexities from the @cost directives of the
+// schema. Fields without directive cost 1 plus their selections.
+type costSchema struct {
+	graphql.ExecutableSchema
+	costs map[string]fieldCost
+}
+
+func newCostSchema(es graphql.ExecutableSchema) (*costSchema, error) {
+	s := &costSchema{ExecutableSchema: es, costs: make(map[string]fieldCost)}
+
+	for _, def := range es.Schema().Types {
+		for _, field := range def.Fields {
+			d := field.Directives.ForName(costDirective)
+			if d == nil {
+				continue
+			}
+
+			cost := fieldCost{weight: 1}
+
+			for _, arg := range d.Arguments {
+				v, err := arg.Value.Value(nil)
+				if err != nil {
+					return nil, fmt.Errorf("invalid cost of %s.%s: %w", def.Name, field.Name, err)
+				}
+
+				switch arg.Name {
+				case "weight":
+					cost.weight = int(v.(int64))
+				case "listSize":
+					cost.listSize = int(v.(int64))
+				case "multipliers":
+					for _, m := range v.([]interface{}) {
+						cost.multipliers = append(cost.multipliers, m.(string))
+					}
+				}
+			}
+
+			s.costs[def.Name+"."+field.Name] = cost
+		}
+	}
+
+	return s, nil
+}
+
+func (s *costSchema) Complexity(typeName, field string, childComplexity int, args map[string]interface{}) (int, bool) {
+	cost, ok := s.costs[typeName+"."+field]
+	if !ok {
+		return s.ExecutableSchema.Complexity(typeName, field, childComplexity, args)
+	}
+
+	return saturatingMul(cost.weight+childComplexity, cost.items(args)), true
+}
+
+// items returns the number of items a field is expected to return.
+func (c fieldCost) items(args map[string]interface{}) int {
+	var (
+		n   int
+		set bool
+	)
+
+	for _, name := range c.multipliers {
+		switch v := args[name].(type) {
+		case []interface{}:
+			n, set = n+len(v), true
+		case int:
+			n, set = n+v, true
+		case int64:
+			n, set = n+int(v), true
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				n, set = n+int(i), true
+			}
+		}
+	}
+
+	switch {
+	case set:
+		return n
+	case c.listSize > 0:
+		return c.listSize
+	default:
+		return 1
+	}
+}
+
+// saturatingMul multiplies non-negative a and b without overflowing, so huge
+// inputs can't wrap around below the limit.
+func saturatingMul(a, b int) int {
+	const maxInt = int(^uint(0) >> 1)
+
+	if a <= 0 || b <= 0 {
+		return a
+	}
+
+	if a > maxInt/b {
+		return maxInt
+	}
+
+	return a * b
+}
+
+// operationLimits rejects operations exceeding a complexity or a depth before
+// they are executed. Zero disables a limit.
+type operationLimits struct {
+	maxComplexity int
+	maxDepth      int
+
+	es graphql.ExecutableSchema
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = &operationLimits{}
+
+func (*operationLimits) ExtensionName() string {
+	return "OperationLimits"
+}
+
+func (l *operationLimits) Validate(es graphql.ExecutableSchema) error {
+	l.es = es
+	return nil
+}
+
+func (l *operationLimits) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if l.maxDepth > 0 {
+		if depth := selectionDepth(rc.Operation.SelectionSet); depth > l.maxDepth {
+			observability.Metrics.TrackRejectedOperation(rejectedDepth)
+
+			err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, l.maxDepth)
+			errcode.Set(err, errCodeDepth)
+
+			return err
+		}
+	}
+
+	if l.maxComplexity > 0 {
+		if c := complexity.Calculate(l.es, rc.Operation, rc.Variables); c > l.maxComplexity {
+			observability.Metrics.TrackRejectedOperation(rejectedComplexity)
+
+			err := gqlerror.Errorf("operation has complexity %d, which exceeds the limit of %d", c, l.maxComplexity)
+			errcode.Set(err, errCodeComplexity)
+			err.Extensions["complexity"] = c
+			err.Extensions["limit"] = l.maxComplexity
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectionDepth returns the number of nested fields. Introspection isn't
+// counted, so clients can still fetch the schema.
+func selectionDepth(set ast.SelectionSet) int {
+	var depth int
+
+	for _, selection := range set {
+		var d int
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Name == "__schema" || s.Name == "__type" {
+				continue
+			}
+
+			d = 1 + selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			d = selectionDepth(s.Definition.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet)
+		}
+
+		if d > depth {
+			depth = d
+		}
+	}
+
+	return depth
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+
+	"github.com/obitech/artist-db/graph/generated"
+)
+
+func TestCostSchema(t *testing.T) {
+	es, err := newCostSchema(generated.NewExecutableSchema(generated.Config{}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		vars  map[string]interface{}
+		want  int
+	}{
+		{
+			name:  "fields without cost",
+			query: `{ getWebhookDeliveries(webhookID: "1", limit: 2) { id } }`,
+			want:  (1 + 1) * 2,
+		},
+		{
+			name:  "input list multiplies",
+			query: `{ getArtists(input: [{id: "1"}, {id: "2"}, {id: "3"}]) { id lastName } }`,
+			want:  (1 + 2) * 3,
+		},
+		{
+			name:  "variables multiply",
+			query: `query q($in: [GetArtistInput!]) { getArtists(input: $in) { id } }`,
+			vars:  map[string]interface{}{"in": []interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}}},
+			want:  (1 + 1) * 2,
+		},
+		{
+			name:  "page size is assumed without input",
+			query: `{ getArtists { id } }`,
+			want:  (1 + 1) * 20,
+		},
+		{
+			name:  "limit multiplies",
+			query: `{ getArtists(limit: 100) { id } }`,
+			want:  (1 + 1) * 100,
+		},
+		{
+			name:  "nested costs",
+			query: `{ getEvents(input: [{id: "1"}]) { id location { id } artists { confirmed } } }`,
+			want:  (2 + 1 + (2 + 1) + (2+1)*10) * 1,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			doc, gqlErr := gqlparser.LoadQuery(es.Schema(), tc.query)
+			require.Nil(t, gqlErr)
+
+			assert.Equal(t, tc.want, complexity.Calculate(es, doc.Operations[0], tc.vars))
+		})
+	}
+}
+
+func TestOperationLimits(t *testing.T) {
+	es, err := newCostSchema(generated.NewExecutableSchema(generated.Config{}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		limits   operationLimits
+		query    string
+		wantCode string
+	}{
+		{
+			name:   "within limits",
+			limits: operationLimits{maxComplexity: 100, maxDepth: 4},
+			query:  `{ getEvents(input: [{id: "1"}]) { artists { artist { id } } } }`,
+		},
+		{
+			name:     "too deep",
+			limits:   operationLimits{maxDepth: 3},
+			query:    `{ getEvents(input: [{id: "1"}]) { slots { artist { id } } } }`,
+			wantCode: errCodeDepth,
+		},
+		{
+			name:     "fragments count towards depth",
+			limits:   operationLimits{maxDepth: 3},
+			query:    `{ getEvents(input: [{id: "1"}]) { ...f } } fragment f on Event { slots { artist { id } } }`,
+			wantCode: errCodeDepth,
+		},
+		{
+			name:   "introspection is not counted",
+			limits: operationLimits{maxDepth: 1},
+			query:  `{ __schema { types { fields { type { ofType { name } } } } } }`,
+		},
+		{
+			name:     "too complex",
+			limits:   operationLimits{maxComplexity: 99},
+			query:    `{ getArtists { id firstName lastName email } }`,
+			wantCode: errCodeComplexity,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			doc, gqlErr := gqlparser.LoadQuery(es.Schema(), tc.query)
+			require.Nil(t, gqlErr)
+
+			require.NoError(t, tc.limits.Validate(es))
+
+			err := tc.limits.MutateOperationContext(context.Background(), &graphql.OperationContext{Operation: doc.Operations[0]})
+			if tc.wantCode == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			require.NotNil(t, err)
+			assert.Equal(t, tc.wantCode, err.Extensions["code"])
+		})
+	}
+}
+
+func TestGQLHandler_Limits(t *testing.T) {
+	s, err := NewServer(nil, WithOperationLimits(10, 0))
+	require.NoError(t, err)
+
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf(`{id: \"%d\"}`, i)
+	}
+
+	body := `{"query": "{ getArtists(input: [` + strings.Join(ids, ",") + `]) { id } }"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	assert.Contains(t, rec.Body.String(), errCodeComplexity)
+}
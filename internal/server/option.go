@@ -2,8 +2,15 @@ package server
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/internal/money"
 )
 
 // Option allows customization of the default Server.
@@ -20,3 +27,146 @@ func WithLogger(logger *zap.Logger) Option {
 		return nil
 	}
 }
+
+// WithReadinessTimeout sets the deadline for all readiness checks combined.
+func WithReadinessTimeout(timeout time.Duration) Option {
+	return func(s *Server) error {
+		if timeout <= 0 {
+			return errors.New("readiness timeout must be positive")
+		}
+
+		s.readinessTimeout = timeout
+		return nil
+	}
+}
+
+// WithOperationTimeout sets the deadline for a single GraphQL operation. Zero
+// disables the deadline.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(s *Server) error {
+		if timeout < 0 {
+			return errors.New("operation timeout must not be negative")
+		}
+
+		s.operationTimeout = timeout
+		return nil
+	}
+}
+
+// WithPublicMaxAge sets how long responses of the public API may be cached.
+// Zero requires revalidation on every request.
+func WithPublicMaxAge(maxAge time.Duration) Option {
+	return func(s *Server) error {
+		if maxAge < 0 {
+			return errors.New("public max age must not be negative")
+		}
+
+		s.publicMaxAge = maxAge
+		return nil
+	}
+}
+
+// WithSubscriber sets the source of changes for GraphQL subscriptions.
+func WithSubscriber(subscriber graph.Subscriber) Option {
+	return func(s *Server) error {
+		if subscriber == nil {
+			return errors.New("subscriber is nil")
+		}
+
+		s.subscriber = subscriber
+		return nil
+	}
+}
+
+// WithExchangeRates sets the rates amounts are converted between currencies
+// with. Without them, only amounts in the same currency can be summed up.
+func WithExchangeRates(rates *money.Rates) Option {
+	return func(s *Server) error {
+		if rates == nil {
+			return errors.New("exchange rates are nil")
+		}
+
+		s.rates = rates
+		return nil
+	}
+}
+
+// WithAPQCache sets where automatic persisted queries are stored.
+func WithAPQCache(cache graphql.Cache) Option {
+	return func(s *Server) error {
+		if cache == nil {
+			return errors.New("APQ cache is nil")
+		}
+
+		s.apqCache = cache
+		return nil
+	}
+}
+
+// WithAllowlist lets clients refer to known queries by hash. If strict is
+// set, no other queries are accepted.
+func WithAllowlist(queries Allowlist, strict bool) Option {
+	return func(s *Server) error {
+		if strict && len(queries) == 0 {
+			return errors.New("strict mode requires allowed queries")
+		}
+
+		s.allowlist = &allowlist{queries: queries, strict: strict}
+		return nil
+	}
+}
+
+// WithOperationLimits rejects GraphQL operations exceeding a complexity, as
+// defined by the @cost directives of the schema, or a depth. Zero disables a
+// limit.
+func WithOperationLimits(maxComplexity, maxDepth int) Option {
+	return func(s *Server) error {
+		if maxComplexity < 0 || maxDepth < 0 {
+			return errors.New("operation limits must not be negative")
+		}
+
+		s.limits = &operationLimits{maxComplexity: maxComplexity, maxDepth: maxDepth}
+		return nil
+	}
+}
+
+// WithRateLimit allows each client rate requests per second with bursts of
+// up to burst requests. Clients are identified by one of apiKeys, or by IP.
+func WithRateLimit(rate float64, burst int, apiKeys ...string) Option {
+	return func(s *Server) error {
+		if rate <= 0 || burst < 1 {
+			return errors.New("rate and burst must be positive")
+		}
+
+		s.limiter = newRateLimiter(rate, burst, apiKeys...)
+		return nil
+	}
+}
+
+// WithTrustedProxies identifies rate limited clients behind the given
+// proxies by X-Forwarded-For instead of by their address. Proxies are IPs or
+// CIDR ranges.
+func WithTrustedProxies(proxies ...string) Option {
+	return func(s *Server) error {
+		for _, p := range proxies {
+			if ip := net.ParseIP(p); ip != nil {
+				bits := 8 * len(ip)
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+
+				s.trustedProxies = append(s.trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+
+			_, network, err := net.ParseCIDR(p)
+			if err != nil {
+				return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+			}
+
+			s.trustedProxies = append(s.trustedProxies, network)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/location"
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+// The public API only exposes published events, their confirmed artists and
+// locations. Its types are separate from the database types, so that new
+// fields are never published by accident.
+
+type publicEvent struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	Start      *time.Time       `json:"start,omitempty"`
+	End        *time.Time       `json:"end,omitempty"`
+	Timezone   string           `json:"timezone"`
+	Recurrence string           `json:"recurrence,omitempty"`
+	Exceptions []time.Time      `json:"exceptions,omitempty"`
+	Overrides  []publicOverride `json:"overrides,omitempty"`
+	Location   *publicLocation  `json:"location,omitempty"`
+	Artists    []publicArtist   `json:"artists"`
+	Slots      []publicSlot     `json:"slots"`
+}
+
+type publicOverride struct {
+	RecurrenceID time.Time  `json:"recurrenceID"`
+	Name         string     `json:"name,omitempty"`
+	Start        time.Time  `json:"start"`
+	End          *time.Time `json:"end,omitempty"`
+}
+
+type publicLocation struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Street  string `json:"street,omitempty"`
+	Zip     string `json:"zip,omitempty"`
+	City    string `json:"city,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+type publicArtist struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	BioGerman  string `json:"bioGerman,omitempty"`
+	BioEnglish string `json:"bioEnglish,omitempty"`
+	Instagram  string `json:"instagram,omitempty"`
+	Facebook   string `json:"facebook,omitempty"`
+	Bandcamp   string `json:"bandcamp,omitempty"`
+}
+
+type publicSlot struct {
+	ArtistID string    `json:"artistID"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Stage    string    `json:"stage,omitempty"`
+}
+
+func (s *Server) publicRoutes(r chi.Router) {
+	r.Get("/events", s.publicEvents)
+	r.Get("/events/{id}", s.publicEvent)
+}
+
+// publicEvents serves all events which are currently published, ordered by
+// start.
+func (s *Server) publicEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	events, err := s.db.EventHandler.Get(ctx, event.PublishedAt(time.Now()))
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		s.publicError(w, r, err)
+		return
+	}
+
+	out, err := s.publicEventList(ctx, events)
+	if err != nil {
+		s.publicError(w, r, err)
+		return
+	}
+
+	s.writePublic(w, r, struct {
+		Events []publicEvent `json:"events"`
+	}{out})
+}
+
+// publicEvent serves a single event if it is currently published.
+func (s *Server) publicEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		s.publicError(w, r, core.ErrInvalidUUID)
+		return
+	}
+
+	events, err := s.db.EventHandler.Get(ctx, event.ByID(id))
+	if err != nil {
+		s.publicError(w, r, err)
+		return
+	}
+
+	// Unpublished events don't exist as far as the public is concerned.
+	if !events[0].PublishedAt(time.Now()) {
+		s.publicError(w, r, core.ErrNotFound)
+		return
+	}
+
+	out, err := s.publicEventList(ctx, events)
+	if err != nil {
+		s.publicError(w, r, err)
+		return
+	}
+
+	s.writePublic(w, r, out[0])
+}
+
+// publicEventList converts events, looking up their locations and confirmed
+// artists.
+func (s *Server) publicEventList(ctx context.Context, events []*event.Event) ([]publicEvent, error) {
+	var ids []string
+	for _, ev := range events {
+		for _, ia := range ev.InvitedArtists {
+			if ia.Confirmed {
+				ids = append(ids, ia.ID)
+			}
+		}
+	}
+
+	profiles, err := s.db.ArtistHandler.Profiles(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving artists failed: %w", err)
+	}
+
+	locations := make(map[string]*publicLocation)
+
+	out := make([]publicEvent, 0, len(events))
+	for _, ev := range events {
+		pe := publicEvent{
+			ID:         ev.ID,
+			Name:       ev.Name,
+			Start:      ev.StartTime,
+			End:        ev.EndTime,
+			Timezone:   ev.Location().String(),
+			Exceptions: ev.Exceptions,
+			Artists:    []publicArtist{},
+			Slots:      []publicSlot{},
+		}
+
+		if ev.Recurrence != nil {
+			pe.Recurrence = ev.Recurrence.String()
+		}
+
+		for _, o := range ev.Overrides {
+			pe.Overrides = append(pe.Overrides, publicOverride{
+				RecurrenceID: o.RecurrenceID,
+				Name:         o.Name,
+				Start:        o.StartTime,
+				End:          o.EndTime,
+			})
+		}
+
+		if ev.LocationID != nil {
+			if pe.Location, err = s.publicLocation(ctx, locations, *ev.LocationID); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, ia := range ev.InvitedArtists {
+			p, ok := profiles[ia.ID]
+			if !ia.Confirmed || !ok {
+				continue
+			}
+
+			pe.Artists = append(pe.Artists, publicArtist{
+				ID:         p.ID,
+				Name:       p.Name,
+				BioGerman:  p.BioGerman,
+				BioEnglish: p.BioEnglish,
+				Instagram:  p.Socials.Instagram,
+				Facebook:   p.Socials.Facebook,
+				Bandcamp:   p.Socials.Bandcamp,
+			})
+		}
+
+		for _, slot := range ev.Slots {
+			if _, ok := profiles[slot.ArtistID]; !ok || !confirmed(ev.InvitedArtists, slot.ArtistID) {
+				continue
+			}
+
+			pe.Slots = append(pe.Slots, publicSlot{
+				ArtistID: slot.ArtistID,
+				Start:    slot.StartTime,
+				End:      slot.EndTime,
+				Stage:    slot.Stage,
+			})
+		}
+
+		out = append(out, pe)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		switch {
+		case out[j].Start == nil:
+			return out[i].Start != nil
+		case out[i].Start == nil:
+			return false
+		default:
+			return out[i].Start.Before(*out[j].Start)
+		}
+	})
+
+	return out, nil
+}
+
+// publicLocation returns a location. Results are cached in seen.
+func (s *Server) publicLocation(ctx context.Context, seen map[string]*publicLocation, id string) (*publicLocation, error) {
+	if loc, ok := seen[id]; ok {
+		return loc, nil
+	}
+
+	locations, err := s.db.LocationHandler.Get(ctx, location.ByID(id))
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		return nil, fmt.Errorf("retrieving location failed: %w", err)
+	}
+
+	var loc *publicLocation
+	if len(locations) > 0 {
+		l := locations[0]
+		loc = &publicLocation{
+			ID:      l.ID,
+			Name:    l.Name,
+			Street:  l.Address.Street,
+			Zip:     l.Address.Zip,
+			City:    l.Address.City,
+			Country: l.Address.Country,
+		}
+	}
+
+	seen[id] = loc
+
+	return loc, nil
+}
+
+func confirmed(invited event.InvitedArtists, id string) bool {
+	for _, ia := range invited {
+		if ia.ID == id {
+			return ia.Confirmed
+		}
+	}
+
+	return false
+}
+
+// writePublic writes v as JSON which may be cached by browsers and proxies.
+// The ETag is a hash of the body, so unchanged responses are revalidated
+// without sending them again.
+func (s *Server) writePublic(w http.ResponseWriter, r *http.Request, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		s.publicError(w, r, fmt.Errorf("encoding response failed: %w", err))
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	h := w.Header()
+	h.Set("Cache-Control", publicCacheControl(s.publicMaxAge))
+	h.Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := buf.WriteTo(w); err != nil {
+		s.logger.Error("writing response failed", zap.Error(err), observability.TraceField(r.Context()))
+	}
+}
+
+// publicCacheControl lets clients use responses for maxAge, and serve stale
+// responses for a day while revalidating or for a week if the API fails.
+func publicCacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+
+	return fmt.Sprintf("public, max-age=%d, stale-while-revalidate=86400, stale-if-error=604800", int(maxAge.Seconds()))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) publicError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeJSONError(w, r, err)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWritePublic(t *testing.T) {
+	s := &Server{logger: zap.NewNop(), publicMaxAge: 5 * time.Minute}
+
+	serve := func(method, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/public/events", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		w := httptest.NewRecorder()
+		s.writePublic(w, r, map[string]string{"name": "festival"})
+
+		return w
+	}
+
+	w := serve(http.MethodGet, "")
+	etag := w.Header().Get("ETag")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=300, stale-while-revalidate=86400, stale-if-error=604800", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"name": "festival"}`, w.Body.String())
+	assert.NotEmpty(t, etag)
+
+	t.Run("same response has the same ETag", func(t *testing.T) {
+		assert.Equal(t, etag, serve(http.MethodGet, "").Header().Get("ETag"))
+	})
+
+	t.Run("matching ETag is not modified", func(t *testing.T) {
+		for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+			w := serve(http.MethodGet, header)
+
+			assert.Equal(t, http.StatusNotModified, w.Code, header)
+			assert.Empty(t, w.Body.String(), header)
+		}
+	})
+
+	t.Run("other ETag is sent again", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, `"other"`).Code)
+	})
+
+	t.Run("head has no body", func(t *testing.T) {
+		w := serve(http.MethodHead, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("caching can be disabled", func(t *testing.T) {
+		assert.Equal(t, "no-cache", publicCacheControl(0))
+	})
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/obitech/artist-db/internal/observability"
+)
+
+const (
+	apiKeyHeader       = "X-API-Key"
+	forwardedForHeader = "X-Forwarded-For"
+
+	// rateLimitSweepInterval is how often buckets of idle clients are
+	// removed.
+	rateLimitSweepInterval = time.Minute
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// rateLimiter is a token bucket per client. Clients sending one of apiKeys
+// get a bucket of their own, all others share one per IP. Behind one of
+// trustedProxies, the IP is taken from X-Forwarded-For.
+type rateLimiter struct {
+	rate           float64
+	burst          float64
+	apiKeys        map[string]bool
+	trustedProxies []*net.IPNet
+	now            func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter allows rate requests per second and bursts of burst
+// requests per client.
+func newRateLimiter(rate float64, burst int, apiKeys ...string) *rateLimiter {
+	l := &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		apiKeys: make(map[string]bool, len(apiKeys)),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+
+	for _, key := range apiKeys {
+		l.apiKeys[key] = true
+	}
+
+	return l
+}
+
+// key identifies the client of r. Unknown API keys are ignored, so clients
+// can't evade the limit by sending a new key with every request.
+func (l *rateLimiter) key(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); l.apiKeys[key] {
+		return "key:" + key
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	// Proxies append the address they received the request from, so the
+	// client is the last address not added by a trusted proxy. Anything
+	// before it may be forged.
+	forwarded := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(forwarded) - 1; i >= 0 && l.trusted(host); i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+
+		host = ip.String()
+	}
+
+	return "ip:" + host
+}
+
+// trusted returns true if host is the address of a trusted proxy.
+func (l *rateLimiter) trusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range l.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allow takes a token from the bucket of key. If there is none, it returns
+// how long until the next one is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// sweep removes buckets which have been refilled completely, as they are the
+// same as new ones.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimit responds with 429 Too Many Requests to clients exceeding their
+// rate.
+func (s *Server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := s.limiter.allow(s.limiter.key(r))
+		if !ok {
+			observability.Metrics.TrackRateLimited()
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			s.writeJSONError(w, r, errRateLimited)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
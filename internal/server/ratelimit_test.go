@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2022, 6, 2, 20, 0, 0, 0, time.UTC)
+
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.allow("a")
+		assert.True(t, ok, "burst request %d", i)
+	}
+
+	ok, retryAfter := l.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _ = l.allow("b")
+	assert.True(t, ok, "clients have separate buckets")
+
+	now = now.Add(500 * time.Millisecond)
+
+	ok, _ = l.allow("a")
+	assert.True(t, ok, "bucket is refilled")
+
+	ok, _ = l.allow("a")
+	assert.False(t, ok)
+
+	// Idle clients are removed once their bucket is full again.
+	now = now.Add(rateLimitSweepInterval)
+	_, _ = l.allow("c")
+
+	assert.NotContains(t, l.buckets, "a")
+	assert.NotContains(t, l.buckets, "b")
+}
+
+func TestRateLimiter_Key(t *testing.T) {
+	l := newRateLimiter(1, 1, "known")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	assert.Equal(t, "ip:192.0.2.1", l.key(req))
+
+	req.Header.Set(apiKeyHeader, "unknown")
+	assert.Equal(t, "ip:192.0.2.1", l.key(req))
+
+	req.Header.Set(apiKeyHeader, "known")
+	assert.Equal(t, "key:known", l.key(req))
+}
+
+func TestRateLimiter_KeyBehindProxy(t *testing.T) {
+	s, err := NewServer(nil, WithRateLimit(1, 1), WithTrustedProxies("10.0.0.0/8", "192.0.2.1"))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "untrusted proxy", remoteAddr: "198.51.100.1:1234", forwarded: []string{"203.0.113.1"}, want: "ip:198.51.100.1"},
+		{name: "trusted proxy", remoteAddr: "192.0.2.1:1234", forwarded: []string{"203.0.113.1"}, want: "ip:203.0.113.1"},
+		{name: "trusted proxy without header", remoteAddr: "192.0.2.1:1234", want: "ip:192.0.2.1"},
+		{name: "chain of trusted proxies", remoteAddr: "10.0.0.1:1234", forwarded: []string{"203.0.113.1, 10.0.0.2"}, want: "ip:203.0.113.1"},
+		{name: "forged addresses are ignored", remoteAddr: "10.0.0.1:1234", forwarded: []string{"198.51.100.7", "203.0.113.1"}, want: "ip:203.0.113.1"},
+		{name: "invalid address", remoteAddr: "10.0.0.1:1234", forwarded: []string{"203.0.113.1, unknown"}, want: "ip:10.0.0.1"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+
+			for _, v := range tc.forwarded {
+				req.Header.Add(forwardedForHeader, v)
+			}
+
+			assert.Equal(t, tc.want, s.limiter.key(req))
+		})
+	}
+
+	_, err = NewServer(nil, WithTrustedProxies("proxy"))
+	assert.Error(t, err)
+}
+
+func TestRateLimit(t *testing.T) {
+	s, err := NewServer(nil, WithRateLimit(1, 1))
+	require.NoError(t, err)
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, restPrefix+"/openapi.json", nil))
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get().Code)
+
+	rec := get()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), errCodeRateLimited)
+}
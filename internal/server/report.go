@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/money"
+	"github.com/obitech/artist-db/internal/observability"
+	"github.com/obitech/artist-db/internal/report"
+)
+
+func (s *Server) reportRoutes(r chi.Router) {
+	r.Get("/budget.csv", s.budgetReport)
+}
+
+// budgetReport serves the costs of the events given by the event query
+// parameter, or of all events. If the currency parameter is set, costs are
+// converted at the exchange rates valid on the date given by the at parameter,
+// which defaults to today.
+func (s *Server) budgetReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var at *time.Time
+	if v := query.Get("at"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "invalid date: "+v, http.StatusBadRequest)
+			return
+		}
+
+		at = &t
+	}
+
+	conv, err := graph.NewConversion(s.rates, query.Get("currency"), at)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The report is buffered, so errors can still be returned.
+	var buf bytes.Buffer
+	if err := graph.WriteBudgetCSV(ctx, s.db, &buf, conv, query["event"]...); err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidUUID), errors.Is(err, money.ErrNoRate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			msg := "building report failed"
+			s.logger.Error(msg, zap.Error(err), observability.TraceField(ctx))
+
+			http.Error(w, msg, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", report.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="budget.csv"`)
+
+	if _, err := buf.WriteTo(w); err != nil {
+		s.logger.Error("writing report failed", zap.Error(err), observability.TraceField(ctx))
+	}
+}
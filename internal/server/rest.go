@@ -0,0 +1,526 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/observability"
+	"github.com/obitech/artist-db/internal/openapi"
+)
+
+const (
+	restPrefix       = "/api/v1"
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// The REST API offers the CRUD operations of the GraphQL API to clients which
+// can't speak GraphQL. It calls the same resolvers and returns the same
+// types, so both APIs behave alike.
+
+type artistPage struct {
+	Items []*model.Artist `json:"items"`
+	// Next is the cursor of the next page, unset on the last page.
+	Next *string `json:"next"`
+}
+
+type locationPage struct {
+	Items []*model.Location `json:"items"`
+	Next  *string           `json:"next"`
+}
+
+type eventPage struct {
+	Items []*model.Event `json:"items"`
+	Next  *string        `json:"next"`
+}
+
+// restOperation is a route of the REST API. The router and the OpenAPI
+// document are both built from them.
+type restOperation struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	tag     string
+	// params are the query parameters, path parameters are derived from path.
+	params   []openapi.Parameter
+	body     interface{}
+	status   int
+	response interface{}
+	handler  http.HandlerFunc
+}
+
+var pageParams = []openapi.Parameter{
+	{Name: "limit", In: "query", Description: fmt.Sprintf("page size, at most %d", maxPageLimit), Schema: &openapi.Schema{Type: "integer", Format: "int32"}},
+	{Name: "after", In: "query", Description: "cursor returned as next by the previous page", Schema: &openapi.Schema{Type: "string", Format: "uuid"}},
+}
+
+var conflictParams = []openapi.Parameter{
+	{Name: "allowConflicts", In: "query", Description: "store the event despite schedule conflicts", Schema: &openapi.Schema{Type: "boolean"}},
+}
+
+func (s *Server) restOperations() []restOperation {
+	return []restOperation{
+		{http.MethodGet, "/artists", "listArtists", "List artists", "artists", pageParams, nil, http.StatusOK, artistPage{}, s.listArtists},
+		{http.MethodPost, "/artists", "createArtist", "Create an artist", "artists", nil, model.ArtistInput{}, http.StatusCreated, model.Artist{}, s.putArtist},
+		{http.MethodGet, "/artists/{id}", "getArtist", "Get an artist", "artists", nil, nil, http.StatusOK, model.Artist{}, s.getArtist},
+		{http.MethodPut, "/artists/{id}", "putArtist", "Create or replace an artist", "artists", nil, model.ArtistInput{}, http.StatusOK, model.Artist{}, s.putArtist},
+		{http.MethodDelete, "/artists/{id}", "deleteArtist", "Delete an artist", "artists", nil, nil, http.StatusNoContent, nil, s.deleteArtist},
+
+		{http.MethodGet, "/locations", "listLocations", "List locations", "locations", pageParams, nil, http.StatusOK, locationPage{}, s.listLocations},
+		{http.MethodPost, "/locations", "createLocation", "Create a location", "locations", nil, model.LocationInput{}, http.StatusCreated, model.Location{}, s.putLocation},
+		{http.MethodGet, "/locations/{id}", "getLocation", "Get a location", "locations", nil, nil, http.StatusOK, model.Location{}, s.getLocation},
+		{http.MethodPut, "/locations/{id}", "putLocation", "Create or replace a location", "locations", nil, model.LocationInput{}, http.StatusOK, model.Location{}, s.putLocation},
+		{http.MethodDelete, "/locations/{id}", "deleteLocation", "Delete a location", "locations", nil, nil, http.StatusNoContent, nil, s.deleteLocation},
+
+		{http.MethodGet, "/events", "listEvents", "List events", "events", pageParams, nil, http.StatusOK, eventPage{}, s.listEvents},
+		{http.MethodPost, "/events", "createEvent", "Create an event", "events", conflictParams, model.EventInput{}, http.StatusCreated, model.Event{}, s.putEvent},
+		{http.MethodGet, "/events/{id}", "getEvent", "Get an event", "events", nil, nil, http.StatusOK, model.Event{}, s.getEvent},
+		{http.MethodPut, "/events/{id}", "putEvent", "Create or replace an event", "events", conflictParams, model.EventInput{}, http.StatusOK, model.Event{}, s.putEvent},
+		{http.MethodDelete, "/events/{id}", "deleteEvent", "Delete an event", "events", nil, nil, http.StatusNoContent, nil, s.deleteEvent},
+	}
+}
+
+func (s *Server) restRoutes(r chi.Router) {
+	if s.operationTimeout > 0 {
+		r.Use(s.restTimeout)
+	}
+
+	ops := s.restOperations()
+
+	spec, err := json.Marshal(restDocument(ops))
+	if err != nil {
+		// The document is built from static types only.
+		panic(fmt.Sprintf("encoding OpenAPI document: %v", err))
+	}
+
+	r.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if _, err := w.Write(spec); err != nil {
+			s.logger.Error("writing OpenAPI document failed", zap.Error(err), observability.TraceField(r.Context()))
+		}
+	})
+
+	for _, op := range ops {
+		r.Method(op.method, op.path, op.handler)
+	}
+}
+
+// restTimeout limits requests to the operation timeout, like GraphQL
+// operations.
+func (s *Server) restTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), s.operationTimeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// restDocument describes ops as OpenAPI document.
+func restDocument(ops []restOperation) openapi.Document {
+	schemas := openapi.Schemas{}
+	for _, enum := range []interface{}{
+		model.AllFeeType,
+		model.AllPaymentStatus,
+	} {
+		schemas.Enum(enum)
+	}
+
+	errorResponse := &openapi.Response{
+		Description: "error",
+		Content:     openapi.JSON(schemas.Of(jsonError{})),
+	}
+
+	doc := openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:   internal.Name,
+			Version: internal.Version,
+		},
+		Servers: []openapi.Server{{URL: restPrefix}},
+		Paths:   make(map[string]openapi.PathItem),
+		Tags:    []openapi.Tag{{Name: "artists"}, {Name: "locations"}, {Name: "events"}},
+	}
+
+	for _, op := range ops {
+		o := &openapi.Operation{
+			OperationID: op.id,
+			Summary:     op.summary,
+			Tags:        []string{op.tag},
+			Parameters:  op.params,
+			Responses: map[string]*openapi.Response{
+				"default": errorResponse,
+			},
+		}
+
+		if strings.Contains(op.path, "{id}") {
+			o.Parameters = append([]openapi.Parameter{{
+				Name:     "id",
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: "string", Format: "uuid"},
+			}}, o.Parameters...)
+		}
+
+		if op.body != nil {
+			o.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  openapi.JSON(schemas.Of(op.body)),
+			}
+		}
+
+		resp := &openapi.Response{Description: http.StatusText(op.status)}
+		if op.response != nil {
+			resp.Content = openapi.JSON(schemas.Of(op.response))
+		}
+
+		o.Responses[strconv.Itoa(op.status)] = resp
+
+		if doc.Paths[op.path] == nil {
+			doc.Paths[op.path] = openapi.PathItem{}
+		}
+
+		doc.Paths[op.path][strings.ToLower(op.method)] = o
+	}
+
+	doc.Components.Schemas = schemas
+
+	return doc
+}
+
+// page returns the cursor and limit of a list request.
+func page(r *http.Request) (string, int, error) {
+	query := r.URL.Query()
+
+	after := query.Get("after")
+	if after != "" {
+		if _, err := uuid.Parse(after); err != nil {
+			return "", 0, core.ErrInvalidUUID
+		}
+	}
+
+	limit := defaultPageLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return "", 0, &graph.InputError{Err: fmt.Errorf("limit must be between 1 and %d", maxPageLimit)}
+		}
+
+		limit = n
+	}
+
+	return after, limit, nil
+}
+
+// next returns the cursor of the page after a full page ending with lastID.
+func next(n, limit int, lastID string) *string {
+	if n < limit {
+		return nil
+	}
+
+	return &lastID
+}
+
+// pathID returns the ID path parameter.
+func pathID(r *http.Request) (string, error) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", core.ErrInvalidUUID
+	}
+
+	return id, nil
+}
+
+// decodeBody decodes the JSON request body into v. The ID of v is set to the
+// path parameter for PUT requests, and must not be set by POST requests.
+func decodeBody(r *http.Request, v interface{}, id **string) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return &graph.InputError{Err: fmt.Errorf("decoding body: %w", err)}
+	}
+
+	if r.Method == http.MethodPost {
+		if *id != nil {
+			return &graph.InputError{Err: errors.New("id must not be set, use PUT to create with an ID")}
+		}
+
+		return nil
+	}
+
+	pid, err := pathID(r)
+	if err != nil {
+		return err
+	}
+
+	if *id != nil && **id != pid {
+		return &graph.InputError{Err: errors.New("id of body and path differ")}
+	}
+
+	*id = &pid
+
+	return nil
+}
+
+// writeJSON responds with v encoded as JSON.
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("writing response failed", zap.Error(err), observability.TraceField(r.Context()))
+	}
+}
+
+// created responds with the status of a successful POST or PUT.
+func created(r *http.Request) int {
+	if r.Method == http.MethodPost {
+		return http.StatusCreated
+	}
+
+	return http.StatusOK
+}
+
+func (s *Server) listArtists(w http.ResponseWriter, r *http.Request) {
+	after, limit, err := page(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	artists, err := s.resolver.ArtistPage(r.Context(), after, limit)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	p := artistPage{Items: artists}
+	if len(artists) > 0 {
+		p.Next = next(len(artists), limit, artists[len(artists)-1].ID)
+	}
+
+	if p.Items == nil {
+		p.Items = []*model.Artist{}
+	}
+
+	s.writeJSON(w, r, http.StatusOK, p)
+}
+
+func (s *Server) getArtist(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	artists, err := s.resolver.Query().GetArtists(r.Context(), []*model.GetArtistInput{{ID: &id}}, nil, nil)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, artists[0])
+}
+
+func (s *Server) putArtist(w http.ResponseWriter, r *http.Request) {
+	var input model.ArtistInput
+	if err := decodeBody(r, &input, &input.ID); err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	artists, err := s.resolver.Mutation().UpsertArtists(r.Context(), []*model.ArtistInput{&input})
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, created(r), artists[0])
+}
+
+func (s *Server) deleteArtist(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	if _, err := s.resolver.Mutation().DeleteArtistByID(r.Context(), id); err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listLocations(w http.ResponseWriter, r *http.Request) {
+	after, limit, err := page(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	locations, err := s.resolver.LocationPage(r.Context(), after, limit)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	p := locationPage{Items: locations}
+	if len(locations) > 0 {
+		p.Next = next(len(locations), limit, locations[len(locations)-1].ID)
+	}
+
+	if p.Items == nil {
+		p.Items = []*model.Location{}
+	}
+
+	s.writeJSON(w, r, http.StatusOK, p)
+}
+
+func (s *Server) getLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeLocation(w, r, http.StatusOK, id)
+}
+
+func (s *Server) writeLocation(w http.ResponseWriter, r *http.Request, status int, id string) {
+	locations, err := s.resolver.Query().GetLocations(r.Context(), []*model.GetLocationInput{{ID: &id}})
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, status, locations[0])
+}
+
+func (s *Server) putLocation(w http.ResponseWriter, r *http.Request) {
+	var input model.LocationInput
+	if err := decodeBody(r, &input, &input.ID); err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	ids, err := s.resolver.Mutation().UpsertLocations(r.Context(), []*model.LocationInput{&input})
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeLocation(w, r, created(r), ids[0])
+}
+
+func (s *Server) deleteLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	if _, err := s.resolver.Mutation().DeleteLocationByID(r.Context(), id); err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	after, limit, err := page(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	events, err := s.resolver.EventPage(r.Context(), after, limit)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	p := eventPage{Items: events}
+	if len(events) > 0 {
+		p.Next = next(len(events), limit, events[len(events)-1].ID)
+	}
+
+	if p.Items == nil {
+		p.Items = []*model.Event{}
+	}
+
+	s.writeJSON(w, r, http.StatusOK, p)
+}
+
+func (s *Server) getEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeEvent(w, r, http.StatusOK, id)
+}
+
+func (s *Server) writeEvent(w http.ResponseWriter, r *http.Request, status int, id string) {
+	events, err := s.resolver.Query().GetEvents(r.Context(), []*model.GetEventInput{{ID: &id}}, nil, nil)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, status, events[0])
+}
+
+// putEvent upserts an event. Schedule conflicts are rejected unless the
+// allowConflicts query parameter is true.
+func (s *Server) putEvent(w http.ResponseWriter, r *http.Request) {
+	var input model.EventInput
+	if err := decodeBody(r, &input, &input.ID); err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	allowConflicts := r.URL.Query().Get("allowConflicts") == "true"
+
+	ids, err := s.resolver.Mutation().UpsertEvents(r.Context(), []*model.EventInput{&input}, &allowConflicts)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	s.writeEvent(w, r, created(r), ids[0])
+}
+
+func (s *Server) deleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	if _, err := s.resolver.Mutation().DeleteEventByID(r.Context(), id); err != nil {
+		s.writeJSONError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
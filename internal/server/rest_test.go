@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/graph/model"
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+func TestRestDocument(t *testing.T) {
+	s := &Server{}
+	ops := s.restOperations()
+
+	doc := restDocument(ops)
+
+	for _, op := range ops {
+		o := doc.Paths[op.path][strings.ToLower(op.method)]
+		require.NotNil(t, o, op.id)
+		assert.Equal(t, op.id, o.OperationID)
+	}
+
+	// Every reference has to resolve.
+	raw, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	for _, ref := range strings.Split(string(raw), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		assert.Contains(t, doc.Components.Schemas, name)
+	}
+
+	assert.Equal(t, []string{"id", "firstName", "lastName"}, doc.Components.Schemas["Artist"].Required)
+}
+
+func TestPage(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		query     string
+		wantAfter string
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "defaults", wantLimit: defaultPageLimit},
+		{name: "cursor", query: "after=3f6b0c8a-3ad9-4c5e-8a3e-0c5b7c2a9d11&limit=10", wantAfter: "3f6b0c8a-3ad9-4c5e-8a3e-0c5b7c2a9d11", wantLimit: 10},
+		{name: "invalid cursor", query: "after=foo", wantErr: core.ErrInvalidUUID},
+		{name: "limit too large", query: "limit=501", wantErr: &graph.InputError{}},
+		{name: "limit not a number", query: "limit=ten", wantErr: &graph.InputError{}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			after, limit, err := page(httptest.NewRequest(http.MethodGet, "/artists?"+tc.query, nil))
+
+			switch want := tc.wantErr.(type) {
+			case nil:
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantAfter, after)
+				assert.Equal(t, tc.wantLimit, limit)
+			case *graph.InputError:
+				assert.ErrorAs(t, err, &want)
+			default:
+				assert.ErrorIs(t, err, want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	assert.Nil(t, next(9, 10, "last"))
+	assert.Equal(t, "last", *next(10, 10, "last"))
+}
+
+func TestDecodeBody(t *testing.T) {
+	const id = "3f6b0c8a-3ad9-4c5e-8a3e-0c5b7c2a9d11"
+
+	decode := func(method, body string) (model.LocationInput, error) {
+		r := httptest.NewRequest(method, "/locations/"+id, strings.NewReader(body))
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		var input model.LocationInput
+		err := decodeBody(r, &input, &input.ID)
+
+		return input, err
+	}
+
+	t.Run("put takes ID from path", func(t *testing.T) {
+		input, err := decode(http.MethodPut, `{"name": "club"}`)
+		require.NoError(t, err)
+
+		assert.Equal(t, id, *input.ID)
+		assert.Equal(t, "club", input.Name)
+	})
+
+	t.Run("put with other ID in body", func(t *testing.T) {
+		_, err := decode(http.MethodPut, `{"id": "2c1e5a0b-6f1e-4d43-9b9a-5f0f3c0f5d22", "name": "club"}`)
+
+		var inputErr *graph.InputError
+		assert.ErrorAs(t, err, &inputErr)
+	})
+
+	t.Run("post with ID", func(t *testing.T) {
+		_, err := decode(http.MethodPost, `{"id": "`+id+`", "name": "club"}`)
+
+		var inputErr *graph.InputError
+		assert.ErrorAs(t, err, &inputErr)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := decode(http.MethodPost, `{"name": "club", "capacity": 300}`)
+
+		var inputErr *graph.InputError
+		assert.ErrorAs(t, err, &inputErr)
+	})
+}
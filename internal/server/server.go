@@ -2,13 +2,20 @@ package server
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/riandyrn/otelchi"
 	"go.opentelemetry.io/otel"
@@ -18,24 +25,46 @@ import (
 	"github.com/obitech/artist-db/graph"
 	"github.com/obitech/artist-db/graph/generated"
 	"github.com/obitech/artist-db/internal"
+	"github.com/obitech/artist-db/internal/broker"
 	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/money"
 )
 
 // Server holds API handlers.
 type Server struct {
-	router chi.Router
-	db     *database.Database
-	logger *zap.Logger
-	tracer trace.TracerProvider
+	router     chi.Router
+	db         *database.Database
+	subscriber graph.Subscriber
+	rates      *money.Rates
+	resolver   *graph.Resolver
+	logger     *zap.Logger
+	tracer     trace.TracerProvider
+	apqCache   graphql.Cache
+	allowlist  *allowlist
+	limits     *operationLimits
+	limiter    *rateLimiter
+
+	trustedProxies []*net.IPNet
+
+	readinessTimeout time.Duration
+	operationTimeout time.Duration
+	publicMaxAge     time.Duration
 }
 
 // NewServer returns a server.
 func NewServer(db *database.Database, opts ...Option) (*Server, error) {
 	srv := &Server{
-		router: chi.NewRouter(),
-		db:     db,
-		logger: zap.NewNop(),
-		tracer: otel.GetTracerProvider(),
+		router:     chi.NewRouter(),
+		db:         db,
+		subscriber: broker.New(zap.NewNop()),
+		logger:     zap.NewNop(),
+		tracer:     otel.GetTracerProvider(),
+		apqCache:   lru.New(100),
+		limits:     &operationLimits{maxComplexity: 5000, maxDepth: 10},
+
+		readinessTimeout: 2 * time.Second,
+		operationTimeout: 10 * time.Second,
+		publicMaxAge:     5 * time.Minute,
 	}
 
 	for _, fn := range opts {
@@ -44,14 +73,54 @@ func NewServer(db *database.Database, opts ...Option) (*Server, error) {
 		}
 	}
 
+	if srv.limiter != nil {
+		srv.limiter.trustedProxies = srv.trustedProxies
+	}
+
 	srv.router.Route("/internal", func(r chi.Router) {
 		r.Get("/health", srv.health)
+		r.Get("/livez", srv.livez)
+		r.Get("/readyz", srv.readyz)
 		r.Get("/playground", playground.Handler("GraphQL playground", "/query"))
 		r.Handle("/metrics", promhttp.Handler())
 		r.Method(http.MethodGet, "/pprof/*", middleware.Profiler())
 		r.Get("/version", srv.versionHandler)
 	})
 
+	srv.resolver = graph.NewResolver(srv.db, srv.subscriber, srv.rates, srv.presentError, srv.logger)
+
+	// The public API is read-only and cached, it gets neither the GraphQL
+	// endpoint nor credentials.
+	srv.router.Route("/public", func(r chi.Router) {
+		r.Use(
+			otelchi.Middleware(
+				internal.Name,
+				otelchi.WithTracerProvider(srv.tracer),
+				otelchi.WithPropagators(otel.GetTextMapPropagator()),
+			),
+			cors.Handler(cors.Options{
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{http.MethodGet, http.MethodHead},
+				ExposedHeaders: []string{"ETag"},
+				MaxAge:         86400,
+			}),
+			loggingMiddleware(srv.logger),
+			prometheusMiddleware,
+			middleware.GetHead,
+		)
+
+		if srv.limiter != nil {
+			r.Use(srv.rateLimit)
+		}
+
+		srv.publicRoutes(r)
+	})
+
+	gql, err := srv.gqlHandler()
+	if err != nil {
+		return nil, fmt.Errorf("setting up GraphQL handler failed: %w", err)
+	}
+
 	srv.router.Route("/", func(r chi.Router) {
 		r.Use(
 			otelchi.Middleware(
@@ -64,16 +133,73 @@ func NewServer(db *database.Database, opts ...Option) (*Server, error) {
 			prometheusMiddleware,
 		)
 
-		r.Handle("/query", gqlHandler(db, srv.logger))
+		if srv.limiter != nil {
+			r.Use(srv.rateLimit)
+		}
+
+		r.Handle("/query", gql)
+		r.Route("/calendar", srv.calendarRoutes)
+		r.Route("/reports", srv.reportRoutes)
+		r.Route(restPrefix, srv.restRoutes)
 	})
 
 	return srv, nil
 }
 
-func gqlHandler(db *database.Database, logger *zap.Logger) http.HandlerFunc {
-	h := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: graph.NewResolver(db, logger)}))
+func (s *Server) gqlHandler() (http.HandlerFunc, error) {
+	es, err := newCostSchema(generated.NewExecutableSchema(generated.Config{Resolvers: s.resolver}))
+	if err != nil {
+		return nil, err
+	}
+
+	h := handler.New(es)
+
+	h.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			// Cross-origin requests are allowed for every other transport too.
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+	})
+	h.AddTransport(transport.Options{})
+	h.AddTransport(transport.GET{})
+	h.AddTransport(transport.POST{})
+	h.AddTransport(transport.MultipartForm{})
+
+	h.SetQueryCache(lru.New(1000))
+
+	h.Use(extension.Introspection{})
+
+	if s.allowlist != nil {
+		h.Use(*s.allowlist)
+	}
+
+	h.Use(extension.AutomaticPersistedQuery{
+		Cache: s.apqCache,
+	})
+
+	if s.limits != nil {
+		h.Use(s.limits)
+	}
+
+	// Caches which persist queries only do so for queries which passed the
+	// limits above.
+	if ext, ok := s.apqCache.(interface {
+		graphql.HandlerExtension
+		graphql.OperationContextMutator
+	}); ok {
+		h.Use(ext)
+	}
+
+	if s.operationTimeout > 0 {
+		h.Use(operationTimeout{timeout: s.operationTimeout})
+	}
+
+	h.SetErrorPresenter(s.presentError)
 
-	return h.ServeHTTP
+	return h.ServeHTTP, nil
 }
 
 func (s *Server) versionHandler(w http.ResponseWriter, r *http.Request) {
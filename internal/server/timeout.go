@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// operationTimeout is a gqlgen extension which bounds the time a single
+// GraphQL operation may take. The deadline is passed on to the database
+// through the resolver context. Subscriptions are long-lived and therefore
+// not affected.
+type operationTimeout struct {
+	timeout time.Duration
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = operationTimeout{}
+
+func (o operationTimeout) ExtensionName() string {
+	return "OperationTimeout"
+}
+
+func (o operationTimeout) Validate(_ graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (o operationTimeout) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if op := graphql.GetOperationContext(ctx).Operation; op != nil && op.Operation == ast.Subscription {
+		return next(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	return next(ctx)
+}
@@ -5,11 +5,23 @@ import (
 	"log"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 
+	"github.com/obitech/artist-db/graph"
+	"github.com/obitech/artist-db/internal/broker"
 	"github.com/obitech/artist-db/internal/config"
 	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/outbox"
+	"github.com/obitech/artist-db/internal/database/query"
+	"github.com/obitech/artist-db/internal/database/retention"
+	"github.com/obitech/artist-db/internal/database/webhook"
+	"github.com/obitech/artist-db/internal/encryption"
+	"github.com/obitech/artist-db/internal/money"
 	"github.com/obitech/artist-db/internal/observability"
+	"github.com/obitech/artist-db/internal/rpc"
 	"github.com/obitech/artist-db/internal/server"
 )
 
@@ -33,12 +45,87 @@ func main() {
 
 	defer tp.Shutdown()
 
+	// Change notifications
+	var changes interface {
+		graph.Subscriber
+		core.Publisher
+	}
+
+	switch cfg.ChangeBackend {
+	case "postgres":
+		b, err := broker.NewPostgres(ctx, cfg.DbConnectionString, logger, otel.GetTracerProvider())
+		if err != nil {
+			logger.Fatal("setting up change broker failed", zap.Error(err))
+		}
+
+		defer b.Close()
+		changes = b
+	default:
+		changes = broker.New(logger)
+	}
+
 	// Database
-	db, err := database.NewDatabase(
-		ctx,
-		cfg.DbConnectionString,
+	dbOpts := []database.Option{
 		database.WithLogger(logger),
-	)
+		database.WithPoolConfig(core.PoolConfig{
+			MaxConns:          cfg.DbPool.MaxConns,
+			MinConns:          cfg.DbPool.MinConns,
+			MaxConnLifetime:   cfg.DbPool.MaxConnLifetime,
+			MaxConnIdleTime:   cfg.DbPool.MaxConnIdleTime,
+			HealthCheckPeriod: cfg.DbPool.HealthCheckPeriod,
+			StatementTimeout:  cfg.DbPool.StatementTimeout,
+		}),
+		database.WithReplicas(cfg.DbReplicas.CheckInterval, cfg.DbReplicas.ConnectionStrings...),
+		database.WithPublisher(changes),
+		database.WithOutboxRelay(outbox.RelayConfig{
+			Interval:  cfg.Outbox.Interval,
+			BatchSize: cfg.Outbox.BatchSize,
+			Retention: cfg.Outbox.Retention,
+		}),
+	}
+
+	if cfg.Webhooks.Enabled {
+		dbOpts = append(dbOpts, database.WithWebhooks(webhook.DispatcherConfig{
+			Timeout:     cfg.Webhooks.Timeout,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			Workers:     cfg.Webhooks.Workers,
+
+			AllowInternalTargets: cfg.Webhooks.AllowInternalTargets,
+		}))
+	}
+
+	if cfg.Retention.Enabled {
+		dbOpts = append(dbOpts, database.WithRetention(retention.PurgerConfig{
+			Interval: cfg.Retention.Interval,
+			Policy: retention.Policy{
+				Periods: map[retention.Category]time.Duration{
+					retention.CategoryContact: cfg.Retention.ContactData,
+					retention.CategoryOrigin:  cfg.Retention.OriginData,
+					retention.CategoryProfile: cfg.Retention.ProfileData,
+				},
+				DeletedAfter: cfg.Retention.DeletedRows,
+			},
+		}))
+	}
+
+	if cfg.GraphQL.APQCache == "postgres" {
+		if cfg.GraphQL.APQMaxStored <= 0 {
+			logger.Fatal("maximum number of stored persisted queries must be positive")
+		}
+
+		dbOpts = append(dbOpts, database.WithQueryPruning(query.PrunerConfig{Keep: cfg.GraphQL.APQMaxStored}))
+	}
+
+	enc, err := encryption.NewFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatal("setting up encryption failed", zap.Error(err))
+	}
+
+	if enc != nil {
+		dbOpts = append(dbOpts, database.WithEncryption(enc, cfg.Encryption.Columns...))
+	}
+
+	db, err := database.NewDatabase(ctx, cfg.DbConnectionString, dbOpts...)
 	if err != nil {
 		logger.Fatal("setting up database connection failed", zap.Error(err))
 	}
@@ -57,14 +144,80 @@ func main() {
 	logger.Info("database initialized")
 
 	// Server
-	srv, err := server.NewServer(
-		db,
+	srvOpts := []server.Option{
 		server.WithLogger(logger),
-	)
+		server.WithReadinessTimeout(cfg.ReadinessTimeout),
+		server.WithOperationTimeout(cfg.OperationTimeout),
+		server.WithPublicMaxAge(cfg.PublicMaxAge),
+		server.WithSubscriber(changes),
+		server.WithOperationLimits(cfg.GraphQL.MaxComplexity, cfg.GraphQL.MaxDepth),
+	}
+
+	if cfg.RateLimit.Rate > 0 {
+		srvOpts = append(srvOpts,
+			server.WithRateLimit(cfg.RateLimit.Rate, cfg.RateLimit.Burst, cfg.RateLimit.APIKeys...),
+			server.WithTrustedProxies(cfg.RateLimit.TrustedProxies...),
+		)
+	}
+
+	if cfg.GraphQL.APQCacheSize <= 0 {
+		logger.Fatal("APQ cache size must be positive")
+	}
+
+	switch cfg.GraphQL.APQCache {
+	case "postgres":
+		srvOpts = append(srvOpts, server.WithAPQCache(server.NewPostgresQueryCache(db.QueryHandler, cfg.GraphQL.APQCacheSize, logger)))
+	default:
+		srvOpts = append(srvOpts, server.WithAPQCache(lru.New(cfg.GraphQL.APQCacheSize)))
+	}
+
+	if cfg.GraphQL.AllowlistFile != "" {
+		queries, err := server.LoadAllowlist(cfg.GraphQL.AllowlistFile)
+		if err != nil {
+			logger.Fatal("loading query allowlist failed", zap.Error(err))
+		}
+
+		srvOpts = append(srvOpts, server.WithAllowlist(queries, cfg.GraphQL.Strict))
+	} else if cfg.GraphQL.Strict {
+		logger.Fatal("strict mode requires an allowlist file")
+	}
+
+	if cfg.ExchangeRates.File != "" {
+		rates, err := money.LoadRates(cfg.ExchangeRates.File, cfg.ExchangeRates.Base)
+		if err != nil {
+			logger.Fatal("loading exchange rates failed", zap.Error(err))
+		}
+
+		srvOpts = append(srvOpts, server.WithExchangeRates(rates))
+	}
+
+	srv, err := server.NewServer(db, srvOpts...)
 	if err != nil {
 		logger.Fatal("setting up server failed", zap.Error(err))
 	}
 
+	if cfg.GRPC.ListenAddress != "" {
+		grpcSrv, err := rpc.NewServer(db,
+			rpc.WithLogger(logger),
+			rpc.WithTracerProvider(otel.GetTracerProvider()),
+			rpc.WithSubscriber(changes),
+			rpc.WithTokens(cfg.GRPC.Tokens...),
+		)
+		if err != nil {
+			logger.Fatal("setting up gRPC server failed", zap.Error(err))
+		}
+
+		defer grpcSrv.Stop()
+
+		logger.Info("Starting gRPC server...", zap.String("listenAddress", cfg.GRPC.ListenAddress))
+
+		go func() {
+			if err := grpcSrv.ListenAndServe(cfg.GRPC.ListenAddress); err != nil {
+				logger.Error("gRPC listen failed", zap.Error(err))
+			}
+		}()
+	}
+
 	logger.Info("Starting HTTP server...", zap.String("listenAddress", cfg.ListenAddress))
 
 	if err := srv.ListenAndServe(cfg.ListenAddress); err != nil {
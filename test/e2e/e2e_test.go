@@ -2,6 +2,8 @@ package e2e
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,11 +18,13 @@ import (
 
 	"github.com/obitech/artist-db/graph/model"
 	"github.com/obitech/artist-db/internal/conversion"
+	"github.com/obitech/artist-db/internal/database/artist"
 )
 
 type graphQLResponse struct {
-	Data   data           `json:"data"`
-	Errors []graphQLError `json:"errors"`
+	Data       data                   `json:"data"`
+	Errors     []graphQLError         `json:"errors"`
+	Extensions map[string]interface{} `json:"extensions"`
 }
 
 type data struct {
@@ -28,6 +32,14 @@ type data struct {
 	UpsertArtists    []model.Artist `json:"upsertArtists"`
 	DeleteArtistByID bool           `json:"deleteArtistByID"`
 
+	UpsertArtistsBatch []model.ArtistResult `json:"upsertArtistsBatch"`
+
+	ExportArtistPersonalData string `json:"exportArtistPersonalData"`
+	AnonymizeArtist          bool   `json:"anonymizeArtist"`
+
+	RecordConsent string          `json:"recordConsent"`
+	Consents      []model.Consent `json:"consents"`
+
 	GetLocations       []model.Location `json:"getLocations"`
 	UpsertLocations    []string         `json:"upsertLocations"`
 	DeleteLocationByID bool             `json:"deleteLocationByID"`
@@ -35,10 +47,18 @@ type data struct {
 	UpsertEvents    []string      `json:"upsertEvents"`
 	GetEvents       []model.Event `json:"getEvents"`
 	DeleteEventByID bool          `json:"deleteEventByID"`
+
+	Conflicts   []model.Conflict   `json:"conflicts"`
+	Occurrences []model.Occurrence `json:"occurrences"`
+
+	UpsertBudgetLines []string            `json:"upsertBudgetLines"`
+	Budget            []model.EventBudget `json:"budget"`
+	Convert           string              `json:"convert"`
 }
 
 type graphQLError struct {
-	Message string `json:"message"`
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions"`
 }
 
 var httpClient = &http.Client{}
@@ -88,6 +108,49 @@ func TestServerIntegration(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 
+	t.Run("liveness endpoint is reachable", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/internal/livez", nil)
+		require.NoError(t, err)
+
+		resp, err := httpClient.Do(req)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, resp.Body.Close())
+		}()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("readiness endpoint reports all checks", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/internal/readyz", nil)
+		require.NoError(t, err)
+
+		resp, err := httpClient.Do(req)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, resp.Body.Close())
+		}()
+
+		var report struct {
+			Status string `json:"status"`
+			Checks map[string]struct {
+				Status string `json:"status"`
+			} `json:"checks"`
+		}
+
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "ok", report.Status)
+
+		for _, check := range []string{"database", "migrations", "pool"} {
+			require.Contains(t, report.Checks, check)
+			assert.Equal(t, "ok", report.Checks[check].Status, check)
+		}
+	})
+
 	t.Run("graphql query endpoint is reachable", func(t *testing.T) {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/query", nil)
 		require.NoError(t, err)
@@ -177,6 +240,26 @@ func TestServerIntegration(t *testing.T) {
 			assert.Equal(t, "BBR", *result.Data.GetArtists[0].ArtistName)
 		})
 
+		t.Run("retrieval of single artist by email works", func(t *testing.T) {
+			str := `{"query": "{getArtists(input: [{email: \"FOO@bar.com\"}]){id email}}"}`
+
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+
+			require.Len(t, result.Data.GetArtists, 1)
+			assert.Equal(t, testID, result.Data.GetArtists[0].ID)
+		})
+
+		t.Run("retrieval without input is paginated", func(t *testing.T) {
+			result := graphQuery(t, ctx, `{"query": "{getArtists(limit: 1){ id }}"}`)
+			require.Len(t, result.Errors, 0, result.Errors)
+			assert.Len(t, result.Data.GetArtists, 1)
+
+			result = graphQuery(t, ctx, `{"query": "{getArtists(limit: 1000){ id }}"}`)
+			require.Len(t, result.Errors, 1, result.Errors)
+			assert.Equal(t, "BAD_REQUEST", result.Errors[0].Extensions["code"])
+		})
+
 		t.Run("Retrieval with invalid ID throws error", func(t *testing.T) {
 			str := fmt.Sprintf(`{"query": "{getArtists(input: [{id: \"%s\"}]){ id, lastName, artistName}}"}`, "bogusßß")
 
@@ -195,6 +278,51 @@ func TestServerIntegration(t *testing.T) {
 
 			assert.Equal(t, true, result.Data.DeleteArtistByID)
 		})
+
+		t.Run("consent is recorded", func(t *testing.T) {
+			str := fmt.Sprintf(`{"query": "mutation { recordConsent(input: {artistID: \"%s\", purpose: PUBLISH_BIO, granted: true, method: FORM})}"}`, testID)
+
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			assert.NotEmpty(t, result.Data.RecordConsent)
+
+			str = fmt.Sprintf(`{"query": "{ consents(artistID: \"%s\") {id purpose granted method recordedAt}}"}`, testID)
+
+			result = graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			require.Len(t, result.Data.Consents, 1)
+			assert.Equal(t, model.ConsentPurposePublishBio, result.Data.Consents[0].Purpose)
+			assert.True(t, result.Data.Consents[0].Granted)
+		})
+
+		t.Run("deleted artist can be exported and anonymized", func(t *testing.T) {
+			str := fmt.Sprintf(`{"query": "{ exportArtistPersonalData(id: \"%s\")}"}`, testID)
+
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+
+			var export artist.PersonalData
+			require.NoError(t, json.Unmarshal([]byte(result.Data.ExportArtistPersonalData), &export))
+			assert.Equal(t, "foo@bar.com", export.Artist.Email)
+			assert.NotNil(t, export.Artist.DeletedAt)
+			assert.Len(t, export.Consents, 1)
+
+			str = fmt.Sprintf(`{"query": "mutation { anonymizeArtist(id: \"%s\")}"}`, testID)
+
+			result = graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			assert.True(t, result.Data.AnonymizeArtist)
+
+			str = fmt.Sprintf(`{"query": "{ exportArtistPersonalData(id: \"%s\")}"}`, testID)
+
+			result = graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+
+			var anonymized artist.PersonalData
+			require.NoError(t, json.Unmarshal([]byte(result.Data.ExportArtistPersonalData), &anonymized))
+			assert.Empty(t, anonymized.Artist.Email)
+			assert.Equal(t, artist.AnonymizedFirstName, anonymized.Artist.FirstName)
+		})
 	})
 
 	t.Run("test locations endpoints", func(t *testing.T) {
@@ -286,6 +414,68 @@ func TestServerIntegration(t *testing.T) {
 			})
 		})
 
+		t.Run("insertion of event with schedule works", func(t *testing.T) {
+			str := `{"query": "mutation { upsertEvents(input: [{name: \"Festival\", start: \"2022-07-01T16:00:00Z\", end: \"2022-07-03T16:00:00Z\", timezone: \"Europe/Berlin\"}])}"}`
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			require.Len(t, result.Data.UpsertEvents, 1)
+
+			id := result.Data.UpsertEvents[0]
+
+			t.Run("times are returned in the event's timezone", func(t *testing.T) {
+				str := fmt.Sprintf(`{"query": "{getEvents(input: [{id: \"%s\"}]){ id, start, end, timezone}}"}`, id)
+				result := graphQuery(t, ctx, str)
+				require.Len(t, result.Errors, 0, result.Errors)
+
+				require.Len(t, result.Data.GetEvents, 1)
+				assert.Equal(t, "2022-07-01T18:00:00+02:00", result.Data.GetEvents[0].Start.Format(time.RFC3339))
+				assert.Equal(t, "2022-07-03T18:00:00+02:00", result.Data.GetEvents[0].End.Format(time.RFC3339))
+				assert.Equal(t, "Europe/Berlin", *result.Data.GetEvents[0].Timezone)
+			})
+		})
+
+		t.Run("recurring event is expanded", func(t *testing.T) {
+			str := `{"query": "mutation { upsertEvents(input: [{name: \"Open Stage\", start: \"2023-03-16T20:00:00+01:00\", timezone: \"Europe/Berlin\", recurrence: \"FREQ=WEEKLY;COUNT=3\"}])}"}`
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			require.Len(t, result.Data.UpsertEvents, 1)
+
+			id := result.Data.UpsertEvents[0]
+
+			str = fmt.Sprintf(`{"query": "mutation { cancelOccurrence(eventID: \"%s\", recurrenceID: \"2023-03-23T20:00:00+01:00\")}"}`, id)
+			result = graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+
+			str = `{"query": "{occurrences(from: \"2023-03-16T00:00:00Z\", to: \"2023-04-01T00:00:00Z\"){ event {id}, start}}"}`
+			result = graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+
+			var starts []string
+			for _, occ := range result.Data.Occurrences {
+				if occ.Event.ID == id {
+					starts = append(starts, occ.Start.Format(time.RFC3339))
+				}
+			}
+
+			// Clocks change in between, the wall clock time is kept.
+			assert.Equal(t, []string{"2023-03-16T20:00:00+01:00", "2023-03-30T20:00:00+02:00"}, starts)
+		})
+
+		t.Run("event without start has no startTime", func(t *testing.T) {
+			str := `{"query": "mutation { upsertEvents(input: [{name: \"Unscheduled\"}])}"}`
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			require.Len(t, result.Data.UpsertEvents, 1)
+
+			str = fmt.Sprintf(`{"query": "{getEvents(input: [{id: \"%s\"}]){ id, startTime, start}}"}`, result.Data.UpsertEvents[0])
+			result = graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+
+			require.Len(t, result.Data.GetEvents, 1)
+			assert.Nil(t, result.Data.GetEvents[0].StartTime)
+			assert.Nil(t, result.Data.GetEvents[0].Start)
+		})
+
 		t.Run("insertion of single event+location works", func(t *testing.T) {
 			var (
 				locID   string
@@ -345,6 +535,44 @@ func TestServerIntegration(t *testing.T) {
 					assert.Equal(t, locName, result.Data.GetEvents[0].Location.Name)
 				})
 
+				t.Run("double-booking the location is rejected", func(t *testing.T) {
+					str := fmt.Sprintf(`{"query": "mutation { upsertEvents(input: [{name: \"Ballern 2b\", startTime:%d, locationID: \"%s\"}])}"}`, startTime, locID)
+					result := graphQuery(t, ctx, str)
+
+					require.Len(t, result.Errors, 1, result.Errors)
+					assert.Equal(t, "SCHEDULE_CONFLICT", result.Errors[0].Extensions["code"])
+					assert.Len(t, result.Errors[0].Extensions["conflicts"], 1)
+
+					t.Run("unless conflicts are allowed", func(t *testing.T) {
+						str := fmt.Sprintf(`{"query": "mutation { upsertEvents(input: [{name: \"Ballern 2b\", startTime:%d, locationID: \"%s\"}], allowConflicts: true)}"}`, startTime, locID)
+						result := graphQuery(t, ctx, str)
+
+						require.Len(t, result.Errors, 0, result.Errors)
+						require.Len(t, result.Data.UpsertEvents, 1)
+						assert.Len(t, result.Extensions["warnings"], 1)
+
+						conflictID := result.Data.UpsertEvents[0]
+
+						t.Run("conflict is listed", func(t *testing.T) {
+							from := time.Unix(int64(startTime), 0).UTC().Format(time.RFC3339)
+							to := time.Unix(int64(startTime)+1, 0).UTC().Format(time.RFC3339)
+
+							str := fmt.Sprintf(`{"query": "{conflicts(from: \"%s\", to: \"%s\"){ kind, location {id}, events {id}}}"}`, from, to)
+							result := graphQuery(t, ctx, str)
+							require.Len(t, result.Errors, 0, result.Errors)
+
+							require.Len(t, result.Data.Conflicts, 1)
+							assert.Equal(t, model.ConflictKindLocation, result.Data.Conflicts[0].Kind)
+							assert.Equal(t, locID, result.Data.Conflicts[0].Location.ID)
+							assert.Len(t, result.Data.Conflicts[0].Events, 2)
+						})
+
+						str = fmt.Sprintf(`{"query": "mutation { deleteEventByID(input: \"%s\")}"}`, conflictID)
+						result = graphQuery(t, ctx, str)
+						require.Len(t, result.Errors, 0, result.Errors)
+					})
+				})
+
 				t.Run("delete works", func(t *testing.T) {
 					str := fmt.Sprintf(`{"query": "mutation { deleteEventByID(input: \"%s\")}"}`, id)
 
@@ -432,10 +660,290 @@ func TestServerIntegration(t *testing.T) {
 					assert.Equal(t, artistLastName, result.Data.GetEvents[0].Artists[0].Artist.LastName)
 					assert.Equal(t, artistConfirmed, result.Data.GetEvents[0].Artists[0].Confirmed)
 				})
+
+				t.Run("artist calendar contains event", func(t *testing.T) {
+					resp, body := httpGet(t, ctx, fmt.Sprintf("http://localhost:8080/calendar/artists/%s.ics", artistID))
+
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+					assert.Contains(t, body, fmt.Sprintf("UID:%s@", id))
+					assert.Contains(t, body, "SUMMARY:"+eventName)
+					assert.Contains(t, body, fmt.Sprintf(`- %s %s (unconfirmed)`, artistFirstName, artistLastName))
+				})
+
+				t.Run("events calendar contains event", func(t *testing.T) {
+					resp, body := httpGet(t, ctx, "http://localhost:8080/calendar/events.ics")
+
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Contains(t, body, fmt.Sprintf("UID:%s@", id))
+				})
+
+				t.Run("calendar of unknown location is not found", func(t *testing.T) {
+					resp, _ := httpGet(t, ctx, fmt.Sprintf("http://localhost:8080/calendar/locations/%s.ics", uuid.NewString()))
+
+					assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+				})
+
+				t.Run("budget is exported", func(t *testing.T) {
+					str := fmt.Sprintf(`{"query": "mutation { upsertBudgetLines(input: [{eventID: \"%s\", category: SHIPPING, planned: 12000, actual: 13050, currency: \"EUR\"}])}"}`, id)
+					result := graphQuery(t, ctx, str)
+					require.Len(t, result.Errors, 0, result.Errors)
+					require.Len(t, result.Data.UpsertBudgetLines, 1)
+
+					str = fmt.Sprintf(`{"query": "{budget(eventIDs: [\"%s\"]) {event {id}, lines {id}, costs {category, planned, actual, variance}}}"}`, id)
+					result = graphQuery(t, ctx, str)
+					require.Len(t, result.Errors, 0, result.Errors)
+
+					require.Len(t, result.Data.Budget, 1)
+					require.Len(t, result.Data.Budget[0].Costs, 1)
+					assert.Equal(t, model.BudgetCategoryShipping, result.Data.Budget[0].Costs[0].Category)
+					assert.Equal(t, 1050, result.Data.Budget[0].Costs[0].Variance)
+
+					resp, body := httpGet(t, ctx, fmt.Sprintf("http://localhost:8080/reports/budget.csv?event=%s", id))
+
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+					assert.Contains(t, body, fmt.Sprintf("%s,%s,shipping,EUR,120.00,130.50,10.50", id, eventName))
+				})
 			})
 		})
 	})
 
+	t.Run("money is parsed and formatted", func(t *testing.T) {
+		result := graphQuery(t, ctx, `{"query": "{convert(amount: \"10.5 eur\", currency: \"EUR\")}"}`)
+		require.Len(t, result.Errors, 0, result.Errors)
+
+		assert.Equal(t, "10.50 EUR", result.Data.Convert)
+
+		t.Run("invalid amount is rejected", func(t *testing.T) {
+			result := graphQuery(t, ctx, `{"query": "{convert(amount: \"10.505 EUR\", currency: \"EUR\")}"}`)
+			require.Len(t, result.Errors, 1, result.Errors)
+		})
+	})
+
+	t.Run("published events are public", func(t *testing.T) {
+		str := `{"query": "mutation { upsertArtists(input: [{firstName:\"Public\",lastName:\"Artist\",artistName:\"PA\",bioEn:\"plays loud\",instagram:\"public_artist\",email:\"public@artist.com\"}]) { id }}"}`
+		result := graphQuery(t, ctx, str)
+		require.Len(t, result.Errors, 0, result.Errors)
+		require.Len(t, result.Data.UpsertArtists, 1)
+
+		artistID := result.Data.UpsertArtists[0].ID
+
+		str = fmt.Sprintf(`{"query": "mutation { recordConsent(input: {artistID: \"%s\", purpose: PUBLISH_BIO, granted: true, method: FORM})}"}`, artistID)
+		result = graphQuery(t, ctx, str)
+		require.Len(t, result.Errors, 0, result.Errors)
+
+		str = fmt.Sprintf(`{"query": "mutation { upsertEvents(input: [{name: \"Open Air\", start: \"2030-07-01T18:00:00Z\", published: true, invitedArtists: [{id: \"%[1]s\", confirmed: true}]}, {name: \"Secret\", published: false, invitedArtists: [{id: \"%[1]s\", confirmed: true}]}])}"}`, artistID)
+		result = graphQuery(t, ctx, str)
+		require.Len(t, result.Errors, 0, result.Errors)
+		require.Len(t, result.Data.UpsertEvents, 2)
+
+		published, unpublished := result.Data.UpsertEvents[0], result.Data.UpsertEvents[1]
+
+		t.Run("published event is served", func(t *testing.T) {
+			resp, body := httpGet(t, ctx, fmt.Sprintf("http://localhost:8080/public/events/%s", published))
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.Contains(t, resp.Header.Get("Cache-Control"), "public")
+			assert.NotEmpty(t, resp.Header.Get("ETag"))
+
+			assert.Contains(t, body, `"name":"PA"`)
+			assert.Contains(t, body, "plays loud")
+			assert.NotContains(t, body, "public_artist", "socials require consent")
+			assert.NotContains(t, body, "public@artist.com")
+
+			t.Run("unchanged event is not sent again", func(t *testing.T) {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:8080/public/events/%s", published), nil)
+				require.NoError(t, err)
+				req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+
+				resp, err := httpClient.Do(req)
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+
+				assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+			})
+		})
+
+		t.Run("unpublished event is not found", func(t *testing.T) {
+			resp, _ := httpGet(t, ctx, fmt.Sprintf("http://localhost:8080/public/events/%s", unpublished))
+
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("only published events are listed", func(t *testing.T) {
+			resp, body := httpGet(t, ctx, "http://localhost:8080/public/events")
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Contains(t, body, published)
+			assert.NotContains(t, body, unpublished)
+		})
+	})
+
+	t.Run("rest api works", func(t *testing.T) {
+		const api = "http://localhost:8080/api/v1"
+
+		do := func(t *testing.T, method, url, body string) (*http.Response, string) {
+			req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := httpClient.Do(req)
+			require.NoError(t, err)
+
+			defer func() {
+				require.NoError(t, resp.Body.Close())
+			}()
+
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			return resp, string(b)
+		}
+
+		var loc model.Location
+
+		t.Run("create location", func(t *testing.T) {
+			resp, body := do(t, http.MethodPost, api+"/locations", `{"name": "Rest Club", "city": "Leipzig"}`)
+			require.Equal(t, http.StatusCreated, resp.StatusCode, body)
+
+			require.NoError(t, json.Unmarshal([]byte(body), &loc))
+			assert.NotEmpty(t, loc.ID)
+			assert.Equal(t, "Leipzig", *loc.City)
+		})
+
+		t.Run("replace location", func(t *testing.T) {
+			resp, body := do(t, http.MethodPut, api+"/locations/"+loc.ID, `{"name": "Rest Club", "city": "Halle"}`)
+			require.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+			resp, body = do(t, http.MethodGet, api+"/locations/"+loc.ID, "")
+			require.Equal(t, http.StatusOK, resp.StatusCode, body)
+			assert.Contains(t, body, `"city":"Halle"`)
+		})
+
+		t.Run("list locations", func(t *testing.T) {
+			var ids []string
+
+			for cursor := ""; ; {
+				resp, body := do(t, http.MethodGet, api+"/locations?limit=1&after="+cursor, "")
+				require.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+				var page struct {
+					Items []model.Location `json:"items"`
+					Next  *string          `json:"next"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(body), &page))
+
+				for _, l := range page.Items {
+					ids = append(ids, l.ID)
+				}
+
+				if page.Next == nil {
+					break
+				}
+
+				cursor = *page.Next
+			}
+
+			assert.Contains(t, ids, loc.ID)
+		})
+
+		t.Run("errors are JSON", func(t *testing.T) {
+			resp, body := do(t, http.MethodPost, api+"/events", `{"name": ""}`)
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Contains(t, body, `"code":"BAD_REQUEST"`)
+
+			resp, body = do(t, http.MethodGet, api+"/artists/"+uuid.NewString(), "")
+
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			assert.Contains(t, body, `"code":"NOT_FOUND"`)
+		})
+
+		t.Run("delete location", func(t *testing.T) {
+			resp, _ := do(t, http.MethodDelete, api+"/locations/"+loc.ID, "")
+			require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+			resp, _ = do(t, http.MethodGet, api+"/locations/"+loc.ID, "")
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("openapi document is served", func(t *testing.T) {
+			resp, body := httpGet(t, ctx, api+"/openapi.json")
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var doc map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(body), &doc))
+			assert.Equal(t, "3.0.3", doc["openapi"])
+		})
+	})
+
+	t.Run("automatic persisted queries work", func(t *testing.T) {
+		query := fmt.Sprintf(`{ getArtists(input: [{id: %q}]) { id } }`, uuid.NewString())
+		sum := sha256.Sum256([]byte(query))
+		ext := fmt.Sprintf(`"extensions": {"persistedQuery": {"version": 1, "sha256Hash": %q}}`, hex.EncodeToString(sum[:]))
+
+		res := graphQuery(t, ctx, `{`+ext+`}`)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "PERSISTED_QUERY_NOT_FOUND", res.Errors[0].Extensions["code"])
+
+		body, err := json.Marshal(query)
+		require.NoError(t, err)
+
+		res = graphQuery(t, ctx, `{"query": `+string(body)+`, `+ext+`}`)
+		for _, e := range res.Errors {
+			assert.NotEqual(t, "PERSISTED_QUERY_NOT_FOUND", e.Extensions["code"])
+		}
+
+		res = graphQuery(t, ctx, `{`+ext+`}`)
+		for _, e := range res.Errors {
+			assert.NotEqual(t, "PERSISTED_QUERY_NOT_FOUND", e.Extensions["code"])
+		}
+	})
+
+	t.Run("errors carry codes and fields", func(t *testing.T) {
+		str := fmt.Sprintf(`{"query": "mutation { upsertEvents(input: [{name: \"Nowhere\", startTime: 1637830936, locationID: \"%s\"}])}"}`, uuid.NewString())
+		res := graphQuery(t, ctx, str)
+		require.Len(t, res.Errors, 1, res.Errors)
+		assert.Equal(t, "REFERENCE_NOT_FOUND", res.Errors[0].Extensions["code"])
+		assert.Equal(t, "locationID", res.Errors[0].Extensions["field"])
+		assert.NotContains(t, res.Errors[0].Message, "fk_locations")
+	})
+
+	t.Run("batch upserts return a result per artist", func(t *testing.T) {
+		query := func(mode string) []model.ArtistResult {
+			str := fmt.Sprintf(`{"query": "mutation { upsertArtistsBatch(mode: %s, input: [{firstName: \"Ada\", lastName: \"Batch\"}, {id: \"foo\", firstName: \"Bad\", lastName: \"Batch\"}]) { index id status error { code message } }}"}`, mode)
+
+			result := graphQuery(t, ctx, str)
+			require.Len(t, result.Errors, 0, result.Errors)
+			require.Len(t, result.Data.UpsertArtistsBatch, 2)
+
+			return result.Data.UpsertArtistsBatch
+		}
+
+		t.Run("partial", func(t *testing.T) {
+			res := query("PARTIAL")
+
+			assert.Equal(t, model.ItemStatusSaved, res[0].Status)
+			assert.NotEmpty(t, conversion.String(res[0].ID))
+			assert.Nil(t, res[0].Error)
+
+			assert.Equal(t, 1, res[1].Index)
+			assert.Equal(t, model.ItemStatusFailed, res[1].Status)
+			require.NotNil(t, res[1].Error)
+			assert.NotEmpty(t, res[1].Error.Code)
+		})
+
+		t.Run("atomic", func(t *testing.T) {
+			res := query("ATOMIC")
+
+			assert.Equal(t, model.ItemStatusRolledBack, res[0].Status)
+			assert.Nil(t, res[0].ID)
+			assert.Equal(t, model.ItemStatusFailed, res[1].Status)
+		})
+	})
+
 	// This should always be the last test in this suite.
 	t.Run("metrics endpoint is reachable", func(t *testing.T) {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/internal/metrics", nil)
@@ -475,3 +983,20 @@ func graphQuery(t *testing.T, ctx context.Context, query string) graphQLResponse
 
 	return result
 }
+
+func httpGet(t *testing.T, ctx context.Context, url string) (*http.Response, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(body)
+}
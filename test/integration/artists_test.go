@@ -254,3 +254,73 @@ func Test_ArtistsIntegration(t *testing.T) {
 		})
 	})
 }
+
+func Test_ArtistBatchIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, conn, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	outbox := func(t *testing.T) int {
+		var n int
+		require.NoError(t, conn.QueryRow(ctx, "SELECT count(*) FROM outbox").Scan(&n))
+
+		return n
+	}
+
+	batch := func() []*artist.Artist {
+		invalid := artist.New()
+		invalid.ID = "not-a-uuid"
+
+		return []*artist.Artist{artist.New(), invalid, artist.New()}
+	}
+
+	t.Run("partial batch saves all valid artists", func(t *testing.T) {
+		before := outbox(t)
+		artists := batch()
+
+		errs, err := db.ArtistHandler.UpsertBatch(ctx, core.BatchPartial, artists...)
+		require.NoError(t, err)
+		require.Len(t, errs, 3)
+
+		assert.NoError(t, errs[0])
+		assert.Error(t, errs[1])
+		assert.NoError(t, errs[2])
+
+		for _, a := range []*artist.Artist{artists[0], artists[2]} {
+			_, err := db.ArtistHandler.Get(ctx, artist.ByID(a.ID))
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, before+2, outbox(t))
+	})
+
+	t.Run("atomic batch saves nothing if an artist fails", func(t *testing.T) {
+		before := outbox(t)
+		artists := batch()
+
+		errs, err := db.ArtistHandler.UpsertBatch(ctx, core.BatchAtomic, artists...)
+		require.NoError(t, err)
+		require.Len(t, errs, 3)
+
+		assert.ErrorIs(t, errs[0], core.ErrRolledBack)
+		assert.Error(t, errs[1])
+		assert.NotErrorIs(t, errs[1], core.ErrRolledBack)
+		assert.ErrorIs(t, errs[2], core.ErrRolledBack)
+
+		for _, a := range []*artist.Artist{artists[0], artists[2]} {
+			_, err := db.ArtistHandler.Get(ctx, artist.ByID(a.ID))
+			assert.ErrorIs(t, err, core.ErrNotFound)
+		}
+
+		assert.Equal(t, before, outbox(t))
+	})
+
+	t.Run("upsert only returns the errors of failed artists", func(t *testing.T) {
+		err := db.ArtistHandler.Upsert(ctx, batch()...)
+		require.Error(t, err)
+
+		assert.NotErrorIs(t, err, core.ErrRolledBack)
+	})
+}
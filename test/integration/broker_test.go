@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/obitech/artist-db/internal/broker"
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+func Test_BrokerIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	connString := os.Getenv("TEST_DB_CONN_STRING")
+
+	b, err := broker.NewPostgres(ctx, connString, zap.NewNop(), otel.GetTracerProvider())
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := database.NewDatabase(ctx, connString, database.WithPublisher(b))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	changes := b.Subscribe(ctx, broker.ForEntity(core.EntityArtist))
+
+	a := artist.New()
+	a.FirstName = "Bob"
+	a.LastName = "Ross"
+
+	// The listener connects in the background, so keep upserting until the
+	// first notification arrives.
+	var change core.Change
+	require.Eventually(t, func() bool {
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+		select {
+		case change = <-changes:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, core.EntityArtist, change.Entity)
+	assert.Equal(t, core.ActionUpsert, change.Action)
+	assert.Equal(t, a.ID, change.ID)
+}
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/webhook"
+	"github.com/obitech/artist-db/internal/encryption"
+)
+
+func Test_ArtistEncryptionIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plainDB, conn, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	keys := encryption.NewKeyring()
+	require.NoError(t, keys.Add("k1", bytes.Repeat([]byte{1}, encryption.KeySize)))
+
+	indexKey := bytes.Repeat([]byte{9}, encryption.KeySize)
+
+	enc, err := encryption.New(keys, indexKey)
+	require.NoError(t, err)
+
+	db, err := database.NewDatabase(ctx, os.Getenv("TEST_DB_CONN_STRING"),
+		database.WithEncryption(enc, "email", "date_of_birth", "place_of_birth"),
+	)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	a := &artist.Artist{
+		ID:        uuid.NewString(),
+		FirstName: "first",
+		LastName:  "last",
+		Origin: artist.Origin{
+			DateOfBirth:  time.Date(1990, 4, 1, 0, 0, 0, 0, time.UTC),
+			PlaceOfBirth: "Leipzig",
+			Nationality:  "de",
+		},
+		Email: "artist@example.com",
+	}
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+	stored := func(t *testing.T) (email, dob, pob, nationality string) {
+		require.NoError(t, conn.QueryRow(ctx,
+			"SELECT email, date_of_birth, place_of_birth, nationality FROM artists WHERE id=$1", a.ID,
+		).Scan(&email, &dob, &pob, &nationality))
+
+		return email, dob, pob, nationality
+	}
+
+	t.Run("configured columns are encrypted at rest", func(t *testing.T) {
+		email, dob, pob, nationality := stored(t)
+
+		assert.True(t, encryption.IsEncrypted(email))
+		assert.True(t, encryption.IsEncrypted(dob))
+		assert.True(t, encryption.IsEncrypted(pob))
+		assert.NotContains(t, email, "artist@example.com")
+		assert.Equal(t, "de", nationality)
+	})
+
+	t.Run("get decrypts", func(t *testing.T) {
+		got, err := db.ArtistHandler.Get(ctx, artist.ByID(a.ID))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		assert.Equal(t, a.Email, got[0].Email)
+		assert.Equal(t, a.Origin, got[0].Origin)
+	})
+
+	t.Run("email is looked up by blind index", func(t *testing.T) {
+		got, err := db.ArtistHandler.Get(ctx, artist.ByEmail("Artist@Example.com"))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		assert.Equal(t, a.ID, got[0].ID)
+	})
+
+	t.Run("export decrypts", func(t *testing.T) {
+		data, err := db.ArtistHandler.ExportPersonalData(ctx, a.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, a.Email, data.Artist.Email)
+		assert.Equal(t, "Leipzig", data.Artist.PlaceOfBirth)
+	})
+
+	t.Run("encrypted data can't be read without keys", func(t *testing.T) {
+		_, err := plainDB.ArtistHandler.Get(ctx, artist.ByID(a.ID))
+		assert.Error(t, err)
+	})
+
+	t.Run("webhook secrets are encrypted at rest", func(t *testing.T) {
+		w := webhook.New()
+		w.URL = "https://hooks.example.com"
+		w.Secret = "s3cr3t"
+
+		require.NoError(t, db.WebhookHandler.Upsert(ctx, w))
+
+		var secret string
+		require.NoError(t, conn.QueryRow(ctx, "SELECT secret FROM webhooks WHERE id=$1", w.ID).Scan(&secret))
+		assert.True(t, encryption.IsEncrypted(secret))
+
+		got, err := db.WebhookHandler.Get(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, "s3cr3t", got[0].Secret)
+	})
+
+	t.Run("re-encryption rotates keys and columns", func(t *testing.T) {
+		require.NoError(t, keys.Add("k2", bytes.Repeat([]byte{2}, encryption.KeySize)))
+
+		rotated, err := database.NewDatabase(ctx, os.Getenv("TEST_DB_CONN_STRING"),
+			database.WithEncryption(enc, "email", "nationality"),
+		)
+		require.NoError(t, err)
+
+		defer rotated.Close()
+
+		n, err := rotated.ArtistHandler.Reencrypt(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		email, dob, pob, nationality := stored(t)
+
+		assert.True(t, strings.HasPrefix(email, "enc:v1:k2:"))
+		assert.True(t, strings.HasPrefix(nationality, "enc:v1:k2:"))
+		assert.Equal(t, "1990-04-01T00:00:00Z", dob)
+		assert.Equal(t, "Leipzig", pob)
+
+		got, err := rotated.ArtistHandler.Get(ctx, artist.ByEmail(a.Email))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, a.Origin, got[0].Origin)
+
+		n, err = rotated.ArtistHandler.Reencrypt(ctx, 1)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
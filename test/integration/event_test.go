@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/obitech/artist-db/internal/conversion"
 	"github.com/obitech/artist-db/internal/database/artist"
 	"github.com/obitech/artist-db/internal/database/core"
 	"github.com/obitech/artist-db/internal/database/event"
@@ -183,6 +184,137 @@ func Test_EventsIntegration(t *testing.T) {
 		require.NoError(t, db.EventHandler.Upsert(ctx, events[4]))
 	})
 
+	t.Run("inserting event with schedule works", func(t *testing.T) {
+		start := time.Date(2022, 7, 1, 16, 0, 0, 0, time.UTC)
+
+		ev, err := event.New("festival",
+			event.WithStartTime(start),
+			event.WithEndTime(start.Add(48*time.Hour)),
+			event.WithTimezone("Europe/Berlin"),
+			event.WithInvitedArtists(event.InvitedArtist{ID: artist1.ID}),
+			event.WithSlots(event.Slot{
+				ArtistID:  artist1.ID,
+				StartTime: start.Add(time.Hour),
+				EndTime:   start.Add(2 * time.Hour),
+				Stage:     "Main",
+			}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+
+		require.Len(t, got, 1)
+		assert.Equal(t, ev, got[0])
+
+		t.Run("slot of another event is rejected without writing anything", func(t *testing.T) {
+			thief, err := event.New("thief",
+				event.WithStartTime(start),
+				event.WithInvitedArtists(event.InvitedArtist{ID: artist1.ID}),
+				event.WithSlots(event.Slot{
+					ID:        ev.Slots[0].ID,
+					ArtistID:  artist1.ID,
+					StartTime: start.Add(5 * time.Hour),
+					EndTime:   start.Add(6 * time.Hour),
+					Stage:     "Side",
+				}),
+			)
+			require.NoError(t, err)
+
+			require.ErrorIs(t, db.EventHandler.Upsert(ctx, thief), core.ErrNotFound)
+
+			_, err = db.EventHandler.Get(ctx, event.ByID(thief.ID))
+			assert.ErrorIs(t, err, core.ErrNotFound)
+
+			got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, ev.Slots, got[0].Slots)
+		})
+
+		t.Run("removed slots are deleted", func(t *testing.T) {
+			edited := *ev
+			edited.Slots = []event.Slot{{
+				ID:        uuid.New().String(),
+				ArtistID:  artist1.ID,
+				StartTime: start.Add(3 * time.Hour),
+				EndTime:   start.Add(4 * time.Hour),
+				Stage:     "Side",
+			}}
+
+			require.NoError(t, db.EventHandler.Upsert(ctx, &edited))
+
+			got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, edited.Slots, got[0].Slots)
+		})
+
+		t.Run("upsert without invitations and slots keeps them", func(t *testing.T) {
+			before, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+			require.NoError(t, err)
+			require.Len(t, before, 1)
+			require.NotEmpty(t, before[0].InvitedArtists)
+			require.NotEmpty(t, before[0].Slots)
+
+			renamed := *ev
+			renamed.Name = "renamed festival"
+			renamed.InvitedArtists = nil
+			renamed.Slots = nil
+
+			require.NoError(t, db.EventHandler.Upsert(ctx, &renamed))
+
+			got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, "renamed festival", got[0].Name)
+			assert.Equal(t, before[0].InvitedArtists, got[0].InvitedArtists)
+			assert.Equal(t, before[0].Slots, got[0].Slots)
+		})
+
+		t.Run("end before start is rejected", func(t *testing.T) {
+			end := start.Add(-time.Hour)
+			ev.EndTime = &end
+
+			require.Error(t, db.EventHandler.Upsert(ctx, ev))
+		})
+
+		require.NoError(t, db.EventHandler.DeleteByID(ctx, ev.ID))
+	})
+
+	t.Run("retrieving events by location, artist and all works", func(t *testing.T) {
+		ev, err := db.EventHandler.Get(ctx, event.ByLocationID(loc2.ID))
+		require.NoError(t, err)
+
+		require.Len(t, ev, 1)
+		assert.Equal(t, withAll.ID, ev[0].ID)
+
+		ev, err = db.EventHandler.Get(ctx, event.ByArtistID(artist2.ID))
+		require.NoError(t, err)
+
+		require.Len(t, ev, 1)
+		assert.Equal(t, withAll.ID, ev[0].ID)
+
+		ev, err = db.EventHandler.Get(ctx, event.ByArtistID(artist1.ID))
+		require.NoError(t, err)
+		assert.Len(t, ev, 2)
+
+		ev, err = db.EventHandler.Get(ctx, event.All())
+		require.NoError(t, err)
+
+		ids := make(map[string]bool)
+		for _, e := range ev {
+			ids[e.ID] = true
+			assert.NotZero(t, e.UpdatedAt)
+		}
+
+		for _, e := range []*event.Event{onlyName, withTime, withLoc, withAll, withArtist} {
+			assert.True(t, ids[e.ID], "event %q missing", e.Name)
+		}
+	})
+
 	t.Run("deleting assigned location erases location in event", func(t *testing.T) {
 		locID := events[3].LocationID
 		evID := events[3].ID
@@ -227,3 +359,622 @@ func Test_EventsIntegration(t *testing.T) {
 		})
 	})
 }
+
+func Test_EventConflictsIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	loc := location.New()
+	a := artist.New()
+
+	require.NoError(t, db.LocationHandler.Upsert(ctx, loc))
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+	start := time.Date(2023, 8, 4, 18, 0, 0, 0, time.UTC)
+
+	first, err := event.New("first",
+		event.WithStartTime(start),
+		event.WithEndTime(start.Add(6*time.Hour)),
+		event.WithLocationID(loc.ID),
+		event.WithInvitedArtists(event.InvitedArtist{ID: a.ID}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, db.EventHandler.Upsert(ctx, first))
+
+	t.Run("adjacent event does not conflict", func(t *testing.T) {
+		next, err := event.New("next",
+			event.WithStartTime(start.Add(6*time.Hour)),
+			event.WithEndTime(start.Add(8*time.Hour)),
+			event.WithLocationID(loc.ID),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, db.EventHandler.Upsert(ctx, next))
+		require.NoError(t, db.EventHandler.DeleteByID(ctx, next.ID))
+	})
+
+	overlapping, err := event.New("overlapping",
+		event.WithStartTime(start.Add(2*time.Hour)),
+		event.WithEndTime(start.Add(10*time.Hour)),
+		event.WithLocationID(loc.ID),
+		event.WithInvitedArtists(event.InvitedArtist{ID: a.ID}),
+	)
+	require.NoError(t, err)
+
+	t.Run("double-booking is rejected", func(t *testing.T) {
+		err := db.EventHandler.Upsert(ctx, overlapping)
+
+		var conflictErr *event.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 2)
+
+		_, err = db.EventHandler.Get(ctx, event.ByID(overlapping.ID))
+		require.ErrorIs(t, err, core.ErrNotFound)
+	})
+
+	t.Run("double-booking can be allowed", func(t *testing.T) {
+		conflicts, err := db.EventHandler.UpsertAllowingConflicts(ctx, overlapping)
+		require.NoError(t, err)
+		require.Len(t, conflicts, 2)
+
+		kinds := []event.ConflictKind{conflicts[0].Kind, conflicts[1].Kind}
+		assert.ElementsMatch(t, []event.ConflictKind{event.ConflictArtist, event.ConflictLocation}, kinds)
+
+		for _, c := range conflicts {
+			assert.Equal(t, start.Add(2*time.Hour), c.Start)
+			assert.Equal(t, start.Add(6*time.Hour), c.End)
+			assert.ElementsMatch(t, []string{first.ID, overlapping.ID}, []string{c.EventID, c.OtherEventID})
+		}
+	})
+
+	t.Run("double-booked stage is rejected", func(t *testing.T) {
+		b := artist.New()
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, b))
+
+		first.InvitedArtists = append(first.InvitedArtists, event.InvitedArtist{ID: b.ID})
+		first.Slots = []event.Slot{
+			{ID: uuid.New().String(), ArtistID: a.ID, StartTime: start, EndTime: start.Add(time.Hour), Stage: "Main"},
+		}
+
+		_, err := db.EventHandler.UpsertAllowingConflicts(ctx, first)
+		require.NoError(t, err)
+
+		first.Slots = append(first.Slots, event.Slot{
+			ID: uuid.New().String(), ArtistID: b.ID, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(2 * time.Hour), Stage: "Main",
+		})
+
+		err = db.EventHandler.Upsert(ctx, first)
+
+		// The conflicts with overlapping existed before, only the stage is
+		// new.
+		var conflictErr *event.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+
+		c := conflictErr.Conflicts[0]
+		assert.Equal(t, event.ConflictStage, c.Kind)
+		assert.Equal(t, "Main", c.Stage)
+		assert.NotEmpty(t, c.SlotID)
+		assert.NotEmpty(t, c.OtherSlotID)
+
+		_, err = db.EventHandler.UpsertAllowingConflicts(ctx, first)
+		require.NoError(t, err)
+	})
+
+	t.Run("existing conflicts don't block edits", func(t *testing.T) {
+		first.Name = "first renamed"
+		require.NoError(t, db.EventHandler.Upsert(ctx, first))
+
+		// Overlapping longer than before is a new conflict.
+		end := start.Add(8 * time.Hour)
+		first.EndTime = &end
+
+		var conflictErr *event.ConflictError
+		require.ErrorAs(t, db.EventHandler.Upsert(ctx, first), &conflictErr)
+	})
+
+	t.Run("later occurrences of recurring events conflict", func(t *testing.T) {
+		other := location.New()
+		require.NoError(t, db.LocationHandler.Upsert(ctx, other))
+
+		weekly := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7).Add(18 * time.Hour)
+
+		recurring, err := event.New("weekly",
+			event.WithStartTime(weekly),
+			event.WithEndTime(weekly.Add(3*time.Hour)),
+			event.WithLocationID(other.ID),
+			event.WithRecurrence("FREQ=WEEKLY"),
+		)
+		require.NoError(t, err)
+		require.NoError(t, db.EventHandler.Upsert(ctx, recurring))
+
+		oneOff, err := event.New("one-off",
+			event.WithStartTime(weekly.AddDate(0, 0, 21).Add(time.Hour)),
+			event.WithEndTime(weekly.AddDate(0, 0, 21).Add(5*time.Hour)),
+			event.WithLocationID(other.ID),
+		)
+		require.NoError(t, err)
+
+		var conflictErr *event.ConflictError
+		require.ErrorAs(t, db.EventHandler.Upsert(ctx, oneOff), &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+		assert.Equal(t, event.ConflictLocation, conflictErr.Conflicts[0].Kind)
+		assert.Equal(t, weekly.AddDate(0, 0, 21).Add(time.Hour), conflictErr.Conflicts[0].Start)
+
+		require.NoError(t, db.EventHandler.DeleteByID(ctx, recurring.ID))
+	})
+
+	t.Run("listing conflicts works", func(t *testing.T) {
+		conflicts, err := db.EventHandler.Conflicts(ctx, start, start.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, conflicts, 3)
+
+		conflicts, err = db.EventHandler.Conflicts(ctx, start.Add(-24*time.Hour), start)
+		require.NoError(t, err)
+		assert.Len(t, conflicts, 0)
+
+		_, err = db.EventHandler.Conflicts(ctx, start, start)
+		require.Error(t, err)
+	})
+}
+
+func Test_EventRecurrenceIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	start := time.Date(2023, 5, 4, 18, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(0, 0, 28)
+
+	ev, err := event.New("open stage",
+		event.WithStartTime(start),
+		event.WithEndTime(start.Add(3*time.Hour)),
+		event.WithTimezone("Europe/Berlin"),
+		event.WithRecurrence("FREQ=WEEKLY;COUNT=4", start.AddDate(0, 0, 7)),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+	t.Run("recurrence is stored", func(t *testing.T) {
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+
+		require.Len(t, got, 1)
+		assert.Equal(t, ev, got[0])
+	})
+
+	t.Run("occurrences are expanded", func(t *testing.T) {
+		occs, err := db.EventHandler.Occurrences(ctx, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, occs, 3)
+		assert.Equal(t, start, occs[0].StartTime)
+		assert.Equal(t, start.AddDate(0, 0, 14), occs[1].StartTime)
+		assert.Equal(t, start.AddDate(0, 0, 21), occs[2].StartTime)
+	})
+
+	t.Run("overriding an occurrence works", func(t *testing.T) {
+		recurrenceID := start.AddDate(0, 0, 14)
+
+		require.NoError(t, db.EventHandler.OverrideOccurrence(ctx, ev.ID, event.Override{
+			RecurrenceID: recurrenceID,
+			Name:         "open stage special",
+			StartTime:    recurrenceID.Add(time.Hour),
+		}))
+
+		occs, err := db.EventHandler.Occurrences(ctx, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, occs, 3)
+		assert.True(t, occs[1].Overridden)
+		assert.Equal(t, "open stage special", occs[1].Name)
+		assert.Equal(t, recurrenceID.Add(time.Hour), occs[1].StartTime)
+
+		t.Run("unknown occurrence is rejected", func(t *testing.T) {
+			err := db.EventHandler.OverrideOccurrence(ctx, ev.ID, event.Override{
+				RecurrenceID: recurrenceID.Add(time.Minute),
+				StartTime:    recurrenceID,
+			})
+			require.ErrorIs(t, err, core.ErrNotFound)
+		})
+	})
+
+	t.Run("ranges longer than the maximum are rejected", func(t *testing.T) {
+		_, err := db.EventHandler.Occurrences(ctx, from, from.Add(event.MaxOccurrenceRange+time.Hour))
+		require.ErrorIs(t, err, core.ErrInvalidInput)
+	})
+
+	t.Run("cancelling an occurrence works", func(t *testing.T) {
+		require.NoError(t, db.EventHandler.CancelOccurrence(ctx, ev.ID, start.AddDate(0, 0, 14)))
+
+		occs, err := db.EventHandler.Occurrences(ctx, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, occs, 2)
+		assert.Equal(t, start, occs[0].StartTime)
+		assert.Equal(t, start.AddDate(0, 0, 21), occs[1].StartTime)
+
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+
+		assert.Len(t, got[0].Exceptions, 2)
+		assert.Empty(t, got[0].Overrides)
+	})
+
+	t.Run("overrides of removed occurrences are deleted", func(t *testing.T) {
+		recurrenceID := start.AddDate(0, 0, 21)
+
+		require.NoError(t, db.EventHandler.OverrideOccurrence(ctx, ev.ID, event.Override{
+			RecurrenceID: recurrenceID,
+			StartTime:    recurrenceID.Add(time.Hour),
+		}))
+
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+		require.Len(t, got[0].Overrides, 1)
+
+		// Editing the event keeps the override.
+		edited := *got[0]
+		edited.Overrides = nil
+		edited.Name = "open stage night"
+		require.NoError(t, db.EventHandler.Upsert(ctx, &edited))
+
+		got, err = db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+		require.Len(t, got[0].Overrides, 1)
+
+		// The fourth occurrence is gone once the rule only repeats thrice.
+		shortened := *got[0]
+		shortened.Overrides = nil
+		shortened.Recurrence.Count = 3
+		require.NoError(t, db.EventHandler.Upsert(ctx, &shortened))
+
+		got, err = db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+		assert.Empty(t, got[0].Overrides)
+	})
+
+	t.Run("conflicting override is rejected", func(t *testing.T) {
+		loc := location.New()
+		loc.Name = "club"
+		require.NoError(t, db.LocationHandler.Upsert(ctx, loc))
+
+		other, err := event.New("concert",
+			event.WithStartTime(start.Add(24*time.Hour)),
+			event.WithEndTime(start.Add(26*time.Hour)),
+			event.WithLocationID(loc.ID),
+		)
+		require.NoError(t, err)
+		require.NoError(t, db.EventHandler.Upsert(ctx, other))
+
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+
+		moved := *got[0]
+		moved.LocationID = &loc.ID
+		require.NoError(t, db.EventHandler.Upsert(ctx, &moved))
+
+		err = db.EventHandler.OverrideOccurrence(ctx, ev.ID, event.Override{
+			RecurrenceID: start,
+			StartTime:    start.Add(24 * time.Hour),
+			EndTime:      conversion.TimeP(start.Add(25 * time.Hour)),
+		})
+
+		var conflictErr *event.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+		assert.ElementsMatch(t, []string{ev.ID, other.ID}, []string{conflictErr.Conflicts[0].EventID, conflictErr.Conflicts[0].OtherEventID})
+
+		got, err = db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+		assert.Empty(t, got[0].Overrides)
+	})
+}
+
+func Test_EventFeesIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	a := artist.New()
+	b := artist.New()
+
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a, b))
+
+	due := time.Now().UTC().AddDate(0, 0, -1).Truncate(time.Microsecond)
+
+	ev, err := event.New("festival",
+		event.WithInvitedArtists(
+			event.InvitedArtist{ID: a.ID, Confirmed: true, Fee: &event.Fee{
+				Amount:          20000,
+				Currency:        "EUR",
+				Type:            event.FeeDaily,
+				Days:            2,
+				TravelAllowance: 5000,
+				DueDate:         &due,
+				ContractRefs:    []string{"contracts/festival-a.pdf"},
+			}},
+			event.InvitedArtist{ID: b.ID},
+		),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+	t.Run("fee is stored", func(t *testing.T) {
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+
+		require.Len(t, got, 1)
+		assert.ElementsMatch(t, ev.InvitedArtists, got[0].InvitedArtists)
+	})
+
+	t.Run("upsert without fee keeps it", func(t *testing.T) {
+		ev.InvitedArtists[0].Fee = nil
+		require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+		got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+
+		for _, ia := range got[0].InvitedArtists {
+			if ia.ID == a.ID {
+				require.NotNil(t, ia.Fee)
+				assert.Equal(t, int64(45000), ia.Fee.Total())
+			}
+		}
+	})
+
+	t.Run("setting fee works", func(t *testing.T) {
+		require.NoError(t, db.EventHandler.SetFee(ctx, ev.ID, b.ID, &event.Fee{
+			Amount:   10000,
+			Currency: "EUR",
+			Status:   event.PaymentPaid,
+		}))
+
+		t.Run("unknown invitation", func(t *testing.T) {
+			err := db.EventHandler.SetFee(ctx, ev.ID, uuid.NewString(), &event.Fee{Amount: 1, Currency: "EUR"})
+			require.ErrorIs(t, err, core.ErrNotFound)
+		})
+
+		t.Run("invalid fee", func(t *testing.T) {
+			err := db.EventHandler.SetFee(ctx, ev.ID, b.ID, &event.Fee{Amount: 1, Currency: "euro"})
+			require.ErrorIs(t, err, core.ErrInvalidInput)
+		})
+	})
+
+	t.Run("totals are aggregated", func(t *testing.T) {
+		totals, err := db.EventHandler.FeeTotalsByEvent(ctx, event.FeeFilter{EventIDs: []string{ev.ID}})
+		require.NoError(t, err)
+
+		require.Len(t, totals, 1)
+		assert.Equal(t, event.FeeTotal{
+			ID:          ev.ID,
+			Currency:    "EUR",
+			Fees:        50000,
+			Allowances:  5000,
+			Paid:        10000,
+			Outstanding: 45000,
+			Overdue:     45000,
+		}, totals[0])
+
+		totals, err = db.EventHandler.FeeTotalsByArtist(ctx, event.FeeFilter{ArtistIDs: []string{b.ID}})
+		require.NoError(t, err)
+
+		require.Len(t, totals, 1)
+		assert.Equal(t, int64(10000), totals[0].Paid)
+	})
+
+	t.Run("clearing fee works", func(t *testing.T) {
+		require.NoError(t, db.EventHandler.SetFee(ctx, ev.ID, b.ID, nil))
+
+		totals, err := db.EventHandler.FeeTotalsByArtist(ctx, event.FeeFilter{ArtistIDs: []string{b.ID}})
+		require.NoError(t, err)
+
+		assert.Empty(t, totals)
+	})
+}
+
+func Test_EventBudgetIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	a := artist.New()
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+	ev, err := event.New("festival", event.WithInvitedArtists(event.InvitedArtist{ID: a.ID, Fee: &event.Fee{
+		Amount:          30000,
+		Currency:        "EUR",
+		TravelAllowance: 8000,
+		Status:          event.PaymentPaid,
+	}}))
+	require.NoError(t, err)
+
+	other, err := event.New("opening")
+	require.NoError(t, err)
+
+	require.NoError(t, db.EventHandler.Upsert(ctx, ev, other))
+
+	actual := int64(13000)
+	venue := &event.BudgetLine{EventID: ev.ID, Category: event.BudgetVenue, Planned: 100000, Currency: "EUR"}
+	shipping := &event.BudgetLine{
+		EventID:     ev.ID,
+		Category:    event.BudgetShipping,
+		Description: "spedition of sculptures",
+		Planned:     12000,
+		Actual:      &actual,
+		Currency:    "EUR",
+	}
+	travel := &event.BudgetLine{EventID: ev.ID, Category: event.BudgetTravel, Planned: 2000, Actual: &actual, Currency: "EUR"}
+
+	require.NoError(t, db.EventHandler.UpsertBudgetLines(ctx, venue, shipping, travel))
+
+	t.Run("budget lines are stored", func(t *testing.T) {
+		lines, err := db.EventHandler.BudgetLines(ctx, ev.ID)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []event.BudgetLine{*venue, *shipping, *travel}, lines)
+	})
+
+	t.Run("line of unknown event is rejected", func(t *testing.T) {
+		err := db.EventHandler.UpsertBudgetLines(ctx, &event.BudgetLine{
+			EventID:  uuid.NewString(),
+			Category: event.BudgetVenue,
+			Currency: "EUR",
+		})
+		require.ErrorIs(t, err, core.ErrNotFound)
+
+		t.Run("moving a line to another event", func(t *testing.T) {
+			moved := *venue
+			moved.EventID = other.ID
+
+			require.ErrorIs(t, db.EventHandler.UpsertBudgetLines(ctx, &moved), core.ErrNotFound)
+		})
+	})
+
+	t.Run("costs are aggregated", func(t *testing.T) {
+		costs, err := db.EventHandler.Costs(ctx, ev.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []event.Cost{
+			{EventID: ev.ID, Category: event.BudgetArtistFees, Currency: "EUR", Planned: 30000, Actual: 30000},
+			{EventID: ev.ID, Category: event.BudgetShipping, Currency: "EUR", Planned: 12000, Actual: 13000},
+			{EventID: ev.ID, Category: event.BudgetTravel, Currency: "EUR", Planned: 10000, Actual: 21000},
+			{EventID: ev.ID, Category: event.BudgetVenue, Currency: "EUR", Planned: 100000, Actual: 0},
+		}, costs)
+	})
+
+	t.Run("deleting a line works", func(t *testing.T) {
+		require.NoError(t, db.EventHandler.DeleteBudgetLine(ctx, venue.ID))
+		require.ErrorIs(t, db.EventHandler.DeleteBudgetLine(ctx, venue.ID), core.ErrNotFound)
+
+		lines, err := db.EventHandler.BudgetLines(ctx, ev.ID)
+		require.NoError(t, err)
+
+		assert.Len(t, lines, 2)
+	})
+}
+
+func Test_EventPublicationIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	var (
+		now       = time.Now().UTC().Truncate(time.Second)
+		yesterday = now.Add(-24 * time.Hour)
+		tomorrow  = now.Add(24 * time.Hour)
+	)
+
+	a := artist.New()
+	a.ArtistName = "public"
+	a.BioEnglish = "bio"
+	a.Socials.Instagram = "insta"
+	a.Email = "public@example.com"
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+	open, err := event.New("open", event.WithPublication(nil, nil), event.WithInvitedArtists(event.InvitedArtist{ID: a.ID, Confirmed: true}))
+	require.NoError(t, err)
+
+	window, err := event.New("window", event.WithPublication(&yesterday, &tomorrow))
+	require.NoError(t, err)
+
+	expired, err := event.New("expired", event.WithPublication(nil, &yesterday))
+	require.NoError(t, err)
+
+	scheduled, err := event.New("scheduled", event.WithPublication(&tomorrow, nil))
+	require.NoError(t, err)
+
+	unpublished, err := event.New("unpublished")
+	require.NoError(t, err)
+
+	require.NoError(t, db.EventHandler.Upsert(ctx, open, window, expired, scheduled, unpublished))
+
+	t.Run("publication is stored", func(t *testing.T) {
+		got, err := db.EventHandler.Get(ctx, event.ByID(window.ID))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		assert.True(t, got[0].Published)
+		assert.Equal(t, &yesterday, got[0].PublishFrom)
+		assert.Equal(t, &tomorrow, got[0].PublishUntil)
+	})
+
+	t.Run("only events within their window are published", func(t *testing.T) {
+		got, err := db.EventHandler.Get(ctx, event.PublishedAt(now))
+		require.NoError(t, err)
+
+		var names []string
+		for _, ev := range got {
+			names = append(names, ev.Name)
+		}
+
+		assert.ElementsMatch(t, []string{"open", "window"}, names)
+	})
+
+	t.Run("profiles require consent", func(t *testing.T) {
+		profiles, err := db.ArtistHandler.Profiles(ctx, a.ID)
+		require.NoError(t, err)
+		require.Contains(t, profiles, a.ID)
+
+		assert.Equal(t, &artist.Profile{ID: a.ID, Name: "public"}, profiles[a.ID])
+
+		require.NoError(t, db.ArtistHandler.RecordConsent(ctx, &artist.Consent{
+			ArtistID: a.ID,
+			Purpose:  artist.ConsentPublishBio,
+			Granted:  true,
+			Method:   artist.ConsentForm,
+		}))
+
+		profiles, err = db.ArtistHandler.Profiles(ctx, a.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, "bio", profiles[a.ID].BioEnglish)
+		assert.Empty(t, profiles[a.ID].Socials)
+	})
+
+	t.Run("full names require consent", func(t *testing.T) {
+		legal := artist.New()
+		legal.FirstName = "Ada"
+		legal.LastName = "Lovelace"
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, legal))
+
+		profiles, err := db.ArtistHandler.Profiles(ctx, legal.ID)
+		require.NoError(t, err)
+		assert.Empty(t, profiles)
+
+		require.NoError(t, db.ArtistHandler.RecordConsent(ctx, &artist.Consent{
+			ArtistID: legal.ID,
+			Purpose:  artist.ConsentPublishProfile,
+			Granted:  true,
+			Method:   artist.ConsentForm,
+		}))
+
+		profiles, err = db.ArtistHandler.Profiles(ctx, legal.ID)
+		require.NoError(t, err)
+		require.Contains(t, profiles, legal.ID)
+		assert.Equal(t, "Ada Lovelace", profiles[legal.ID].Name)
+	})
+
+	t.Run("anonymized artists have no profile", func(t *testing.T) {
+		require.NoError(t, db.ArtistHandler.Anonymize(ctx, a.ID))
+
+		profiles, err := db.ArtistHandler.Profiles(ctx, a.ID)
+		require.NoError(t, err)
+
+		assert.Empty(t, profiles)
+	})
+}
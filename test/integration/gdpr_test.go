@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+)
+
+func Test_ArtistPersonalDataIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	a := &artist.Artist{
+		ID:         uuid.NewString(),
+		FirstName:  "first",
+		LastName:   "last",
+		ArtistName: "artist",
+		Pronouns:   []string{"they", "them"},
+		Origin: artist.Origin{
+			DateOfBirth:  time.Date(1990, 4, 1, 0, 0, 0, 0, time.UTC),
+			PlaceOfBirth: "Leipzig",
+			Nationality:  "de",
+		},
+		Email: "artist@example.com",
+	}
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+	start := time.Date(2023, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	ev, err := event.New("festival",
+		event.WithStartTime(start),
+		event.WithEndTime(start.Add(4*time.Hour)),
+		event.WithInvitedArtists(event.InvitedArtist{ID: a.ID, Confirmed: true, Fee: &event.Fee{Amount: 50000, Currency: "EUR"}}),
+		event.WithSlots(event.Slot{ArtistID: a.ID, StartTime: start, EndTime: start.Add(time.Hour), Stage: "main"}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+	t.Run("export contains everything linked to the artist", func(t *testing.T) {
+		data, err := db.ArtistHandler.ExportPersonalData(ctx, a.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, a.ID, data.Artist.ID)
+		assert.Equal(t, a.Email, data.Artist.Email)
+		assert.Equal(t, a.Pronouns, data.Artist.Pronouns)
+		require.NotNil(t, data.Artist.DateOfBirth)
+		assert.Equal(t, a.Origin.DateOfBirth, *data.Artist.DateOfBirth)
+
+		require.Len(t, data.Invitations, 1)
+		assert.Equal(t, ev.ID, data.Invitations[0].EventID)
+		assert.True(t, data.Invitations[0].Confirmed)
+		require.NotNil(t, data.Invitations[0].Fee)
+		assert.Equal(t, int64(50000), data.Invitations[0].Fee.Amount)
+
+		require.Len(t, data.Slots, 1)
+		assert.Equal(t, "main", data.Slots[0].Stage)
+
+		assert.NotEmpty(t, data.Changes)
+	})
+
+	t.Run("deleted artist is exported", func(t *testing.T) {
+		require.NoError(t, db.ArtistHandler.DeleteByID(ctx, a.ID))
+
+		data, err := db.ArtistHandler.ExportPersonalData(ctx, a.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, a.Email, data.Artist.Email)
+		assert.NotNil(t, data.Artist.DeletedAt)
+	})
+
+	t.Run("unknown artist is not found", func(t *testing.T) {
+		_, err := db.ArtistHandler.ExportPersonalData(ctx, uuid.NewString())
+		require.ErrorIs(t, err, core.ErrNotFound)
+	})
+
+	t.Run("anonymizing scrubs personal data", func(t *testing.T) {
+		require.NoError(t, db.ArtistHandler.Anonymize(ctx, a.ID))
+
+		data, err := db.ArtistHandler.ExportPersonalData(ctx, a.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, artist.ExportedArtist{
+			ID:           a.ID,
+			FirstName:    artist.AnonymizedFirstName,
+			LastName:     artist.AnonymizedLastName,
+			CreatedAt:    data.Artist.CreatedAt,
+			UpdatedAt:    data.Artist.UpdatedAt,
+			DeletedAt:    data.Artist.DeletedAt,
+			AnonymizedAt: data.Artist.AnonymizedAt,
+		}, data.Artist)
+		assert.NotNil(t, data.Artist.AnonymizedAt)
+
+		t.Run("historical events are kept", func(t *testing.T) {
+			assert.Len(t, data.Invitations, 1)
+			assert.Len(t, data.Slots, 1)
+
+			got, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+			require.NoError(t, err)
+			assert.Len(t, got[0].InvitedArtists, 1)
+		})
+
+		t.Run("anonymizing again does nothing", func(t *testing.T) {
+			require.NoError(t, db.ArtistHandler.Anonymize(ctx, a.ID))
+		})
+
+		t.Run("restoring personal data is rejected", func(t *testing.T) {
+			err := db.ArtistHandler.Upsert(ctx, a)
+			require.ErrorIs(t, err, artist.ErrAnonymized)
+		})
+	})
+
+	t.Run("anonymizing unknown artist is not found", func(t *testing.T) {
+		require.ErrorIs(t, db.ArtistHandler.Anonymize(ctx, uuid.NewString()), core.ErrNotFound)
+		require.ErrorIs(t, db.ArtistHandler.Anonymize(ctx, "foo"), core.ErrInvalidUUID)
+	})
+}
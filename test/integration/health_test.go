@@ -0,0 +1,34 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HealthIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	t.Run("migrations are up to date", func(t *testing.T) {
+		status, err := db.MigrationStatus(ctx)
+		require.NoError(t, err)
+
+		assert.NotZero(t, status.Expected)
+		assert.Equal(t, status.Expected, status.Current)
+		assert.True(t, status.UpToDate())
+	})
+
+	t.Run("pool statistics are available", func(t *testing.T) {
+		stat := db.PoolStat()
+		require.NotNil(t, stat)
+
+		assert.NotZero(t, stat.MaxConns())
+	})
+}
@@ -74,6 +74,45 @@ func Test_LocationsIntegration(t *testing.T) {
 		})
 	})
 
+	t.Run("address is stored", func(t *testing.T) {
+		loc := location.New()
+		loc.Name = "Club"
+		loc.Address = location.Address{
+			Street:  "Street 1",
+			Zip:     "12345",
+			City:    "Berlin",
+			Country: "Germany",
+		}
+
+		require.NoError(t, db.LocationHandler.Upsert(ctx, loc))
+
+		locs, err := db.LocationHandler.Get(ctx, location.ByID(loc.ID))
+		require.NoError(t, err)
+
+		require.Len(t, locs, 1)
+		assert.Equal(t, loc, locs[0])
+		assert.Equal(t, "Street 1, 12345 Berlin, Germany", locs[0].Address.String())
+	})
+
+	t.Run("locations are paginated by ID", func(t *testing.T) {
+		all, err := db.LocationHandler.Get(ctx, location.After("", 100))
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(all), 2)
+
+		first, err := db.LocationHandler.Get(ctx, location.After("", 1))
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, all[0].ID, first[0].ID)
+
+		second, err := db.LocationHandler.Get(ctx, location.After(first[0].ID, 1))
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, all[1].ID, second[0].ID)
+
+		_, err = db.LocationHandler.Get(ctx, location.After(all[len(all)-1].ID, 1))
+		assert.ErrorIs(t, err, core.ErrNotFound)
+	})
+
 	t.Run("deleting location works", func(t *testing.T) {
 		t.Run("invalid ID throws error", func(t *testing.T) {
 			require.ErrorIs(t, db.LocationHandler.DeleteByID(ctx, "foo"), core.ErrInvalidUUID)
@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/location"
+	"github.com/obitech/artist-db/internal/database/outbox"
+)
+
+type recordingSink struct {
+	mu      sync.Mutex
+	changes []core.Change
+	err     error
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Send(_ context.Context, changes ...core.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.changes = append(s.changes, changes...)
+	return nil
+}
+
+func (s *recordingSink) received() []core.Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]core.Change(nil), s.changes...)
+}
+
+func (s *recordingSink) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+func Test_OutboxIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, conn, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	pending := func(t *testing.T) int {
+		var n int
+		require.NoError(t, conn.QueryRow(ctx, "SELECT count(*) FROM outbox WHERE dispatched_at IS NULL").Scan(&n))
+		return n
+	}
+
+	sink := &recordingSink{}
+
+	db, err := database.NewDatabase(ctx, os.Getenv("TEST_DB_CONN_STRING"),
+		database.WithOutboxSinks(sink),
+		database.WithOutboxRelay(outbox.RelayConfig{Interval: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	a := artist.New()
+	a.FirstName = "Bob"
+	a.LastName = "Ross"
+
+	t.Run("committed changes are relayed in order", func(t *testing.T) {
+		loc := location.New()
+		loc.Name = "Somewhere"
+
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+		require.NoError(t, db.LocationHandler.Upsert(ctx, loc))
+		require.NoError(t, db.ArtistHandler.DeleteByID(ctx, a.ID))
+
+		require.Eventually(t, func() bool { return len(sink.received()) == 3 }, 5*time.Second, 10*time.Millisecond)
+
+		assert.Equal(t, []core.Change{
+			{Entity: core.EntityArtist, Action: core.ActionUpsert, ID: a.ID},
+			{Entity: core.EntityLocation, Action: core.ActionUpsert, ID: loc.ID},
+			{Entity: core.EntityArtist, Action: core.ActionDelete, ID: a.ID},
+		}, sink.received())
+		assert.Zero(t, pending(t))
+	})
+
+	t.Run("rolled back changes are not recorded", func(t *testing.T) {
+		var before int
+		require.NoError(t, conn.QueryRow(ctx, "SELECT count(*) FROM outbox").Scan(&before))
+
+		invalid := artist.New()
+		invalid.ID = "not-a-uuid"
+
+		require.Error(t, db.ArtistHandler.Upsert(ctx, artist.New(), invalid))
+		require.ErrorIs(t, db.ArtistHandler.DeleteByID(ctx, artist.New().ID), core.ErrNotFound)
+
+		var after int
+		require.NoError(t, conn.QueryRow(ctx, "SELECT count(*) FROM outbox").Scan(&after))
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("changes stay pending while a sink fails", func(t *testing.T) {
+		sink.setErr(errors.New("sink down"))
+
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, 1, pending(t))
+
+		sink.setErr(nil)
+
+		require.Eventually(t, func() bool { return pending(t) == 0 }, 5*time.Second, 10*time.Millisecond)
+		assert.Len(t, sink.received(), 4)
+	})
+
+	t.Run("invitation changes are relayed when they change", func(t *testing.T) {
+		b, c := artist.New(), artist.New()
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, b, c))
+
+		ev, err := event.New("Invitations", event.WithInvitedArtists(
+			event.InvitedArtist{ID: b.ID},
+			event.InvitedArtist{ID: c.ID},
+		))
+		require.NoError(t, err)
+		require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+		// Unchanged invitations aren't relayed again, removed ones are.
+		ev.InvitedArtists = event.InvitedArtists{{ID: b.ID, Confirmed: true}}
+		require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+		require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+
+		var invitations []core.Change
+		require.Eventually(t, func() bool {
+			invitations = nil
+			for _, change := range sink.received() {
+				if change.Entity == core.EntityInvitation && change.EventID == ev.ID {
+					invitations = append(invitations, change)
+				}
+			}
+
+			return len(invitations) == 4
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.ElementsMatch(t, []core.Change{
+			{Entity: core.EntityInvitation, Action: core.ActionUpsert, ID: b.ID, EventID: ev.ID},
+			{Entity: core.EntityInvitation, Action: core.ActionUpsert, ID: c.ID, EventID: ev.ID},
+		}, invitations[:2])
+		assert.ElementsMatch(t, []core.Change{
+			{Entity: core.EntityInvitation, Action: core.ActionUpsert, ID: b.ID, EventID: ev.ID, Confirmed: true},
+			{Entity: core.EntityInvitation, Action: core.ActionDelete, ID: c.ID, EventID: ev.ID},
+		}, invitations[2:])
+
+		stored, err := db.EventHandler.Get(ctx, event.ByID(ev.ID))
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		assert.Equal(t, ev.InvitedArtists, stored[0].InvitedArtists)
+	})
+}
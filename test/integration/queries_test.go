@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database/core"
+)
+
+func Test_PersistedQueriesIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	const (
+		hash  = "5e8c5b2c0f5b8d0f6a3b1b0f9f0c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"
+		query = "{ getArtists { id } }"
+	)
+
+	_, err := db.QueryHandler.Get(ctx, hash)
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	require.NoError(t, db.QueryHandler.Add(ctx, hash, query))
+
+	// Adding again leaves the query unchanged.
+	require.NoError(t, db.QueryHandler.Add(ctx, hash, "{ getLocations { id } }"))
+
+	got, err := db.QueryHandler.Get(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, query, got)
+
+	// Only the most recently used queries are kept.
+	const other = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"
+	require.NoError(t, db.QueryHandler.Add(ctx, other, "{ getLocations { id } }"))
+
+	pruned, err := db.QueryHandler.Prune(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+
+	_, err = db.QueryHandler.Get(ctx, hash)
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	_, err = db.QueryHandler.Get(ctx, other)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/location"
+)
+
+func Test_ReplicasIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	// The primary doubles as replica, which is enough to verify routing.
+	connString := os.Getenv("TEST_DB_CONN_STRING")
+
+	db, err := database.NewDatabase(ctx, connString, database.WithReplicas(100*time.Millisecond, connString))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	loc := location.New()
+	loc.Name = "replicated"
+
+	t.Run("writes and reads are routed", func(t *testing.T) {
+		require.NoError(t, db.LocationHandler.Upsert(ctx, loc))
+
+		res, err := db.LocationHandler.Get(ctx, location.ByID(loc.ID))
+		require.NoError(t, err)
+
+		require.Len(t, res, 1)
+		assert.Equal(t, loc, res[0])
+	})
+
+	t.Run("reads can be routed to the primary", func(t *testing.T) {
+		res, err := db.LocationHandler.Get(core.WithPrimary(ctx), location.ByID(loc.ID))
+		require.NoError(t, err)
+
+		require.Len(t, res, 1)
+		assert.Equal(t, loc, res[0])
+	})
+
+	t.Run("primary is ready", func(t *testing.T) {
+		require.NoError(t, db.Ready(ctx))
+	})
+}
@@ -0,0 +1,216 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/event"
+	"github.com/obitech/artist-db/internal/database/retention"
+)
+
+func Test_ArtistConsentIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	a := &artist.Artist{ID: uuid.NewString(), FirstName: "first", LastName: "last"}
+	require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+	granted := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, db.ArtistHandler.RecordConsent(ctx, &artist.Consent{
+		ArtistID:   a.ID,
+		Purpose:    artist.ConsentPublishBio,
+		Granted:    true,
+		Method:     artist.ConsentForm,
+		Note:       "signed at registration",
+		RecordedAt: granted,
+	}))
+
+	require.NoError(t, db.ArtistHandler.RecordConsent(ctx, &artist.Consent{
+		ArtistID:   a.ID,
+		Purpose:    artist.ConsentPublishBio,
+		Granted:    false,
+		Method:     artist.ConsentEmail,
+		RecordedAt: granted.Add(24 * time.Hour),
+	}))
+
+	t.Run("consents are returned latest first", func(t *testing.T) {
+		consents, err := db.ArtistHandler.Consents(ctx, a.ID)
+		require.NoError(t, err)
+		require.Len(t, consents, 2)
+
+		assert.False(t, consents[0].Granted)
+		assert.Equal(t, artist.ConsentEmail, consents[0].Method)
+		assert.Equal(t, granted.Add(24*time.Hour), consents[0].RecordedAt)
+
+		assert.True(t, consents[1].Granted)
+		assert.Equal(t, artist.ConsentPublishBio, consents[1].Purpose)
+		assert.Equal(t, "signed at registration", consents[1].Note)
+	})
+
+	t.Run("consents are exported", func(t *testing.T) {
+		data, err := db.ArtistHandler.ExportPersonalData(ctx, a.ID)
+		require.NoError(t, err)
+
+		require.Len(t, data.Consents, 2)
+		assert.True(t, data.Consents[0].Granted)
+	})
+
+	t.Run("unknown artist", func(t *testing.T) {
+		err := db.ArtistHandler.RecordConsent(ctx, &artist.Consent{
+			ArtistID: uuid.NewString(),
+			Purpose:  artist.ConsentStoreData,
+			Method:   artist.ConsentVerbal,
+		})
+		assert.ErrorIs(t, err, core.ErrNotFound)
+	})
+
+	t.Run("unknown purpose", func(t *testing.T) {
+		err := db.ArtistHandler.RecordConsent(ctx, &artist.Consent{
+			ArtistID: a.ID,
+			Purpose:  "sell_data",
+			Method:   artist.ConsentVerbal,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func Test_RetentionIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, conn, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	db, err := database.NewDatabase(ctx, os.Getenv("TEST_DB_CONN_STRING"),
+		database.WithRetention(retention.PurgerConfig{
+			Interval: time.Hour,
+			Policy: retention.Policy{
+				Periods: map[retention.Category]time.Duration{
+					retention.CategoryContact: 3 * 365 * 24 * time.Hour,
+				},
+				DeletedAfter: 30 * 24 * time.Hour,
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	newArtist := func(t *testing.T) *artist.Artist {
+		a := &artist.Artist{
+			ID:         uuid.NewString(),
+			FirstName:  "first",
+			LastName:   "last",
+			Email:      "artist@example.com",
+			Socials:    artist.Socials{Instagram: "@artist"},
+			BioEnglish: "bio",
+		}
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+		return a
+	}
+
+	invite := func(t *testing.T, a *artist.Artist, start time.Time) {
+		ev, err := event.New("event",
+			event.WithStartTime(start),
+			event.WithInvitedArtists(event.InvitedArtist{ID: a.ID}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, db.EventHandler.Upsert(ctx, ev))
+	}
+
+	backdate := func(t *testing.T, column, id string, at time.Time) {
+		_, err := conn.Exec(ctx, "UPDATE artists SET "+column+"=$2 WHERE id=$1", id, at)
+		require.NoError(t, err)
+	}
+
+	longAgo := time.Now().AddDate(-4, 0, 0)
+
+	inactive := newArtist(t)
+	invite(t, inactive, longAgo)
+	backdate(t, "updated_at", inactive.ID, longAgo)
+
+	upcoming := newArtist(t)
+	invite(t, upcoming, time.Now().AddDate(0, 1, 0))
+	backdate(t, "updated_at", upcoming.ID, longAgo)
+
+	deleted := newArtist(t)
+	require.NoError(t, db.ArtistHandler.DeleteByID(ctx, deleted.ID))
+	backdate(t, "deleted_at", deleted.ID, longAgo)
+
+	deletedInvited := newArtist(t)
+	invite(t, deletedInvited, longAgo)
+	require.NoError(t, db.ArtistHandler.DeleteByID(ctx, deletedInvited.ID))
+	backdate(t, "deleted_at", deletedInvited.ID, longAgo)
+
+	res, err := db.Purge(ctx)
+	require.NoError(t, err)
+
+	t.Run("expired contact data is purged", func(t *testing.T) {
+		assert.Equal(t, []string{inactive.ID}, res.Purged[retention.CategoryContact])
+
+		got, err := db.ArtistHandler.Get(ctx, artist.ByID(inactive.ID))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		assert.Empty(t, got[0].Email)
+		assert.Empty(t, got[0].Socials.Instagram)
+		assert.Equal(t, "bio", got[0].BioEnglish)
+	})
+
+	t.Run("upcoming events keep contact data", func(t *testing.T) {
+		got, err := db.ArtistHandler.Get(ctx, artist.ByID(upcoming.ID))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		assert.Equal(t, upcoming.Email, got[0].Email)
+	})
+
+	t.Run("long soft-deleted artists are deleted", func(t *testing.T) {
+		assert.EqualValues(t, 1, res.DeletedArtists)
+
+		var n int
+		require.NoError(t, conn.QueryRow(ctx, "SELECT count(*) FROM artists WHERE id=$1", deleted.ID).Scan(&n))
+		assert.Zero(t, n)
+	})
+
+	t.Run("long soft-deleted artists with invitations are anonymized", func(t *testing.T) {
+		assert.Equal(t, []string{deletedInvited.ID}, res.AnonymizedArtists)
+
+		var (
+			firstName, lastName string
+			email               *string
+			invitations         int
+		)
+
+		require.NoError(t, conn.QueryRow(ctx, "SELECT first_name, last_name, email FROM artists WHERE id=$1", deletedInvited.ID).Scan(&firstName, &lastName, &email))
+		assert.Equal(t, artist.AnonymizedFirstName, firstName)
+		assert.Equal(t, artist.AnonymizedLastName, lastName)
+		assert.Nil(t, email)
+
+		require.NoError(t, conn.QueryRow(ctx, "SELECT count(*) FROM artist_event WHERE artist_id=$1", deletedInvited.ID).Scan(&invitations))
+		assert.Equal(t, 1, invitations)
+	})
+
+	t.Run("purging again does nothing", func(t *testing.T) {
+		res, err := db.Purge(ctx)
+		require.NoError(t, err)
+
+		assert.Empty(t, res.Purged)
+		assert.Empty(t, res.AnonymizedArtists)
+		assert.Zero(t, res.DeletedArtists)
+	})
+}
@@ -48,6 +48,54 @@ func Test_TablesExistsIntegration(t *testing.T) {
 		assert.True(t, exists)
 	})
 
+	t.Run("webhooks exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableWebhooks).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+
+	t.Run("webhook_deliveries exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableWebhookDeliveries).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+
+	t.Run("outbox exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableOutbox).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+
+	t.Run("event_slots exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableEventSlots).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+
+	t.Run("event_overrides exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableEventOverrides).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+
+	t.Run("event_budget_lines exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableEventBudgetLines).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+	t.Run("artist_consents exists", func(t *testing.T) {
+		var exists bool
+		require.NoError(t, conn.QueryRow(ctx, stmt, core.TableArtistConsents).Scan(&exists))
+
+		assert.True(t, exists)
+	})
+
 	// t.Run("artworks exists", func(t *testing.T) {
 	// 	var exists bool
 	// 	require.NoError(t, conn.QueryRow(ctx, stmt, database.TableArtworks).Scan(&exists))
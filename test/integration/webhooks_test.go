@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obitech/artist-db/internal/database"
+	"github.com/obitech/artist-db/internal/database/artist"
+	"github.com/obitech/artist-db/internal/database/core"
+	"github.com/obitech/artist-db/internal/database/webhook"
+)
+
+func Test_WebhooksIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	_, _, teardown := setup(t, ctx)
+	defer teardown(t)
+
+	const secret = "s3cr3t"
+
+	received := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	db, err := database.NewDatabase(ctx, os.Getenv("TEST_DB_CONN_STRING"), database.WithWebhooks(webhook.DispatcherConfig{
+		BaseDelay:            10 * time.Millisecond,
+		AllowInternalTargets: true,
+	}))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	t.Run("upsert and get webhook", func(t *testing.T) {
+		w := webhook.New()
+		w.URL = srv.URL
+		w.Secret = secret
+		w.Entities = []string{core.EntityArtist}
+
+		require.NoError(t, db.WebhookHandler.Upsert(ctx, w))
+
+		webhooks, err := db.WebhookHandler.Get(ctx)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, w.ID, webhooks[0].ID)
+		assert.Equal(t, w.URL, webhooks[0].URL)
+		assert.Equal(t, w.Entities, webhooks[0].Entities)
+		assert.Empty(t, webhooks[0].Actions)
+	})
+
+	t.Run("failed webhook rolls back the others", func(t *testing.T) {
+		valid := webhook.New()
+		valid.URL = srv.URL
+
+		invalid := webhook.New()
+		invalid.ID = "foo"
+		invalid.URL = srv.URL
+
+		err := db.WebhookHandler.Upsert(ctx, valid, invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "uuid")
+
+		webhooks, err := db.WebhookHandler.Get(ctx)
+		require.NoError(t, err)
+		assert.Len(t, webhooks, 1)
+	})
+
+	t.Run("deliver signed change", func(t *testing.T) {
+		a := artist.New()
+		a.FirstName = "Bob"
+		a.LastName = "Ross"
+
+		require.NoError(t, db.ArtistHandler.Upsert(ctx, a))
+
+		select {
+		case r := <-received:
+			body := <-bodies
+			assert.True(t, webhook.Verify(secret, body, r.Header.Get(webhook.HeaderSignature)))
+			assert.Equal(t, "artist.upsert", r.Header.Get(webhook.HeaderEvent))
+			assert.Contains(t, string(body), a.ID)
+		case <-ctx.Done():
+			t.Fatal("webhook not delivered")
+		}
+	})
+
+	t.Run("delivery is logged", func(t *testing.T) {
+		webhooks, err := db.WebhookHandler.Get(ctx)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			deliveries, err := db.WebhookHandler.Deliveries(ctx, webhooks[0].ID, 10)
+			return err == nil && len(deliveries) == 1 && deliveries[0].Succeeded
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("delete webhook", func(t *testing.T) {
+		webhooks, err := db.WebhookHandler.Get(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, db.WebhookHandler.DeleteByID(ctx, webhooks[0].ID))
+
+		_, err = db.WebhookHandler.Get(ctx)
+		assert.ErrorIs(t, err, core.ErrNotFound)
+	})
+}